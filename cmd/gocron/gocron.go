@@ -8,6 +8,8 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	// 内置时区数据库, 任务时区不依赖运行环境的tzdata
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
 
@@ -22,7 +24,7 @@ import (
 )
 
 var (
-	AppVersion           = "1.5.3"
+	AppVersion           = "1.6.0"
 	BuildDate, GitCommit string
 )
 
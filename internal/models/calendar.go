@@ -0,0 +1,153 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 日历日期格式
+const CalendarDateFormat = "2006-01-02"
+
+// 日历
+type Calendar struct {
+	Id        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string    `json:"name" gorm:"type:varchar(32);not null;uniqueIndex"`
+	Remark    string    `json:"remark" gorm:"type:varchar(100);not null;default:''"`
+	CreatedAt time.Time `json:"created" gorm:"column:created;autoCreateTime"`
+	BaseModel `json:"-" gorm:"-"`
+	Dates     []CalendarDate `json:"dates" gorm:"-"`
+}
+
+// 日历中的日期, 开始、结束日期均包含在内, 格式 2006-01-02
+type CalendarDate struct {
+	Id         int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	CalendarId int    `json:"calendar_id" gorm:"not null;index"`
+	StartDate  string `json:"start_date" gorm:"type:varchar(10);not null"`
+	EndDate    string `json:"end_date" gorm:"type:varchar(10);not null"`
+	Summary    string `json:"summary" gorm:"type:varchar(128);not null;default:''"`
+}
+
+// 日期是否在范围内, date格式 2006-01-02
+func (cd CalendarDate) Contains(date string) bool {
+	return date >= cd.StartDate && date <= cd.EndDate
+}
+
+// 新增日历及日期
+func (calendar *Calendar) Create() (insertId int, err error) {
+	err = Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(calendar).Error; err != nil {
+			return err
+		}
+		return replaceCalendarDates(tx, calendar.Id, calendar.Dates)
+	})
+	if err == nil {
+		insertId = calendar.Id
+	}
+
+	return insertId, err
+}
+
+// 更新日历并替换全部日期
+func (calendar *Calendar) UpdateBean(id int) (int64, error) {
+	var rowsAffected int64
+	err := Db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Calendar{}).Where("id = ?", id).
+			Select("name", "remark").
+			Updates(calendar)
+		if result.Error != nil {
+			return result.Error
+		}
+		rowsAffected = result.RowsAffected
+		return replaceCalendarDates(tx, id, calendar.Dates)
+	})
+
+	return rowsAffected, err
+}
+
+func replaceCalendarDates(tx *gorm.DB, calendarId int, dates []CalendarDate) error {
+	if err := tx.Where("calendar_id = ?", calendarId).Delete(&CalendarDate{}).Error; err != nil {
+		return err
+	}
+	if len(dates) == 0 {
+		return nil
+	}
+	for i := range dates {
+		dates[i].Id = 0
+		dates[i].CalendarId = calendarId
+	}
+
+	return tx.CreateInBatches(dates, 100).Error
+}
+
+// 删除日历及日期
+func (calendar *Calendar) Delete(id int) (int64, error) {
+	var rowsAffected int64
+	err := Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("calendar_id = ?", id).Delete(&CalendarDate{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&Calendar{}, id)
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+
+	return rowsAffected, err
+}
+
+func (calendar *Calendar) Detail(id int) (Calendar, error) {
+	c := Calendar{}
+	err := Db.Where("id = ?", id).First(&c).Error
+	if err != nil {
+		return c, err
+	}
+	c.Dates, err = calendar.GetDates(id)
+
+	return c, err
+}
+
+func (calendar *Calendar) GetDates(id int) ([]CalendarDate, error) {
+	list := make([]CalendarDate, 0)
+	err := Db.Where("calendar_id = ?", id).Order("start_date ASC").Find(&list).Error
+
+	return list, err
+}
+
+func (calendar *Calendar) NameExists(name string, id int) (bool, error) {
+	var count int64
+	query := Db.Model(&Calendar{}).Where("name = ?", name)
+	if id > 0 {
+		query = query.Where("id != ?", id)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+func (calendar *Calendar) List(params CommonMap) ([]Calendar, error) {
+	calendar.parsePageAndPageSize(params)
+	list := make([]Calendar, 0)
+	query := Db.Order("id DESC")
+	calendar.parseWhere(query, params)
+	err := query.Limit(calendar.PageSize).Offset(calendar.pageLimitOffset()).Find(&list).Error
+
+	return list, err
+}
+
+func (calendar *Calendar) Total(params CommonMap) (int64, error) {
+	var count int64
+	query := Db.Model(&Calendar{})
+	calendar.parseWhere(query, params)
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// 解析where
+func (calendar *Calendar) parseWhere(query *gorm.DB, params CommonMap) {
+	if len(params) == 0 {
+		return
+	}
+	name, ok := params["Name"]
+	if ok && name.(string) != "" {
+		query.Where("name LIKE ?", "%"+name.(string)+"%")
+	}
+}
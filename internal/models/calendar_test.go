@@ -0,0 +1,80 @@
+package models
+
+import "testing"
+
+func TestCalendarCreateAndUpdateDates(t *testing.T) {
+	setupTestDb(t, &Calendar{}, &CalendarDate{})
+	calendarModel := &Calendar{
+		Name: "holidays",
+		Dates: []CalendarDate{
+			{StartDate: "2024-10-01", EndDate: "2024-10-07", Summary: "National Day"},
+			{StartDate: "2024-01-01", EndDate: "2024-01-01"},
+		},
+	}
+	id, err := calendarModel.Create()
+	if err != nil || id == 0 {
+		t.Fatalf("create failed, id=%d err=%v", id, err)
+	}
+
+	detail, err := calendarModel.Detail(id)
+	if err != nil {
+		t.Fatalf("detail failed: %v", err)
+	}
+	if len(detail.Dates) != 2 || detail.Dates[0].StartDate != "2024-01-01" {
+		t.Fatalf("unexpected dates %+v", detail.Dates)
+	}
+
+	update := &Calendar{Name: "holidays-2025", Dates: []CalendarDate{{StartDate: "2025-01-01", EndDate: "2025-01-01"}}}
+	if _, err := update.UpdateBean(id); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	detail, _ = calendarModel.Detail(id)
+	if detail.Name != "holidays-2025" || len(detail.Dates) != 1 {
+		t.Fatalf("expected dates to be replaced, got %+v", detail)
+	}
+
+	if exists, _ := calendarModel.NameExists("holidays-2025", 0); !exists {
+		t.Fatal("expected calendar name to exist")
+	}
+	if exists, _ := calendarModel.NameExists("holidays-2025", id); exists {
+		t.Fatal("expected own name to be ignored")
+	}
+}
+
+func TestGetCalendarsByTaskIds(t *testing.T) {
+	setupTestDb(t, &Calendar{}, &CalendarDate{}, &TaskCalendar{})
+	holidays := &Calendar{Name: "holidays", Dates: []CalendarDate{{StartDate: "2024-10-01", EndDate: "2024-10-07"}}}
+	holidayId, _ := holidays.Create()
+	workdays := &Calendar{Name: "workdays", Dates: []CalendarDate{{StartDate: "2024-09-29", EndDate: "2024-09-29"}}}
+	workdayId, _ := workdays.Create()
+
+	taskCalendarModel := new(TaskCalendar)
+	err := taskCalendarModel.Add(10, []TaskCalendar{
+		{CalendarId: holidayId, Mode: TaskCalendarExclude},
+		{CalendarId: workdayId, Mode: TaskCalendarInclude},
+	})
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	_ = taskCalendarModel.Add(11, []TaskCalendar{{CalendarId: holidayId, Mode: TaskCalendarExclude}})
+
+	calendarMap, err := taskCalendarModel.GetCalendarsByTaskIds([]int{10, 11, 12})
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if len(calendarMap[10]) != 2 || len(calendarMap[11]) != 1 || len(calendarMap[12]) != 0 {
+		t.Fatalf("unexpected calendar map %+v", calendarMap)
+	}
+	first := calendarMap[10][0]
+	if first.Name != "holidays" || first.Mode != TaskCalendarExclude || len(first.Dates) != 1 {
+		t.Fatalf("unexpected calendar detail %+v", first)
+	}
+
+	if exist, _ := taskCalendarModel.CalendarIdExist(workdayId); !exist {
+		t.Fatal("expected calendar to be referenced")
+	}
+	_ = taskCalendarModel.Remove(10)
+	if exist, _ := taskCalendarModel.CalendarIdExist(workdayId); exist {
+		t.Fatal("expected calendar reference to be removed")
+	}
+}
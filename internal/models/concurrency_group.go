@@ -0,0 +1,149 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ConcurrencyScope int8
+
+// 并发限制的计算范围
+const (
+	ConcurrencyScopeGroup ConcurrencyScope = 1 // 组内所有任务共享
+	ConcurrencyScopeHost  ConcurrencyScope = 2 // 每台主机分别计算
+	ConcurrencyScopeTask  ConcurrencyScope = 3 // 每个任务分别计算
+)
+
+// 并发限制组, 任务可加入多个组, 执行前需在所有组内获得名额
+type ConcurrencyGroup struct {
+	Id             int              `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name           string           `json:"name" gorm:"type:varchar(32);not null;uniqueIndex"`
+	MaxConcurrency int              `json:"max_concurrency" gorm:"type:smallint;not null;default:1"`
+	Scope          ConcurrencyScope `json:"scope" gorm:"type:tinyint;not null;default:1"`
+	Tag            string           `json:"tag" gorm:"type:varchar(32);not null;default:''"` // 该标签的任务自动加入
+	Remark         string           `json:"remark" gorm:"type:varchar(100);not null;default:''"`
+	CreatedAt      time.Time        `json:"created" gorm:"column:created;autoCreateTime"`
+	BaseModel      `json:"-" gorm:"-"`
+}
+
+// 任务加入的并发限制组
+type TaskConcurrencyGroup struct {
+	Id      int `json:"id" gorm:"primaryKey;autoIncrement"`
+	TaskId  int `json:"task_id" gorm:"not null;index"`
+	GroupId int `json:"group_id" gorm:"not null;index"`
+}
+
+func (group *ConcurrencyGroup) Create() (insertId int, err error) {
+	result := Db.Create(group)
+	if result.Error == nil {
+		insertId = group.Id
+	}
+
+	return insertId, result.Error
+}
+
+func (group *ConcurrencyGroup) UpdateBean(id int) (int64, error) {
+	result := Db.Model(&ConcurrencyGroup{}).Where("id = ?", id).
+		Select("name", "max_concurrency", "scope", "tag", "remark").
+		Updates(group)
+	return result.RowsAffected, result.Error
+}
+
+func (group *ConcurrencyGroup) Delete(id int) (int64, error) {
+	result := Db.Delete(&ConcurrencyGroup{}, id)
+	return result.RowsAffected, result.Error
+}
+
+func (group *ConcurrencyGroup) Detail(id int) (ConcurrencyGroup, error) {
+	g := ConcurrencyGroup{}
+	err := Db.Where("id = ?", id).First(&g).Error
+
+	return g, err
+}
+
+func (group *ConcurrencyGroup) NameExists(name string, id int) (bool, error) {
+	var count int64
+	query := Db.Model(&ConcurrencyGroup{}).Where("name = ?", name)
+	if id > 0 {
+		query = query.Where("id != ?", id)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// 获取任务所属的组, 包括按标签自动加入的组
+func (group *ConcurrencyGroup) GetByTask(taskId int, tag string) ([]ConcurrencyGroup, error) {
+	list := make([]ConcurrencyGroup, 0)
+	subQuery := Db.Model(&TaskConcurrencyGroup{}).Select("group_id").Where("task_id = ?", taskId)
+	query := Db.Where("id IN (?)", subQuery)
+	if tag != "" {
+		query = query.Or("tag = ?", tag)
+	}
+	err := query.Order("id ASC").Find(&list).Error
+
+	return list, err
+}
+
+func (group *ConcurrencyGroup) List(params CommonMap) ([]ConcurrencyGroup, error) {
+	group.parsePageAndPageSize(params)
+	list := make([]ConcurrencyGroup, 0)
+	query := Db.Order("id DESC")
+	group.parseWhere(query, params)
+	err := query.Limit(group.PageSize).Offset(group.pageLimitOffset()).Find(&list).Error
+
+	return list, err
+}
+
+func (group *ConcurrencyGroup) Total(params CommonMap) (int64, error) {
+	var count int64
+	query := Db.Model(&ConcurrencyGroup{})
+	group.parseWhere(query, params)
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// 解析where
+func (group *ConcurrencyGroup) parseWhere(query *gorm.DB, params CommonMap) {
+	if len(params) == 0 {
+		return
+	}
+	name, ok := params["Name"]
+	if ok && name.(string) != "" {
+		query.Where("name LIKE ?", "%"+name.(string)+"%")
+	}
+}
+
+func (tg *TaskConcurrencyGroup) Remove(taskId int) error {
+	return Db.Where("task_id = ?", taskId).Delete(&TaskConcurrencyGroup{}).Error
+}
+
+func (tg *TaskConcurrencyGroup) Add(taskId int, groupIds []int) error {
+	err := tg.Remove(taskId)
+	if err != nil {
+		return err
+	}
+	if len(groupIds) == 0 {
+		return nil
+	}
+	list := make([]TaskConcurrencyGroup, len(groupIds))
+	for i, groupId := range groupIds {
+		list[i] = TaskConcurrencyGroup{TaskId: taskId, GroupId: groupId}
+	}
+
+	return Db.Create(&list).Error
+}
+
+// 判断组是否被任务引用
+func (tg *TaskConcurrencyGroup) GroupIdExist(groupId int) (bool, error) {
+	var count int64
+	err := Db.Model(&TaskConcurrencyGroup{}).Where("group_id = ?", groupId).Count(&count).Error
+	return count > 0, err
+}
+
+func (tg *TaskConcurrencyGroup) GetGroupIds(taskId int) ([]int, error) {
+	groupIds := make([]int, 0)
+	err := Db.Model(&TaskConcurrencyGroup{}).Where("task_id = ?", taskId).Order("group_id ASC").Pluck("group_id", &groupIds).Error
+
+	return groupIds, err
+}
@@ -0,0 +1,41 @@
+package models
+
+import "testing"
+
+func TestConcurrencyGroupGetByTask(t *testing.T) {
+	setupTestDb(t, &ConcurrencyGroup{}, &TaskConcurrencyGroup{})
+	explicit := &ConcurrencyGroup{Name: "per-host", MaxConcurrency: 5, Scope: ConcurrencyScopeHost}
+	tagged := &ConcurrencyGroup{Name: "backup", MaxConcurrency: 2, Scope: ConcurrencyScopeGroup, Tag: "backup"}
+	other := &ConcurrencyGroup{Name: "other", MaxConcurrency: 1, Scope: ConcurrencyScopeGroup}
+	for _, group := range []*ConcurrencyGroup{explicit, tagged, other} {
+		if _, err := group.Create(); err != nil {
+			t.Fatalf("create group failed: %v", err)
+		}
+	}
+	taskGroupModel := new(TaskConcurrencyGroup)
+	if err := taskGroupModel.Add(1, []int{explicit.Id}); err != nil {
+		t.Fatalf("add task group failed: %v", err)
+	}
+
+	groups, err := explicit.GetByTask(1, "backup")
+	if err != nil {
+		t.Fatalf("get groups failed: %v", err)
+	}
+	if len(groups) != 2 || groups[0].Id != explicit.Id || groups[1].Id != tagged.Id {
+		t.Fatalf("expected explicit and tagged groups, got %+v", groups)
+	}
+	groups, _ = explicit.GetByTask(2, "")
+	if len(groups) != 0 {
+		t.Fatalf("expected no groups for task without membership, got %+v", groups)
+	}
+
+	if exist, _ := taskGroupModel.GroupIdExist(explicit.Id); !exist {
+		t.Fatal("expected group to be referenced")
+	}
+	if err := taskGroupModel.Remove(1); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	if ids, _ := taskGroupModel.GetGroupIds(1); len(ids) != 0 {
+		t.Fatalf("expected memberships to be removed, got %v", ids)
+	}
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 数据源支持的数据库类型, 与gocron自身使用的数据库驱动相同
+var DatasourceEngines = []string{"mysql", "postgres", "sqlite"}
+
+// 数据源, SQL任务引用后在该数据库上执行语句
+type Datasource struct {
+	Id          int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string    `json:"name" gorm:"type:varchar(32);not null;uniqueIndex"`
+	Engine      string    `json:"engine" gorm:"type:varchar(16);not null"`
+	Host        string    `json:"host" gorm:"type:varchar(64);not null;default:''"`
+	Port        int       `json:"port" gorm:"not null;default:0"`
+	User        string    `json:"user" gorm:"type:varchar(64);not null;default:''"`
+	Password    string    `json:"-" gorm:"type:varchar(255);not null;default:''"` // 不返回给前端
+	Database    string    `json:"database" gorm:"type:varchar(255);not null"`     // 数据库名, sqlite为数据库文件路径
+	Remark      string    `json:"remark" gorm:"type:varchar(100);not null;default:''"`
+	HasPassword bool      `json:"has_password" gorm:"-"`
+	CreatedAt   time.Time `json:"created" gorm:"column:created;autoCreateTime"`
+	BaseModel   `json:"-" gorm:"-"`
+}
+
+func (ds *Datasource) Create() (insertId int, err error) {
+	result := Db.Create(ds)
+	if result.Error == nil {
+		insertId = ds.Id
+	}
+
+	return insertId, result.Error
+}
+
+// 更新, keepPassword为true时保留原有的密码
+func (ds *Datasource) UpdateBean(id int, keepPassword bool) (int64, error) {
+	columns := []interface{}{"engine", "host", "port", "user", "database", "remark"}
+	if !keepPassword {
+		columns = append(columns, "password")
+	}
+	result := Db.Model(&Datasource{}).Where("id = ?", id).
+		Select("name", columns...).
+		Updates(ds)
+	return result.RowsAffected, result.Error
+}
+
+func (ds *Datasource) Delete(id int) (int64, error) {
+	result := Db.Delete(&Datasource{}, id)
+	return result.RowsAffected, result.Error
+}
+
+// 详情, 包括密码
+func (ds *Datasource) Detail(id int) (Datasource, error) {
+	d := Datasource{}
+	err := Db.Where("id = ?", id).First(&d).Error
+	d.HasPassword = d.Password != ""
+
+	return d, err
+}
+
+func (ds *Datasource) NameExists(name string, id int) (bool, error) {
+	var count int64
+	query := Db.Model(&Datasource{}).Where("name = ?", name)
+	if id > 0 {
+		query = query.Where("id != ?", id)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+func (ds *Datasource) List(params CommonMap) ([]Datasource, error) {
+	ds.parsePageAndPageSize(params)
+	list := make([]Datasource, 0)
+	query := Db.Order("id DESC")
+	ds.parseWhere(query, params)
+	err := query.Limit(ds.PageSize).Offset(ds.pageLimitOffset()).Find(&list).Error
+	for i := range list {
+		list[i].HasPassword = list[i].Password != ""
+	}
+
+	return list, err
+}
+
+func (ds *Datasource) Total(params CommonMap) (int64, error) {
+	var count int64
+	query := Db.Model(&Datasource{})
+	ds.parseWhere(query, params)
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// 解析where
+func (ds *Datasource) parseWhere(query *gorm.DB, params CommonMap) {
+	if len(params) == 0 {
+		return
+	}
+	name, ok := params["Name"]
+	if ok && name.(string) != "" {
+		query.Where("name LIKE ?", "%"+name.(string)+"%")
+	}
+}
+
+// Open 连接数据源, 使用完需关闭
+func (ds Datasource) Open() (*sql.DB, error) {
+	dialector, err := newDialector(ds.Engine, buildDSN(ds.Engine, ds.User, ds.Password, ds.Host, ds.Port, ds.Database, "utf8mb4"))
+	if err != nil {
+		return nil, err
+	}
+	// sqlite连接不存在的文件时会创建新的数据库
+	if ds.Engine == "sqlite" {
+		if _, err = os.Stat(ds.Database); err != nil {
+			return nil, fmt.Errorf("数据库文件不存在: %s", ds.Database)
+		}
+	}
+	// 连接在执行时按超时时间建立
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent), DisableAutomaticPing: true})
+	if err != nil {
+		return nil, err
+	}
+
+	return db.DB()
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDatasourceUpdateKeepsPassword(t *testing.T) {
+	setupTestDb(t, &Datasource{}, &TaskSql{})
+	ds := &Datasource{Name: "report", Engine: "mysql", Host: "db", Port: 3306, User: "cron", Password: "secret", Database: "report"}
+	id, err := ds.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	update := &Datasource{Name: "report", Engine: "postgres", Host: "pg", Port: 5432, User: "cron", Database: "report"}
+	if _, err = update.UpdateBean(id, true); err != nil {
+		t.Fatal(err)
+	}
+	detail, err := ds.Detail(id)
+	if err != nil || detail.Password != "secret" || !detail.HasPassword || detail.Engine != "postgres" || detail.Port != 5432 {
+		t.Fatalf("expected password to be kept, got %+v err=%v", detail, err)
+	}
+	if _, err = update.UpdateBean(id, false); err != nil {
+		t.Fatal(err)
+	}
+	list, err := ds.List(CommonMap{})
+	if err != nil || len(list) != 1 || list[0].Password != "" || list[0].HasPassword {
+		t.Fatalf("expected password to be cleared, got %+v err=%v", list, err)
+	}
+
+	taskSqlModel := new(TaskSql)
+	if exist, _ := taskSqlModel.DatasourceIdExist(id); exist {
+		t.Fatal("expected datasource not to be referenced")
+	}
+	if err = taskSqlModel.Save(1, TaskSql{DatasourceId: id, Statements: "select 1"}); err != nil {
+		t.Fatal(err)
+	}
+	if exist, _ := taskSqlModel.DatasourceIdExist(id); !exist {
+		t.Fatal("expected datasource to be referenced")
+	}
+	def, err := taskSqlModel.GetByTaskId(1)
+	if err != nil || def == nil || def.Statements != "select 1" {
+		t.Fatalf("unexpected definition %+v err=%v", def, err)
+	}
+	if err = taskSqlModel.Remove(1); err != nil {
+		t.Fatal(err)
+	}
+	if def, err = taskSqlModel.GetByTaskId(1); err != nil || def != nil {
+		t.Fatalf("expected definition to be removed, got %+v err=%v", def, err)
+	}
+}
+
+func TestDatasourceOpenSqlite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.db")
+	if _, err := (Datasource{Engine: "sqlite", Database: path}).Open(); err == nil {
+		t.Fatal("expected error for missing sqlite file")
+	}
+	if _, err := (Datasource{Engine: "oracle", Database: "x"}).Open(); err == nil {
+		t.Fatal("expected error for unsupported engine")
+	}
+}
@@ -4,6 +4,12 @@ import (
 	"gorm.io/gorm"
 )
 
+// SSH认证方式
+const (
+	HostSshAuthPassword = 1 // 密码
+	HostSshAuthKey      = 2 // 私钥
+)
+
 // 主机
 type Host struct {
 	Id        int16  `json:"id" gorm:"primaryKey;autoIncrement;type:smallint"`
@@ -11,8 +17,16 @@ type Host struct {
 	Alias     string `json:"alias" gorm:"type:varchar(32);not null;default:''"`
 	Port      int    `json:"port" gorm:"not null;default:5921"`
 	Remark    string `json:"remark" gorm:"type:varchar(100);not null;default:''"`
+	Labels    string `json:"labels" gorm:"type:varchar(255);not null;default:''"` // 主机标签, 格式 key=value,key=value
+	SshPort   int    `json:"ssh_port" gorm:"not null;default:22"`
+	SshUser   string `json:"ssh_user" gorm:"type:varchar(64);not null;default:''"`
+	SshAuth   int8   `json:"ssh_auth" gorm:"type:tinyint;not null;default:1"`
+	SshPass   string `json:"-" gorm:"type:varchar(255);not null;default:''"`         // 密码认证的密码, 私钥认证时为私钥的密码
+	SshKey    string `json:"-" gorm:"type:text"`                                     // PEM格式的私钥
+	HostKey   string `json:"host_key" gorm:"type:varchar(1024);not null;default:''"` // 主机公钥, 用于校验主机身份
 	BaseModel `json:"-" gorm:"-"`
 	Selected  bool `json:"-" gorm:"-"`
+	HasSecret bool `json:"has_ssh_secret" gorm:"-"` // 是否已保存SSH密码或私钥, 密码、私钥不返回给前端
 }
 
 // 新增
@@ -25,9 +39,14 @@ func (host *Host) Create() (insertId int16, err error) {
 	return insertId, result.Error
 }
 
-func (host *Host) UpdateBean(id int16) (int64, error) {
+// 更新, keepSecret为true时保留原有的SSH密码、私钥
+func (host *Host) UpdateBean(id int16, keepSecret bool) (int64, error) {
+	columns := []interface{}{"alias", "port", "remark", "labels", "ssh_port", "ssh_user", "ssh_auth", "host_key"}
+	if !keepSecret {
+		columns = append(columns, "ssh_pass", "ssh_key")
+	}
 	result := Db.Model(&Host{}).Where("id = ?", id).
-		Select("name", "alias", "port", "remark").
+		Select("name", columns...).
 		Updates(host)
 	return result.RowsAffected, result.Error
 }
@@ -42,6 +61,11 @@ func (host *Host) Update(id int, data CommonMap) (int64, error) {
 	return result.RowsAffected, result.Error
 }
 
+// 按主机名更新标签
+func (host *Host) UpdateLabelsByName(name string, labels string) error {
+	return Db.Model(&Host{}).Where("name = ?", name).UpdateColumn("labels", labels).Error
+}
+
 // 删除
 func (host *Host) Delete(id int) (int64, error) {
 	result := Db.Delete(&Host{}, id)
@@ -49,7 +73,10 @@ func (host *Host) Delete(id int) (int64, error) {
 }
 
 func (host *Host) Find(id int) error {
-	return Db.First(host, id).Error
+	err := Db.First(host, id).Error
+	host.HasSecret = host.SshPass != "" || host.SshKey != ""
+
+	return err
 }
 
 func (host *Host) NameExists(name string, id int16) (bool, error) {
@@ -68,6 +95,9 @@ func (host *Host) List(params CommonMap) ([]Host, error) {
 	query := Db.Order("id DESC")
 	host.parseWhere(query, params)
 	err := query.Limit(host.PageSize).Offset(host.pageLimitOffset()).Find(&list).Error
+	for i := range list {
+		list[i].HasSecret = list[i].SshPass != "" || list[i].SshKey != ""
+	}
 
 	return list, err
 }
@@ -79,6 +109,24 @@ func (host *Host) AllList() ([]Host, error) {
 	return list, err
 }
 
+// 获取标签匹配选择器的主机, 按ID排序
+func (host *Host) ListByLabels(selector Labels) ([]Host, error) {
+	list := make([]Host, 0)
+	err := Db.Where("labels <> ''").Order("id ASC").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]Host, 0, len(list))
+	for _, item := range list {
+		labels, err := ParseLabels(item.Labels)
+		if err == nil && labels.Match(selector) {
+			matched = append(matched, item)
+		}
+	}
+
+	return matched, nil
+}
+
 func (host *Host) Total(params CommonMap) (int64, error) {
 	var count int64
 	query := Db.Model(&Host{})
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 主机标签, 文本格式为 key=value,key=value
+// 任务的标签选择器使用相同格式, 主机包含选择器中的所有标签时匹配
+type Labels map[string]string
+
+var (
+	labelKeyPattern   = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.\-/]{0,62}$`)
+	labelValuePattern = regexp.MustCompile(`^[A-Za-z0-9_.\-]{0,63}$`)
+)
+
+// ParseLabels 解析标签文本, 空文本返回空标签
+func ParseLabels(text string) (Labels, error) {
+	labels := make(Labels)
+	for _, item := range strings.Split(text, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || !labelKeyPattern.MatchString(key) || !labelValuePattern.MatchString(value) {
+			return nil, fmt.Errorf("标签格式错误: %s", item)
+		}
+		if _, exists := labels[key]; exists {
+			return nil, fmt.Errorf("标签重复: %s", key)
+		}
+		labels[key] = value
+	}
+
+	return labels, nil
+}
+
+// 按key排序后的标签文本
+func (labels Labels) String() string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	items := make([]string, len(keys))
+	for i, key := range keys {
+		items[i] = key + "=" + labels[key]
+	}
+
+	return strings.Join(items, ",")
+}
+
+// Match 是否包含选择器中的所有标签, 空选择器不匹配任何主机
+func (labels Labels) Match(selector Labels) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for key, value := range selector {
+		if v, ok := labels[key]; !ok || v != value {
+			return false
+		}
+	}
+
+	return true
+}
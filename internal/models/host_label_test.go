@@ -0,0 +1,78 @@
+package models
+
+import "testing"
+
+func TestParseLabels(t *testing.T) {
+	labels, err := ParseLabels(" role=db , env=prod,zone= ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labels.String() != "env=prod,role=db,zone=" {
+		t.Fatalf("unexpected labels %q", labels.String())
+	}
+	if labels, _ = ParseLabels(""); len(labels) != 0 {
+		t.Fatalf("expected empty labels, got %v", labels)
+	}
+	for _, text := range []string{"role", "=db", "role=db,role=web", "role=d b", "role=\"db\""} {
+		if _, err = ParseLabels(text); err == nil {
+			t.Fatalf("expected error for %q", text)
+		}
+	}
+}
+
+func TestLabelsMatch(t *testing.T) {
+	labels := Labels{"role": "db", "env": "prod", "zone": "a"}
+	cases := []struct {
+		selector Labels
+		match    bool
+	}{
+		{Labels{"role": "db"}, true},
+		{Labels{"role": "db", "env": "prod"}, true},
+		{Labels{"role": "db", "env": "test"}, false},
+		{Labels{"dc": ""}, false},
+		{Labels{}, false},
+	}
+	for _, c := range cases {
+		if labels.Match(c.selector) != c.match {
+			t.Fatalf("selector %v expected match=%v", c.selector, c.match)
+		}
+	}
+}
+
+func TestGetHostsBySelector(t *testing.T) {
+	setupTestDb(t, &Host{})
+	for _, host := range []Host{
+		{Id: 1, Name: "db-1", Alias: "db-1", Port: 5921, Labels: "env=prod,role=db"},
+		{Id: 2, Name: "db-2", Alias: "db-2", Port: 5921, Labels: "env=test,role=db"},
+		{Id: 3, Name: "web-1", Alias: "web-1", Port: 5921, Labels: "env=prod,role=web"},
+		{Id: 4, Name: "db-3", Alias: "db-3", Port: 5922, Labels: "env=prod,role=db"},
+		{Id: 5, Name: "plain", Alias: "plain", Port: 5921},
+	} {
+		if _, err := host.Create(); err != nil {
+			t.Fatalf("create host failed: %v", err)
+		}
+	}
+	taskHostModel := new(TaskHost)
+	hosts, err := taskHostModel.GetHostsBySelector(7, "role=db,env=prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 2 || hosts[0].Name != "db-1" || hosts[1].Name != "db-3" || hosts[1].Port != 5922 {
+		t.Fatalf("unexpected hosts %+v", hosts)
+	}
+	if hosts[0].TaskId != 7 || hosts[0].HostId != 1 {
+		t.Fatalf("expected task and host ids to be set, got %+v", hosts[0])
+	}
+
+	// 新主机注册后, 下次获取时匹配
+	hostModel := new(Host)
+	if err = hostModel.UpdateLabelsByName("plain", "env=prod,role=db"); err != nil {
+		t.Fatal(err)
+	}
+	if hosts, _ = taskHostModel.GetHostsBySelector(7, "role=db,env=prod"); len(hosts) != 3 {
+		t.Fatalf("expected relabeled host to match, got %+v", hosts)
+	}
+	if _, err = taskHostModel.GetHostsBySelector(7, "role"); err == nil {
+		t.Fatal("expected invalid selector error")
+	}
+}
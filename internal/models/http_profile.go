@@ -0,0 +1,109 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 重定向策略
+type HttpRedirectPolicy int8
+
+const (
+	HttpRedirectFollow HttpRedirectPolicy = 1 // 跟随重定向, 最多MaxRedirects次
+	HttpRedirectNone   HttpRedirectPolicy = 2 // 不跟随, 返回重定向响应
+)
+
+// HTTP客户端配置, HTTP任务引用后使用配置的证书、代理和重定向策略发送请求
+type HttpProfile struct {
+	Id           int                `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name         string             `json:"name" gorm:"type:varchar(32);not null;uniqueIndex"`
+	CaCert       string             `json:"ca_cert" gorm:"type:text;not null"`                      // PEM格式的CA证书, 追加到系统证书
+	ClientCert   string             `json:"client_cert" gorm:"type:text;not null"`                  // PEM格式的客户端证书
+	ClientKey    string             `json:"-" gorm:"type:text;not null"`                            // PEM格式的客户端私钥, 不返回给前端
+	SkipVerify   int8               `json:"skip_verify" gorm:"type:tinyint;not null;default:0"`     // 是否跳过服务端证书校验
+	ProxyUrl     string             `json:"proxy_url" gorm:"type:varchar(255);not null;default:''"` // 代理地址, 支持http、https、socks5
+	Redirect     HttpRedirectPolicy `json:"redirect" gorm:"type:tinyint;not null;default:1"`        // 重定向策略
+	MaxRedirects int16              `json:"max_redirects" gorm:"type:smallint;not null;default:10"` // 跟随重定向的最大次数
+	Remark       string             `json:"remark" gorm:"type:varchar(100);not null;default:''"`
+	HasClientKey bool               `json:"has_client_key" gorm:"-"`
+	CreatedAt    time.Time          `json:"created" gorm:"column:created;autoCreateTime"`
+	BaseModel    `json:"-" gorm:"-"`
+}
+
+func (profile *HttpProfile) Create() (insertId int, err error) {
+	result := Db.Create(profile)
+	if result.Error == nil {
+		insertId = profile.Id
+	}
+
+	return insertId, result.Error
+}
+
+// 更新, keepKey为true时保留原有的客户端私钥
+func (profile *HttpProfile) UpdateBean(id int, keepKey bool) (int64, error) {
+	columns := []interface{}{"ca_cert", "client_cert", "skip_verify", "proxy_url", "redirect", "max_redirects", "remark"}
+	if !keepKey {
+		columns = append(columns, "client_key")
+	}
+	result := Db.Model(&HttpProfile{}).Where("id = ?", id).
+		Select("name", columns...).
+		Updates(profile)
+	return result.RowsAffected, result.Error
+}
+
+func (profile *HttpProfile) Delete(id int) (int64, error) {
+	result := Db.Delete(&HttpProfile{}, id)
+	return result.RowsAffected, result.Error
+}
+
+// 详情, 包括客户端私钥
+func (profile *HttpProfile) Detail(id int) (HttpProfile, error) {
+	p := HttpProfile{}
+	err := Db.Where("id = ?", id).First(&p).Error
+	p.HasClientKey = p.ClientKey != ""
+
+	return p, err
+}
+
+func (profile *HttpProfile) NameExists(name string, id int) (bool, error) {
+	var count int64
+	query := Db.Model(&HttpProfile{}).Where("name = ?", name)
+	if id > 0 {
+		query = query.Where("id != ?", id)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+func (profile *HttpProfile) List(params CommonMap) ([]HttpProfile, error) {
+	profile.parsePageAndPageSize(params)
+	list := make([]HttpProfile, 0)
+	query := Db.Order("id DESC")
+	profile.parseWhere(query, params)
+	err := query.Limit(profile.PageSize).Offset(profile.pageLimitOffset()).Find(&list).Error
+	for i := range list {
+		list[i].HasClientKey = list[i].ClientKey != ""
+	}
+
+	return list, err
+}
+
+func (profile *HttpProfile) Total(params CommonMap) (int64, error) {
+	var count int64
+	query := Db.Model(&HttpProfile{})
+	profile.parseWhere(query, params)
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// 解析where
+func (profile *HttpProfile) parseWhere(query *gorm.DB, params CommonMap) {
+	if len(params) == 0 {
+		return
+	}
+	name, ok := params["Name"]
+	if ok && name.(string) != "" {
+		query.Where("name LIKE ?", "%"+name.(string)+"%")
+	}
+}
@@ -0,0 +1,41 @@
+package models
+
+import "testing"
+
+func TestHttpProfileUpdateKeepsKey(t *testing.T) {
+	setupTestDb(t, &HttpProfile{}, &TaskHttp{})
+	profile := &HttpProfile{Name: "mtls", ClientCert: "cert", ClientKey: "key", Redirect: HttpRedirectFollow}
+	id, err := profile.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	update := &HttpProfile{Name: "mtls", ClientCert: "new-cert", ProxyUrl: "http://proxy:3128", Redirect: HttpRedirectNone}
+	if _, err = update.UpdateBean(id, true); err != nil {
+		t.Fatal(err)
+	}
+	detail, err := profile.Detail(id)
+	if err != nil || detail.ClientKey != "key" || !detail.HasClientKey || detail.ClientCert != "new-cert" ||
+		detail.Redirect != HttpRedirectNone {
+		t.Fatalf("expected key to be kept, got %+v err=%v", detail, err)
+	}
+	update.ClientKey = "new-key"
+	if _, err = update.UpdateBean(id, false); err != nil {
+		t.Fatal(err)
+	}
+	list, err := profile.List(CommonMap{})
+	if err != nil || len(list) != 1 || list[0].ClientKey != "new-key" || !list[0].HasClientKey {
+		t.Fatalf("expected updated key, got %+v err=%v", list, err)
+	}
+
+	taskHttpModel := new(TaskHttp)
+	if exist, _ := taskHttpModel.ProfileIdExist(id); exist {
+		t.Fatal("expected profile not to be referenced")
+	}
+	if err = taskHttpModel.Save(1, TaskHttp{ProfileId: id}); err != nil {
+		t.Fatal(err)
+	}
+	if exist, _ := taskHttpModel.ProfileIdExist(id); !exist {
+		t.Fatal("expected profile to be referenced")
+	}
+}
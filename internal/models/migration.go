@@ -2,6 +2,9 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/gocronx-team/gocron/internal/modules/logger"
 	"gorm.io/gorm"
@@ -14,6 +17,10 @@ func (migration *Migration) Install(dbName string) error {
 	setting := new(Setting)
 	tables := []interface{}{
 		&User{}, &Task{}, &TaskLog{}, &Host{}, setting, &LoginLog{}, &TaskHost{}, &AgentToken{},
+		&SchedulerLease{}, &SchedulerRequest{}, &Calendar{}, &CalendarDate{}, &TaskCalendar{},
+		&Workflow{}, &WorkflowNode{}, &WorkflowEdge{}, &WorkflowRun{},
+		&ConcurrencyGroup{}, &TaskConcurrencyGroup{}, &TaskBackfill{}, &TaskHostLog{},
+		&TaskHttp{}, &HttpProfile{}, &TaskSql{}, &Datasource{},
 	}
 
 	for _, table := range tables {
@@ -46,7 +53,7 @@ func (migration *Migration) Upgrade(oldVersionId int) {
 		return
 	}
 
-	versionIds := []int{110, 122, 130, 140, 150, 151, 152, 153, 154}
+	versionIds := []int{110, 122, 130, 140, 150, 151, 152, 153, 154, 160}
 	upgradeFuncs := []func(*gorm.DB) error{
 		migration.upgradeFor110,
 		migration.upgradeFor122,
@@ -57,6 +64,7 @@ func (migration *Migration) Upgrade(oldVersionId int) {
 		migration.upgradeFor152,
 		migration.upgradeFor153,
 		migration.upgradeFor154,
+		migration.upgradeFor160,
 	}
 
 	startIndex := -1
@@ -460,6 +468,185 @@ func (m *Migration) upgradeFor154(tx *gorm.DB) error {
 	return nil
 }
 
+// 升级到v1.6.0版本
+func (m *Migration) upgradeFor160(tx *gorm.DB) error {
+	logger.Info("开始升级到v1.6.0")
+
+	// 创建调度器租约表, 用于多实例高可用部署
+	if err := tx.AutoMigrate(&SchedulerLease{}, &SchedulerRequest{}); err != nil {
+		return err
+	}
+
+	// 创建日历表, 任务按节假日、停机日历跳过或限定执行日期
+	if err := tx.AutoMigrate(&Calendar{}, &CalendarDate{}, &TaskCalendar{}); err != nil {
+		return err
+	}
+
+	// 创建工作流表
+	if err := tx.AutoMigrate(&Workflow{}, &WorkflowNode{}, &WorkflowEdge{}, &WorkflowRun{}); err != nil {
+		return err
+	}
+
+	// 创建并发限制组表
+	if err := tx.AutoMigrate(&ConcurrencyGroup{}, &TaskConcurrencyGroup{}); err != nil {
+		return err
+	}
+
+	// 创建任务回填记录表
+	if err := tx.AutoMigrate(&TaskBackfill{}); err != nil {
+		return err
+	}
+
+	// 创建任务主机执行记录表
+	if err := tx.AutoMigrate(&TaskHostLog{}); err != nil {
+		return err
+	}
+
+	// 创建HTTP任务请求定义表、HTTP客户端配置表
+	if err := tx.AutoMigrate(&TaskHttp{}, &HttpProfile{}); err != nil {
+		return err
+	}
+
+	// 创建SQL任务执行定义表、数据源表
+	if err := tx.AutoMigrate(&TaskSql{}, &Datasource{}); err != nil {
+		return err
+	}
+
+	// task表增加字段 misfire_policy, misfire_max_runs, timezone, run_at, once_action,
+	// retry_strategy, retry_max_interval, retry_jitter, retry_on, rerun_interrupted,
+	// sla_max_duration, sla_deadline, ping_token, ping_grace, overlap_policy,
+	// host_strategy, host_selector, rolling_batch, rolling_pause, rolling_max_fail, rolling_fail_rate,
+	// host_success_rule
+	// task_log表增加字段 trigger_type, scheduled_time, workflow_run_id, attempts, overrides, sla_notified,
+	// backfill_id, callback_token, callback
+	// host表增加字段 labels, ssh_port, ssh_user, ssh_auth, ssh_pass, ssh_key, host_key
+	for _, table := range []interface{}{&Task{}, &TaskLog{}, &Host{}} {
+		if err := addMissingColumns(tx, table); err != nil {
+			return err
+		}
+	}
+
+	// 子任务依赖由工作流替代, 已配置的子任务迁移为工作流
+	if err := migrateTaskDependencies(tx); err != nil {
+		return err
+	}
+
+	// 任务日志的执行主机不再保存html
+	if err := migrateTaskLogHostname(tx); err != nil {
+		return err
+	}
+
+	// HTTP任务的请求地址由任务的命令移到请求定义
+	if err := migrateTaskHttpUrl(tx); err != nil {
+		return err
+	}
+
+	logger.Info("已升级到v1.6.0\n")
+
+	return nil
+}
+
+// 已有任务日志的执行主机由<br>分隔改为逗号分隔
+func migrateTaskLogHostname(tx *gorm.DB) error {
+	expr := "TRIM(TRAILING ', ' FROM REPLACE(hostname, '<br>', ', '))"
+	if tx.Dialector.Name() == "sqlite" {
+		expr = "RTRIM(REPLACE(hostname, '<br>', ', '), ', ')"
+	}
+
+	return tx.Model(&TaskLog{}).Where("hostname LIKE ?", "%<br>%").
+		UpdateColumn("hostname", gorm.Expr(expr)).Error
+}
+
+// 已保存请求定义的HTTP任务, 请求地址复制任务的命令
+func migrateTaskHttpUrl(tx *gorm.DB) error {
+	command := tx.Table(TablePrefix + "task as t").Select("t.command").
+		Where("t.id = " + TablePrefix + "task_http.task_id")
+
+	return tx.Model(&TaskHttp{}).Where("url = ''").UpdateColumn("url", command).Error
+}
+
+// 将主任务的子任务配置迁移为工作流, 主任务与子任务之间按依赖关系连线
+// 工作流使用主任务的表达式调度, 主任务不再单独定时执行, 避免重复执行
+func migrateTaskDependencies(tx *gorm.DB) error {
+	parents := make([]Task, 0)
+	err := tx.Where("level = ? AND dependency_task_id != ''", TaskLevelParent).Find(&parents).Error
+	if err != nil {
+		return err
+	}
+	for _, parent := range parents {
+		childIds := make([]int, 0)
+		for _, value := range strings.Split(parent.DependencyTaskId, ",") {
+			if childId, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && childId != parent.Id {
+				childIds = append(childIds, childId)
+			}
+		}
+		children := make([]Task, 0)
+		if len(childIds) > 0 {
+			err = tx.Where("id IN ? AND level = ?", childIds, TaskLevelChild).Order("id ASC").Find(&children).Error
+			if err != nil {
+				return err
+			}
+		}
+		updates := map[string]interface{}{"dependency_task_id": ""}
+		if len(children) > 0 {
+			condition := WorkflowEdgeOnSuccess
+			if parent.DependencyStatus == TaskDependencyStatusWeak {
+				condition = WorkflowEdgeAlways
+			}
+			parentKey := fmt.Sprintf("task-%d", parent.Id)
+			nodes := []WorkflowNode{{NodeKey: parentKey, Name: parent.Name, TaskId: parent.Id}}
+			edges := make([]WorkflowEdge, 0, len(children))
+			for _, child := range children {
+				childKey := fmt.Sprintf("task-%d", child.Id)
+				nodes = append(nodes, WorkflowNode{NodeKey: childKey, Name: child.Name, TaskId: child.Id})
+				edges = append(edges, WorkflowEdge{FromKey: parentKey, ToKey: childKey, Condition: condition})
+			}
+			workflow := &Workflow{
+				Name:     fmt.Sprintf("依赖任务-%d", parent.Id),
+				Spec:     parent.Spec,
+				Timezone: parent.Timezone,
+				Remark:   fmt.Sprintf("由任务[%s]的子任务配置迁移", parent.Name),
+				Status:   parent.Status,
+			}
+			if err = tx.Create(workflow).Error; err != nil {
+				return err
+			}
+			if err = replaceWorkflowGraph(tx, workflow.Id, nodes, edges); err != nil {
+				return err
+			}
+			// 一次性任务没有表达式, 工作流只能手动运行, 主任务保持原有调度
+			if parent.Spec != "" {
+				updates["status"] = Disabled
+			}
+			logger.Infof("子任务配置已迁移为工作流#任务ID-%d#工作流ID-%d", parent.Id, workflow.Id)
+		}
+		if err = tx.Model(&Task{}).Where("id = ?", parent.Id).UpdateColumns(updates).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 为已存在的表添加模型中新增的字段, 不修改已有字段
+func addMissingColumns(tx *gorm.DB, model interface{}) error {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	for _, dbName := range stmt.Schema.DBNames {
+		field := stmt.Schema.FieldsByDBName[dbName]
+		if field.IgnoreMigration || tx.Migrator().HasColumn(model, dbName) {
+			continue
+		}
+		if err := tx.Migrator().AddColumn(model, dbName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // contains 检查字符串是否包含子串
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || containsMiddle(s, substr)))
@@ -502,6 +689,10 @@ func (m *Migration) fixSQLiteAutoIncrement() {
 		`)
 		Db.Exec(`DROP TABLE task_log;`)
 		Db.Exec(`ALTER TABLE task_log_new RENAME TO task_log;`)
+		// 重建时只包含基础字段, 补充后续版本新增的字段
+		if err := addMissingColumns(Db, &TaskLog{}); err != nil {
+			logger.Error("补充task_log表字段失败", err)
+		}
 		logger.Info("修复task_log表完成")
 	}
 
@@ -521,6 +712,9 @@ func (m *Migration) fixSQLiteAutoIncrement() {
 		`)
 		Db.Exec(`DROP TABLE host;`)
 		Db.Exec(`ALTER TABLE host_new RENAME TO host;`)
+		if err := addMissingColumns(Db, &Host{}); err != nil {
+			logger.Error("补充host表字段失败", err)
+		}
 		logger.Info("修复host表完成")
 	}
 }
@@ -0,0 +1,190 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+func TestMain(m *testing.M) {
+	_ = os.MkdirAll("log", 0o750)
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+// 使用临时SQLite数据库替换全局Db
+func setupTestDb(t *testing.T, tables ...interface{}) {
+	t.Helper()
+	dbPath := filepath.Join(t.TempDir(), "gocron.db")
+	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{SingularTable: true},
+		Logger:         gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+	original := Db
+	Db = db
+	t.Cleanup(func() {
+		Db = original
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+}
+
+func TestAddMissingColumns(t *testing.T) {
+	setupTestDb(t)
+	err := Db.Exec(`
+		CREATE TABLE task_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			task_id integer NOT NULL DEFAULT 0,
+			name varchar(32) NOT NULL,
+			spec varchar(64) NOT NULL,
+			protocol tinyint NOT NULL,
+			command varchar(256) NOT NULL,
+			timeout mediumint NOT NULL DEFAULT 0,
+			retry_times tinyint NOT NULL DEFAULT 0,
+			hostname varchar(128) NOT NULL DEFAULT '',
+			start_time datetime,
+			end_time datetime,
+			status tinyint NOT NULL DEFAULT 1,
+			result mediumtext NOT NULL
+		);
+	`).Error
+	if err != nil {
+		t.Fatalf("create table failed: %v", err)
+	}
+
+	if err := addMissingColumns(Db, &TaskLog{}); err != nil {
+		t.Fatalf("add columns failed: %v", err)
+	}
+	for _, column := range []string{"trigger_type", "scheduled_time"} {
+		if !Db.Migrator().HasColumn(&TaskLog{}, column) {
+			t.Fatalf("expected column %s to be added", column)
+		}
+	}
+	if Db.Migrator().HasColumn(&TaskLog{}, "total_time") {
+		t.Fatal("ignored field should not be migrated")
+	}
+}
+
+func TestInstallSQLiteKeepsNewColumns(t *testing.T) {
+	setupTestDb(t)
+	migration := new(Migration)
+	if err := migration.Install(""); err != nil {
+		t.Fatalf("install failed: %v", err)
+	}
+
+	var tableSQL string
+	Db.Raw("SELECT sql FROM sqlite_master WHERE type='table' AND name='task_log'").Scan(&tableSQL)
+	if !contains(tableSQL, "AUTOINCREMENT") {
+		t.Fatalf("expected task_log to be rebuilt with AUTOINCREMENT: %s", tableSQL)
+	}
+	if !Db.Migrator().HasColumn(&TaskLog{}, "trigger_type") {
+		t.Fatal("expected task_log.trigger_type to exist after install")
+	}
+}
+
+func TestMigrateTaskDependencies(t *testing.T) {
+	setupTestDb(t, &Task{}, &Workflow{}, &WorkflowNode{}, &WorkflowEdge{})
+	tasks := []Task{
+		{Name: "parent", Level: TaskLevelParent, Spec: "0 0 1 * * *", Status: Enabled, Command: "echo",
+			DependencyTaskId: "2,3,9", DependencyStatus: TaskDependencyStatusWeak},
+		{Name: "child-a", Level: TaskLevelChild, Command: "echo"},
+		{Name: "child-b", Level: TaskLevelChild, Command: "echo"},
+		{Name: "standalone", Level: TaskLevelParent, Spec: "0 0 2 * * *", Status: Enabled, Command: "echo"},
+	}
+	for i := range tasks {
+		if _, err := tasks[i].Create(); err != nil {
+			t.Fatalf("create task failed: %v", err)
+		}
+	}
+
+	if err := migrateTaskDependencies(Db); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+
+	workflowModel := new(Workflow)
+	workflows, err := workflowModel.ActiveList()
+	if err != nil || len(workflows) != 1 {
+		t.Fatalf("expected one migrated workflow, got %+v err=%v", workflows, err)
+	}
+	detail, err := workflowModel.Detail(workflows[0].Id)
+	if err != nil {
+		t.Fatalf("detail failed: %v", err)
+	}
+	if detail.Spec != "0 0 1 * * *" || len(detail.Nodes) != 3 || len(detail.Edges) != 2 {
+		t.Fatalf("unexpected workflow %+v", detail)
+	}
+	for _, edge := range detail.Edges {
+		if edge.FromKey != "task-1" || edge.Condition != WorkflowEdgeAlways {
+			t.Fatalf("unexpected edge %+v", edge)
+		}
+	}
+	taskModel := new(Task)
+	parent := Task{}
+	if err = Db.First(&parent, 1).Error; err != nil {
+		t.Fatalf("get parent failed: %v", err)
+	}
+	if parent.DependencyTaskId != "" || parent.Status != Disabled {
+		t.Fatalf("expected parent scheduled by workflow only, got %+v", parent)
+	}
+	if status, _ := taskModel.GetStatus(4); status != Enabled {
+		t.Fatal("tasks without child tasks should not be changed")
+	}
+}
+
+func TestMigrateTaskLogHostname(t *testing.T) {
+	setupTestDb(t, &TaskLog{})
+	for i, hostname := range []string{"web-1 - 10.0.0.1<br>web-2 - 10.0.0.2<br>", "web-3"} {
+		taskLog := &TaskLog{Id: int64(i + 1), TaskId: 1, Name: "deploy", Hostname: hostname}
+		if _, err := taskLog.Create(); err != nil {
+			t.Fatalf("create log failed: %v", err)
+		}
+	}
+	if err := migrateTaskLogHostname(Db); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+	expected := []string{"web-1 - 10.0.0.1, web-2 - 10.0.0.2", "web-3"}
+	for i, want := range expected {
+		taskLog := TaskLog{}
+		if err := Db.First(&taskLog, i+1).Error; err != nil || taskLog.Hostname != want {
+			t.Fatalf("log %d: expected hostname %q, got %q err=%v", i+1, want, taskLog.Hostname, err)
+		}
+	}
+}
+
+func TestMigrateTaskHttpUrl(t *testing.T) {
+	setupTestDb(t, &Task{}, &TaskHttp{})
+	for i, command := range []string{"https://example.com/a", "https://example.com/b"} {
+		task := &Task{Id: i + 1, Name: "http", Protocol: TaskHTTP, Command: command}
+		if err := Db.Create(task).Error; err != nil {
+			t.Fatalf("create task failed: %v", err)
+		}
+	}
+	taskHttpModel := new(TaskHttp)
+	if err := taskHttpModel.Save(1, TaskHttp{}); err != nil {
+		t.Fatal(err)
+	}
+	// 已有请求地址的不覆盖
+	if err := taskHttpModel.Save(2, TaskHttp{Url: "https://example.com/long"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateTaskHttpUrl(Db); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+	defMap, err := taskHttpModel.GetByTaskIds([]int{1, 2})
+	if err != nil || defMap[1].Url != "https://example.com/a" || defMap[2].Url != "https://example.com/long" {
+		t.Fatalf("unexpected definitions %+v err=%v", defMap, err)
+	}
+}
@@ -27,12 +27,15 @@ var TablePrefix = ""
 var Db *gorm.DB
 
 const (
-	Disabled Status = 0 // 禁用
-	Failure  Status = 0 // 失败
-	Enabled  Status = 1 // 启用
-	Running  Status = 1 // 运行中
-	Finish   Status = 2 // 完成
-	Cancel   Status = 3 // 取消
+	Disabled    Status = 0 // 禁用
+	Failure     Status = 0 // 失败
+	Enabled     Status = 1 // 启用
+	Running     Status = 1 // 运行中
+	Finish      Status = 2 // 完成
+	Cancel      Status = 3 // 取消
+	Skipped     Status = 4 // 跳过
+	Queued      Status = 5 // 排队中, 等待并发名额
+	Interrupted Status = 6 // 中断, 执行期间服务异常退出, 执行结果丢失
 )
 
 const (
@@ -137,46 +140,58 @@ func CreateDb() *gorm.DB {
 // 创建临时数据库连接
 func CreateTmpDb(setting *setting.Setting) (*gorm.DB, error) {
 	dsn := getDbEngineDSN(setting)
-	var dialector gorm.Dialector
-
 	engine := strings.ToLower(setting.Db.Engine)
+	if engine == "sqlite" {
+		ensureSqliteDir(dsn)
+	}
+	dialector, err := newDialector(engine, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return gorm.Open(dialector, &gorm.Config{})
+}
+
+// 按数据库类型创建gorm驱动
+func newDialector(engine, dsn string) (gorm.Dialector, error) {
 	switch engine {
 	case "mysql":
-		dialector = mysql.Open(dsn)
+		return mysql.Open(dsn), nil
 	case "postgres":
-		dialector = postgres.Open(dsn)
+		return postgres.Open(dsn), nil
 	case "sqlite":
-		ensureSqliteDir(dsn)
-		dialector = sqlite.Open(dsn)
-	default:
-		return nil, fmt.Errorf("不支持的数据库类型: %s", engine)
+		return sqlite.Open(dsn), nil
 	}
 
-	return gorm.Open(dialector, &gorm.Config{})
+	return nil, fmt.Errorf("不支持的数据库类型: %s", engine)
 }
 
 // 获取数据库引擎DSN  mysql,postgres
 func getDbEngineDSN(setting *setting.Setting) string {
-	engine := strings.ToLower(setting.Db.Engine)
+	return buildDSN(strings.ToLower(setting.Db.Engine), setting.Db.User, setting.Db.Password,
+		setting.Db.Host, setting.Db.Port, setting.Db.Database, setting.Db.Charset)
+}
+
+func buildDSN(engine, user, password, host string, port int, database, charset string) string {
 	dsn := ""
 	switch engine {
 	case "mysql":
 		dsn = fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=Local",
-			setting.Db.User,
-			setting.Db.Password,
-			setting.Db.Host,
-			setting.Db.Port,
-			setting.Db.Database,
-			setting.Db.Charset)
+			user,
+			password,
+			host,
+			port,
+			database,
+			charset)
 	case "postgres":
 		dsn = fmt.Sprintf("user=%s password=%s host=%s port=%d dbname=%s sslmode=disable",
-			setting.Db.User,
-			setting.Db.Password,
-			setting.Db.Host,
-			setting.Db.Port,
-			setting.Db.Database)
+			user,
+			password,
+			host,
+			port,
+			database)
 	case "sqlite":
-		dsn = setting.Db.Database
+		dsn = database
 	}
 
 	return dsn
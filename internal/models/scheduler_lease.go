@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SchedulerLeaseName 调度器租约名称, 多实例部署时竞争同一行记录
+const SchedulerLeaseName = "scheduler"
+
+// SchedulerLease 调度器租约, 用于多实例部署时选举唯一的调度节点
+// 持有者在ExpiresAt之前需续约, 过期后其他实例可接管
+// Revision 任务变更版本号, 非调度节点修改任务后递增, 调度节点检测到变化后重新加载任务
+type SchedulerLease struct {
+	Name      string    `json:"name" gorm:"primaryKey;type:varchar(32)"`
+	Holder    string    `json:"holder" gorm:"type:varchar(128);not null;default:''"`
+	Revision  int64     `json:"revision" gorm:"type:bigint;not null;default:0"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// 确保租约记录存在
+func (lease *SchedulerLease) ensure(name string) error {
+	return Db.Clauses(clause.OnConflict{DoNothing: true}).Create(&SchedulerLease{
+		Name:      name,
+		ExpiresAt: time.Unix(0, 0),
+	}).Error
+}
+
+// TryAcquire 尝试获取或续约租约, 租约未被占用、已过期或由holder持有时成功
+func (lease *SchedulerLease) TryAcquire(name, holder string, ttl time.Duration) (bool, error) {
+	if err := lease.ensure(name); err != nil {
+		return false, err
+	}
+	// 使用数据库时间计算过期时间, 避免各实例时钟不一致时多个实例同时持有租约
+	now, err := DbNow()
+	if err != nil {
+		return false, err
+	}
+	result := Db.Model(&SchedulerLease{}).
+		Where("name = ? AND (holder = ? OR holder = '' OR expires_at < ?)", name, holder, now).
+		UpdateColumns(map[string]interface{}{
+			"holder":     holder,
+			"expires_at": now.Add(ttl),
+			"updated_at": now,
+		})
+
+	return result.RowsAffected > 0, result.Error
+}
+
+// Release 释放租约, 仅持有者可释放
+func (lease *SchedulerLease) Release(name, holder string) error {
+	now, err := DbNow()
+	if err != nil {
+		return err
+	}
+	return Db.Model(&SchedulerLease{}).
+		Where("name = ? AND holder = ?", name, holder).
+		UpdateColumns(map[string]interface{}{
+			"holder":     "",
+			"expires_at": time.Unix(0, 0),
+			"updated_at": now,
+		}).Error
+}
+
+// GetRevision 获取任务变更版本号
+func (lease *SchedulerLease) GetRevision(name string) (int64, error) {
+	current := SchedulerLease{}
+	err := Db.Where("name = ?", name).First(&current).Error
+
+	return current.Revision, err
+}
+
+// BumpRevision 递增任务变更版本号
+func (lease *SchedulerLease) BumpRevision(name string) error {
+	if err := lease.ensure(name); err != nil {
+		return err
+	}
+	return Db.Model(&SchedulerLease{}).
+		Where("name = ?", name).
+		UpdateColumn("revision", gorm.Expr("revision + ?", 1)).Error
+}
+
+// DbNow 数据库当前时间, 多实例部署时以数据库时间为准
+func DbNow() (time.Time, error) {
+	var query string
+	switch Db.Dialector.Name() {
+	case "mysql":
+		query = "SELECT UNIX_TIMESTAMP(NOW(6))"
+	case "postgres":
+		query = "SELECT EXTRACT(EPOCH FROM CURRENT_TIMESTAMP)"
+	default:
+		query = "SELECT (julianday('now') - 2440587.5) * 86400.0"
+	}
+	var seconds float64
+	if err := Db.Raw(query).Row().Scan(&seconds); err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(0, int64(seconds*float64(time.Second))), nil
+}
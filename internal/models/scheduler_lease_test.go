@@ -0,0 +1,106 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedulerLeaseAcquireAndRenew(t *testing.T) {
+	setupTestDb(t, &SchedulerLease{})
+	lease := new(SchedulerLease)
+
+	ok, err := lease.TryAcquire(SchedulerLeaseName, "a", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected a to acquire lease, ok=%v err=%v", ok, err)
+	}
+	ok, err = lease.TryAcquire(SchedulerLeaseName, "b", time.Minute)
+	if err != nil || ok {
+		t.Fatalf("expected b to be rejected, ok=%v err=%v", ok, err)
+	}
+	ok, err = lease.TryAcquire(SchedulerLeaseName, "a", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected a to renew lease, ok=%v err=%v", ok, err)
+	}
+}
+
+func TestSchedulerLeaseTakeoverAfterExpiry(t *testing.T) {
+	setupTestDb(t, &SchedulerLease{})
+	lease := new(SchedulerLease)
+
+	if ok, _ := lease.TryAcquire(SchedulerLeaseName, "a", -time.Second); !ok {
+		t.Fatal("expected a to acquire lease")
+	}
+	ok, err := lease.TryAcquire(SchedulerLeaseName, "b", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected b to take over expired lease, ok=%v err=%v", ok, err)
+	}
+}
+
+func TestSchedulerLeaseRelease(t *testing.T) {
+	setupTestDb(t, &SchedulerLease{})
+	lease := new(SchedulerLease)
+
+	_, _ = lease.TryAcquire(SchedulerLeaseName, "a", time.Minute)
+	if err := lease.Release(SchedulerLeaseName, "b"); err != nil {
+		t.Fatalf("release failed: %v", err)
+	}
+	if ok, _ := lease.TryAcquire(SchedulerLeaseName, "b", time.Minute); ok {
+		t.Fatal("non-holder release should not free the lease")
+	}
+	if err := lease.Release(SchedulerLeaseName, "a"); err != nil {
+		t.Fatalf("release failed: %v", err)
+	}
+	if ok, _ := lease.TryAcquire(SchedulerLeaseName, "b", time.Minute); !ok {
+		t.Fatal("expected b to acquire released lease")
+	}
+}
+
+func TestSchedulerLeaseRevision(t *testing.T) {
+	setupTestDb(t, &SchedulerLease{})
+	lease := new(SchedulerLease)
+
+	if err := lease.BumpRevision(SchedulerLeaseName); err != nil {
+		t.Fatalf("bump failed: %v", err)
+	}
+	if err := lease.BumpRevision(SchedulerLeaseName); err != nil {
+		t.Fatalf("bump failed: %v", err)
+	}
+	revision, err := lease.GetRevision(SchedulerLeaseName)
+	if err != nil || revision != 2 {
+		t.Fatalf("expected revision 2, got %d err=%v", revision, err)
+	}
+}
+
+func TestDbNow(t *testing.T) {
+	setupTestDb(t, &SchedulerLease{})
+
+	now, err := DbNow()
+	if err != nil {
+		t.Fatalf("DbNow failed: %v", err)
+	}
+	if diff := time.Since(now); diff < -time.Second || diff > time.Second {
+		t.Fatalf("expected database time close to local time, diff=%s", diff)
+	}
+}
+
+func TestSchedulerRequestClaimOnce(t *testing.T) {
+	setupTestDb(t, &SchedulerRequest{})
+	requestModel := new(SchedulerRequest)
+	for _, targetId := range []int64{3, 1} {
+		request := &SchedulerRequest{Type: SchedulerRequestRun, TargetId: targetId}
+		if _, err := request.Create(); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+	}
+
+	requests, err := requestModel.Pending(10)
+	if err != nil || len(requests) != 2 || requests[0].TargetId != 3 {
+		t.Fatalf("expected requests in insertion order, got %+v err=%v", requests, err)
+	}
+	if claimed, err := requestModel.Claim(requests[0].Id); err != nil || !claimed {
+		t.Fatalf("expected first claim to succeed, claimed=%v err=%v", claimed, err)
+	}
+	if claimed, _ := requestModel.Claim(requests[0].Id); claimed {
+		t.Fatal("request should only be claimed once")
+	}
+}
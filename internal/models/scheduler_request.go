@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+type SchedulerRequestType int8
+
+const (
+	SchedulerRequestRun         SchedulerRequestType = 1 // 手动运行任务
+	SchedulerRequestWorkflowRun SchedulerRequestType = 2 // 手动运行工作流
+	SchedulerRequestBackfill    SchedulerRequestType = 3 // 开始回填
+	SchedulerRequestStopSql     SchedulerRequestType = 4 // 停止执行中的SQL任务
+	SchedulerRequestStopHosts   SchedulerRequestType = 5 // 停止在主机上执行中的RPC、SSH任务
+)
+
+// 调度请求, 高可用模式下非调度节点收到的手动运行、回填请求写入该表, 由调度节点取出后执行
+type SchedulerRequest struct {
+	Id        int64                `json:"id" gorm:"primaryKey;autoIncrement"`
+	Type      SchedulerRequestType `json:"type" gorm:"type:tinyint;not null"`
+	TargetId  int64                `json:"target_id" gorm:"type:bigint;not null"` // 任务ID、工作流ID、回填记录ID或任务日志ID
+	Payload   string               `json:"payload" gorm:"type:text;not null"`     // 请求参数, JSON格式
+	CreatedAt time.Time            `json:"created" gorm:"column:created;autoCreateTime"`
+}
+
+func (request *SchedulerRequest) Create() (insertId int64, err error) {
+	result := Db.Create(request)
+	if result.Error == nil {
+		insertId = request.Id
+	}
+
+	return insertId, result.Error
+}
+
+// 按写入顺序获取待处理的请求
+func (request *SchedulerRequest) Pending(limit int) ([]SchedulerRequest, error) {
+	list := make([]SchedulerRequest, 0)
+	err := Db.Order("id ASC").Limit(limit).Find(&list).Error
+
+	return list, err
+}
+
+// 取出请求, 删除成功的节点负责处理, 避免切换调度节点时重复处理
+func (request *SchedulerRequest) Claim(id int64) (bool, error) {
+	result := Db.Where("id = ?", id).Delete(&SchedulerRequest{})
+
+	return result.RowsAffected > 0, result.Error
+}
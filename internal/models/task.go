@@ -3,7 +3,6 @@ package models
 import (
 	"encoding/json"
 	"errors"
-	"strings"
 	"time"
 
 	"github.com/gocronx-team/gocron/internal/modules/logger"
@@ -13,15 +12,23 @@ import (
 type TaskProtocol int8
 
 const (
-	TaskHTTP TaskProtocol = iota + 1 // HTTP协议
-	TaskRPC                          // RPC方式执行命令
+	TaskHTTP      TaskProtocol = iota + 1 // HTTP协议
+	TaskRPC                               // RPC方式执行命令
+	TaskHeartbeat                         // 被动心跳, 由外部系统定时请求心跳地址
+	TaskSSH                               // SSH方式执行命令, 主机无需部署gocron-node
+	TaskSQL                               // 在数据源上执行SQL语句
 )
 
+// 是否在关联的主机上执行命令
+func (protocol TaskProtocol) OnHosts() bool {
+	return protocol == TaskRPC || protocol == TaskSSH
+}
+
 type TaskLevel int8
 
 const (
 	TaskLevelParent TaskLevel = 1 // 父任务
-	TaskLevelChild  TaskLevel = 2 // 子任务(依赖任务)
+	TaskLevelChild  TaskLevel = 2 // 子任务, 不定时执行, 作为工作流节点执行
 )
 
 type TaskDependencyStatus int8
@@ -34,10 +41,97 @@ const (
 type TaskHTTPMethod int8
 
 const (
-	TaskHTTPMethodGet  TaskHTTPMethod = 1
-	TaskHttpMethodPost TaskHTTPMethod = 2
+	TaskHTTPMethodGet    TaskHTTPMethod = 1
+	TaskHttpMethodPost   TaskHTTPMethod = 2
+	TaskHttpMethodPut    TaskHTTPMethod = 3
+	TaskHttpMethodPatch  TaskHTTPMethod = 4
+	TaskHttpMethodDelete TaskHTTPMethod = 5
+)
+
+type TaskMisfirePolicy int8
+
+// 服务停止期间错过的执行, 启动时的处理策略
+const (
+	TaskMisfireSkip    TaskMisfirePolicy = 1 // 跳过
+	TaskMisfireRunOnce TaskMisfirePolicy = 2 // 补偿执行一次
+	TaskMisfireRunAll  TaskMisfirePolicy = 3 // 补偿执行每一次, 最多MisfireMaxRuns次
+)
+
+// 补偿执行次数默认上限
+const DefaultMisfireMaxRuns = 10
+
+type TaskOverlapPolicy int8
+
+// 单实例运行的任务, 上次执行未结束时的处理策略
+const (
+	TaskOverlapSkip    TaskOverlapPolicy = 1 // 跳过本次执行
+	TaskOverlapQueue   TaskOverlapPolicy = 2 // 排队, 上次执行结束后执行, 最多保留一次等待执行
+	TaskOverlapReplace TaskOverlapPolicy = 3 // 停止上次执行, 立即开始本次执行
+)
+
+type TaskHostStrategy int8
+
+// RPC任务选择执行主机的策略
+const (
+	TaskHostAll         TaskHostStrategy = 1 // 所有主机执行
+	TaskHostRandom      TaskHostStrategy = 2 // 随机选择一台
+	TaskHostRoundRobin  TaskHostStrategy = 3 // 轮询
+	TaskHostLeastRecent TaskHostStrategy = 4 // 选择最久未执行的主机
+	TaskHostFailover    TaskHostStrategy = 5 // 按主机顺序执行, 主机不可用时使用下一台
 )
 
+type TaskHostSuccessRule int8
+
+// 多台主机执行时, 判断任务执行成功的规则
+const (
+	TaskHostSuccessAll    TaskHostSuccessRule = 1 // 所有主机执行成功
+	TaskHostSuccessAny    TaskHostSuccessRule = 2 // 任意一台主机执行成功
+	TaskHostSuccessQuorum TaskHostSuccessRule = 3 // 超过半数的主机执行成功
+)
+
+// 每天最晚完成时间的格式
+const SlaDeadlineFormat = "15:04"
+
+type TaskOnceAction int8
+
+// 一次性任务执行后的处理
+const (
+	TaskOnceKeep    TaskOnceAction = 1 // 保留任务
+	TaskOnceDisable TaskOnceAction = 2 // 禁用任务
+	TaskOnceDelete  TaskOnceAction = 3 // 删除任务
+)
+
+type TaskRetryStrategy int8
+
+// 失败重试间隔策略
+const (
+	TaskRetryDefault     TaskRetryStrategy = 0 // 默认, 配置了重试间隔时固定间隔, 否则每次递增1分钟
+	TaskRetryFixed       TaskRetryStrategy = 1 // 固定间隔
+	TaskRetryLinear      TaskRetryStrategy = 2 // 线性递增, 间隔*重试次数
+	TaskRetryExponential TaskRetryStrategy = 3 // 指数退避, 间隔*2^(重试次数-1)
+)
+
+// 执行失败的错误类型, 用于配置哪些失败需要重试
+const (
+	TaskErrorUnavailable = "unavailable" // 无法连接远程服务器
+	TaskErrorTimeout     = "timeout"     // 执行超时
+	TaskErrorHTTP5xx     = "http_5xx"    // HTTP状态码5xx
+	TaskErrorHTTP4xx     = "http_4xx"    // HTTP状态码4xx
+	TaskErrorExit        = "exit"        // 命令执行失败, 如退出码非0
+	TaskErrorOther       = "other"       // 其他错误
+	TaskErrorAssert      = "assert"      // HTTP响应断言失败, SQL查询返回数据
+	TaskErrorTemplate    = "template"    // 命令模板渲染失败, 不重试
+)
+
+// 可配置重试的错误类型
+var TaskRetryableErrors = []string{
+	TaskErrorUnavailable, TaskErrorTimeout, TaskErrorHTTP5xx,
+	TaskErrorHTTP4xx, TaskErrorExit, TaskErrorOther, TaskErrorAssert,
+}
+
+// 任务被工作流节点引用, 不能删除
+var ErrTaskInWorkflow = errors.New("任务被工作流引用, 不能删除")
+
 // NextRunTime 自定义时间类型，零值时序列化为空字符串
 type NextRunTime time.Time
 
@@ -71,28 +165,64 @@ type Task struct {
 	Id               int                  `json:"id" gorm:"primaryKey;autoIncrement"`
 	Name             string               `json:"name" gorm:"type:varchar(32);not null"`
 	Level            TaskLevel            `json:"level" gorm:"type:tinyint;not null;index;default:1"`
-	DependencyTaskId string               `json:"dependency_task_id" gorm:"type:varchar(64);not null;default:''"`
-	DependencyStatus TaskDependencyStatus `json:"dependency_status" gorm:"type:tinyint;not null;default:1"`
+	DependencyTaskId string               `json:"dependency_task_id" gorm:"type:varchar(64);not null;default:''"` // 已废弃, 升级时迁移为工作流
+	DependencyStatus TaskDependencyStatus `json:"dependency_status" gorm:"type:tinyint;not null;default:1"`       // 已废弃, 升级时迁移为工作流
 	Spec             string               `json:"spec" gorm:"type:varchar(64);not null"`
+	Timezone         string               `json:"timezone" gorm:"type:varchar(64);not null;default:''"`
+	RunAt            string               `json:"run_at" gorm:"type:varchar(19);not null;default:''"`
+	OnceAction       TaskOnceAction       `json:"once_action" gorm:"type:tinyint;not null;default:1"`
 	Protocol         TaskProtocol         `json:"protocol" gorm:"type:tinyint;not null;index"`
 	Command          string               `json:"command" gorm:"type:varchar(256);not null"`
 	HttpMethod       TaskHTTPMethod       `json:"http_method" gorm:"type:tinyint;not null;default:1"`
 	Timeout          int                  `json:"timeout" gorm:"type:mediumint;not null;default:0"`
 	Multi            int8                 `json:"multi" gorm:"type:tinyint;not null;default:1"`
+	OverlapPolicy    TaskOverlapPolicy    `json:"overlap_policy" gorm:"type:tinyint;not null;default:1"`      // 单实例运行时, 上次执行未结束的处理策略
+	HostStrategy     TaskHostStrategy     `json:"host_strategy" gorm:"type:tinyint;not null;default:1"`       // RPC任务执行主机的选择策略
+	HostSelector     string               `json:"host_selector" gorm:"type:varchar(255);not null;default:''"` // 主机标签选择器, 设置后每次执行时选择匹配的主机
+	RollingBatch     int16                `json:"rolling_batch" gorm:"type:smallint;not null;default:0"`      // 滚动执行每批的主机数, 0为所有主机同时执行
+	RollingPause     int                  `json:"rolling_pause" gorm:"type:mediumint;not null;default:0"`     // 滚动执行批次间隔(秒)
+	RollingMaxFail   int16                `json:"rolling_max_fail" gorm:"type:smallint;not null;default:0"`   // 失败主机数超过该值时停止执行剩余的批次
+	RollingFailRate  int8                 `json:"rolling_fail_rate" gorm:"type:tinyint;not null;default:0"`   // 大于0时按失败主机比例(%)判断是否停止, 代替失败主机数
+	HostSuccessRule  TaskHostSuccessRule  `json:"host_success_rule" gorm:"type:tinyint;not null;default:1"`   // 多台主机执行时, 判断任务执行成功的规则
 	RetryTimes       int8                 `json:"retry_times" gorm:"type:tinyint;not null;default:0"`
 	RetryInterval    int16                `json:"retry_interval" gorm:"type:smallint;not null;default:0"`
+	RetryStrategy    TaskRetryStrategy    `json:"retry_strategy" gorm:"type:tinyint;not null;default:0"`
+	RetryMaxInterval int                  `json:"retry_max_interval" gorm:"type:mediumint;not null;default:0"`
+	RetryJitter      int8                 `json:"retry_jitter" gorm:"type:tinyint;not null;default:0"`
+	RetryOn          string               `json:"retry_on" gorm:"type:varchar(128);not null;default:''"`
 	NotifyStatus     int8                 `json:"notify_status" gorm:"type:tinyint;not null;default:1"`
 	NotifyType       int8                 `json:"notify_type" gorm:"type:tinyint;not null;default:0"`
 	NotifyReceiverId string               `json:"notify_receiver_id" gorm:"type:varchar(256);not null;default:''"`
 	NotifyKeyword    string               `json:"notify_keyword" gorm:"type:varchar(128);not null;default:''"`
+	MisfirePolicy    TaskMisfirePolicy    `json:"misfire_policy" gorm:"type:tinyint;not null;default:1"`
+	MisfireMaxRuns   int16                `json:"misfire_max_runs" gorm:"type:smallint;not null;default:0"`
+	RerunInterrupted int8                 `json:"rerun_interrupted" gorm:"type:tinyint;not null;default:0"`     // 执行中断后是否重新执行
+	SlaMaxDuration   int                  `json:"sla_max_duration" gorm:"type:mediumint;not null;default:0"`    // 预期最长执行时间(秒), 超出时发送通知, 0不限制
+	PingToken        string               `json:"ping_token" gorm:"type:varchar(64);not null;default:'';index"` // 心跳地址的密钥, 仅被动心跳任务
+	PingGrace        int                  `json:"ping_grace" gorm:"type:mediumint;not null;default:0"`          // 心跳宽限时间(秒), 预期时间点后等待心跳的时间
+	SlaDeadline      string               `json:"sla_deadline" gorm:"type:varchar(5);not null;default:''"`      // 每天最晚完成时间HH:MM, 按任务时区, 未执行成功时发送通知
 	Tag              string               `json:"tag" gorm:"type:varchar(32);not null;default:''"`
 	Remark           string               `json:"remark" gorm:"type:varchar(100);not null;default:''"`
 	Status           Status               `json:"status" gorm:"type:tinyint;not null;index;default:0"`
 	CreatedAt        time.Time            `json:"created" gorm:"column:created;autoCreateTime"`
 	DeletedAt        *time.Time           `json:"deleted" gorm:"column:deleted;index"`
 	BaseModel        `json:"-" gorm:"-"`
-	Hosts            []TaskHostDetail `json:"hosts" gorm:"-"`
-	NextRunTime      NextRunTime      `json:"next_run_time" gorm:"-"`
+	Hosts            []TaskHostDetail     `json:"hosts" gorm:"-"`
+	Calendars        []TaskCalendarDetail `json:"calendars" gorm:"-"`
+	LimitGroupIds    []int                `json:"concurrency_group_ids" gorm:"-"`
+	Http             *TaskHttp            `json:"http" gorm:"-"`
+	Sql              *TaskSql             `json:"sql" gorm:"-"`
+	RunEnv           map[string]string    `json:"-" gorm:"-"` // 本次执行追加的环境变量
+	RunScheduledTime time.Time            `json:"-" gorm:"-"` // 本次执行的计划执行时间, 用于渲染命令模板
+	RunAttempt       int                  `json:"-" gorm:"-"` // 本次执行是第几次执行, 用于渲染命令模板
+	RunCallbackUrl   string               `json:"-" gorm:"-"` // 异步HTTP任务本次执行的回调地址, 用于渲染命令模板
+	RunCallbackToken string               `json:"-" gorm:"-"` // 异步HTTP任务本次执行的回调令牌, 用于渲染命令模板
+	NextRunTime      NextRunTime          `json:"next_run_time" gorm:"-"`
+}
+
+// 是否为一次性任务, 一次性任务在RunAt指定的时间(任务时区)执行一次
+func (task Task) IsOnce() bool {
+	return task.RunAt != ""
 }
 
 // 新增
@@ -109,8 +239,12 @@ func (task *Task) UpdateBean(id int) (int64, error) {
 	result := Db.Model(&Task{}).Where("id = ?", id).
 		Select("name", "spec", "protocol", "command", "timeout", "multi",
 			"retry_times", "retry_interval", "remark", "notify_status",
-			"notify_type", "notify_receiver_id", "dependency_task_id",
-			"dependency_status", "tag", "http_method", "notify_keyword").
+			"notify_type", "notify_receiver_id", "tag", "http_method", "notify_keyword",
+			"misfire_policy", "misfire_max_runs", "timezone", "run_at", "once_action",
+			"retry_strategy", "retry_max_interval", "retry_jitter", "retry_on",
+			"rerun_interrupted", "sla_max_duration", "sla_deadline", "ping_token", "ping_grace", "overlap_policy",
+			"host_strategy", "host_selector", "rolling_batch", "rolling_pause", "rolling_max_fail", "rolling_fail_rate",
+			"host_success_rule").
 		Updates(task)
 	return result.RowsAffected, result.Error
 }
@@ -131,6 +265,33 @@ func (task *Task) Delete(id int) (int64, error) {
 	return result.RowsAffected, result.Error
 }
 
+// 删除任务及关联的主机、日历、并发限制组和执行定义, 被工作流引用的任务不能删除
+func (task *Task) Remove(id int) error {
+	workflowModel := new(Workflow)
+	inWorkflow, err := workflowModel.TaskIdExist(id)
+	if err != nil {
+		return err
+	}
+	if inWorkflow {
+		return ErrTaskInWorkflow
+	}
+	if _, err = task.Delete(id); err != nil {
+		return err
+	}
+	taskHostModel := new(TaskHost)
+	_ = taskHostModel.Remove(id)
+	taskCalendarModel := new(TaskCalendar)
+	_ = taskCalendarModel.Remove(id)
+	taskConcurrencyGroupModel := new(TaskConcurrencyGroup)
+	_ = taskConcurrencyGroupModel.Remove(id)
+	taskHttpModel := new(TaskHttp)
+	_ = taskHttpModel.Remove(id)
+	taskSqlModel := new(TaskSql)
+	_ = taskSqlModel.Remove(id)
+
+	return nil
+}
+
 // 禁用
 func (task *Task) Disable(id int) (int64, error) {
 	return task.Update(id, CommonMap{"status": Disabled})
@@ -154,7 +315,7 @@ func (task *Task) ActiveList(page, pageSize int) ([]Task, error) {
 		return list, err
 	}
 
-	return task.setHostsForTasks(list)
+	return task.setRelationsForTasks(list)
 }
 
 // 获取某个主机下的所有激活任务
@@ -175,7 +336,120 @@ func (task *Task) ActiveListByHostId(hostId int16) ([]Task, error) {
 		return list, err
 	}
 
-	return task.setHostsForTasks(list)
+	return task.setRelationsForTasks(list)
+}
+
+// 获取指定ID中的所有激活任务
+func (task *Task) ActiveListByIds(taskIds []interface{}) ([]Task, error) {
+	list := make([]Task, 0)
+	if len(taskIds) == 0 {
+		return list, nil
+	}
+	err := Db.Where("status = ? AND level = ?", Enabled, TaskLevelParent).
+		Where("id IN ?", taskIds).
+		Find(&list).Error
+	if err != nil {
+		return list, err
+	}
+
+	return task.setRelationsForTasks(list)
+}
+
+// 获取配置了SLA的任务
+func (task *Task) SlaList() ([]Task, error) {
+	list := make([]Task, 0)
+	err := Db.Where("sla_max_duration > 0 OR sla_deadline <> ''").Find(&list).Error
+	if err != nil {
+		return list, err
+	}
+
+	return task.setRelationsForTasks(list)
+}
+
+// 批量查询任务关联的主机、日历、HTTP请求定义、SQL执行定义
+func (task *Task) setRelationsForTasks(tasks []Task) ([]Task, error) {
+	tasks, err := task.setHostsForTasks(tasks)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err = task.setCalendarsForTasks(tasks)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err = task.setHttpForTasks(tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	return task.setSqlForTasks(tasks)
+}
+
+// 批量查询SQL任务的执行定义
+func (task *Task) setSqlForTasks(tasks []Task) ([]Task, error) {
+	taskIds := make([]int, 0)
+	for _, t := range tasks {
+		if t.Protocol == TaskSQL {
+			taskIds = append(taskIds, t.Id)
+		}
+	}
+	if len(taskIds) == 0 {
+		return tasks, nil
+	}
+	taskSqlModel := new(TaskSql)
+	defMap, err := taskSqlModel.GetByTaskIds(taskIds)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		tasks[i].Sql = defMap[tasks[i].Id]
+	}
+
+	return tasks, nil
+}
+
+// 批量查询HTTP任务的请求定义
+func (task *Task) setHttpForTasks(tasks []Task) ([]Task, error) {
+	taskIds := make([]int, 0)
+	for _, t := range tasks {
+		if t.Protocol == TaskHTTP {
+			taskIds = append(taskIds, t.Id)
+		}
+	}
+	if len(taskIds) == 0 {
+		return tasks, nil
+	}
+	taskHttpModel := new(TaskHttp)
+	defMap, err := taskHttpModel.GetByTaskIds(taskIds)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		tasks[i].Http = defMap[tasks[i].Id]
+		tasks[i].Http.applyUrl(&tasks[i])
+	}
+
+	return tasks, nil
+}
+
+// 批量查询任务关联的日历
+func (task *Task) setCalendarsForTasks(tasks []Task) ([]Task, error) {
+	if len(tasks) == 0 {
+		return tasks, nil
+	}
+	taskIds := make([]int, len(tasks))
+	for i, t := range tasks {
+		taskIds[i] = t.Id
+	}
+	taskCalendarModel := new(TaskCalendar)
+	calendarMap, err := taskCalendarModel.GetCalendarsByTaskIds(taskIds)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		tasks[i].Calendars = calendarMap[tasks[i].Id]
+	}
+
+	return tasks, nil
 }
 
 // 优化：批量查询任务主机信息，避免N+1查询问题
@@ -197,9 +471,14 @@ func (task *Task) setHostsForTasks(tasks []Task) ([]Task, error) {
 		return nil, err
 	}
 
-	// 分配主机信息到对应任务
+	// 分配主机信息到对应任务, 使用标签选择器的任务按选择器匹配主机
 	for i := range tasks {
-		if hosts, ok := hostsMap[tasks[i].Id]; ok {
+		if tasks[i].HostSelector != "" {
+			tasks[i].Hosts, err = taskHostModel.GetHostsBySelector(tasks[i].Id, tasks[i].HostSelector)
+			if err != nil {
+				return nil, err
+			}
+		} else if hosts, ok := hostsMap[tasks[i].Id]; ok {
 			tasks[i].Hosts = hosts
 		} else {
 			tasks[i].Hosts = []TaskHostDetail{}
@@ -242,7 +521,51 @@ func (task *Task) Detail(id int) (Task, error) {
 	}
 
 	taskHostModel := new(TaskHost)
-	t.Hosts, err = taskHostModel.GetHostIdsByTaskId(id)
+	if t.HostSelector != "" {
+		t.Hosts, err = taskHostModel.GetHostsBySelector(id, t.HostSelector)
+	} else {
+		t.Hosts, err = taskHostModel.GetHostIdsByTaskId(id)
+	}
+	if err != nil {
+		return t, err
+	}
+	taskCalendarModel := new(TaskCalendar)
+	calendarMap, err := taskCalendarModel.GetCalendarsByTaskIds([]int{id})
+	if err != nil {
+		return t, err
+	}
+	t.Calendars = calendarMap[id]
+	if t.Protocol == TaskHTTP {
+		taskHttpModel := new(TaskHttp)
+		t.Http, err = taskHttpModel.GetByTaskId(id)
+		if err != nil {
+			return t, err
+		}
+		t.Http.applyUrl(&t)
+	}
+	if t.Protocol == TaskSQL {
+		taskSqlModel := new(TaskSql)
+		t.Sql, err = taskSqlModel.GetByTaskId(id)
+		if err != nil {
+			return t, err
+		}
+	}
+	taskConcurrencyGroupModel := new(TaskConcurrencyGroup)
+	t.LimitGroupIds, err = taskConcurrencyGroupModel.GetGroupIds(id)
+
+	return t, err
+}
+
+// 根据心跳地址的密钥获取被动心跳任务, 不存在时返回的任务ID为0
+func (task *Task) DetailByPingToken(token string) (Task, error) {
+	t := Task{}
+	if token == "" {
+		return t, nil
+	}
+	err := Db.Where("ping_token = ? AND protocol = ?", token, TaskHeartbeat).First(&t).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return t, nil
+	}
 
 	return t, err
 }
@@ -266,26 +589,7 @@ func (task *Task) List(params CommonMap) ([]Task, error) {
 		return nil, err
 	}
 
-	return task.setHostsForTasks(list)
-}
-
-// 获取依赖任务列表
-func (task *Task) GetDependencyTaskList(ids string) ([]Task, error) {
-	list := make([]Task, 0)
-	if ids == "" {
-		return list, nil
-	}
-	idList := strings.Split(ids, ",")
-
-	err := Db.Where("level = ?", TaskLevelChild).
-		Where("id IN ?", idList).
-		Find(&list).Error
-
-	if err != nil {
-		return list, err
-	}
-
-	return task.setHostsForTasks(list)
+	return task.setRelationsForTasks(list)
 }
 
 func (task *Task) Total(params CommonMap) (int64, error) {
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 任务回填记录, 按任务表达式计算时间范围内的执行时间点逐个执行, 各次执行的任务日志通过BackfillId关联
+type TaskBackfill struct {
+	Id          int64     `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
+	TaskId      int       `json:"task_id" gorm:"not null;index"`
+	Name        string    `json:"name" gorm:"type:varchar(32);not null"`
+	RangeStart  LocalTime `json:"range_start" gorm:"column:range_start"`
+	RangeEnd    LocalTime `json:"range_end" gorm:"column:range_end"`
+	Parallelism int8      `json:"parallelism" gorm:"type:tinyint;not null;default:1"`
+	Occurrences int       `json:"occurrences" gorm:"type:int;not null;default:0"` // 需要执行的次数
+	Succeeded   int       `json:"succeeded" gorm:"type:int;not null;default:0"`   // 执行成功的次数
+	Failed      int       `json:"failed" gorm:"type:int;not null;default:0"`      // 执行失败的次数
+	Skipped     int       `json:"skipped" gorm:"type:int;not null;default:0"`     // 被日历阻止或未执行的次数
+	Status      Status    `json:"status" gorm:"type:tinyint;not null;index;default:1"`
+	Canceled    int8      `json:"canceled" gorm:"type:tinyint;not null;default:0"` // 已请求取消, 由执行回填的节点检查后停止
+	Result      string    `json:"result" gorm:"type:text;not null"`
+	StartTime   LocalTime `json:"start_time" gorm:"column:start_time;autoCreateTime"`
+	EndTime     LocalTime `json:"end_time" gorm:"column:end_time;autoUpdateTime"`
+	BaseModel   `json:"-" gorm:"-"`
+}
+
+func (backfill *TaskBackfill) Create() (insertId int64, err error) {
+	result := Db.Create(backfill)
+	if result.Error == nil {
+		insertId = backfill.Id
+	}
+
+	return insertId, result.Error
+}
+
+// 更新
+func (backfill *TaskBackfill) Update(id int64, data CommonMap) (int64, error) {
+	updateData := make(map[string]interface{})
+	for k, v := range data {
+		updateData[k] = v
+	}
+	result := Db.Model(&TaskBackfill{}).Where("id = ?", id).UpdateColumns(updateData)
+	return result.RowsAffected, result.Error
+}
+
+// 请求取消执行中的回填, 回填不在执行中时返回false
+func (backfill *TaskBackfill) RequestCancel(id int64) (bool, error) {
+	result := Db.Model(&TaskBackfill{}).Where("id = ? AND status = ?", id, Running).
+		UpdateColumn("canceled", 1)
+
+	return result.RowsAffected > 0, result.Error
+}
+
+// 获取回填记录详情, 不存在时返回的记录Id为0
+func (backfill *TaskBackfill) Detail(id int64) (TaskBackfill, error) {
+	b := TaskBackfill{}
+	err := Db.Where("id = ?", id).Limit(1).Find(&b).Error
+
+	return b, err
+}
+
+func (backfill *TaskBackfill) List(params CommonMap) ([]TaskBackfill, error) {
+	backfill.parsePageAndPageSize(params)
+	list := make([]TaskBackfill, 0)
+	query := Db.Order("id DESC")
+	backfill.parseWhere(query, params)
+	err := query.Limit(backfill.PageSize).Offset(backfill.pageLimitOffset()).Find(&list).Error
+
+	return list, err
+}
+
+func (backfill *TaskBackfill) Total(params CommonMap) (int64, error) {
+	var count int64
+	query := Db.Model(&TaskBackfill{})
+	backfill.parseWhere(query, params)
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// 执行中的记录标记为中断
+func (backfill *TaskBackfill) InterruptRunning(result string) (int64, error) {
+	res := Db.Model(&TaskBackfill{}).Where("status = ?", Running).UpdateColumns(map[string]interface{}{
+		"status":   Interrupted,
+		"result":   result,
+		"end_time": time.Now(),
+	})
+	return res.RowsAffected, res.Error
+}
+
+// 删除N天前的回填记录
+func (backfill *TaskBackfill) RemoveByDays(days int) (int64, error) {
+	if days <= 0 {
+		return 0, nil
+	}
+	t := time.Now().AddDate(0, 0, -days)
+	result := Db.Where("start_time < ?", t).Delete(&TaskBackfill{})
+	return result.RowsAffected, result.Error
+}
+
+// 解析where
+func (backfill *TaskBackfill) parseWhere(query *gorm.DB, params CommonMap) {
+	if len(params) == 0 {
+		return
+	}
+	taskId, ok := params["TaskId"]
+	if ok && taskId.(int) > 0 {
+		query.Where("task_id = ?", taskId)
+	}
+	status, ok := params["Status"]
+	if ok && status.(int) > -1 {
+		query.Where("status = ?", status)
+	}
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskBackfillRecords(t *testing.T) {
+	setupTestDb(t, &TaskBackfill{}, &TaskLog{})
+	now := LocalTime(time.Now())
+	backfillModel := new(TaskBackfill)
+	for i, status := range []Status{Running, Finish} {
+		// 测试库的表主键未自增, 手动指定ID
+		backfill := &TaskBackfill{Id: int64(i + 1), TaskId: 1, Name: "etl", Status: status,
+			RangeStart: now, RangeEnd: now, StartTime: now}
+		if _, err := backfill.Create(); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+	}
+	if _, err := backfillModel.Update(1, CommonMap{"succeeded": 3, "failed": 1}); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	backfill, err := backfillModel.Detail(1)
+	if err != nil || backfill.Succeeded != 3 || backfill.Failed != 1 {
+		t.Fatalf("unexpected detail %+v err=%v", backfill, err)
+	}
+	if backfill, _ = backfillModel.Detail(10); backfill.Id != 0 {
+		t.Fatalf("expected missing backfill to return empty record, got %+v", backfill)
+	}
+	params := CommonMap{"TaskId": 1, "Status": int(Running)}
+	if total, _ := backfillModel.Total(params); total != 1 {
+		t.Fatalf("expected one running backfill, got %d", total)
+	}
+
+	// 只有执行中的回填可以取消
+	if ok, err := backfillModel.RequestCancel(2); err != nil || ok {
+		t.Fatalf("expected finished backfill not to be canceled, got %v err=%v", ok, err)
+	}
+	if ok, err := backfillModel.RequestCancel(1); err != nil || !ok {
+		t.Fatalf("expected running backfill to be canceled, got %v err=%v", ok, err)
+	}
+	if backfill, _ = backfillModel.Detail(1); backfill.Canceled != 1 {
+		t.Fatalf("expected cancel request to be stored, got %+v", backfill)
+	}
+
+	if affected, _ := backfillModel.InterruptRunning("restart"); affected != 1 {
+		t.Fatalf("expected running backfill to be interrupted, got %d", affected)
+	}
+	if backfill, _ = backfillModel.Detail(1); backfill.Status != Interrupted {
+		t.Fatalf("expected interrupted status, got %d", backfill.Status)
+	}
+
+	for i, status := range []Status{Running, Queued, Finish} {
+		taskLog := &TaskLog{Id: int64(i + 1), TaskId: 1, Name: "etl", Status: status, BackfillId: 1}
+		if _, err = taskLog.Create(); err != nil {
+			t.Fatalf("create log failed: %v", err)
+		}
+	}
+	other := &TaskLog{Id: 4, TaskId: 1, Name: "etl", Status: Running}
+	if _, err = other.Create(); err != nil {
+		t.Fatalf("create log failed: %v", err)
+	}
+	taskLogModel := new(TaskLog)
+	logs, err := taskLogModel.UnfinishedByBackfill(1)
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("expected running and queued backfill logs, got %+v err=%v", logs, err)
+	}
+}
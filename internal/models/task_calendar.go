@@ -0,0 +1,108 @@
+package models
+
+// 任务关联日历的方式
+type TaskCalendarMode int8
+
+const (
+	TaskCalendarExclude TaskCalendarMode = 1 // 日历中的日期不执行
+	TaskCalendarInclude TaskCalendarMode = 2 // 只在日历中的日期执行
+)
+
+type TaskCalendar struct {
+	Id         int              `json:"id" gorm:"primaryKey;autoIncrement"`
+	TaskId     int              `json:"task_id" gorm:"not null;index"`
+	CalendarId int              `json:"calendar_id" gorm:"not null;index"`
+	Mode       TaskCalendarMode `json:"mode" gorm:"type:tinyint;not null;default:1"`
+}
+
+type TaskCalendarDetail struct {
+	TaskCalendar
+	Name  string         `json:"name"`
+	Dates []CalendarDate `json:"-" gorm:"-"`
+}
+
+func (TaskCalendarDetail) TableName() string {
+	return TablePrefix + "task_calendar"
+}
+
+func (tc *TaskCalendar) Remove(taskId int) error {
+	return Db.Where("task_id = ?", taskId).Delete(&TaskCalendar{}).Error
+}
+
+func (tc *TaskCalendar) Add(taskId int, calendars []TaskCalendar) error {
+	err := tc.Remove(taskId)
+	if err != nil {
+		return err
+	}
+	if len(calendars) == 0 {
+		return nil
+	}
+	for i := range calendars {
+		calendars[i].Id = 0
+		calendars[i].TaskId = taskId
+	}
+
+	return Db.Create(&calendars).Error
+}
+
+// 判断日历是否被任务引用
+func (tc *TaskCalendar) CalendarIdExist(calendarId int) (bool, error) {
+	var count int64
+	err := Db.Model(&TaskCalendar{}).Where("calendar_id = ?", calendarId).Count(&count).Error
+	return count > 0, err
+}
+
+func (tc *TaskCalendar) GetTaskIdsByCalendarId(calendarId int) ([]interface{}, error) {
+	list := make([]TaskCalendar, 0)
+	err := Db.Select("task_id").Where("calendar_id = ?", calendarId).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+
+	taskIds := make([]interface{}, len(list))
+	for i, value := range list {
+		taskIds[i] = value.TaskId
+	}
+
+	return taskIds, err
+}
+
+// 批量获取多个任务关联的日历及日期
+func (tc *TaskCalendar) GetCalendarsByTaskIds(taskIds []int) (map[int][]TaskCalendarDetail, error) {
+	calendarMap := make(map[int][]TaskCalendarDetail)
+	if len(taskIds) == 0 {
+		return calendarMap, nil
+	}
+
+	list := make([]TaskCalendarDetail, 0)
+	err := Db.Table(TablePrefix+"task_calendar as tc").
+		Select("tc.id", "tc.task_id", "tc.calendar_id", "tc.mode", "c.name").
+		Joins("INNER JOIN "+TablePrefix+"calendar as c ON tc.calendar_id = c.id").
+		Where("tc.task_id IN ?", taskIds).
+		Order("tc.id ASC").
+		Find(&list).Error
+	if err != nil || len(list) == 0 {
+		return calendarMap, err
+	}
+
+	calendarIds := make([]int, 0, len(list))
+	for _, item := range list {
+		calendarIds = append(calendarIds, item.CalendarId)
+	}
+	dates := make([]CalendarDate, 0)
+	err = Db.Where("calendar_id IN ?", calendarIds).Order("start_date ASC").Find(&dates).Error
+	if err != nil {
+		return calendarMap, err
+	}
+	datesMap := make(map[int][]CalendarDate)
+	for _, date := range dates {
+		datesMap[date.CalendarId] = append(datesMap[date.CalendarId], date)
+	}
+
+	for _, item := range list {
+		item.Dates = datesMap[item.CalendarId]
+		calendarMap[item.TaskId] = append(calendarMap[item.TaskId], item)
+	}
+
+	return calendarMap, nil
+}
@@ -8,9 +8,10 @@ type TaskHost struct {
 
 type TaskHostDetail struct {
 	TaskHost
-	Name  string `json:"name"`
-	Port  int    `json:"port"`
-	Alias string `json:"alias"`
+	Name    string `json:"name"`
+	Port    int    `json:"port"`
+	Alias   string `json:"alias"`
+	SshPort int    `json:"ssh_port"`
 }
 
 func (TaskHostDetail) TableName() string {
@@ -39,14 +40,39 @@ func (th *TaskHost) Add(taskId int, hostIds []int) error {
 func (th *TaskHost) GetHostIdsByTaskId(taskId int) ([]TaskHostDetail, error) {
 	list := make([]TaskHostDetail, 0)
 	err := Db.Table(TablePrefix+"task_host as th").
-		Select("th.id", "th.host_id", "h.alias", "h.name", "h.port").
+		Select("th.id", "th.host_id", "h.alias", "h.name", "h.port", "h.ssh_port").
 		Joins("LEFT JOIN "+TablePrefix+"host as h ON th.host_id = h.id").
 		Where("th.task_id = ?", taskId).
+		Order("th.id ASC").
 		Find(&list).Error
 
 	return list, err
 }
 
+// 获取匹配标签选择器的主机
+func (th *TaskHost) GetHostsBySelector(taskId int, selector string) ([]TaskHostDetail, error) {
+	labels, err := ParseLabels(selector)
+	if err != nil {
+		return nil, err
+	}
+	hostModel := new(Host)
+	hosts, err := hostModel.ListByLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]TaskHostDetail, len(hosts))
+	for i, host := range hosts {
+		list[i].TaskId = taskId
+		list[i].HostId = host.Id
+		list[i].Name = host.Name
+		list[i].Port = host.Port
+		list[i].SshPort = host.SshPort
+		list[i].Alias = host.Alias
+	}
+
+	return list, nil
+}
+
 func (th *TaskHost) GetTaskIdsByHostId(hostId int16) ([]interface{}, error) {
 	list := make([]TaskHost, 0)
 	err := Db.Select("task_id").Where("host_id = ?", hostId).Find(&list).Error
@@ -77,9 +103,10 @@ func (th *TaskHost) GetHostsByTaskIds(taskIds []int) (map[int][]TaskHostDetail,
 
 	list := make([]TaskHostDetail, 0)
 	err := Db.Table(TablePrefix+"task_host as th").
-		Select("th.task_id", "th.id", "th.host_id", "h.alias", "h.name", "h.port").
+		Select("th.task_id", "th.id", "th.host_id", "h.alias", "h.name", "h.port", "h.ssh_port").
 		Joins("LEFT JOIN "+TablePrefix+"host as h ON th.host_id = h.id").
 		Where("th.task_id IN ?", taskIds).
+		Order("th.id ASC").
 		Find(&list).Error
 
 	if err != nil {
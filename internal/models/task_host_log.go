@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+)
+
+// 任务在每台主机上的执行记录, 每次执行(包括重试)一条记录
+type TaskHostLog struct {
+	Id        int64     `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
+	TaskLogId int64     `json:"task_log_id" gorm:"type:bigint;not null;index"`
+	TaskId    int       `json:"task_id" gorm:"not null;index"`
+	HostId    int16     `json:"host_id" gorm:"type:smallint;not null;default:0"`
+	Alias     string    `json:"alias" gorm:"type:varchar(32);not null;default:''"`
+	Name      string    `json:"name" gorm:"type:varchar(64);not null"`
+	Port      int       `json:"port" gorm:"not null;default:0"`
+	Attempt   int       `json:"attempt" gorm:"type:smallint;not null;default:1"` // 第几次执行, 重试时递增
+	Status    Status    `json:"status" gorm:"type:tinyint;not null;default:1"`
+	ErrorType string    `json:"error_type" gorm:"type:varchar(32);not null;default:''"` // 失败时的错误类型
+	Error     string    `json:"error" gorm:"type:varchar(512);not null;default:''"`
+	Output    string    `json:"output" gorm:"type:mediumtext;not null"`
+	StartTime LocalTime `json:"start_time" gorm:"column:start_time"`
+	EndTime   LocalTime `json:"end_time" gorm:"column:end_time"`
+}
+
+func (hostLog *TaskHostLog) Create() (insertId int64, err error) {
+	result := Db.Create(hostLog)
+	if result.Error == nil {
+		insertId = hostLog.Id
+	}
+
+	return insertId, result.Error
+}
+
+// 更新
+func (hostLog *TaskHostLog) Update(id int64, data CommonMap) (int64, error) {
+	updateData := make(map[string]interface{})
+	for k, v := range data {
+		updateData[k] = v
+	}
+	result := Db.Model(&TaskHostLog{}).Where("id = ?", id).UpdateColumns(updateData)
+	return result.RowsAffected, result.Error
+}
+
+// 获取任务日志的每台主机执行记录, 按执行次数、主机排序
+func (hostLog *TaskHostLog) ListByTaskLogId(taskLogId int64) ([]TaskHostLog, error) {
+	list := make([]TaskHostLog, 0)
+	err := Db.Where("task_log_id = ?", taskLogId).Order("attempt ASC, id ASC").Find(&list).Error
+
+	return list, err
+}
+
+// 获取任务日志执行中的主机记录
+func (hostLog *TaskHostLog) RunningByTaskLogIds(taskLogIds []int64) ([]TaskHostLog, error) {
+	list := make([]TaskHostLog, 0)
+	if len(taskLogIds) == 0 {
+		return list, nil
+	}
+	err := Db.Where("task_log_id IN ? AND status = ?", taskLogIds, Running).Order("id ASC").Find(&list).Error
+
+	return list, err
+}
+
+// 执行中的记录标记为中断
+func (hostLog *TaskHostLog) InterruptByTaskLogId(taskLogId int64, result string) (int64, error) {
+	res := Db.Model(&TaskHostLog{}).
+		Where("task_log_id = ? AND status = ?", taskLogId, Running).
+		UpdateColumns(map[string]interface{}{
+			"status":   Interrupted,
+			"error":    result,
+			"end_time": time.Now(),
+		})
+	return res.RowsAffected, res.Error
+}
+
+// 删除开始时间早于t的记录
+func (hostLog *TaskHostLog) RemoveBefore(t time.Time) (int64, error) {
+	result := Db.Where("start_time < ?", t).Delete(&TaskHostLog{})
+	return result.RowsAffected, result.Error
+}
+
+// 清空表
+func (hostLog *TaskHostLog) Clear() (int64, error) {
+	result := Db.Where("1=1").Delete(&TaskHostLog{})
+	return result.RowsAffected, result.Error
+}
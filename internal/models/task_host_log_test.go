@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskHostLogRecords(t *testing.T) {
+	setupTestDb(t, &TaskLog{}, &TaskHostLog{})
+	now := time.Now()
+	taskLog := &TaskLog{Id: 1, TaskId: 1, Name: "deploy", Status: Running, StartTime: LocalTime(now)}
+	if _, err := taskLog.Create(); err != nil {
+		t.Fatalf("create log failed: %v", err)
+	}
+	// 测试库的表主键未自增, 手动指定ID
+	for i, hostLog := range []TaskHostLog{
+		{Id: 1, TaskLogId: 1, TaskId: 1, HostId: 2, Name: "b", Attempt: 2, Status: Running},
+		{Id: 2, TaskLogId: 1, TaskId: 1, HostId: 1, Name: "a", Attempt: 1, Status: Cancel},
+		{Id: 3, TaskLogId: 1, TaskId: 1, HostId: 2, Name: "b", Attempt: 1, Status: Finish},
+		{Id: 4, TaskLogId: 2, TaskId: 1, HostId: 1, Name: "a", Attempt: 1, Status: Running},
+	} {
+		hostLog.StartTime = LocalTime(now.Add(time.Duration(i) * time.Second))
+		if _, err := hostLog.Create(); err != nil {
+			t.Fatalf("create host log failed: %v", err)
+		}
+	}
+
+	hostLogModel := new(TaskHostLog)
+	list, err := hostLogModel.ListByTaskLogId(1)
+	if err != nil || len(list) != 3 || list[0].Id != 2 || list[1].Id != 3 || list[2].Id != 1 {
+		t.Fatalf("expected host logs ordered by attempt, got %+v err=%v", list, err)
+	}
+
+	if list, err = hostLogModel.RunningByTaskLogIds([]int64{1, 2}); err != nil || len(list) != 2 || list[0].Id != 1 || list[1].Id != 4 {
+		t.Fatalf("expected running host logs, got %+v err=%v", list, err)
+	}
+
+	// 中断任务日志时, 执行中的主机记录一起标记为中断
+	if updated, err := taskLog.Interrupt(1, "restart"); !updated || err != nil {
+		t.Fatalf("expected log to be interrupted, updated=%v err=%v", updated, err)
+	}
+	list, _ = hostLogModel.ListByTaskLogId(1)
+	if list[2].Status != Interrupted || list[2].Error != "restart" || list[0].Status != Cancel {
+		t.Fatalf("expected only running host log to be interrupted, got %+v", list)
+	}
+	if list, _ = hostLogModel.ListByTaskLogId(2); list[0].Status != Running {
+		t.Fatalf("expected host log of another task log to be untouched, got %+v", list)
+	}
+
+	if removed, err := hostLogModel.RemoveBefore(now.Add(1500 * time.Millisecond)); err != nil || removed != 2 {
+		t.Fatalf("expected two host logs to be removed, got %d err=%v", removed, err)
+	}
+}
@@ -0,0 +1,191 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// HTTP任务请求体类型
+type TaskHttpBodyType int8
+
+const (
+	TaskHttpBodyNone TaskHttpBodyType = 1 // 无请求体
+	TaskHttpBodyRaw  TaskHttpBodyType = 2 // 原样发送
+	TaskHttpBodyJson TaskHttpBodyType = 3 // JSON
+	TaskHttpBodyForm TaskHttpBodyType = 4 // 表单, 格式为 a=1&b=2
+)
+
+// HTTP任务认证方式
+type TaskHttpAuthType int8
+
+const (
+	TaskHttpAuthNone   TaskHttpAuthType = 1
+	TaskHttpAuthBasic  TaskHttpAuthType = 2 // 用户名、密码
+	TaskHttpAuthBearer TaskHttpAuthType = 3 // 令牌
+)
+
+// 未设置允许的状态码时, 2xx为成功
+const TaskHttpDefaultStatusCodes = "200-299"
+
+// 异步任务未设置等待回调的时间时, 最长等待1小时
+const TaskHttpDefaultDeadline = 3600
+
+// HTTP任务的请求定义, 请求地址保存在Url, 任务的命令只保存地址的摘要, 查询任务时命令替换为完整的地址
+// 未保存请求定义的旧任务仍按GET或表单POST执行, 请求地址使用任务的命令
+// 异步任务请求成功后保持执行中, 远程系统回调后结束执行, 超过等待时间未回调则执行失败
+type TaskHttp struct {
+	Id          int              `json:"id" gorm:"primaryKey;autoIncrement"`
+	TaskId      int              `json:"task_id" gorm:"not null;uniqueIndex"`
+	Url         string           `json:"url" gorm:"type:text;not null"`     // 请求地址
+	Headers     string           `json:"headers" gorm:"type:text;not null"` // 每行一个请求头 Name: Value
+	BodyType    TaskHttpBodyType `json:"body_type" gorm:"type:tinyint;not null;default:1"`
+	Body        string           `json:"body" gorm:"type:mediumtext;not null"`
+	AuthType    TaskHttpAuthType `json:"auth_type" gorm:"type:tinyint;not null;default:1"`
+	AuthUser    string           `json:"auth_user" gorm:"type:varchar(128);not null;default:''"`
+	AuthSecret  string           `json:"-" gorm:"type:varchar(1024);not null;default:''"`           // basic认证的密码或bearer令牌, 不返回给前端
+	StatusCodes string           `json:"status_codes" gorm:"type:varchar(128);not null;default:''"` // 允许的状态码, 如 200-299,304
+	AssertRegex string           `json:"assert_regex" gorm:"type:varchar(512);not null;default:''"` // 响应内容需匹配的正则
+	JsonPath    string           `json:"json_path" gorm:"type:varchar(255);not null;default:''"`    // 响应JSON字段路径, 如 data.items.0.status
+	JsonValue   string           `json:"json_value" gorm:"type:varchar(512);not null;default:''"`   // JSON字段需等于的值
+	ProfileId   int              `json:"profile_id" gorm:"not null;default:0;index"`                // 使用的HTTP客户端配置, 0为默认配置
+	Async       int8             `json:"async" gorm:"type:tinyint;not null;default:0"`              // 是否异步执行, 等待远程系统回调
+	Deadline    int              `json:"deadline" gorm:"type:mediumint;not null;default:0"`         // 等待回调的最长时间(秒), 0为TaskHttpDefaultDeadline
+	HasSecret   bool             `json:"has_auth_secret" gorm:"-"`                                  // 是否已保存认证密码或令牌
+}
+
+// 保存任务的请求定义
+func (th *TaskHttp) Save(taskId int, def TaskHttp) error {
+	def.Id = 0
+	def.TaskId = taskId
+	return Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("task_id = ?", taskId).Delete(&TaskHttp{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&def).Error
+	})
+}
+
+func (th *TaskHttp) Remove(taskId int) error {
+	return Db.Where("task_id = ?", taskId).Delete(&TaskHttp{}).Error
+}
+
+// 获取任务的请求定义, 不存在时返回nil
+func (th *TaskHttp) GetByTaskId(taskId int) (*TaskHttp, error) {
+	def := &TaskHttp{}
+	err := Db.Where("task_id = ?", taskId).First(def).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	def.HasSecret = def.AuthSecret != ""
+
+	return def, nil
+}
+
+// 批量获取任务的请求定义
+func (th *TaskHttp) GetByTaskIds(taskIds []int) (map[int]*TaskHttp, error) {
+	defMap := make(map[int]*TaskHttp)
+	if len(taskIds) == 0 {
+		return defMap, nil
+	}
+	list := make([]TaskHttp, 0)
+	err := Db.Where("task_id IN ?", taskIds).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].HasSecret = list[i].AuthSecret != ""
+		defMap[list[i].TaskId] = &list[i]
+	}
+
+	return defMap, nil
+}
+
+// 使用请求定义中的完整地址作为任务的命令
+func (th *TaskHttp) applyUrl(task *Task) {
+	if th != nil && th.Url != "" {
+		task.Command = th.Url
+	}
+}
+
+// 判断HTTP客户端配置是否被任务引用
+func (th *TaskHttp) ProfileIdExist(profileId int) (bool, error) {
+	var count int64
+	err := Db.Model(&TaskHttp{}).Where("profile_id = ?", profileId).Count(&count).Error
+	return count > 0, err
+}
+
+// ParseHttpHeaders 解析请求头文本, 每行一个 Name: Value, 忽略空行
+func ParseHttpHeaders(text string) (http.Header, error) {
+	header := make(http.Header)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("请求头格式错误: %s", line)
+		}
+		header.Add(name, strings.TrimSpace(value))
+	}
+
+	return header, nil
+}
+
+var statusRangePattern = regexp.MustCompile(`^([1-5][0-9]{2})(?:-([1-5][0-9]{2}))?$`)
+
+// HttpStatusCodes 允许的状态码, 每项为闭区间
+type HttpStatusCodes [][2]int
+
+// ParseHttpStatusCodes 解析允许的状态码, 格式为 200,201,300-399, 空文本为2xx
+func ParseHttpStatusCodes(text string) (HttpStatusCodes, error) {
+	if strings.TrimSpace(text) == "" {
+		text = TaskHttpDefaultStatusCodes
+	}
+	codes := make(HttpStatusCodes, 0)
+	for _, item := range strings.Split(text, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		matches := statusRangePattern.FindStringSubmatch(item)
+		if matches == nil {
+			return nil, fmt.Errorf("状态码格式错误: %s", item)
+		}
+		start, _ := strconv.Atoi(matches[1])
+		end := start
+		if matches[2] != "" {
+			end, _ = strconv.Atoi(matches[2])
+		}
+		if end < start {
+			return nil, fmt.Errorf("状态码范围错误: %s", item)
+		}
+		codes = append(codes, [2]int{start, end})
+	}
+	if len(codes) == 0 {
+		return nil, errors.New("状态码不能为空")
+	}
+
+	return codes, nil
+}
+
+// Contains 状态码是否允许
+func (codes HttpStatusCodes) Contains(statusCode int) bool {
+	for _, item := range codes {
+		if statusCode >= item[0] && statusCode <= item[1] {
+			return true
+		}
+	}
+
+	return false
+}
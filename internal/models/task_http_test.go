@@ -0,0 +1,89 @@
+package models
+
+import "testing"
+
+func TestParseHttpHeaders(t *testing.T) {
+	header, err := ParseHttpHeaders("X-Token: abc\n\n  Accept : application/json \nX-Token: def\nX-Empty:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Get("Accept") != "application/json" || len(header.Values("X-Token")) != 2 || header.Get("X-Empty") != "" {
+		t.Fatalf("unexpected header %v", header)
+	}
+	for _, text := range []string{"X-Token", ": abc", "X Token: abc"} {
+		if _, err = ParseHttpHeaders(text); err == nil {
+			t.Fatalf("expected error for %q", text)
+		}
+	}
+}
+
+func TestParseHttpStatusCodes(t *testing.T) {
+	codes, err := ParseHttpStatusCodes("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !codes.Contains(204) || codes.Contains(304) {
+		t.Fatalf("expected default 2xx, got %v", codes)
+	}
+	codes, err = ParseHttpStatusCodes("200, 300-304")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !codes.Contains(200) || !codes.Contains(302) || codes.Contains(201) || codes.Contains(404) {
+		t.Fatalf("unexpected status codes %v", codes)
+	}
+	for _, text := range []string{"abc", "600", "304-300", "20", ","} {
+		if _, err = ParseHttpStatusCodes(text); err == nil {
+			t.Fatalf("expected error for %q", text)
+		}
+	}
+}
+
+func TestTaskHttpSave(t *testing.T) {
+	setupTestDb(t, &TaskHttp{})
+	taskHttpModel := new(TaskHttp)
+	if err := taskHttpModel.Save(1, TaskHttp{BodyType: TaskHttpBodyJson, Body: `{"a":1}`}); err != nil {
+		t.Fatal(err)
+	}
+	// 再次保存时替换原有的定义
+	if err := taskHttpModel.Save(1, TaskHttp{BodyType: TaskHttpBodyForm, Body: "a=1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := taskHttpModel.Save(2, TaskHttp{AuthType: TaskHttpAuthBearer, AuthSecret: "token"}); err != nil {
+		t.Fatal(err)
+	}
+
+	def, err := taskHttpModel.GetByTaskId(1)
+	if err != nil || def == nil || def.BodyType != TaskHttpBodyForm || def.Body != "a=1" {
+		t.Fatalf("unexpected definition %+v err=%v", def, err)
+	}
+	defMap, err := taskHttpModel.GetByTaskIds([]int{1, 2, 3})
+	if err != nil || len(defMap) != 2 || defMap[2].AuthSecret != "token" || !defMap[2].HasSecret || defMap[1].HasSecret {
+		t.Fatalf("unexpected definitions %+v err=%v", defMap, err)
+	}
+
+	if err = taskHttpModel.Remove(1); err != nil {
+		t.Fatal(err)
+	}
+	if def, err = taskHttpModel.GetByTaskId(1); err != nil || def != nil {
+		t.Fatalf("expected removed definition, got %+v err=%v", def, err)
+	}
+}
+
+func TestTaskHttpApplyUrl(t *testing.T) {
+	task := Task{Protocol: TaskHTTP, Command: "https://example.com/..."}
+	var def *TaskHttp
+	def.applyUrl(&task)
+	if task.Command != "https://example.com/..." {
+		t.Fatalf("expected command to be kept without definition, got %q", task.Command)
+	}
+	// 旧任务未保存请求地址时仍使用任务的命令
+	(&TaskHttp{}).applyUrl(&task)
+	if task.Command != "https://example.com/..." {
+		t.Fatalf("expected command to be kept without url, got %q", task.Command)
+	}
+	(&TaskHttp{Url: "https://example.com/api?a=1"}).applyUrl(&task)
+	if task.Command != "https://example.com/api?a=1" {
+		t.Fatalf("expected command to be replaced by url, got %q", task.Command)
+	}
+}
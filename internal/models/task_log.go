@@ -2,6 +2,7 @@ package models
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -43,6 +44,39 @@ func (t *LocalTime) Scan(value interface{}) error {
 
 type TaskType int8
 
+type TaskLogTrigger int8
+
+// 任务触发方式
+const (
+	TaskLogTriggerCron       TaskLogTrigger = 1 // 定时触发
+	TaskLogTriggerManual     TaskLogTrigger = 2 // 手动运行
+	TaskLogTriggerDependency TaskLogTrigger = 3 // 依赖任务
+	TaskLogTriggerMisfire    TaskLogTrigger = 4 // 错过执行后补偿
+	TaskLogTriggerWorkflow   TaskLogTrigger = 5 // 工作流节点
+	TaskLogTriggerRerun      TaskLogTrigger = 6 // 中断后重新执行
+	TaskLogTriggerPing       TaskLogTrigger = 7 // 被动心跳任务收到心跳请求
+	TaskLogTriggerBackfill   TaskLogTrigger = 8 // 回填历史时间范围内的执行
+)
+
+// 异步HTTP任务的回调状态
+type TaskLogCallback int8
+
+const (
+	TaskLogCallbackNone    TaskLogCallback = 0 // 非异步任务
+	TaskLogCallbackPending TaskLogCallback = 1 // 等待回调
+	TaskLogCallbackSuccess TaskLogCallback = 2 // 回调执行成功
+	TaskLogCallbackFailure TaskLogCallback = 3 // 回调执行失败
+	TaskLogCallbackExpired TaskLogCallback = 4 // 超过等待时间未回调
+)
+
+// 手动运行时覆盖的任务参数, 保存到任务日志用于审计及重新执行
+type TaskRunOverride struct {
+	Args    string            `json:"args,omitempty"`     // 追加到命令后的参数, HTTP任务追加到URL查询参数
+	Env     map[string]string `json:"env,omitempty"`      // 环境变量, 只支持RPC任务
+	HostIds []int             `json:"host_ids,omitempty"` // 只在指定的主机上执行, 只支持RPC任务
+	Timeout int               `json:"timeout,omitempty"`  // 任务执行超时时间(单位秒), 0使用任务配置
+}
+
 // 任务执行日志
 type TaskLog struct {
 	Id         int64        `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
@@ -53,13 +87,37 @@ type TaskLog struct {
 	Command    string       `json:"command" gorm:"type:varchar(256);not null"`
 	Timeout    int          `json:"timeout" gorm:"type:mediumint;not null;default:0"`
 	RetryTimes int8         `json:"retry_times" gorm:"type:tinyint;not null;default:0"`
-	Hostname   string       `json:"hostname" gorm:"type:varchar(128);not null;default:''"`
+	Hostname   string       `json:"hostname" gorm:"type:varchar(128);not null;default:''"` // 执行主机的别名, 逗号分隔
 	StartTime  LocalTime    `json:"start_time" gorm:"column:start_time;autoCreateTime"`
 	EndTime    LocalTime    `json:"end_time" gorm:"column:end_time;autoUpdateTime"`
 	Status     Status       `json:"status" gorm:"type:tinyint;not null;index;default:1"`
 	Result     string       `json:"result" gorm:"type:mediumtext;not null"`
-	TotalTime  int          `json:"total_time" gorm:"-"`
-	BaseModel  `json:"-" gorm:"-"`
+	Attempts   string       `json:"attempts" gorm:"type:mediumtext"` // 发生重试时每次执行的结果, JSON数组
+	// 触发方式及计划执行时间, 补偿执行时为错过的执行时间
+	TriggerType   TaskLogTrigger `json:"trigger_type" gorm:"type:tinyint;not null;default:1"`
+	ScheduledTime *LocalTime     `json:"scheduled_time" gorm:"column:scheduled_time;default:null"`
+	WorkflowRunId int64          `json:"workflow_run_id" gorm:"type:bigint;not null;index;default:0"`
+	BackfillId    int64          `json:"backfill_id" gorm:"type:bigint;not null;index;default:0"`
+	Overrides     string         `json:"overrides" gorm:"type:text"`                          // 手动运行时覆盖的参数, JSON
+	SlaNotified   int8           `json:"sla_notified" gorm:"type:tinyint;not null;default:0"` // 是否已发送执行超时通知
+	// 异步HTTP任务的回调令牌及回调状态, 远程系统通过令牌回调结束执行
+	CallbackToken string          `json:"-" gorm:"type:varchar(64);not null;default:'';index"`
+	Callback      TaskLogCallback `json:"callback" gorm:"type:tinyint;not null;default:0"`
+	TotalTime     int             `json:"total_time" gorm:"-"`
+	BaseModel     `json:"-" gorm:"-"`
+}
+
+// 解析手动运行时覆盖的参数, 未覆盖参数时返回nil
+func (taskLog TaskLog) RunOverride() (*TaskRunOverride, error) {
+	if taskLog.Overrides == "" {
+		return nil, nil
+	}
+	override := new(TaskRunOverride)
+	if err := json.Unmarshal([]byte(taskLog.Overrides), override); err != nil {
+		return nil, err
+	}
+
+	return override, nil
 }
 
 func (taskLog *TaskLog) Create() (insertId int64, err error) {
@@ -81,6 +139,13 @@ func (taskLog *TaskLog) Update(id int64, data CommonMap) (int64, error) {
 	return result.RowsAffected, result.Error
 }
 
+func (taskLog *TaskLog) Detail(id int64) (TaskLog, error) {
+	l := TaskLog{}
+	err := Db.Where("id = ?", id).First(&l).Error
+
+	return l, err
+}
+
 func (taskLog *TaskLog) List(params CommonMap) ([]TaskLog, error) {
 	taskLog.parsePageAndPageSize(params)
 	list := make([]TaskLog, 0)
@@ -102,17 +167,161 @@ func (taskLog *TaskLog) List(params CommonMap) ([]TaskLog, error) {
 	return list, err
 }
 
+// 获取所有执行中、排队中的任务日志
+func (taskLog *TaskLog) UnfinishedList() ([]TaskLog, error) {
+	list := make([]TaskLog, 0)
+	err := Db.Where("status IN ?", []Status{Running, Queued}).Order("id ASC").Find(&list).Error
+
+	return list, err
+}
+
+// 回填记录中执行中或排队中的任务日志
+func (taskLog *TaskLog) UnfinishedByBackfill(backfillId int64) ([]TaskLog, error) {
+	list := make([]TaskLog, 0)
+	err := Db.Where("backfill_id = ? AND status IN ?", backfillId, []Status{Running, Queued}).Find(&list).Error
+
+	return list, err
+}
+
+// 任务日志仍为执行中或排队中时标记为中断, 返回是否更新成功
+func (taskLog *TaskLog) Interrupt(id int64, result string) (bool, error) {
+	res := Db.Model(&TaskLog{}).
+		Where("id = ? AND status IN ?", id, []Status{Running, Queued}).
+		UpdateColumns(map[string]interface{}{
+			"status":   Interrupted,
+			"result":   result,
+			"end_time": time.Now(),
+		})
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false, res.Error
+	}
+	hostLogModel := new(TaskHostLog)
+	_, err := hostLogModel.InterruptByTaskLogId(id, result)
+
+	return true, err
+}
+
+// 等待回调, 重试时使用新的令牌, 原令牌失效
+func (taskLog *TaskLog) WaitCallback(id int64, token string) error {
+	return Db.Model(&TaskLog{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"callback_token": token,
+		"callback":       TaskLogCallbackPending,
+	}).Error
+}
+
+// 收到回调, 任务日志执行中且等待回调时保存回调结果, 返回是否更新成功
+func (taskLog *TaskLog) FinishCallback(token string, callback TaskLogCallback, result string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+	res := Db.Model(&TaskLog{}).
+		Where("callback_token = ? AND callback = ? AND status = ?", token, TaskLogCallbackPending, Running).
+		UpdateColumns(map[string]interface{}{
+			"callback": callback,
+			"result":   result,
+		})
+
+	return res.RowsAffected > 0, res.Error
+}
+
+// 超过等待时间仍未回调时标记为已过期, 之后的回调不再生效, 返回是否更新成功
+func (taskLog *TaskLog) ExpireCallback(id int64, token string) (bool, error) {
+	res := Db.Model(&TaskLog{}).
+		Where("id = ? AND callback_token = ? AND callback = ?", id, token, TaskLogCallbackPending).
+		UpdateColumn("callback", TaskLogCallbackExpired)
+
+	return res.RowsAffected > 0, res.Error
+}
+
+// 获取任务执行中且未发送执行超时通知的任务日志
+func (taskLog *TaskLog) SlaRunningList(taskIds []int) ([]TaskLog, error) {
+	list := make([]TaskLog, 0)
+	if len(taskIds) == 0 {
+		return list, nil
+	}
+	err := Db.Where("status = ? AND sla_notified = 0 AND task_id IN ?", Running, taskIds).Find(&list).Error
+
+	return list, err
+}
+
+// 标记已发送执行超时通知, 返回是否更新成功, 已标记时返回false
+func (taskLog *TaskLog) MarkSlaNotified(id int64) (bool, error) {
+	res := Db.Model(&TaskLog{}).
+		Where("id = ? AND sla_notified = 0", id).
+		UpdateColumn("sla_notified", 1)
+
+	return res.RowsAffected > 0, res.Error
+}
+
+// 任务在指定时间段内是否执行成功
+func (taskLog *TaskLog) FinishedBetween(taskId int, start, end time.Time) (bool, error) {
+	var count int64
+	err := Db.Model(&TaskLog{}).
+		Where("task_id = ? AND status = ? AND end_time >= ? AND end_time <= ?", taskId, Finish, start, end).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// 任务在(start, end]内是否收到过心跳请求
+func (taskLog *TaskLog) PingedBetween(taskId int, start, end time.Time) (bool, error) {
+	var count int64
+	err := Db.Model(&TaskLog{}).
+		Where("task_id = ? AND trigger_type = ? AND start_time > ? AND start_time <= ?", taskId, TaskLogTriggerPing, start, end).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// 获取任务最近一次定时执行的计划执行时间, 不包含手动运行及依赖任务
+// 升级前的日志没有计划执行时间, 以开始时间为准
+func (taskLog *TaskLog) LastScheduledTime(taskId int) (time.Time, error) {
+	triggers := []TaskLogTrigger{TaskLogTriggerCron, TaskLogTriggerMisfire}
+	list := make([]TaskLog, 0, 1)
+	err := Db.Select("scheduled_time").
+		Where("task_id = ? AND trigger_type IN ? AND scheduled_time IS NOT NULL", taskId, triggers).
+		Order("scheduled_time DESC").
+		Limit(1).
+		Find(&list).Error
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(list) > 0 && list[0].ScheduledTime != nil {
+		return time.Time(*list[0].ScheduledTime), nil
+	}
+	err = Db.Select("start_time").
+		Where("task_id = ? AND trigger_type IN ?", taskId, triggers).
+		Order("start_time DESC").
+		Limit(1).
+		Find(&list).Error
+	if err != nil || len(list) == 0 {
+		return time.Time{}, err
+	}
+
+	return time.Time(list[0].StartTime), nil
+}
+
 // 清空表
 func (taskLog *TaskLog) Clear() (int64, error) {
 	result := Db.Where("1=1").Delete(&TaskLog{})
-	return result.RowsAffected, result.Error
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	hostLogModel := new(TaskHostLog)
+	_, err := hostLogModel.Clear()
+	return result.RowsAffected, err
 }
 
 // 删除N个月前的日志
 func (taskLog *TaskLog) Remove(id int) (int64, error) {
 	t := time.Now().AddDate(0, -id, 0)
 	result := Db.Where("start_time <= ?", t.Format(DefaultTimeFormat)).Delete(&TaskLog{})
-	return result.RowsAffected, result.Error
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	hostLogModel := new(TaskHostLog)
+	_, err := hostLogModel.RemoveBefore(t)
+	return result.RowsAffected, err
 }
 
 // 删除N天前的日志
@@ -122,7 +331,12 @@ func (taskLog *TaskLog) RemoveByDays(days int) (int64, error) {
 	}
 	t := time.Now().AddDate(0, 0, -days)
 	result := Db.Where("start_time < ?", t).Delete(&TaskLog{})
-	return result.RowsAffected, result.Error
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	hostLogModel := new(TaskHostLog)
+	_, err := hostLogModel.RemoveBefore(t)
+	return result.RowsAffected, err
 }
 
 func (taskLog *TaskLog) Total(params CommonMap) (int64, error) {
@@ -150,4 +364,16 @@ func (taskLog *TaskLog) parseWhere(query *gorm.DB, params CommonMap) {
 	if ok && status.(int) > -1 {
 		query.Where("status = ?", status)
 	}
+	triggerType, ok := params["TriggerType"]
+	if ok && triggerType.(int) > 0 {
+		query.Where("trigger_type = ?", triggerType)
+	}
+	workflowRunId, ok := params["WorkflowRunId"]
+	if ok && workflowRunId.(int64) > 0 {
+		query.Where("workflow_run_id = ?", workflowRunId)
+	}
+	backfillId, ok := params["BackfillId"]
+	if ok && backfillId.(int64) > 0 {
+		query.Where("backfill_id = ?", backfillId)
+	}
 }
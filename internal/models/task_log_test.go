@@ -0,0 +1,183 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskLogInterruptOnlyUnfinished(t *testing.T) {
+	setupTestDb(t, &TaskLog{}, &TaskHostLog{})
+	statuses := []Status{Running, Queued, Finish}
+	ids := make([]int64, len(statuses))
+	for i, status := range statuses {
+		// 测试库的task_log表主键未自增, 手动指定ID
+		taskLog := &TaskLog{Id: int64(i + 1), TaskId: i + 1, Name: "task", Status: status}
+		id, err := taskLog.Create()
+		if err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+		ids[i] = id
+	}
+
+	taskLogModel := new(TaskLog)
+	list, err := taskLogModel.UnfinishedList()
+	if err != nil || len(list) != 2 || list[0].Id != ids[0] || list[1].Id != ids[1] {
+		t.Fatalf("expected running and queued logs, got %+v err=%v", list, err)
+	}
+
+	for i, expected := range []bool{true, true, false} {
+		updated, err := taskLogModel.Interrupt(ids[i], "interrupted")
+		if err != nil || updated != expected {
+			t.Fatalf("log %d: expected updated=%v, got %v err=%v", ids[i], expected, updated, err)
+		}
+	}
+	if updated, _ := taskLogModel.Interrupt(ids[0], "again"); updated {
+		t.Fatal("expected interrupted log not to be updated again")
+	}
+	list, _ = taskLogModel.UnfinishedList()
+	if len(list) != 0 {
+		t.Fatalf("expected no unfinished logs, got %+v", list)
+	}
+}
+
+func TestTaskLogSlaQueries(t *testing.T) {
+	setupTestDb(t, &TaskLog{})
+	dayStart := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	logs := []TaskLog{
+		{Id: 1, TaskId: 1, Status: Running},
+		{Id: 2, TaskId: 2, Status: Running},
+		{Id: 3, TaskId: 1, Status: Finish, EndTime: LocalTime(dayStart.Add(2 * time.Hour))},
+		{Id: 4, TaskId: 2, Status: Cancel, EndTime: LocalTime(dayStart.Add(2 * time.Hour))},
+	}
+	for i := range logs {
+		if _, err := logs[i].Create(); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+	}
+
+	taskLogModel := new(TaskLog)
+	list, err := taskLogModel.SlaRunningList([]int{1})
+	if err != nil || len(list) != 1 || list[0].Id != 1 {
+		t.Fatalf("expected running log of task 1, got %+v err=%v", list, err)
+	}
+	if updated, err := taskLogModel.MarkSlaNotified(1); err != nil || !updated {
+		t.Fatalf("expected first mark to succeed, got %v err=%v", updated, err)
+	}
+	if updated, _ := taskLogModel.MarkSlaNotified(1); updated {
+		t.Fatal("expected notified log not to be marked again")
+	}
+	if list, _ = taskLogModel.SlaRunningList([]int{1, 2}); len(list) != 1 || list[0].Id != 2 {
+		t.Fatalf("expected only log 2 to be pending, got %+v", list)
+	}
+
+	deadline := dayStart.Add(3 * time.Hour)
+	if finished, err := taskLogModel.FinishedBetween(1, dayStart, deadline); err != nil || !finished {
+		t.Fatalf("expected task 1 finished, got %v err=%v", finished, err)
+	}
+	if finished, _ := taskLogModel.FinishedBetween(2, dayStart, deadline); finished {
+		t.Fatal("expected cancelled run not to count as finished")
+	}
+	if finished, _ := taskLogModel.FinishedBetween(1, dayStart, dayStart.Add(time.Hour)); finished {
+		t.Fatal("expected run after deadline not to count")
+	}
+}
+
+func TestTaskLogPingedBetween(t *testing.T) {
+	setupTestDb(t, &TaskLog{})
+	base := time.Date(2024, 3, 1, 8, 0, 0, 0, time.Local)
+	logs := []TaskLog{
+		{Id: 1, TaskId: 1, Status: Finish, TriggerType: TaskLogTriggerPing, StartTime: LocalTime(base)},
+		{Id: 2, TaskId: 2, Status: Finish, TriggerType: TaskLogTriggerCron, StartTime: LocalTime(base)},
+	}
+	for i := range logs {
+		if _, err := logs[i].Create(); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+	}
+
+	taskLogModel := new(TaskLog)
+	if pinged, err := taskLogModel.PingedBetween(1, base.Add(-time.Hour), base); err != nil || !pinged {
+		t.Fatalf("expected ping in window, got %v err=%v", pinged, err)
+	}
+	if pinged, _ := taskLogModel.PingedBetween(1, base, base.Add(time.Hour)); pinged {
+		t.Fatal("expected ping at window start to be excluded")
+	}
+	if pinged, _ := taskLogModel.PingedBetween(2, base.Add(-time.Hour), base); pinged {
+		t.Fatal("expected non-ping log not to count")
+	}
+}
+
+func TestTaskLogCallback(t *testing.T) {
+	setupTestDb(t, &TaskLog{})
+	for i, status := range []Status{Running, Running, Finish} {
+		taskLog := &TaskLog{Id: int64(i + 1), TaskId: 1, Name: "task", Status: status}
+		if _, err := taskLog.Create(); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+	}
+	taskLogModel := new(TaskLog)
+	for id, token := range map[int64]string{1: "token-a", 2: "token-b", 3: "token-c"} {
+		if err := taskLogModel.WaitCallback(id, token); err != nil {
+			t.Fatalf("wait callback failed: %v", err)
+		}
+	}
+
+	if updated, err := taskLogModel.FinishCallback("token-a", TaskLogCallbackSuccess, "done"); err != nil || !updated {
+		t.Fatalf("expected callback to finish log, got %v err=%v", updated, err)
+	}
+	if updated, _ := taskLogModel.FinishCallback("token-a", TaskLogCallbackFailure, "again"); updated {
+		t.Fatal("expected repeated callback to be ignored")
+	}
+	if updated, _ := taskLogModel.FinishCallback("token-c", TaskLogCallbackSuccess, "late"); updated {
+		t.Fatal("expected callback for finished log to be ignored")
+	}
+	if updated, _ := taskLogModel.FinishCallback("", TaskLogCallbackSuccess, ""); updated {
+		t.Fatal("expected empty token to be ignored")
+	}
+	detail, _ := taskLogModel.Detail(1)
+	if detail.Callback != TaskLogCallbackSuccess || detail.Result != "done" {
+		t.Fatalf("unexpected callback result %+v", detail)
+	}
+
+	if expired, err := taskLogModel.ExpireCallback(2, "token-b"); err != nil || !expired {
+		t.Fatalf("expected pending callback to expire, got %v err=%v", expired, err)
+	}
+	if updated, _ := taskLogModel.FinishCallback("token-b", TaskLogCallbackSuccess, "late"); updated {
+		t.Fatal("expected callback after deadline to be ignored")
+	}
+	if expired, _ := taskLogModel.ExpireCallback(1, "token-a"); expired {
+		t.Fatal("expected finished callback not to expire")
+	}
+}
+
+func TestTaskLogLastScheduledTime(t *testing.T) {
+	setupTestDb(t, &TaskLog{})
+	taskLogModel := new(TaskLog)
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local)
+
+	// 升级前的日志没有计划执行时间, 以开始时间为准
+	legacy := &TaskLog{Id: 1, TaskId: 1, Name: "task", TriggerType: TaskLogTriggerCron, StartTime: LocalTime(base)}
+	if _, err := legacy.Create(); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	last, err := taskLogModel.LastScheduledTime(1)
+	if err != nil || !last.Equal(base) {
+		t.Fatalf("expected start time %s, got %s err=%v", base, last, err)
+	}
+
+	// 重试或排队导致开始时间晚于计划执行时间, 以计划执行时间为准
+	scheduled := LocalTime(base.Add(time.Hour))
+	delayed := &TaskLog{Id: 2, TaskId: 1, Name: "task", TriggerType: TaskLogTriggerMisfire,
+		ScheduledTime: &scheduled, StartTime: LocalTime(base.Add(time.Hour + 10*time.Minute))}
+	manual := &TaskLog{Id: 3, TaskId: 1, Name: "task", TriggerType: TaskLogTriggerManual,
+		ScheduledTime: &scheduled, StartTime: LocalTime(base.Add(2 * time.Hour))}
+	for _, taskLog := range []*TaskLog{delayed, manual} {
+		if _, err := taskLog.Create(); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+	}
+	last, err = taskLogModel.LastScheduledTime(1)
+	if err != nil || !last.Equal(time.Time(scheduled)) {
+		t.Fatalf("expected scheduled time %s, got %s err=%v", time.Time(scheduled), last, err)
+	}
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// SQL任务的执行定义
+// 语句保存在执行定义中, 任务的命令为语句的摘要, 用于任务列表及任务日志展示
+type TaskSql struct {
+	Id           int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	TaskId       int    `json:"task_id" gorm:"not null;uniqueIndex"`
+	DatasourceId int    `json:"datasource_id" gorm:"not null;index"`
+	Statements   string `json:"statements" gorm:"type:mediumtext;not null"`          // 多条语句以分号分隔, 按顺序执行, 支持命令模板
+	FailOnRows   int8   `json:"fail_on_rows" gorm:"type:tinyint;not null;default:0"` // 查询语句返回数据时执行失败, 用于数据检查
+}
+
+// 保存任务的执行定义
+func (ts *TaskSql) Save(taskId int, def TaskSql) error {
+	def.Id = 0
+	def.TaskId = taskId
+	return Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("task_id = ?", taskId).Delete(&TaskSql{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&def).Error
+	})
+}
+
+func (ts *TaskSql) Remove(taskId int) error {
+	return Db.Where("task_id = ?", taskId).Delete(&TaskSql{}).Error
+}
+
+// 获取任务的执行定义, 不存在时返回nil
+func (ts *TaskSql) GetByTaskId(taskId int) (*TaskSql, error) {
+	def := &TaskSql{}
+	err := Db.Where("task_id = ?", taskId).First(def).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return def, nil
+}
+
+// 批量获取任务的执行定义
+func (ts *TaskSql) GetByTaskIds(taskIds []int) (map[int]*TaskSql, error) {
+	defMap := make(map[int]*TaskSql)
+	if len(taskIds) == 0 {
+		return defMap, nil
+	}
+	list := make([]TaskSql, 0)
+	err := Db.Where("task_id IN ?", taskIds).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		defMap[list[i].TaskId] = &list[i]
+	}
+
+	return defMap, nil
+}
+
+// 判断数据源是否被任务引用
+func (ts *TaskSql) DatasourceIdExist(datasourceId int) (bool, error) {
+	var count int64
+	err := Db.Model(&TaskSql{}).Where("datasource_id = ?", datasourceId).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,222 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WorkflowEdgeCondition int8
+
+// 连线条件, 上游节点执行结果满足条件时下游节点才会执行
+const (
+	WorkflowEdgeOnSuccess WorkflowEdgeCondition = 1 // 上游执行成功
+	WorkflowEdgeOnFailure WorkflowEdgeCondition = 2 // 上游执行失败
+	WorkflowEdgeAlways    WorkflowEdgeCondition = 3 // 上游执行完成, 不论成功失败
+)
+
+type WorkflowJoinMode int8
+
+// 节点有多条入边时的汇合方式
+const (
+	WorkflowJoinAll WorkflowJoinMode = 1 // 所有入边条件都满足才执行
+	WorkflowJoinAny WorkflowJoinMode = 2 // 任一入边条件满足即执行
+)
+
+// 工作流, 由任务节点和连线组成的有向无环图
+type Workflow struct {
+	Id          int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string    `json:"name" gorm:"type:varchar(32);not null;uniqueIndex"`
+	Spec        string    `json:"spec" gorm:"type:varchar(64);not null;default:''"`
+	Timezone    string    `json:"timezone" gorm:"type:varchar(64);not null;default:''"`
+	Remark      string    `json:"remark" gorm:"type:varchar(100);not null;default:''"`
+	Status      Status    `json:"status" gorm:"type:tinyint;not null;index;default:0"`
+	CreatedAt   time.Time `json:"created" gorm:"column:created;autoCreateTime"`
+	BaseModel   `json:"-" gorm:"-"`
+	Nodes       []WorkflowNode `json:"nodes" gorm:"-"`
+	Edges       []WorkflowEdge `json:"edges" gorm:"-"`
+	NextRunTime NextRunTime    `json:"next_run_time" gorm:"-"`
+}
+
+// 工作流节点, TaskId为0时是汇合节点, 不执行任务只用于等待多个分支
+type WorkflowNode struct {
+	Id         int              `json:"id" gorm:"primaryKey;autoIncrement"`
+	WorkflowId int              `json:"workflow_id" gorm:"not null;index"`
+	NodeKey    string           `json:"node_key" gorm:"type:varchar(32);not null"`
+	Name       string           `json:"name" gorm:"type:varchar(32);not null;default:''"`
+	TaskId     int              `json:"task_id" gorm:"not null;default:0"`
+	JoinMode   WorkflowJoinMode `json:"join_mode" gorm:"type:tinyint;not null;default:1"`
+}
+
+// 是否为汇合节点
+func (node WorkflowNode) IsJoin() bool {
+	return node.TaskId == 0
+}
+
+// 工作流连线, 通过节点标识关联
+type WorkflowEdge struct {
+	Id         int                   `json:"id" gorm:"primaryKey;autoIncrement"`
+	WorkflowId int                   `json:"workflow_id" gorm:"not null;index"`
+	FromKey    string                `json:"from_key" gorm:"type:varchar(32);not null"`
+	ToKey      string                `json:"to_key" gorm:"type:varchar(32);not null"`
+	Condition  WorkflowEdgeCondition `json:"condition" gorm:"type:tinyint;not null;default:1"`
+}
+
+// 新增工作流及节点、连线
+func (workflow *Workflow) Create() (insertId int, err error) {
+	err = Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workflow).Error; err != nil {
+			return err
+		}
+		return replaceWorkflowGraph(tx, workflow.Id, workflow.Nodes, workflow.Edges)
+	})
+	if err == nil {
+		insertId = workflow.Id
+	}
+
+	return insertId, err
+}
+
+// 更新工作流并替换全部节点、连线
+func (workflow *Workflow) UpdateBean(id int) (int64, error) {
+	var rowsAffected int64
+	err := Db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Workflow{}).Where("id = ?", id).
+			Select("name", "spec", "timezone", "remark").
+			Updates(workflow)
+		if result.Error != nil {
+			return result.Error
+		}
+		rowsAffected = result.RowsAffected
+		return replaceWorkflowGraph(tx, id, workflow.Nodes, workflow.Edges)
+	})
+
+	return rowsAffected, err
+}
+
+func replaceWorkflowGraph(tx *gorm.DB, workflowId int, nodes []WorkflowNode, edges []WorkflowEdge) error {
+	if err := tx.Where("workflow_id = ?", workflowId).Delete(&WorkflowNode{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("workflow_id = ?", workflowId).Delete(&WorkflowEdge{}).Error; err != nil {
+		return err
+	}
+	for i := range nodes {
+		nodes[i].Id = 0
+		nodes[i].WorkflowId = workflowId
+	}
+	for i := range edges {
+		edges[i].Id = 0
+		edges[i].WorkflowId = workflowId
+	}
+	if len(nodes) > 0 {
+		if err := tx.CreateInBatches(nodes, 100).Error; err != nil {
+			return err
+		}
+	}
+	if len(edges) > 0 {
+		return tx.CreateInBatches(edges, 100).Error
+	}
+
+	return nil
+}
+
+// 更新
+func (workflow *Workflow) Update(id int, data CommonMap) (int64, error) {
+	updateData := make(map[string]interface{})
+	for k, v := range data {
+		updateData[k] = v
+	}
+	result := Db.Model(&Workflow{}).Where("id = ?", id).UpdateColumns(updateData)
+	return result.RowsAffected, result.Error
+}
+
+// 删除工作流及节点、连线, 保留执行记录
+func (workflow *Workflow) Delete(id int) (int64, error) {
+	var rowsAffected int64
+	err := Db.Transaction(func(tx *gorm.DB) error {
+		if err := replaceWorkflowGraph(tx, id, nil, nil); err != nil {
+			return err
+		}
+		result := tx.Delete(&Workflow{}, id)
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+
+	return rowsAffected, err
+}
+
+func (workflow *Workflow) Detail(id int) (Workflow, error) {
+	w := Workflow{}
+	err := Db.Where("id = ?", id).First(&w).Error
+	if err != nil {
+		return w, err
+	}
+	w.Nodes = make([]WorkflowNode, 0)
+	err = Db.Where("workflow_id = ?", id).Order("id ASC").Find(&w.Nodes).Error
+	if err != nil {
+		return w, err
+	}
+	w.Edges = make([]WorkflowEdge, 0)
+	err = Db.Where("workflow_id = ?", id).Order("id ASC").Find(&w.Edges).Error
+
+	return w, err
+}
+
+// 获取所有启用且配置了表达式的工作流
+func (workflow *Workflow) ActiveList() ([]Workflow, error) {
+	list := make([]Workflow, 0)
+	err := Db.Where("status = ? AND spec != ''", Enabled).Find(&list).Error
+
+	return list, err
+}
+
+// 任务是否被工作流引用
+func (workflow *Workflow) TaskIdExist(taskId int) (bool, error) {
+	var count int64
+	err := Db.Model(&WorkflowNode{}).Where("task_id = ?", taskId).Count(&count).Error
+	return count > 0, err
+}
+
+func (workflow *Workflow) NameExists(name string, id int) (bool, error) {
+	var count int64
+	query := Db.Model(&Workflow{}).Where("name = ?", name)
+	if id > 0 {
+		query = query.Where("id != ?", id)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+func (workflow *Workflow) List(params CommonMap) ([]Workflow, error) {
+	workflow.parsePageAndPageSize(params)
+	list := make([]Workflow, 0)
+	query := Db.Order("id DESC")
+	workflow.parseWhere(query, params)
+	err := query.Limit(workflow.PageSize).Offset(workflow.pageLimitOffset()).Find(&list).Error
+
+	return list, err
+}
+
+func (workflow *Workflow) Total(params CommonMap) (int64, error) {
+	var count int64
+	query := Db.Model(&Workflow{})
+	workflow.parseWhere(query, params)
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// 解析where
+func (workflow *Workflow) parseWhere(query *gorm.DB, params CommonMap) {
+	if len(params) == 0 {
+		return
+	}
+	name, ok := params["Name"]
+	if ok && name.(string) != "" {
+		query.Where("name LIKE ?", "%"+name.(string)+"%")
+	}
+	status, ok := params["Status"]
+	if ok && status.(int) > -1 {
+		query.Where("status = ?", status)
+	}
+}
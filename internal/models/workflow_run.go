@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 工作流执行记录, 每次执行中各节点的任务日志通过WorkflowRunId关联
+type WorkflowRun struct {
+	Id          int64          `json:"id" gorm:"primaryKey;autoIncrement;type:bigint"`
+	WorkflowId  int            `json:"workflow_id" gorm:"not null;index"`
+	Name        string         `json:"name" gorm:"type:varchar(32);not null"`
+	TriggerType TaskLogTrigger `json:"trigger_type" gorm:"type:tinyint;not null;default:1"`
+	Status      Status         `json:"status" gorm:"type:tinyint;not null;index;default:1"`
+	Result      string         `json:"result" gorm:"type:text;not null"`
+	StartTime   LocalTime      `json:"start_time" gorm:"column:start_time;autoCreateTime"`
+	EndTime     LocalTime      `json:"end_time" gorm:"column:end_time;autoUpdateTime"`
+	BaseModel   `json:"-" gorm:"-"`
+}
+
+func (run *WorkflowRun) Create() (insertId int64, err error) {
+	result := Db.Create(run)
+	if result.Error == nil {
+		insertId = run.Id
+	}
+
+	return insertId, result.Error
+}
+
+// 更新
+func (run *WorkflowRun) Update(id int64, data CommonMap) (int64, error) {
+	updateData := make(map[string]interface{})
+	for k, v := range data {
+		updateData[k] = v
+	}
+	result := Db.Model(&WorkflowRun{}).Where("id = ?", id).UpdateColumns(updateData)
+	return result.RowsAffected, result.Error
+}
+
+func (run *WorkflowRun) Detail(id int64) (WorkflowRun, error) {
+	r := WorkflowRun{}
+	err := Db.Where("id = ?", id).First(&r).Error
+
+	return r, err
+}
+
+func (run *WorkflowRun) List(params CommonMap) ([]WorkflowRun, error) {
+	run.parsePageAndPageSize(params)
+	list := make([]WorkflowRun, 0)
+	query := Db.Order("id DESC")
+	run.parseWhere(query, params)
+	err := query.Limit(run.PageSize).Offset(run.pageLimitOffset()).Find(&list).Error
+
+	return list, err
+}
+
+func (run *WorkflowRun) Total(params CommonMap) (int64, error) {
+	var count int64
+	query := Db.Model(&WorkflowRun{})
+	run.parseWhere(query, params)
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// 执行中的记录标记为中断
+func (run *WorkflowRun) InterruptRunning(result string) (int64, error) {
+	res := Db.Model(&WorkflowRun{}).Where("status = ?", Running).UpdateColumns(map[string]interface{}{
+		"status":   Interrupted,
+		"result":   result,
+		"end_time": time.Now(),
+	})
+	return res.RowsAffected, res.Error
+}
+
+// 删除N天前的执行记录
+func (run *WorkflowRun) RemoveByDays(days int) (int64, error) {
+	if days <= 0 {
+		return 0, nil
+	}
+	t := time.Now().AddDate(0, 0, -days)
+	result := Db.Where("start_time < ?", t).Delete(&WorkflowRun{})
+	return result.RowsAffected, result.Error
+}
+
+// 解析where
+func (run *WorkflowRun) parseWhere(query *gorm.DB, params CommonMap) {
+	if len(params) == 0 {
+		return
+	}
+	workflowId, ok := params["WorkflowId"]
+	if ok && workflowId.(int) > 0 {
+		query.Where("workflow_id = ?", workflowId)
+	}
+	status, ok := params["Status"]
+	if ok && status.(int) > -1 {
+		query.Where("status = ?", status)
+	}
+}
@@ -0,0 +1,87 @@
+package models
+
+import "testing"
+
+func TestWorkflowCreateAndReplaceGraph(t *testing.T) {
+	setupTestDb(t, &Workflow{}, &WorkflowNode{}, &WorkflowEdge{})
+	workflowModel := &Workflow{
+		Name: "release",
+		Spec: "0 0 2 * * *",
+		Nodes: []WorkflowNode{
+			{NodeKey: "a", TaskId: 1},
+			{NodeKey: "b", TaskId: 2},
+			{NodeKey: "join", JoinMode: WorkflowJoinAny},
+		},
+		Edges: []WorkflowEdge{
+			{FromKey: "a", ToKey: "join", Condition: WorkflowEdgeOnSuccess},
+			{FromKey: "b", ToKey: "join", Condition: WorkflowEdgeAlways},
+		},
+	}
+	id, err := workflowModel.Create()
+	if err != nil || id == 0 {
+		t.Fatalf("create failed, id=%d err=%v", id, err)
+	}
+
+	detail, err := workflowModel.Detail(id)
+	if err != nil {
+		t.Fatalf("detail failed: %v", err)
+	}
+	if len(detail.Nodes) != 3 || len(detail.Edges) != 2 || !detail.Nodes[2].IsJoin() {
+		t.Fatalf("unexpected graph %+v", detail)
+	}
+	if exist, _ := workflowModel.TaskIdExist(2); !exist {
+		t.Fatal("expected task to be referenced by workflow")
+	}
+
+	update := &Workflow{Name: "release", Nodes: []WorkflowNode{{NodeKey: "a", TaskId: 1}}}
+	if _, err := update.UpdateBean(id); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	detail, _ = workflowModel.Detail(id)
+	if len(detail.Nodes) != 1 || len(detail.Edges) != 0 || detail.Spec != "" {
+		t.Fatalf("expected graph to be replaced, got %+v", detail)
+	}
+	if exist, _ := workflowModel.TaskIdExist(2); exist {
+		t.Fatal("expected task reference to be removed")
+	}
+
+	if _, err := workflowModel.Delete(id); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if exist, _ := workflowModel.TaskIdExist(1); exist {
+		t.Fatal("expected nodes to be deleted with workflow")
+	}
+}
+
+func TestTaskRemoveKeepsWorkflowNodes(t *testing.T) {
+	setupTestDb(t, &Task{}, &TaskHost{}, &TaskCalendar{}, &TaskConcurrencyGroup{}, &TaskHttp{}, &TaskSql{},
+		&Workflow{}, &WorkflowNode{}, &WorkflowEdge{})
+	taskModel := new(Task)
+	for _, name := range []string{"in-workflow", "standalone"} {
+		item := &Task{Name: name, Spec: "0 0 1 * * *", Command: "echo"}
+		if _, err := item.Create(); err != nil {
+			t.Fatalf("create task failed: %v", err)
+		}
+	}
+	workflowModel := &Workflow{Name: "release", Nodes: []WorkflowNode{{NodeKey: "a", TaskId: 1}}}
+	if _, err := workflowModel.Create(); err != nil {
+		t.Fatalf("create workflow failed: %v", err)
+	}
+	sqlModel := new(TaskSql)
+	if err := sqlModel.Save(2, TaskSql{DatasourceId: 1, Statements: "select 1"}); err != nil {
+		t.Fatalf("save sql failed: %v", err)
+	}
+
+	if err := taskModel.Remove(1); err != ErrTaskInWorkflow {
+		t.Fatalf("expected ErrTaskInWorkflow, got %v", err)
+	}
+	if err := taskModel.Remove(2); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	if detail, _ := taskModel.Detail(1); detail.Id != 1 {
+		t.Fatal("task referenced by workflow should not be removed")
+	}
+	if def, _ := sqlModel.GetByTaskId(2); def != nil {
+		t.Fatalf("expected sql definition removed, got %+v", def)
+	}
+}
@@ -15,6 +15,7 @@ type ResponseWrapper struct {
 	StatusCode int
 	Body       string
 	Header     http.Header
+	Err        error // 请求未得到响应时的错误
 }
 
 type httpDoer interface {
@@ -78,19 +79,54 @@ func PostJson(url string, body string, timeout int) ResponseWrapper {
 	return request(req, timeout)
 }
 
+// Do 发送指定方法、请求头、请求体的请求, 请求头中的User-Agent优先
+// opts不为nil时按客户端配置发送请求
+func Do(method, url string, header http.Header, body string, timeout int, opts *Options) ResponseWrapper {
+	var reader io.Reader
+	if body != "" {
+		reader = bytes.NewBufferString(body)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return createRequestError(err)
+	}
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	if host := header.Get("Host"); host != "" {
+		req.Host = host
+	}
+	if opts == nil {
+		return request(req, timeout)
+	}
+	client, err := opts.client(timeout)
+	if err != nil {
+		return createRequestError(err)
+	}
+
+	return send(req, client)
+}
+
 func request(req *http.Request, timeout int) ResponseWrapper {
+	return send(req, clientFactory(timeout))
+}
+
+func send(req *http.Request, client httpDoer) ResponseWrapper {
 	wrapper := ResponseWrapper{StatusCode: 0, Body: "", Header: make(http.Header)}
-	client := clientFactory(timeout)
 	setRequestHeader(req)
 	resp, err := client.Do(req)
 	if err != nil {
 		wrapper.Body = fmt.Sprintf("执行HTTP请求错误-%s", err.Error())
+		wrapper.Err = err
 		return wrapper
 	}
 	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		wrapper.Body = fmt.Sprintf("读取HTTP请求返回值失败-%s", err.Error())
+		wrapper.Err = err
 		return wrapper
 	}
 	wrapper.StatusCode = resp.StatusCode
@@ -101,10 +137,12 @@ func request(req *http.Request, timeout int) ResponseWrapper {
 }
 
 func setRequestHeader(req *http.Request) {
-	req.Header.Set("User-Agent", "golang/gocron")
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", "golang/gocron")
+	}
 }
 
 func createRequestError(err error) ResponseWrapper {
 	errorMessage := fmt.Sprintf("创建HTTP请求错误-%s", err.Error())
-	return ResponseWrapper{0, errorMessage, make(http.Header)}
+	return ResponseWrapper{StatusCode: 0, Body: errorMessage, Header: make(http.Header), Err: err}
 }
@@ -89,6 +89,29 @@ func TestPostJsonRequest(t *testing.T) {
 	}
 }
 
+func TestDoRequest(t *testing.T) {
+	withMockClient(t, func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodPut {
+			t.Fatalf("expected PUT, got %s", req.Method)
+		}
+		if req.Header.Get("X-Token") != "abc" || req.Header.Get("User-Agent") != "custom" {
+			t.Fatalf("unexpected headers %v", req.Header)
+		}
+		body, _ := io.ReadAll(req.Body)
+		return &http.Response{
+			StatusCode: 204,
+			Body:       io.NopCloser(strings.NewReader("put:" + string(body))),
+			Header:     http.Header{},
+		}, nil
+	})
+
+	header := http.Header{"X-Token": {"abc"}, "User-Agent": {"custom"}}
+	resp := Do(http.MethodPut, "http://example.com", header, "data", 0, nil)
+	if resp.StatusCode != 204 || resp.Body != "put:data" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
 func TestRequestHandlesClientError(t *testing.T) {
 	withMockClient(t, func(req *http.Request) (*http.Response, error) {
 		return nil, errors.New("timeout")
@@ -0,0 +1,124 @@
+package httpclient
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 客户端配置, 用于双向TLS、自定义CA、代理和重定向策略
+type Options struct {
+	CaCert       string // PEM格式的CA证书, 追加到系统证书
+	ClientCert   string // PEM格式的客户端证书
+	ClientKey    string // PEM格式的客户端私钥
+	SkipVerify   bool
+	ProxyUrl     string
+	NoRedirect   bool // 不跟随重定向, 返回重定向响应
+	MaxRedirects int  // 跟随重定向的最大次数, 0为10次
+}
+
+const defaultMaxRedirects = 10
+
+var (
+	// 相同配置的请求复用Transport(连接池)
+	transportsMu sync.Mutex
+	transports   = make(map[[sha256.Size]byte]*http.Transport)
+)
+
+// Validate 校验证书、私钥、代理地址
+func (o *Options) Validate() error {
+	_, err := o.newTransport()
+	return err
+}
+
+func (o *Options) newTransport() (*http.Transport, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: o.SkipVerify}
+	if strings.TrimSpace(o.CaCert) != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(o.CaCert)) {
+			return nil, errors.New("CA证书格式错误")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if o.ClientCert != "" || o.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(o.ClientCert), []byte(o.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("客户端证书或私钥错误: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := defaultClient.Transport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	if o.ProxyUrl != "" {
+		proxyUrl, err := url.Parse(o.ProxyUrl)
+		if err != nil || proxyUrl.Host == "" {
+			return nil, fmt.Errorf("代理地址错误: %s", o.ProxyUrl)
+		}
+		switch proxyUrl.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("不支持的代理协议: %s", proxyUrl.Scheme)
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+
+	return transport, nil
+}
+
+func (o *Options) transport() (*http.Transport, error) {
+	key := sha256.Sum256([]byte(strings.Join([]string{
+		o.CaCert, o.ClientCert, o.ClientKey, fmt.Sprint(o.SkipVerify), o.ProxyUrl,
+	}, "\x00")))
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	if transport, ok := transports[key]; ok {
+		return transport, nil
+	}
+	transport, err := o.newTransport()
+	if err != nil {
+		return nil, err
+	}
+	transports[key] = transport
+
+	return transport, nil
+}
+
+func (o *Options) client(timeout int) (httpDoer, error) {
+	transport, err := o.transport()
+	if err != nil {
+		return nil, err
+	}
+	if timeout <= 0 {
+		timeout = 300
+	}
+	maxRedirects := o.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+	noRedirect := o.NoRedirect
+
+	return &http.Client{
+		Timeout:   time.Duration(timeout) * time.Second,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if noRedirect {
+				return http.ErrUseLastResponse
+			}
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("重定向次数超过%d次", maxRedirects)
+			}
+			return nil
+		},
+	}, nil
+}
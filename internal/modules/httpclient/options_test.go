@@ -0,0 +1,101 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDoWithOptionsTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("secure"))
+	}))
+	defer server.Close()
+	caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	// 默认配置不信任测试服务器的证书
+	if resp := Do(http.MethodGet, server.URL, nil, "", 5, &Options{}); resp.StatusCode != 0 {
+		t.Fatalf("expected certificate error, got %+v", resp)
+	}
+	if resp := Do(http.MethodGet, server.URL, nil, "", 5, &Options{CaCert: caCert}); resp.Body != "secure" {
+		t.Fatalf("expected request with custom CA to succeed, got %+v", resp)
+	}
+	if resp := Do(http.MethodGet, server.URL, nil, "", 5, &Options{SkipVerify: true}); resp.Body != "secure" {
+		t.Fatalf("expected request skipping verification to succeed, got %+v", resp)
+	}
+}
+
+func TestDoWithOptionsClientCert(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.Organization[0]))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	// 使用测试服务器自身的证书作为客户端证书
+	serverCert := server.TLS.Certificates[0]
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverCert.Certificate[0]}))
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(serverCert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes}))
+	if resp := Do(http.MethodGet, server.URL, nil, "", 5, &Options{SkipVerify: true}); resp.StatusCode != 0 {
+		t.Fatalf("expected handshake error without client cert, got %+v", resp)
+	}
+	opts := &Options{SkipVerify: true, ClientCert: certPEM, ClientKey: keyPEM}
+	if resp := Do(http.MethodGet, server.URL, nil, "", 5, opts); resp.StatusCode != http.StatusOK || resp.Body != "Acme Co" {
+		t.Fatalf("expected request with client cert to succeed, got %+v", resp)
+	}
+}
+
+func TestDoWithOptionsRedirectAndProxy(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer target.Close()
+
+	if resp := Do(http.MethodGet, target.URL+"/old", nil, "", 5, &Options{}); resp.Body != "/new" {
+		t.Fatalf("expected redirect to be followed, got %+v", resp)
+	}
+	if resp := Do(http.MethodGet, target.URL+"/old", nil, "", 5, &Options{NoRedirect: true}); resp.StatusCode != http.StatusFound {
+		t.Fatalf("expected redirect response, got %+v", resp)
+	}
+
+	proxied := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied <- r.URL.String()
+		_, _ = w.Write([]byte("via proxy"))
+	}))
+	defer proxy.Close()
+	resp := Do(http.MethodGet, "http://internal.example/ping", nil, "", 5, &Options{ProxyUrl: proxy.URL})
+	if resp.Body != "via proxy" || <-proxied != "http://internal.example/ping" {
+		t.Fatalf("expected request through proxy, got %+v", resp)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	invalid := []Options{
+		{CaCert: "not a certificate"},
+		{ClientCert: "cert"},
+		{ProxyUrl: "ftp://proxy:21"},
+		{ProxyUrl: "proxy"},
+	}
+	for _, opts := range invalid {
+		if err := opts.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", opts)
+		}
+	}
+	valid := Options{ProxyUrl: "socks5://127.0.0.1:1080", SkipVerify: true}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -47,15 +47,15 @@ var enUS = map[string]string{
 	"invalid_log_id":                         "Invalid log ID",
 	"invalid_task_id":                        "Invalid task ID",
 	"get_task_info_failed":                   "Failed to get task information",
-	"only_shell_task_can_stop":               "Only SHELL tasks can be stopped manually",
+	"only_shell_task_can_stop":               "Only SHELL and SQL tasks can be stopped manually",
 	"task_node_list_empty":                   "Task node list is empty",
 	"stop_task_sent":                         "Stop command sent, please wait for task to exit",
 	"param_range_1_12":                       "Parameter value range: 1-12",
 	"delete_failed":                          "Delete failed",
 	"delete_success":                         "Deleted successfully",
-	"http_task_timeout_max_300":              "HTTP task timeout cannot exceed 300 seconds",
 	"crontab_parse_failed":                   "Failed to parse crontab expression",
-	"cannot_set_self_as_child":               "Cannot set current task as child task",
+	"invalid_timezone":                       "Invalid time zone",
+	"invalid_run_at":                         "Invalid or past run time",
 	"host_not_exist":                         "Host does not exist",
 	"refresh_task_host_failed":               "Failed to refresh task host information",
 	"invalid_url":                            "Please enter a valid URL",
@@ -67,7 +67,6 @@ var enUS = map[string]string{
 	"operation_failed":                       "Operation failed",
 	"select_at_least_one_receiver":           "Please select at least one notification receiver",
 	"select_hostname":                        "Please select hostname",
-	"select_dependency":                      "Please select dependency",
 	"host_in_use_cannot_delete":              "Host is in use by tasks and cannot be deleted",
 	"connection_failed":                      "Connection failed",
 	"connection_success":                     "Connection successful",
@@ -78,4 +77,62 @@ var enUS = map[string]string{
 	"password_must_contain_letter_and_digit": "Password must contain both letters and digits",
 	"account_locked":                         "Account locked, please try again in %d minutes",
 	"login_failed_with_attempts":             "Username or password is incorrect, %d attempts remaining",
+	"calendar_date_invalid":                  "Invalid calendar date, expected YYYY-MM-DD",
+	"calendar_file_required":                 "Please upload an .ics file",
+	"calendar_file_too_large":                "The .ics file must not exceed 2MB",
+	"calendar_file_parse_failed":             "Failed to parse .ics file",
+	"calendar_not_exist":                     "Calendar does not exist",
+	"calendar_name_exists":                   "Calendar name already exists",
+	"calendar_in_use_cannot_delete":          "Calendar is in use by tasks and cannot be deleted",
+	"refresh_task_calendar_failed":           "Failed to refresh task calendars",
+	"task_in_workflow_cannot_delete":         "Task is used by a workflow and cannot be deleted",
+	"workflow_not_exist":                     "Workflow does not exist",
+	"workflow_name_exists":                   "Workflow name already exists",
+	"workflow_task_not_exist":                "Task of workflow node does not exist",
+	"workflow_graph_invalid":                 "Invalid workflow nodes or edges",
+	"workflow_has_cycle":                     "Workflow contains a cycle",
+	"workflow_started_check_runs":            "Workflow started, please check the run history",
+	"invalid_retry_on":                       "Invalid retry error types",
+	"concurrency_group_name_exists":          "Concurrency group name already exists",
+	"concurrency_group_not_exist":            "Concurrency group does not exist",
+	"concurrency_group_in_use_cannot_delete": "Concurrency group is used by tasks and cannot be deleted",
+	"run_override_rpc_only":                  "Environment variables and hosts are only supported for shell tasks",
+	"run_override_invalid_env":               "Environment variable names may only contain letters, digits and underscores and must not start with a digit",
+	"run_override_host_not_in_task":          "The selected host does not belong to this task",
+	"run_override_command_too_long":          "The command exceeds 256 characters after appending arguments",
+	"invalid_command_template":               "Invalid command template",
+	"invalid_sla_deadline":                   "Invalid latest completion time, format is HH:MM",
+	"command_required":                       "Please enter the command",
+	"command_too_long":                       "Command cannot exceed 256 characters",
+	"heartbeat_task_must_be_parent":          "Heartbeat tasks must be main tasks",
+	"heartbeat_task_cannot_run":              "Heartbeat tasks cannot be run manually",
+	"heartbeat_task_disabled":                "Task is disabled, heartbeat ignored",
+	"heartbeat_task_not_found":               "Invalid heartbeat URL",
+	"workflow_task_heartbeat_not_allowed":    "Heartbeat tasks cannot be used as workflow nodes",
+	"cron_warning_every_second":              "The expression fires every second, make sure such a high frequency is intended",
+	"cron_warning_sub_minute":                "The expression fires more than once a minute",
+	"cron_warning_five_fields":               "The expression has 5 fields, the first field is seconds rather than minutes",
+	"cron_warning_dom_or_dow":                "Both day of month and day of week are set, the task fires when either matches",
+	"backfill_invalid_time_range":            "Invalid backfill time range, start time must not be after end time and end time must not be in the future",
+	"backfill_not_supported":                 "Only main tasks scheduled by a cron expression support backfill",
+	"backfill_no_occurrence":                 "The task has no fire time in this range",
+	"backfill_too_many":                      "Too many fire times in this range, please narrow it",
+	"backfill_started":                       "Backfill started, check progress in the backfill records",
+	"backfill_not_running":                   "Backfill is not running",
+	"invalid_host_selector":                  "Invalid label selector, expected key=value,key=value",
+	"invalid_host_labels":                    "Invalid host labels, expected key=value,key=value",
+	"invalid_http_request":                   "Invalid HTTP request configuration",
+	"http_profile_name_exists":               "HTTP client profile name already exists",
+	"http_profile_not_exist":                 "HTTP client profile does not exist",
+	"http_profile_in_use_cannot_delete":      "HTTP client profile is used by tasks and cannot be deleted",
+	"invalid_http_profile":                   "Invalid HTTP client profile",
+	"task_callback_not_found":                "Invalid callback URL or the run has already finished",
+	"invalid_ssh_host_key":                   "Invalid SSH host key",
+	"invalid_ssh_private_key":                "Invalid SSH private key or passphrase",
+	"ssh_host_key_scan_failed":               "Failed to fetch SSH host key",
+	"invalid_sql_statements":                 "Invalid SQL statements",
+	"datasource_not_exist":                   "Datasource does not exist",
+	"datasource_name_exists":                 "Datasource name already exists",
+	"datasource_in_use_cannot_delete":        "Datasource is used by tasks and cannot be deleted",
+	"datasource_host_required":               "Please enter the database host and port",
 }
@@ -17,7 +17,11 @@ var messages = map[Locale]map[string]string{
 }
 
 func T(c *gin.Context, key string, args ...interface{}) string {
-	locale := GetLocale(c)
+	return Translate(GetLocale(c), key)
+}
+
+// Translate 按指定语言翻译, 不存在时使用中文
+func Translate(locale Locale, key string) string {
 	msg, ok := messages[locale][key]
 	if !ok {
 		msg = messages[ZhCN][key]
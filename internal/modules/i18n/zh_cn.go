@@ -47,15 +47,15 @@ var zhCN = map[string]string{
 	"invalid_log_id":                         "参数错误: 无效的日志ID",
 	"invalid_task_id":                        "参数错误: 无效的任务ID",
 	"get_task_info_failed":                   "获取任务信息失败",
-	"only_shell_task_can_stop":               "仅支持SHELL任务手动停止",
+	"only_shell_task_can_stop":               "仅支持SHELL、SQL任务手动停止",
 	"task_node_list_empty":                   "任务节点列表为空",
 	"stop_task_sent":                         "已执行停止操作, 请等待任务退出",
 	"param_range_1_12":                       "参数取值范围1-12",
 	"delete_failed":                          "删除失败",
 	"delete_success":                         "删除成功",
-	"http_task_timeout_max_300":              "HTTP任务超时时间不能超过300秒",
 	"crontab_parse_failed":                   "crontab表达式解析失败",
-	"cannot_set_self_as_child":               "不允许设置当前任务为子任务",
+	"invalid_timezone":                       "时区无效",
+	"invalid_run_at":                         "执行时间格式错误或已过期",
 	"host_not_exist":                         "主机不存在",
 	"refresh_task_host_failed":               "刷新任务主机信息失败",
 	"invalid_url":                            "请输入正确的URL地址",
@@ -67,7 +67,6 @@ var zhCN = map[string]string{
 	"operation_failed":                       "操作失败",
 	"select_at_least_one_receiver":           "至少选择一个通知接收者",
 	"select_hostname":                        "请选择主机名",
-	"select_dependency":                      "请选择依赖关系",
 	"host_in_use_cannot_delete":              "有任务引用此主机，不能删除",
 	"connection_failed":                      "连接失败",
 	"connection_success":                     "连接成功",
@@ -78,4 +77,62 @@ var zhCN = map[string]string{
 	"password_must_contain_letter_and_digit": "密码必须包含字母和数字",
 	"account_locked":                         "账户已被锁定，请在%d分钟后重试",
 	"login_failed_with_attempts":             "用户名或密码错误，还剩%d次尝试机会",
+	"calendar_date_invalid":                  "日历日期格式错误, 格式为YYYY-MM-DD",
+	"calendar_file_required":                 "请上传ics文件",
+	"calendar_file_too_large":                "ics文件不能超过2MB",
+	"calendar_file_parse_failed":             "ics文件解析失败",
+	"calendar_not_exist":                     "日历不存在",
+	"calendar_name_exists":                   "日历名称已存在",
+	"calendar_in_use_cannot_delete":          "有任务引用此日历，不能删除",
+	"refresh_task_calendar_failed":           "刷新任务日历失败",
+	"task_in_workflow_cannot_delete":         "有工作流引用此任务，不能删除",
+	"workflow_not_exist":                     "工作流不存在",
+	"workflow_name_exists":                   "工作流名称已存在",
+	"workflow_task_not_exist":                "工作流节点关联的任务不存在",
+	"workflow_graph_invalid":                 "工作流节点或连线配置错误",
+	"workflow_has_cycle":                     "工作流存在循环依赖",
+	"workflow_started_check_runs":            "工作流已开始运行, 请到执行记录中查看结果",
+	"invalid_retry_on":                       "重试错误类型无效",
+	"concurrency_group_name_exists":          "并发限制组名称已存在",
+	"concurrency_group_not_exist":            "并发限制组不存在",
+	"concurrency_group_in_use_cannot_delete": "并发限制组已被任务使用, 不能删除",
+	"run_override_rpc_only":                  "环境变量及主机只支持Shell任务",
+	"run_override_invalid_env":               "环境变量名称只能包含字母、数字和下划线, 且不能以数字开头",
+	"run_override_host_not_in_task":          "选择的主机不属于该任务",
+	"run_override_command_too_long":          "追加参数后命令超过256个字符",
+	"invalid_command_template":               "命令模板错误",
+	"invalid_sla_deadline":                   "最晚完成时间格式错误, 格式为HH:MM",
+	"command_required":                       "请输入命令",
+	"command_too_long":                       "命令不能超过256个字符",
+	"heartbeat_task_must_be_parent":          "被动心跳任务只能是主任务",
+	"heartbeat_task_cannot_run":              "被动心跳任务不能手动运行",
+	"heartbeat_task_disabled":                "任务已停止, 心跳请求未记录",
+	"heartbeat_task_not_found":               "心跳地址无效",
+	"workflow_task_heartbeat_not_allowed":    "工作流节点不能使用被动心跳任务",
+	"cron_warning_every_second":              "表达式每秒执行一次, 请确认是否需要如此频繁地执行",
+	"cron_warning_sub_minute":                "表达式执行间隔小于1分钟",
+	"cron_warning_five_fields":               "表达式只有5个字段, 第一个字段为秒而不是分钟",
+	"cron_warning_dom_or_dow":                "同时指定了日期和星期, 满足任意一个时都会执行",
+	"backfill_invalid_time_range":            "回填时间范围无效, 开始时间不能晚于结束时间, 结束时间不能晚于当前时间",
+	"backfill_not_supported":                 "只有使用表达式调度的主任务支持回填",
+	"backfill_no_occurrence":                 "时间范围内没有需要执行的时间点",
+	"backfill_too_many":                      "时间范围内的执行时间点过多, 请缩小时间范围",
+	"backfill_started":                       "回填已开始, 请到回填记录中查看进度",
+	"backfill_not_running":                   "回填不在执行中",
+	"invalid_host_selector":                  "标签选择器格式错误, 格式为 key=value,key=value",
+	"invalid_host_labels":                    "主机标签格式错误, 格式为 key=value,key=value",
+	"invalid_http_request":                   "HTTP请求配置错误",
+	"http_profile_name_exists":               "HTTP客户端配置名称已存在",
+	"http_profile_not_exist":                 "HTTP客户端配置不存在",
+	"http_profile_in_use_cannot_delete":      "HTTP客户端配置已被任务使用, 不能删除",
+	"invalid_http_profile":                   "HTTP客户端配置错误",
+	"task_callback_not_found":                "回调地址无效或执行已结束",
+	"invalid_ssh_host_key":                   "主机公钥格式错误",
+	"invalid_ssh_private_key":                "SSH私钥或私钥密码错误",
+	"ssh_host_key_scan_failed":               "获取主机公钥失败",
+	"invalid_sql_statements":                 "SQL语句错误",
+	"datasource_not_exist":                   "数据源不存在",
+	"datasource_name_exists":                 "数据源名称已存在",
+	"datasource_in_use_cannot_delete":        "数据源已被任务使用, 不能删除",
+	"datasource_host_required":               "请填写数据库主机及端口",
 }
@@ -0,0 +1,473 @@
+// Package ical 解析iCalendar(.ics)文件中的日程, 用于导入节假日日历
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 日程, 只保留日期, 开始、结束日期均包含在内
+// 重复日程(RRULE)按规则展开为多个日程, 支持FREQ、INTERVAL、COUNT、UNTIL, 以及EXDATE、RDATE和修改单次日程的RECURRENCE-ID
+// 规则中包含BYDAY、BYMONTH等其他条件时返回错误, 不会只导入第一次
+type Event struct {
+	Summary   string
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+)
+
+const (
+	// 未设置COUNT、UNTIL的重复日程, 展开到当前时间之后的年数
+	recurrenceYears = 5
+	// 单个重复日程最多展开的次数
+	maxOccurrences = 1000
+	// 展开时最多计算的时间点数量, 每月31日等规则会跳过不存在的日期
+	maxRecurrenceScan = 10000
+)
+
+var durationPattern = regexp.MustCompile(`^P(?:(\d+)W)?(?:(\d+)D)?(?:T.*)?$`)
+
+var nowFunc = time.Now
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// 解析后的VEVENT
+type vevent struct {
+	Event
+	uid          string
+	recurrenceId time.Time // 修改或取消重复日程中某一次时, 被替换的日期
+	cancelled    bool
+	rule         *recurrence
+	exDates      map[time.Time]bool
+	rDates       []time.Time
+}
+
+// 解析ics内容, 返回所有未取消的日程, 重复日程展开为多个日程
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := make([]vevent, 0)
+	var current []property
+	inEvent := false
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		prop, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("第%d行格式错误: %s", i+1, err)
+		}
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT"):
+			inEvent = true
+			current = current[:0]
+		case prop.name == "END" && strings.EqualFold(prop.value, "VEVENT"):
+			if !inEvent {
+				continue
+			}
+			inEvent = false
+			event, err := buildEvent(current)
+			if err != nil {
+				return nil, err
+			}
+			parsed = append(parsed, event)
+		case inEvent:
+			current = append(current, prop)
+		}
+	}
+
+	// 被单独修改或取消的日期不再按重复规则展开
+	overridden := make(map[string]map[time.Time]bool)
+	for _, event := range parsed {
+		if event.uid == "" || event.recurrenceId.IsZero() {
+			continue
+		}
+		if overridden[event.uid] == nil {
+			overridden[event.uid] = make(map[time.Time]bool)
+		}
+		overridden[event.uid][event.recurrenceId] = true
+	}
+	events := make([]Event, 0, len(parsed))
+	for _, event := range parsed {
+		if event.cancelled {
+			continue
+		}
+		if event.rule == nil && len(event.rDates) == 0 {
+			events = append(events, event.Event)
+			continue
+		}
+		if event.recurrenceId.IsZero() {
+			for date := range overridden[event.uid] {
+				event.exDates[date] = true
+			}
+		}
+		expanded, err := event.expand()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, expanded...)
+	}
+
+	return events, nil
+}
+
+// 合并折行, 以空格或制表符开头的行是上一行的延续
+func unfoldLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lines := make([]string, 0)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// 解析 NAME;PARAM=VALUE:VALUE
+func parseProperty(line string) (property, error) {
+	colon := strings.Index(line, ":")
+	if colon <= 0 {
+		return property{}, fmt.Errorf("缺少冒号: %s", line)
+	}
+	parts := strings.Split(line[:colon], ";")
+	prop := property{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string),
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) == 2 {
+			prop.params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+
+	return prop, nil
+}
+
+func buildEvent(props []property) (vevent, error) {
+	event := vevent{exDates: make(map[time.Time]bool)}
+	var start, end *property
+	duration := ""
+	rule := ""
+	var exDates, rDates []property
+	var recurrenceId *property
+	for i := range props {
+		prop := &props[i]
+		switch prop.name {
+		case "SUMMARY":
+			event.Summary = unescapeText(prop.value)
+		case "UID":
+			event.uid = prop.value
+		case "DTSTART":
+			start = prop
+		case "DTEND":
+			end = prop
+		case "DURATION":
+			duration = prop.value
+		case "STATUS":
+			event.cancelled = strings.EqualFold(prop.value, "CANCELLED")
+		case "RRULE":
+			rule = prop.value
+		case "EXDATE":
+			exDates = append(exDates, *prop)
+		case "RDATE":
+			rDates = append(rDates, *prop)
+		case "RECURRENCE-ID":
+			recurrenceId = prop
+		}
+	}
+	if start == nil {
+		return event, fmt.Errorf("日程[%s]缺少DTSTART", event.Summary)
+	}
+
+	startTime, allDay, err := parseDateTime(*start)
+	if err != nil {
+		return event, fmt.Errorf("日程[%s]开始时间错误: %s", event.Summary, err)
+	}
+	event.StartDate = truncateDate(startTime)
+	event.EndDate = event.StartDate
+
+	switch {
+	case end != nil:
+		endTime, _, err := parseDateTime(*end)
+		if err != nil {
+			return event, fmt.Errorf("日程[%s]结束时间错误: %s", event.Summary, err)
+		}
+		event.EndDate = exclusiveEndDate(startTime, endTime, allDay)
+	case duration != "":
+		endTime, err := addDuration(startTime, duration)
+		if err != nil {
+			return event, fmt.Errorf("日程[%s]持续时间错误: %s", event.Summary, err)
+		}
+		event.EndDate = exclusiveEndDate(startTime, endTime, allDay)
+	}
+	if event.EndDate.Before(event.StartDate) {
+		event.EndDate = event.StartDate
+	}
+
+	if recurrenceId != nil {
+		t, _, err := parseDateTime(*recurrenceId)
+		if err != nil {
+			return event, fmt.Errorf("日程[%s]RECURRENCE-ID错误: %s", event.Summary, err)
+		}
+		event.recurrenceId = truncateDate(t)
+	}
+	if rule != "" {
+		parsedRule, err := parseRecurrence(rule)
+		if err != nil {
+			return event, fmt.Errorf("日程[%s]重复规则错误: %s", event.Summary, err)
+		}
+		event.rule = &parsedRule
+	}
+	for _, prop := range exDates {
+		dates, err := parseDateList(prop)
+		if err != nil {
+			return event, fmt.Errorf("日程[%s]EXDATE错误: %s", event.Summary, err)
+		}
+		for _, date := range dates {
+			event.exDates[date] = true
+		}
+	}
+	for _, prop := range rDates {
+		dates, err := parseDateList(prop)
+		if err != nil {
+			return event, fmt.Errorf("日程[%s]RDATE错误: %s", event.Summary, err)
+		}
+		event.rDates = append(event.rDates, dates...)
+	}
+
+	return event, nil
+}
+
+// 解析以逗号分隔的多个日期, 只保留日期
+func parseDateList(prop property) ([]time.Time, error) {
+	if strings.EqualFold(prop.params["VALUE"], "PERIOD") {
+		return nil, fmt.Errorf("不支持PERIOD类型")
+	}
+	dates := make([]time.Time, 0)
+	for _, value := range strings.Split(prop.value, ",") {
+		t, _, err := parseDateTime(property{name: prop.name, params: prop.params, value: value})
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, truncateDate(t))
+	}
+
+	return dates, nil
+}
+
+// 重复规则
+type recurrence struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+}
+
+func parseRecurrence(value string) (recurrence, error) {
+	rule := recurrence{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return rule, fmt.Errorf("格式错误: %s", part)
+		}
+		key, val := strings.ToUpper(kv[0]), strings.TrimSpace(kv[1])
+		switch key {
+		case "FREQ":
+			rule.freq = strings.ToUpper(val)
+			switch rule.freq {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+			default:
+				return rule, fmt.Errorf("不支持的重复频率: %s", val)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval <= 0 {
+				return rule, fmt.Errorf("INTERVAL错误: %s", val)
+			}
+			rule.interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count <= 0 {
+				return rule, fmt.Errorf("COUNT错误: %s", val)
+			}
+			rule.count = count
+		case "UNTIL":
+			until, _, err := parseDateTime(property{name: key, params: map[string]string{}, value: val})
+			if err != nil {
+				return rule, fmt.Errorf("UNTIL错误: %s", val)
+			}
+			rule.until = truncateDate(until)
+		case "WKST":
+			// 未使用BYDAY时不影响展开结果
+		default:
+			return rule, fmt.Errorf("不支持的重复条件: %s", key)
+		}
+	}
+	if rule.freq == "" {
+		return rule, fmt.Errorf("缺少FREQ")
+	}
+
+	return rule, nil
+}
+
+// 第n个时间点, 按月、按年重复时跳过不存在的日期(如2月30日)
+func (rule recurrence) occurrence(start time.Time, n int) (time.Time, bool) {
+	step := n * rule.interval
+	var date time.Time
+	switch rule.freq {
+	case "DAILY":
+		return start.AddDate(0, 0, step), true
+	case "WEEKLY":
+		return start.AddDate(0, 0, step*7), true
+	case "MONTHLY":
+		date = time.Date(start.Year(), start.Month()+time.Month(step), start.Day(), 0, 0, 0, 0, time.UTC)
+	default:
+		date = time.Date(start.Year()+step, start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	return date, date.Day() == start.Day()
+}
+
+// 展开重复日程, 每次日程的天数与第一次相同
+func (event vevent) expand() ([]Event, error) {
+	span := event.EndDate.Sub(event.StartDate)
+	dates := make([]time.Time, 0)
+	if event.rule != nil {
+		rule := *event.rule
+		horizon := truncateDate(nowFunc()).AddDate(recurrenceYears, 0, 0)
+		generated := 0
+		for n := 0; n < maxRecurrenceScan; n++ {
+			date, ok := rule.occurrence(event.StartDate, n)
+			if !ok {
+				continue
+			}
+			if !rule.until.IsZero() && date.After(rule.until) ||
+				rule.until.IsZero() && rule.count == 0 && date.After(horizon) {
+				break
+			}
+			generated++
+			if rule.count > 0 && generated > rule.count {
+				break
+			}
+			if generated > maxOccurrences {
+				return nil, fmt.Errorf("日程[%s]重复次数超过%d次", event.Summary, maxOccurrences)
+			}
+			dates = append(dates, date)
+		}
+	} else {
+		dates = append(dates, event.StartDate)
+	}
+	dates = append(dates, event.rDates...)
+
+	events := make([]Event, 0, len(dates))
+	seen := make(map[time.Time]bool)
+	for _, date := range dates {
+		if event.exDates[date] || seen[date] {
+			continue
+		}
+		seen[date] = true
+		events = append(events, Event{Summary: event.Summary, StartDate: date, EndDate: date.Add(span)})
+	}
+
+	return events, nil
+}
+
+// 全天日程的结束日期不包含在内, 非全天日程在零点结束时也不包含结束日期
+func exclusiveEndDate(start, end time.Time, allDay bool) time.Time {
+	endDate := truncateDate(end)
+	if !end.After(start) {
+		return truncateDate(start)
+	}
+	if allDay || end.Equal(endDate) {
+		return endDate.AddDate(0, 0, -1)
+	}
+
+	return endDate
+}
+
+// 解析日期或日期时间, 返回是否为全天日期
+func parseDateTime(prop property) (time.Time, bool, error) {
+	value := strings.TrimSpace(prop.value)
+	if strings.EqualFold(prop.params["VALUE"], "DATE") || len(value) == len(dateLayout) {
+		t, err := time.ParseInLocation(dateLayout, value, time.UTC)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.ParseInLocation(dateTimeLayout, strings.TrimSuffix(value, "Z"), time.UTC)
+		if err != nil {
+			return t, false, err
+		}
+		return wallClockDate(t.In(time.Local)), false, nil
+	}
+	location := time.Local
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if loaded, err := time.LoadLocation(tzid); err == nil {
+			location = loaded
+		}
+	}
+	t, err := time.ParseInLocation(dateTimeLayout, value, location)
+	if err != nil {
+		return t, false, err
+	}
+
+	return wallClockDate(t), false, nil
+}
+
+// 只保留墙上时间, 统一用UTC表示, 便于按日期比较
+func wallClockDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+func truncateDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// 只支持按周、天计算的持续时间, 如 P1D、P2W
+func addDuration(start time.Time, duration string) (time.Time, error) {
+	matches := durationPattern.FindStringSubmatch(duration)
+	if matches == nil {
+		return start, fmt.Errorf("不支持的持续时间: %s", duration)
+	}
+	days := 0
+	if matches[1] != "" {
+		weeks, _ := strconv.Atoi(matches[1])
+		days += weeks * 7
+	}
+	if matches[2] != "" {
+		d, _ := strconv.Atoi(matches[2])
+		days += d
+	}
+
+	return start.AddDate(0, 0, days), nil
+}
+
+func unescapeText(value string) string {
+	replacer := strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return strings.TrimSpace(replacer.Replace(value))
+}
@@ -0,0 +1,142 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const holidayICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//test//holidays//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20241001\r\n" +
+	"DTEND;VALUE=DATE:20241008\r\n" +
+	"SUMMARY:National Day\\, Golden\r\n" +
+	"  Week\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20241225\r\n" +
+	"SUMMARY:Christmas\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;TZID=Asia/Shanghai:20240501T090000\r\n" +
+	"DTEND;TZID=Asia/Shanghai:20240502T000000\r\n" +
+	"SUMMARY:Labour Day\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20240101\r\n" +
+	"DURATION:P2D\r\n" +
+	"SUMMARY:New Year\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20240301\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"SUMMARY:Cancelled\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	events, err := Parse(strings.NewReader(holidayICS))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	expected := []struct {
+		summary, start, end string
+	}{
+		{"National Day, Golden Week", "2024-10-01", "2024-10-07"},
+		{"Christmas", "2024-12-25", "2024-12-25"},
+		{"Labour Day", "2024-05-01", "2024-05-01"},
+		{"New Year", "2024-01-01", "2024-01-02"},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %+v", len(expected), len(events), events)
+	}
+	for i, want := range expected {
+		got := events[i]
+		if got.Summary != want.summary {
+			t.Fatalf("event %d: expected summary %q, got %q", i, want.summary, got.Summary)
+		}
+		if got.StartDate.Format("2006-01-02") != want.start || got.EndDate.Format("2006-01-02") != want.end {
+			t.Fatalf("event %d: expected %s~%s, got %s~%s", i, want.start, want.end,
+				got.StartDate.Format("2006-01-02"), got.EndDate.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	content := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:2024-13-01\nEND:VEVENT\nEND:VCALENDAR\n"
+	if _, err := Parse(strings.NewReader(content)); err == nil {
+		t.Fatal("expected error for invalid date")
+	}
+
+	content = "BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:no start\nEND:VEVENT\nEND:VCALENDAR\n"
+	if _, err := Parse(strings.NewReader(content)); err == nil {
+		t.Fatal("expected error for missing DTSTART")
+	}
+}
+
+func TestParseRecurring(t *testing.T) {
+	original := nowFunc
+	nowFunc = func() time.Time { return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { nowFunc = original }()
+
+	content := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:national-day\r\n" +
+		"DTSTART;VALUE=DATE:20221001\r\n" +
+		"DTEND;VALUE=DATE:20221004\r\n" +
+		"RRULE:FREQ=YEARLY;UNTIL=20241231\r\n" +
+		"SUMMARY:National Day\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:maintenance\r\n" +
+		"DTSTART;VALUE=DATE:20240102\r\n" +
+		"RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=4\r\n" +
+		"EXDATE;VALUE=DATE:20240116\r\n" +
+		"SUMMARY:Maintenance\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:maintenance\r\n" +
+		"RECURRENCE-ID;VALUE=DATE:20240130\r\n" +
+		"DTSTART;VALUE=DATE:20240131\r\n" +
+		"SUMMARY:Maintenance\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20200229\r\n" +
+		"RRULE:FREQ=YEARLY\r\n" +
+		"SUMMARY:Leap Day\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	events, err := Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	got := make([]string, 0, len(events))
+	for _, event := range events {
+		got = append(got, event.Summary+" "+event.StartDate.Format("2006-01-02")+"~"+event.EndDate.Format("2006-01-02"))
+	}
+	expected := []string{
+		"National Day 2022-10-01~2022-10-03",
+		"National Day 2023-10-01~2023-10-03",
+		"National Day 2024-10-01~2024-10-03",
+		"Maintenance 2024-01-02~2024-01-02",
+		"Maintenance 2024-02-13~2024-02-13",
+		"Maintenance 2024-01-31~2024-01-31",
+		"Leap Day 2020-02-29~2020-02-29",
+		"Leap Day 2024-02-29~2024-02-29",
+		"Leap Day 2028-02-29~2028-02-29",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected events:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestParseUnsupportedRule(t *testing.T) {
+	content := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:20241128\n" +
+		"RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=4TH\nSUMMARY:Thanksgiving\nEND:VEVENT\nEND:VCALENDAR\n"
+	_, err := Parse(strings.NewReader(content))
+	if err == nil || !strings.Contains(err.Error(), "BYMONTH") {
+		t.Fatalf("expected unsupported rule error, got %v", err)
+	}
+}
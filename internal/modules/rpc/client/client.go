@@ -8,7 +8,6 @@ import (
 
 	"google.golang.org/grpc/status"
 
-	"github.com/gocronx-team/gocron/internal/models"
 	"github.com/gocronx-team/gocron/internal/modules/logger"
 	"github.com/gocronx-team/gocron/internal/modules/rpc/grpcpool"
 	pb "github.com/gocronx-team/gocron/internal/modules/rpc/proto"
@@ -21,25 +20,35 @@ var (
 )
 
 var (
-	errUnavailable = errors.New("无法连接远程服务器")
+	ErrUnavailable = errors.New("无法连接远程服务器")
+	ErrTimeout     = errors.New("执行超时, 强制结束")
+	ErrCanceled    = errors.New("手动停止")
 )
 
+// 命令在远程主机上执行失败, 如退出码非0
+type ExecError struct {
+	Message string
+}
+
+func (e *ExecError) Error() string {
+	return e.Message
+}
+
 func generateTaskUniqueKey(ip string, port int, id int64) string {
 	return fmt.Sprintf("%s:%d:%d", ip, port, id)
 }
 
-func Stop(ip string, port int, id int64) {
+// 停止本节点发起的运行中的任务, 任务不在运行中时返回false
+func Cancel(ip string, port int, id int64) bool {
 	key := generateTaskUniqueKey(ip, port, id)
-	logger.Infof("尝试停止任务#key-%s#taskLogId-%d", key, id)
 	cancel, ok := taskMap.Load(key)
 	if !ok {
-		logger.Warnf("未找到运行中的任务，可能是历史任务，直接更新数据库状态#key-%s", key)
-		// 对于历史任务（重启后丢失的任务），直接更新数据库状态
-		updateOrphanedTaskLog(id)
-		return
+		return false
 	}
 	logger.Infof("找到运行中的任务，执行停止#key-%s", key)
 	cancel.(context.CancelFunc)()
+
+	return true
 }
 
 func Exec(ip string, port int, taskReq *pb.TaskRequest) (string, error) {
@@ -77,31 +86,37 @@ func Exec(ip string, port int, taskReq *pb.TaskRequest) (string, error) {
 		return resp.Output, nil
 	}
 
-	return resp.Output, errors.New(resp.Error)
+	return resp.Output, &ExecError{Message: resp.Error}
+}
+
+// 查询任务是否仍在远程主机上执行, 返回执行中的任务ID
+func Running(ip string, port int, ids []int64) ([]int64, error) {
+	addr := fmt.Sprintf("%s:%d", ip, port)
+	c, err := grpcpool.Pool.Get(addr)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	resp, err := c.Running(ctx, &pb.RunningRequest{Ids: ids})
+	if err != nil {
+		if status.Code(err) == codes.Unavailable {
+			return nil, ErrUnavailable
+		}
+		return nil, err
+	}
+
+	return resp.Ids, nil
 }
 
 func parseGRPCError(err error) (string, error) {
 	switch status.Code(err) {
 	case codes.Unavailable:
-		return "", errUnavailable
+		return "", ErrUnavailable
 	case codes.DeadlineExceeded:
-		return "", errors.New("执行超时, 强制结束")
+		return "", ErrTimeout
 	case codes.Canceled:
-		return "", errors.New("手动停止")
+		return "", ErrCanceled
 	}
 	return "", err
 }
-
-// 处理孤立的任务日志（重启后丢失的任务）
-func updateOrphanedTaskLog(taskLogId int64) {
-	taskLogModel := new(models.TaskLog)
-	_, err := taskLogModel.Update(taskLogId, models.CommonMap{
-		"status": models.Cancel,
-		"result": "系统重启后手动停止",
-	})
-	if err != nil {
-		logger.Errorf("更新孤立任务日志状态失败#taskLogId-%d#错误-%s", taskLogId, err.Error())
-	} else {
-		logger.Infof("已更新孤立任务日志状态为已取消#taskLogId-%d", taskLogId)
-	}
-}
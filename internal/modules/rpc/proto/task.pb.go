@@ -12,6 +12,8 @@ It has these top-level messages:
 
 	TaskRequest
 	TaskResponse
+	RunningRequest
+	RunningResponse
 */
 package rpc
 
@@ -38,9 +40,10 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 type TaskRequest struct {
-	Command string `protobuf:"bytes,2,opt,name=command" json:"command,omitempty"`
-	Timeout int32  `protobuf:"varint,3,opt,name=timeout" json:"timeout,omitempty"`
-	Id      int64  `protobuf:"varint,4,opt,name=id" json:"id,omitempty"`
+	Command string            `protobuf:"bytes,2,opt,name=command" json:"command,omitempty"`
+	Timeout int32             `protobuf:"varint,3,opt,name=timeout" json:"timeout,omitempty"`
+	Id      int64             `protobuf:"varint,4,opt,name=id" json:"id,omitempty"`
+	Env     map[string]string `protobuf:"bytes,5,rep,name=env" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *TaskRequest) Reset()                    { *m = TaskRequest{} }
@@ -69,6 +72,13 @@ func (m *TaskRequest) GetId() int64 {
 	return 0
 }
 
+func (m *TaskRequest) GetEnv() map[string]string {
+	if m != nil {
+		return m.Env
+	}
+	return nil
+}
+
 type TaskResponse struct {
 	Output string `protobuf:"bytes,1,opt,name=output" json:"output,omitempty"`
 	Error  string `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
@@ -93,9 +103,43 @@ func (m *TaskResponse) GetError() string {
 	return ""
 }
 
+type RunningRequest struct {
+	Ids []int64 `protobuf:"varint,1,rep,packed,name=ids" json:"ids,omitempty"`
+}
+
+func (m *RunningRequest) Reset()                    { *m = RunningRequest{} }
+func (m *RunningRequest) String() string            { return proto.CompactTextString(m) }
+func (*RunningRequest) ProtoMessage()               {}
+func (*RunningRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *RunningRequest) GetIds() []int64 {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+type RunningResponse struct {
+	Ids []int64 `protobuf:"varint,1,rep,packed,name=ids" json:"ids,omitempty"`
+}
+
+func (m *RunningResponse) Reset()                    { *m = RunningResponse{} }
+func (m *RunningResponse) String() string            { return proto.CompactTextString(m) }
+func (*RunningResponse) ProtoMessage()               {}
+func (*RunningResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *RunningResponse) GetIds() []int64 {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*TaskRequest)(nil), "rpc.TaskRequest")
 	proto.RegisterType((*TaskResponse)(nil), "rpc.TaskResponse")
+	proto.RegisterType((*RunningRequest)(nil), "rpc.RunningRequest")
+	proto.RegisterType((*RunningResponse)(nil), "rpc.RunningResponse")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -110,6 +154,7 @@ const _ = grpc.SupportPackageIsVersion4
 
 type TaskClient interface {
 	Run(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*TaskResponse, error)
+	Running(ctx context.Context, in *RunningRequest, opts ...grpc.CallOption) (*RunningResponse, error)
 }
 
 type taskClient struct {
@@ -129,10 +174,20 @@ func (c *taskClient) Run(ctx context.Context, in *TaskRequest, opts ...grpc.Call
 	return out, nil
 }
 
+func (c *taskClient) Running(ctx context.Context, in *RunningRequest, opts ...grpc.CallOption) (*RunningResponse, error) {
+	out := new(RunningResponse)
+	err := grpc.Invoke(ctx, "/rpc.Task/Running", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Task service
 
 type TaskServer interface {
 	Run(context.Context, *TaskRequest) (*TaskResponse, error)
+	Running(context.Context, *RunningRequest) (*RunningResponse, error)
 }
 
 func RegisterTaskServer(s *grpc.Server, srv TaskServer) {
@@ -157,6 +212,24 @@ func _Task_Run_Handler(srv interface{}, ctx context.Context, dec func(interface{
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Task_Running_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunningRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServer).Running(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Task/Running",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServer).Running(ctx, req.(*RunningRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Task_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "rpc.Task",
 	HandlerType: (*TaskServer)(nil),
@@ -165,6 +238,10 @@ var _Task_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Run",
 			Handler:    _Task_Run_Handler,
 		},
+		{
+			MethodName: "Running",
+			Handler:    _Task_Running_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "task.proto",
@@ -173,17 +250,23 @@ var _Task_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("task.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 184 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x4c, 0x8f, 0xcf, 0x8a, 0x83, 0x30,
-	0x10, 0xc6, 0x37, 0x46, 0x5d, 0x76, 0x76, 0x59, 0xda, 0xa1, 0x94, 0xd0, 0x93, 0x78, 0xf2, 0x50,
-	0x3c, 0xb4, 0x3d, 0xf6, 0x25, 0x1a, 0xfa, 0x02, 0x56, 0x73, 0x10, 0xd1, 0x49, 0x93, 0xc9, 0xfb,
-	0x17, 0xff, 0x81, 0xc7, 0xdf, 0x0c, 0xf3, 0xfd, 0xe6, 0x03, 0xe0, 0xca, 0x77, 0xa5, 0x75, 0xc4,
-	0x84, 0xd2, 0xd9, 0x3a, 0x7f, 0xc0, 0xef, 0xb3, 0xf2, 0x9d, 0x36, 0xef, 0x60, 0x3c, 0xa3, 0x82,
-	0xef, 0x9a, 0xfa, 0xbe, 0x1a, 0x1a, 0x15, 0x65, 0xa2, 0xf8, 0xd1, 0x2b, 0x8e, 0x1b, 0x6e, 0x7b,
-	0x43, 0x81, 0x95, 0xcc, 0x44, 0x91, 0xe8, 0x15, 0xf1, 0x1f, 0xa2, 0xb6, 0x51, 0x71, 0x26, 0x0a,
-	0xa9, 0xa3, 0xb6, 0xc9, 0xef, 0xf0, 0x37, 0x47, 0x7a, 0x4b, 0x83, 0x37, 0x78, 0x84, 0x94, 0x02,
-	0xdb, 0xc0, 0x4a, 0x4c, 0x91, 0x0b, 0xe1, 0x01, 0x12, 0xe3, 0x1c, 0xb9, 0xc5, 0x34, 0xc3, 0xe5,
-	0x06, 0xf1, 0x78, 0x8d, 0x67, 0x90, 0x3a, 0x0c, 0xb8, 0x2b, 0x9d, 0xad, 0xcb, 0xcd, 0x8b, 0xa7,
-	0xfd, 0x66, 0x32, 0x1b, 0xf2, 0xaf, 0x57, 0x3a, 0x55, 0xba, 0x7e, 0x02, 0x00, 0x00, 0xff, 0xff,
-	0xd7, 0x7f, 0x8a, 0x9d, 0xe0, 0x00, 0x00, 0x00,
+	// 284 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x51, 0xdd, 0x4a, 0xf4, 0x30,
+	0x14, 0xfc, 0xd2, 0xec, 0xcf, 0xe7, 0x59, 0x59, 0x6b, 0x5c, 0x24, 0xf6, 0xaa, 0xc4, 0x9b, 0x82,
+	0xd2, 0x8b, 0x15, 0x16, 0x11, 0x6f, 0xf7, 0x05, 0x82, 0x2f, 0x50, 0xdb, 0x20, 0xa5, 0xdb, 0xa4,
+	0xe6, 0xa7, 0xb0, 0x2f, 0xe6, 0xf3, 0x49, 0xda, 0x46, 0x76, 0xf5, 0x2e, 0x33, 0x67, 0xce, 0xcc,
+	0x1c, 0x02, 0x60, 0x0b, 0xd3, 0xe4, 0x9d, 0x56, 0x56, 0x11, 0xac, 0xbb, 0x92, 0x7d, 0x21, 0x58,
+	0xbd, 0x15, 0xa6, 0xe1, 0xe2, 0xd3, 0x09, 0x63, 0x09, 0x85, 0x65, 0xa9, 0xda, 0xb6, 0x90, 0x15,
+	0x8d, 0x52, 0x94, 0x5d, 0xf0, 0x00, 0xfd, 0xc4, 0xd6, 0xad, 0x50, 0xce, 0x52, 0x9c, 0xa2, 0x6c,
+	0xce, 0x03, 0x24, 0x6b, 0x88, 0xea, 0x8a, 0xce, 0x52, 0x94, 0x61, 0x1e, 0xd5, 0x15, 0x79, 0x00,
+	0x2c, 0x64, 0x4f, 0xe7, 0x29, 0xce, 0x56, 0xdb, 0xbb, 0x5c, 0x77, 0x65, 0x7e, 0x12, 0x91, 0xef,
+	0x65, 0xbf, 0x97, 0x56, 0x1f, 0xb9, 0x57, 0x25, 0x3b, 0xf8, 0x1f, 0x08, 0x12, 0x03, 0x6e, 0xc4,
+	0x91, 0xa2, 0x21, 0xd8, 0x3f, 0xc9, 0x06, 0xe6, 0x7d, 0x71, 0x70, 0x62, 0x2a, 0x33, 0x82, 0x97,
+	0xe8, 0x19, 0xb1, 0x57, 0xb8, 0x1c, 0x4d, 0x4d, 0xa7, 0xa4, 0x11, 0xe4, 0x16, 0x16, 0xca, 0xd9,
+	0xce, 0xd9, 0x69, 0x7d, 0x42, 0xde, 0x41, 0x68, 0xad, 0x74, 0x70, 0x18, 0x00, 0x63, 0xb0, 0xe6,
+	0x4e, 0xca, 0x5a, 0x7e, 0x84, 0xc3, 0x63, 0xc0, 0x75, 0x65, 0x28, 0x4a, 0x71, 0x86, 0xb9, 0x7f,
+	0xb2, 0x7b, 0xb8, 0xfa, 0xd1, 0x4c, 0x21, 0x7f, 0x44, 0xdb, 0x03, 0xcc, 0x7c, 0x0d, 0xf2, 0x08,
+	0x98, 0x3b, 0x49, 0xe2, 0xdf, 0xd7, 0x26, 0xd7, 0x27, 0xcc, 0xe8, 0xc2, 0xfe, 0x91, 0x1d, 0x2c,
+	0x27, 0x6b, 0x72, 0x33, 0xcc, 0xcf, 0xcb, 0x24, 0x9b, 0x73, 0x32, 0xec, 0xbd, 0x2f, 0x86, 0x9f,
+	0x7b, 0xfa, 0x1e, 0x00, 0x60, 0x94, 0x28, 0xbc, 0xc7, 0x01, 0x00, 0x00,
 }
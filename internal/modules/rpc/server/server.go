@@ -4,6 +4,7 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -19,6 +20,12 @@ import (
 
 type Server struct{}
 
+// 执行中的任务, 任务唯一ID => 执行数
+var runningTasks = struct {
+	sync.Mutex
+	m map[int64]int
+}{m: make(map[int64]int)}
+
 var keepAlivePolicy = keepalive.EnforcementPolicy{
 	MinTime:             10 * time.Second,
 	PermitWithoutStream: true,
@@ -37,7 +44,13 @@ func (s Server) Run(ctx context.Context, req *pb.TaskRequest) (*pb.TaskResponse,
 		}
 	}()
 	log.Infof("execute cmd start: [id: %d cmd: %s]", req.Id, req.Command)
-	output, err := utils.ExecShell(ctx, req.Command)
+	addRunningTask(req.Id)
+	defer removeRunningTask(req.Id)
+	env := make([]string, 0, len(req.Env))
+	for key, value := range req.Env {
+		env = append(env, key+"="+value)
+	}
+	output, err := utils.ExecShell(ctx, req.Command, env...)
 	resp := new(pb.TaskResponse)
 	resp.Output = output
 	if err != nil {
@@ -50,6 +63,35 @@ func (s Server) Run(ctx context.Context, req *pb.TaskRequest) (*pb.TaskResponse,
 	return resp, nil
 }
 
+// 返回请求的任务中仍在执行的任务ID, 调度器重启后据此确认任务是否仍在执行
+func (s Server) Running(ctx context.Context, req *pb.RunningRequest) (*pb.RunningResponse, error) {
+	resp := new(pb.RunningResponse)
+	runningTasks.Lock()
+	defer runningTasks.Unlock()
+	for _, id := range req.Ids {
+		if runningTasks.m[id] > 0 {
+			resp.Ids = append(resp.Ids, id)
+		}
+	}
+
+	return resp, nil
+}
+
+func addRunningTask(id int64) {
+	runningTasks.Lock()
+	defer runningTasks.Unlock()
+	runningTasks.m[id]++
+}
+
+func removeRunningTask(id int64) {
+	runningTasks.Lock()
+	defer runningTasks.Unlock()
+	runningTasks.m[id]--
+	if runningTasks.m[id] <= 0 {
+		delete(runningTasks.m, id)
+	}
+}
+
 func Start(addr string, enableTLS bool, certificate auth.Certificate) {
 	l, err := net.Listen("tcp", addr)
 	if err != nil {
@@ -3,6 +3,7 @@ package setting
 import (
 	"errors"
 	"os"
+	"strings"
 
 	"github.com/gocronx-team/gocron/internal/modules/logger"
 	"github.com/gocronx-team/gocron/internal/modules/utils"
@@ -37,6 +38,15 @@ type Setting struct {
 
 	ConcurrencyQueue int
 	AuthSecret       string
+	// 对外访问地址, 异步HTTP任务回调地址的前缀, 如 https://gocron.example.com
+	CallbackUrl string
+
+	// 高可用部署, 多个实例通过数据库租约选举唯一的调度节点
+	HA struct {
+		Enable   bool
+		LeaseTTL int // 租约有效期(秒), 调度节点宕机后最长经过该时间由其他实例接管
+		NodeId   string
+	}
 }
 
 // 读取配置
@@ -70,6 +80,14 @@ func Read(filename string) (*Setting, error) {
 	if s.AuthSecret == "" {
 		s.AuthSecret = utils.RandAuthToken()
 	}
+	s.CallbackUrl = strings.TrimRight(section.Key("callback.url").MustString(""), "/")
+
+	s.HA.Enable = section.Key("ha.enable").MustBool(false)
+	s.HA.LeaseTTL = section.Key("ha.lease.ttl").MustInt(15)
+	if s.HA.LeaseTTL < 3 {
+		s.HA.LeaseTTL = 3
+	}
+	s.HA.NodeId = section.Key("ha.node.id").MustString("")
 
 	s.EnableTLS = section.Key("enable_tls").MustBool(false)
 	s.CAFile = section.Key("ca_file").MustString("")
@@ -30,6 +30,10 @@ func TestReadReturnsConfiguredValues(t *testing.T) {
 		concurrency.queue=200
 		auth_secret=existing-secret
 		enable_tls=false
+		ha.enable=true
+		ha.lease.ttl=20
+		ha.node.id=node-a
+		callback.url=https://gocron.example.com/
     `
 	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
 		t.Fatalf("write config failed: %v", err)
@@ -49,6 +53,12 @@ func TestReadReturnsConfiguredValues(t *testing.T) {
 	if s.ConcurrencyQueue != 200 || s.AuthSecret != "existing-secret" {
 		t.Fatalf("unexpected concurrency/auth config: %+v", s)
 	}
+	if !s.HA.Enable || s.HA.LeaseTTL != 20 || s.HA.NodeId != "node-a" {
+		t.Fatalf("unexpected ha config: %+v", s.HA)
+	}
+	if s.CallbackUrl != "https://gocron.example.com" {
+		t.Fatalf("unexpected callback url: %s", s.CallbackUrl)
+	}
 }
 
 func TestReadGeneratesAuthSecretWhenMissing(t *testing.T) {
@@ -68,6 +78,9 @@ db.engine=mysql
 	if s.AuthSecret == "" {
 		t.Fatal("expected generated auth secret when config missing")
 	}
+	if s.HA.Enable || s.HA.LeaseTTL != 15 {
+		t.Fatalf("unexpected default ha config: %+v", s.HA)
+	}
 }
 
 func TestReadEnableTLSSucceedsWhenFilesExist(t *testing.T) {
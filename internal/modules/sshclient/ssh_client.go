@@ -0,0 +1,299 @@
+package sshclient
+
+// 通过SSH在主机上执行命令, 主机无需部署gocron-node
+// 超时、停止与RPC调用一致: 超时或停止时向远程进程发送KILL信号并断开连接
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	pb "github.com/gocronx-team/gocron/internal/modules/rpc/proto"
+	"golang.org/x/crypto/ssh"
+)
+
+// 主机的SSH连接配置
+type Config struct {
+	Host       string
+	Port       int
+	User       string
+	Password   string // 密码认证的密码, 私钥认证时为私钥的密码
+	PrivateKey string // PEM格式的私钥, 为空时使用密码认证
+	HostKey    string // 主机公钥, authorized_keys格式, 如 ssh-ed25519 AAAA...
+}
+
+var (
+	ErrUnavailable = errors.New("无法连接远程服务器")
+	ErrTimeout     = errors.New("执行超时, 强制结束")
+	ErrCanceled    = errors.New("手动停止")
+	ErrNoHostKey   = errors.New("未配置主机公钥, 无法校验主机身份")
+)
+
+// 命令在远程主机上执行失败, 如退出码非0
+type ExecError struct {
+	Message string
+}
+
+func (e *ExecError) Error() string {
+	return e.Message
+}
+
+// 建立连接、完成握手的超时时间
+var connectTimeout = 10 * time.Second
+
+var (
+	taskMap sync.Map
+
+	envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+func generateTaskUniqueKey(ip string, port int, id int64) string {
+	return fmt.Sprintf("%s:%d:%d", ip, port, id)
+}
+
+// 停止本节点发起的运行中的任务, 任务不在运行中时返回false
+func Cancel(ip string, port int, id int64) bool {
+	cancel, ok := taskMap.Load(generateTaskUniqueKey(ip, port, id))
+	if !ok {
+		return false
+	}
+	cancel.(context.CancelFunc)()
+
+	return true
+}
+
+// ValidateHostKey 校验主机公钥格式
+func ValidateHostKey(hostKey string) error {
+	_, err := Config{HostKey: hostKey}.hostKey()
+	return err
+}
+
+// ValidatePrivateKey 校验私钥, 私钥有密码时passphrase为私钥的密码
+func ValidatePrivateKey(privateKey, passphrase string) error {
+	_, err := Config{PrivateKey: privateKey, Password: passphrase}.authMethods()
+	return err
+}
+
+func (cfg Config) addr() string {
+	return net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+}
+
+func (cfg Config) hostKey() (ssh.PublicKey, error) {
+	if strings.TrimSpace(cfg.HostKey) == "" {
+		return nil, ErrNoHostKey
+	}
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(cfg.HostKey))
+	if err != nil {
+		return nil, fmt.Errorf("主机公钥格式错误: %s", err)
+	}
+
+	return key, nil
+}
+
+func (cfg Config) authMethods() ([]ssh.AuthMethod, error) {
+	if strings.TrimSpace(cfg.PrivateKey) == "" {
+		return []ssh.AuthMethod{
+			ssh.Password(cfg.Password),
+			ssh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = cfg.Password
+				}
+				return answers, nil
+			}),
+		}, nil
+	}
+	signer, err := ssh.ParsePrivateKey([]byte(cfg.PrivateKey))
+	var missingErr *ssh.PassphraseMissingError
+	if errors.As(err, &missingErr) {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(cfg.PrivateKey), []byte(cfg.Password))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("私钥错误: %s", err)
+	}
+
+	return []ssh.AuthMethod{ssh.PublicKeys(signer)}, nil
+}
+
+func (cfg Config) dial(ctx context.Context) (*ssh.Client, error) {
+	hostKey, err := cfg.hostKey()
+	if err != nil {
+		return nil, err
+	}
+	auth, err := cfg.authMethods()
+	if err != nil {
+		return nil, err
+	}
+	clientConfig := &ssh.ClientConfig{
+		User:            cfg.User,
+		Auth:            auth,
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Timeout:         connectTimeout,
+	}
+
+	return dial(ctx, cfg.addr(), clientConfig)
+}
+
+func dial(ctx context.Context, addr string, clientConfig *ssh.ClientConfig) (*ssh.Client, error) {
+	dialer := net.Dialer{Timeout: connectTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(connectTimeout))
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, clientConfig)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+// Exec 在主机上执行命令, 返回标准输出及标准错误
+func Exec(cfg Config, taskReq *pb.TaskRequest) (string, error) {
+	defer func() {
+		if err := recover(); err != nil {
+			logger.Error("panic#sshclient/ssh_client.go:Exec#", err)
+		}
+	}()
+	if taskReq.Timeout <= 0 || taskReq.Timeout > 86400 {
+		taskReq.Timeout = 86400
+	}
+	command, err := buildCommand(taskReq.Command, taskReq.Env)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(taskReq.Timeout)*time.Second)
+	defer cancel()
+	taskUniqueKey := generateTaskUniqueKey(cfg.Host, cfg.Port, taskReq.Id)
+	taskMap.Store(taskUniqueKey, cancel)
+	defer taskMap.Delete(taskUniqueKey)
+
+	client, err := cfg.dial(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", contextError(ctx)
+		}
+		return "", err
+	}
+	defer client.Close()
+	session, err := client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+
+	output := new(syncBuffer)
+	session.Stdout = output
+	session.Stderr = output
+	if err = session.Start(command); err != nil {
+		return "", err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Wait()
+	}()
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// 断开连接前结束远程进程, 不支持信号的服务端断开连接后由sshd结束
+		_ = session.Signal(ssh.SIGKILL)
+		_ = client.Close()
+		return output.String(), contextError(ctx)
+	}
+	if err == nil {
+		return output.String(), nil
+	}
+	var exitErr *ssh.ExitError
+	var missingErr *ssh.ExitMissingError
+	if errors.As(err, &exitErr) || errors.As(err, &missingErr) {
+		return output.String(), &ExecError{Message: err.Error()}
+	}
+
+	return output.String(), err
+}
+
+func contextError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	return ErrCanceled
+}
+
+// 环境变量通过export传递, sshd默认不接受客户端设置的环境变量
+func buildCommand(command string, env map[string]string) (string, error) {
+	if len(env) == 0 {
+		return command, nil
+	}
+	names := make([]string, 0, len(env))
+	for name := range env {
+		if !envNamePattern.MatchString(name) {
+			return "", fmt.Errorf("环境变量名称错误: %s", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var builder strings.Builder
+	for _, name := range names {
+		builder.WriteString("export " + name + "=" + shellQuote(env[name]) + "; ")
+	}
+	builder.WriteString(command)
+
+	return builder.String(), nil
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// ScanHostKey 获取主机公钥, 返回authorized_keys格式, 用于保存主机时确认主机身份
+func ScanHostKey(host string, port int) (string, error) {
+	var hostKey ssh.PublicKey
+	errCaptured := errors.New("host key captured")
+	clientConfig := &ssh.ClientConfig{
+		User: "gocron",
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			hostKey = key
+			return errCaptured
+		},
+		Timeout: connectTimeout,
+	}
+	client, err := dial(context.Background(), net.JoinHostPort(host, strconv.Itoa(port)), clientConfig)
+	if client != nil {
+		_ = client.Close()
+	}
+	if hostKey == nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(hostKey))), nil
+}
+
+// 标准输出、标准错误写入同一个缓冲区
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
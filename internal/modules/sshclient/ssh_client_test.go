@@ -0,0 +1,277 @@
+package sshclient
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	pb "github.com/gocronx-team/gocron/internal/modules/rpc/proto"
+	"golang.org/x/crypto/ssh"
+)
+
+// 进程内的SSH服务, 使用本机的sh执行命令
+type testServer struct {
+	host      string
+	port      int
+	hostKey   string
+	clientKey string // 允许登录的客户端私钥, PEM格式
+	listener  net.Listener
+}
+
+const (
+	testUser     = "deploy"
+	testPassword = "secret"
+)
+
+func newTestServer(t *testing.T) *testServer {
+	_, hostPriv, _ := ed25519.GenerateKey(rand.Reader)
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientPub, clientPriv, _ := ed25519.GenerateKey(rand.Reader)
+	authorized, err := ssh.NewPublicKey(clientPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(clientPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == testUser && string(password) == testPassword {
+				return nil, nil
+			}
+			return nil, errors.New("password rejected")
+		},
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == testUser && string(key.Marshal()) == string(authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("key rejected")
+		},
+	}
+	config.AddHostKey(hostSigner)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveConn(conn, config)
+		}
+	}()
+	addr := listener.Addr().(*net.TCPAddr)
+
+	return &testServer{
+		host:      addr.IP.String(),
+		port:      addr.Port,
+		hostKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(hostSigner.PublicKey()))),
+		clientKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes})),
+		listener:  listener,
+	}
+}
+
+func serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go serveSession(channel, requests)
+	}
+}
+
+func serveSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	var cmd *exec.Cmd
+	exited := make(chan struct{})
+	for req := range requests {
+		switch req.Type {
+		case "exec":
+			length := binary.BigEndian.Uint32(req.Payload)
+			cmd = exec.Command("sh", "-c", string(req.Payload[4:4+length]))
+			cmd.Stdout = channel
+			cmd.Stderr = channel.Stderr()
+			if err := cmd.Start(); err != nil {
+				_ = req.Reply(false, nil)
+				return
+			}
+			_ = req.Reply(true, nil)
+			go func() {
+				status := 0
+				if err := cmd.Wait(); err != nil {
+					status = 1
+					var exitErr *exec.ExitError
+					if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+						status = exitErr.ExitCode()
+					}
+				}
+				payload := make([]byte, 4)
+				binary.BigEndian.PutUint32(payload, uint32(status))
+				_, _ = channel.SendRequest("exit-status", false, payload)
+				close(exited)
+				_ = channel.Close()
+			}()
+		case "signal":
+			if cmd != nil && cmd.Process != nil {
+				_ = cmd.Process.Signal(syscall.SIGKILL)
+			}
+		default:
+			if req.WantReply {
+				_ = req.Reply(false, nil)
+			}
+		}
+	}
+	if cmd != nil {
+		select {
+		case <-exited:
+		case <-time.After(time.Second):
+			_ = cmd.Process.Kill()
+		}
+	}
+}
+
+func (s *testServer) config() Config {
+	return Config{Host: s.host, Port: s.port, User: testUser, Password: testPassword, HostKey: s.hostKey}
+}
+
+func TestExecWithPasswordAndKey(t *testing.T) {
+	server := newTestServer(t)
+	cfg := server.config()
+	output, err := Exec(cfg, &pb.TaskRequest{Id: 1, Command: "echo $GREETING-$TARGET; echo warn >&2", Timeout: 10,
+		Env: map[string]string{"GREETING": "hello", "TARGET": "it's me"}})
+	// 标准输出、标准错误通过不同的通道返回, 顺序不固定
+	if err != nil || !strings.Contains(output, "hello-it's me\n") || !strings.Contains(output, "warn\n") {
+		t.Fatalf("unexpected output %q err=%v", output, err)
+	}
+
+	cfg.Password = ""
+	cfg.PrivateKey = server.clientKey
+	output, err = Exec(cfg, &pb.TaskRequest{Id: 2, Command: "echo key; exit 3", Timeout: 10})
+	var execErr *ExecError
+	if !errors.As(err, &execErr) || output != "key\n" || !strings.Contains(err.Error(), "3") {
+		t.Fatalf("expected exit error, got %q err=%v", output, err)
+	}
+
+	cfg.PrivateKey = ""
+	cfg.Password = "wrong"
+	if _, err = Exec(cfg, &pb.TaskRequest{Id: 3, Command: "echo denied", Timeout: 10}); err == nil {
+		t.Fatal("expected authentication error")
+	}
+}
+
+func TestExecVerifiesHostKey(t *testing.T) {
+	server := newTestServer(t)
+	other := newTestServer(t)
+	cfg := server.config()
+
+	cfg.HostKey = other.hostKey
+	if _, err := Exec(cfg, &pb.TaskRequest{Id: 1, Command: "echo hi", Timeout: 10}); err == nil ||
+		!strings.Contains(err.Error(), "host key mismatch") {
+		t.Fatalf("expected host key mismatch, got %v", err)
+	}
+	cfg.HostKey = ""
+	if _, err := Exec(cfg, &pb.TaskRequest{Id: 2, Command: "echo hi", Timeout: 10}); !errors.Is(err, ErrNoHostKey) {
+		t.Fatalf("expected missing host key error, got %v", err)
+	}
+
+	scanned, err := ScanHostKey(server.host, server.port)
+	if err != nil || scanned != server.hostKey {
+		t.Fatalf("unexpected scanned host key %q err=%v", scanned, err)
+	}
+}
+
+func TestExecTimeoutAndCancel(t *testing.T) {
+	server := newTestServer(t)
+	cfg := server.config()
+
+	start := time.Now()
+	output, err := Exec(cfg, &pb.TaskRequest{Id: 1, Command: "echo started; sleep 30", Timeout: 1})
+	if !errors.Is(err, ErrTimeout) || output != "started\n" || time.Since(start) > 10*time.Second {
+		t.Fatalf("expected timeout, got %q err=%v", output, err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := Exec(cfg, &pb.TaskRequest{Id: 2, Command: "sleep 30", Timeout: 60})
+		done <- err
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for !Cancel(server.host, server.port, 2) {
+		if time.Now().After(deadline) {
+			t.Fatal("task was not registered for cancellation")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case err = <-done:
+		if !errors.Is(err, ErrCanceled) {
+			t.Fatalf("expected canceled error, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("canceled task did not return")
+	}
+	if Cancel(server.host, server.port, 2) {
+		t.Fatal("expected finished task to be removed")
+	}
+}
+
+func TestExecUnavailable(t *testing.T) {
+	server := newTestServer(t)
+	cfg := server.config()
+	_ = server.listener.Close()
+	if _, err := Exec(cfg, &pb.TaskRequest{Id: 1, Command: "echo hi", Timeout: 10}); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected unavailable error, got %v", err)
+	}
+}
+
+func TestValidateKeys(t *testing.T) {
+	server := newTestServer(t)
+	for _, hostKey := range []string{"", "ssh-ed25519 invalid", server.clientKey} {
+		if err := ValidateHostKey(hostKey); err == nil {
+			t.Fatalf("expected error for host key %q", hostKey)
+		}
+	}
+	if err := ValidateHostKey(server.hostKey); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := ValidatePrivateKey("not a key", ""); err == nil {
+		t.Fatal("expected error for invalid private key")
+	}
+	if err := ValidatePrivateKey(server.clientKey, ""); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if command, _ := buildCommand("run", map[string]string{"B": "2", "A": "1"}); command != "export A='1'; export B='2'; run" {
+		t.Fatalf("unexpected command %s", command)
+	}
+	if _, err := buildCommand("run", map[string]string{"A-B": "1"}); err == nil {
+		t.Fatal("expected invalid env name error")
+	}
+}
@@ -18,9 +18,12 @@ type Result struct {
 	err    error
 }
 
-// 执行shell命令，可设置执行超时时间
-func ExecShell(ctx context.Context, command string) (string, error) {
+// 执行shell命令，可设置执行超时时间, env为追加的环境变量, 格式 KEY=VALUE
+func ExecShell(ctx context.Context, command string, env ...string) (string, error) {
 	cmd := exec.Command("cmd", "/C", command)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	// 隐藏cmd窗口
 	cmd.SysProcAttr = &syscall.SysProcAttr{
 		HideWindow: true,
@@ -164,10 +164,12 @@ else
     HOSTNAME=$(hostname)
 fi
 echo "Using hostname/IP: $HOSTNAME"
+# 主机标签, 例如: curl -fsSL '...' | GOCRON_LABELS='role=db,env=prod' bash
+LABELS="${GOCRON_LABELS:-}"
 REGISTER_URL="${GOCRON_SERVER}/api/agent/register"
 RESPONSE=$(curl -fsSL -X POST "$REGISTER_URL" \
     -H "Content-Type: application/json" \
-    -d "{\"token\":\"$TOKEN\",\"hostname\":\"$HOSTNAME\"}")
+    -d "{\"token\":\"$TOKEN\",\"hostname\":\"$HOSTNAME\",\"labels\":\"$LABELS\"}")
 
 if echo "$RESPONSE" | grep -q '"code":0'; then
     echo "Agent registered successfully"
@@ -255,6 +257,7 @@ func Register(c *gin.Context) {
 	var req struct {
 		Token    string `json:"token" binding:"required"`
 		Hostname string `json:"hostname" binding:"required"`
+		Labels   string `json:"labels" binding:"max=255"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -277,11 +280,19 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	labels, err := models.ParseLabels(req.Labels)
+	if err != nil {
+		json := utils.JsonResponse{}
+		c.String(http.StatusOK, json.CommonFailure("Invalid labels", err))
+		return
+	}
+
 	host := &models.Host{
 		Name:   req.Hostname,
 		Alias:  req.Hostname,
 		Port:   5921,
 		Remark: "Auto registered",
+		Labels: labels.String(),
 	}
 
 	exists, err := host.NameExists(req.Hostname, 0)
@@ -302,6 +313,12 @@ func Register(c *gin.Context) {
 		logger.Infof("主机注册成功: %s", req.Hostname)
 	} else {
 		logger.Infof("主机已存在，跳过创建: %s", req.Hostname)
+		// 重新注册时更新主机标签
+		if len(labels) > 0 {
+			if err := host.UpdateLabelsByName(req.Hostname, host.Labels); err != nil {
+				logger.Error("更新主机标签失败:", err)
+			}
+		}
 	}
 
 	json := utils.JsonResponse{}
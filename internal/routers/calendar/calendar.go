@@ -0,0 +1,291 @@
+package calendar
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/ical"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/routers/base"
+	"github.com/gocronx-team/gocron/internal/service"
+)
+
+// 导入的ics文件最大字节数
+const maxImportSize = 2 << 20
+
+type DateForm struct {
+	StartDate string `form:"start_date" json:"start_date" binding:"required"`
+	EndDate   string `form:"end_date" json:"end_date"`
+	Summary   string `form:"summary" json:"summary" binding:"max=128"`
+}
+
+type CalendarForm struct {
+	Id     int        `form:"id" json:"id"`
+	Name   string     `form:"name" json:"name" binding:"required,max=32"`
+	Remark string     `form:"remark" json:"remark" binding:"max=100"`
+	Dates  []DateForm `form:"dates" json:"dates" binding:"dive"`
+}
+
+// Index 日历列表
+func Index(c *gin.Context) {
+	calendarModel := new(models.Calendar)
+	queryParams := parseQueryParams(c)
+	total, err := calendarModel.Total(queryParams)
+	if err != nil {
+		logger.Error(err)
+	}
+	calendars, err := calendarModel.List(queryParams)
+	if err != nil {
+		logger.Error(err)
+	}
+
+	jsonResp := utils.JsonResponse{}
+	result := jsonResp.Success(utils.SuccessContent, map[string]interface{}{
+		"total": total,
+		"data":  calendars,
+	})
+	c.String(http.StatusOK, result)
+}
+
+// All 获取所有日历
+func All(c *gin.Context) {
+	calendarModel := new(models.Calendar)
+	calendars, err := calendarModel.List(models.CommonMap{})
+	if err != nil {
+		logger.Error(err)
+	}
+
+	jsonResp := utils.JsonResponse{}
+	result := jsonResp.Success(utils.SuccessContent, calendars)
+	c.String(http.StatusOK, result)
+}
+
+// Detail 日历详情
+func Detail(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	calendarModel := new(models.Calendar)
+	calendar, err := calendarModel.Detail(id)
+	jsonResp := utils.JsonResponse{}
+	var result string
+	if err != nil || calendar.Id == 0 {
+		logger.Errorf("获取日历详情失败#日历id-%d", id)
+		result = jsonResp.Success(utils.SuccessContent, nil)
+	} else {
+		result = jsonResp.Success(utils.SuccessContent, calendar)
+	}
+	c.String(http.StatusOK, result)
+}
+
+// Store 保存、修改日历
+func Store(c *gin.Context) {
+	var form CalendarForm
+	json := utils.JsonResponse{}
+	if err := c.ShouldBind(&form); err != nil {
+		result := json.CommonFailure(i18n.T(c, "form_validation_failed"))
+		c.String(http.StatusOK, result)
+		return
+	}
+	dates, err := parseDateForms(form.Dates)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "calendar_date_invalid"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	calendarModel := new(models.Calendar)
+	calendarModel.Name = strings.TrimSpace(form.Name)
+	calendarModel.Remark = strings.TrimSpace(form.Remark)
+	calendarModel.Dates = dates
+	saveCalendar(c, form.Id, calendarModel)
+}
+
+// Import 从iCalendar(.ics)文件导入日期, 指定id时替换该日历的全部日期, 否则新建日历
+func Import(c *gin.Context) {
+	json := utils.JsonResponse{}
+	file, err := c.FormFile("file")
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "calendar_file_required"))
+		c.String(http.StatusOK, result)
+		return
+	}
+	if file.Size > maxImportSize {
+		result := json.CommonFailure(i18n.T(c, "calendar_file_too_large"))
+		c.String(http.StatusOK, result)
+		return
+	}
+	reader, err := file.Open()
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "calendar_file_required"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+	defer reader.Close()
+	events, err := ical.Parse(reader)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "calendar_file_parse_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	id, _ := strconv.Atoi(c.PostForm("id"))
+	calendarModel := new(models.Calendar)
+	if id > 0 {
+		calendar, err := calendarModel.Detail(id)
+		if err != nil {
+			result := json.CommonFailure(i18n.T(c, "calendar_not_exist"))
+			c.String(http.StatusOK, result)
+			return
+		}
+		calendarModel.Name = calendar.Name
+		calendarModel.Remark = calendar.Remark
+	} else {
+		calendarModel.Name = strings.TrimSpace(c.PostForm("name"))
+		calendarModel.Remark = strings.TrimSpace(c.PostForm("remark"))
+		if calendarModel.Name == "" {
+			calendarModel.Name = strings.TrimSuffix(file.Filename, ".ics")
+		}
+		if len([]rune(calendarModel.Name)) > 32 {
+			result := json.CommonFailure(i18n.T(c, "form_validation_failed"))
+			c.String(http.StatusOK, result)
+			return
+		}
+	}
+	calendarModel.Dates = make([]models.CalendarDate, 0, len(events))
+	for _, event := range events {
+		calendarModel.Dates = append(calendarModel.Dates, models.CalendarDate{
+			StartDate: event.StartDate.Format(models.CalendarDateFormat),
+			EndDate:   event.EndDate.Format(models.CalendarDateFormat),
+			Summary:   truncate(event.Summary, 128),
+		})
+	}
+	saveCalendar(c, id, calendarModel)
+}
+
+func saveCalendar(c *gin.Context, id int, calendarModel *models.Calendar) {
+	json := utils.JsonResponse{}
+	nameExists, err := calendarModel.NameExists(calendarModel.Name, id)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "operation_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+	if nameExists {
+		result := json.CommonFailure(i18n.T(c, "calendar_name_exists"))
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	if id > 0 {
+		_, err = calendarModel.UpdateBean(id)
+	} else {
+		id, err = calendarModel.Create()
+	}
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "save_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	// 重新加载关联该日历的任务
+	taskCalendarModel := new(models.TaskCalendar)
+	taskIds, err := taskCalendarModel.GetTaskIdsByCalendarId(id)
+	if err == nil && len(taskIds) > 0 {
+		taskModel := new(models.Task)
+		tasks, err := taskModel.ActiveListByIds(taskIds)
+		if err == nil {
+			service.ServiceTask.BatchAdd(tasks)
+		}
+	}
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "refresh_task_calendar_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	result := json.Success(i18n.T(c, "save_success"), map[string]interface{}{
+		"id":    id,
+		"dates": len(calendarModel.Dates),
+	})
+	c.String(http.StatusOK, result)
+}
+
+// Remove 删除日历
+func Remove(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	json := utils.JsonResponse{}
+	taskCalendarModel := new(models.TaskCalendar)
+	exist, err := taskCalendarModel.CalendarIdExist(id)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "operation_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+	if exist {
+		result := json.CommonFailure(i18n.T(c, "calendar_in_use_cannot_delete"))
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	calendarModel := new(models.Calendar)
+	_, err = calendarModel.Delete(id)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "operation_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	result := json.Success(i18n.T(c, "operation_success"), nil)
+	c.String(http.StatusOK, result)
+}
+
+// 校验日期格式, 结束日期为空时与开始日期相同
+func parseDateForms(forms []DateForm) ([]models.CalendarDate, error) {
+	dates := make([]models.CalendarDate, 0, len(forms))
+	for _, item := range forms {
+		start, err := time.Parse(models.CalendarDateFormat, strings.TrimSpace(item.StartDate))
+		if err != nil {
+			return nil, err
+		}
+		end := start
+		if strings.TrimSpace(item.EndDate) != "" {
+			end, err = time.Parse(models.CalendarDateFormat, strings.TrimSpace(item.EndDate))
+			if err != nil {
+				return nil, err
+			}
+		}
+		if end.Before(start) {
+			start, end = end, start
+		}
+		dates = append(dates, models.CalendarDate{
+			StartDate: start.Format(models.CalendarDateFormat),
+			EndDate:   end.Format(models.CalendarDateFormat),
+			Summary:   strings.TrimSpace(item.Summary),
+		})
+	}
+
+	return dates, nil
+}
+
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) <= length {
+		return value
+	}
+
+	return string(runes[:length])
+}
+
+// 解析查询参数
+func parseQueryParams(c *gin.Context) models.CommonMap {
+	var params = models.CommonMap{}
+	params["Name"] = strings.TrimSpace(c.Query("name"))
+	base.ParsePageAndPageSize(c, params)
+
+	return params
+}
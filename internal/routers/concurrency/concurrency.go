@@ -0,0 +1,138 @@
+package concurrency
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/routers/base"
+)
+
+type GroupForm struct {
+	Id             int                     `form:"id" json:"id"`
+	Name           string                  `form:"name" json:"name" binding:"required,max=32"`
+	MaxConcurrency int                     `form:"max_concurrency" json:"max_concurrency" binding:"required,min=1,max=1000"`
+	Scope          models.ConcurrencyScope `form:"scope" json:"scope" binding:"required,oneof=1 2 3"`
+	Tag            string                  `form:"tag" json:"tag" binding:"max=32"`
+	Remark         string                  `form:"remark" json:"remark" binding:"max=100"`
+}
+
+// Index 并发限制组列表
+func Index(c *gin.Context) {
+	groupModel := new(models.ConcurrencyGroup)
+	queryParams := parseQueryParams(c)
+	total, err := groupModel.Total(queryParams)
+	if err != nil {
+		logger.Error(err)
+	}
+	groups, err := groupModel.List(queryParams)
+	if err != nil {
+		logger.Error(err)
+	}
+
+	jsonResp := utils.JsonResponse{}
+	result := jsonResp.Success(utils.SuccessContent, map[string]interface{}{
+		"total": total,
+		"data":  groups,
+	})
+	c.String(http.StatusOK, result)
+}
+
+// All 获取所有并发限制组
+func All(c *gin.Context) {
+	groupModel := new(models.ConcurrencyGroup)
+	groups, err := groupModel.List(models.CommonMap{})
+	if err != nil {
+		logger.Error(err)
+	}
+
+	jsonResp := utils.JsonResponse{}
+	result := jsonResp.Success(utils.SuccessContent, groups)
+	c.String(http.StatusOK, result)
+}
+
+// Store 保存、修改并发限制组
+func Store(c *gin.Context) {
+	var form GroupForm
+	json := utils.JsonResponse{}
+	if err := c.ShouldBind(&form); err != nil {
+		result := json.CommonFailure(i18n.T(c, "form_validation_failed"))
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	groupModel := new(models.ConcurrencyGroup)
+	groupModel.Name = strings.TrimSpace(form.Name)
+	groupModel.MaxConcurrency = form.MaxConcurrency
+	groupModel.Scope = form.Scope
+	groupModel.Tag = strings.TrimSpace(form.Tag)
+	groupModel.Remark = strings.TrimSpace(form.Remark)
+	nameExists, err := groupModel.NameExists(groupModel.Name, form.Id)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "operation_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+	if nameExists {
+		result := json.CommonFailure(i18n.T(c, "concurrency_group_name_exists"))
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	if form.Id > 0 {
+		_, err = groupModel.UpdateBean(form.Id)
+	} else {
+		_, err = groupModel.Create()
+	}
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "save_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	result := json.Success(i18n.T(c, "save_success"), nil)
+	c.String(http.StatusOK, result)
+}
+
+// Remove 删除并发限制组
+func Remove(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	json := utils.JsonResponse{}
+	taskGroupModel := new(models.TaskConcurrencyGroup)
+	exist, err := taskGroupModel.GroupIdExist(id)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "operation_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+	if exist {
+		result := json.CommonFailure(i18n.T(c, "concurrency_group_in_use_cannot_delete"))
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	groupModel := new(models.ConcurrencyGroup)
+	_, err = groupModel.Delete(id)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "operation_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	result := json.Success(i18n.T(c, "operation_success"), nil)
+	c.String(http.StatusOK, result)
+}
+
+// 解析查询参数
+func parseQueryParams(c *gin.Context) models.CommonMap {
+	var params = models.CommonMap{}
+	params["Name"] = strings.TrimSpace(c.Query("name"))
+	base.ParsePageAndPageSize(c, params)
+
+	return params
+}
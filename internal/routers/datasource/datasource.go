@@ -0,0 +1,191 @@
+package datasource
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/routers/base"
+	"github.com/gocronx-team/gocron/internal/service"
+)
+
+type DatasourceForm struct {
+	Id       int    `form:"id" json:"id"`
+	Name     string `form:"name" json:"name" binding:"required,max=32"`
+	Engine   string `form:"engine" json:"engine" binding:"required,oneof=mysql postgres sqlite"`
+	Host     string `form:"host" json:"host" binding:"max=64"`
+	Port     int    `form:"port" json:"port" binding:"min=0,max=65535"`
+	User     string `form:"user" json:"user" binding:"max=64"`
+	Password string `form:"password" json:"password" binding:"max=255"` // 修改时为空则保留原有的密码
+	Database string `form:"database" json:"database" binding:"required,max=255"`
+	Remark   string `form:"remark" json:"remark" binding:"max=100"`
+}
+
+// Index 数据源列表
+func Index(c *gin.Context) {
+	datasourceModel := new(models.Datasource)
+	queryParams := parseQueryParams(c)
+	total, err := datasourceModel.Total(queryParams)
+	if err != nil {
+		logger.Error(err)
+	}
+	datasources, err := datasourceModel.List(queryParams)
+	if err != nil {
+		logger.Error(err)
+	}
+
+	jsonResp := utils.JsonResponse{}
+	result := jsonResp.Success(utils.SuccessContent, map[string]interface{}{
+		"total": total,
+		"data":  datasources,
+	})
+	c.String(http.StatusOK, result)
+}
+
+// All 获取所有数据源
+func All(c *gin.Context) {
+	datasourceModel := new(models.Datasource)
+	datasources, err := datasourceModel.List(models.CommonMap{})
+	if err != nil {
+		logger.Error(err)
+	}
+
+	jsonResp := utils.JsonResponse{}
+	result := jsonResp.Success(utils.SuccessContent, datasources)
+	c.String(http.StatusOK, result)
+}
+
+// Store 保存、修改数据源
+func Store(c *gin.Context) {
+	var form DatasourceForm
+	json := utils.JsonResponse{}
+	if err := c.ShouldBind(&form); err != nil {
+		result := json.CommonFailure(i18n.T(c, "form_validation_failed"))
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	datasourceModel := parseDatasource(form)
+	if datasourceModel.Engine != "sqlite" && (datasourceModel.Host == "" || datasourceModel.Port == 0) {
+		result := json.CommonFailure(i18n.T(c, "datasource_host_required"))
+		c.String(http.StatusOK, result)
+		return
+	}
+	nameExists, err := datasourceModel.NameExists(datasourceModel.Name, form.Id)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "operation_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+	if nameExists {
+		result := json.CommonFailure(i18n.T(c, "datasource_name_exists"))
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	// 修改时未填写密码则保留原有的密码
+	keepPassword := form.Id > 0 && datasourceModel.Password == ""
+	if form.Id > 0 {
+		_, err = datasourceModel.UpdateBean(form.Id, keepPassword)
+	} else {
+		_, err = datasourceModel.Create()
+	}
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "save_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	result := json.Success(i18n.T(c, "save_success"), nil)
+	c.String(http.StatusOK, result)
+}
+
+// Ping 测试数据源连接, 修改时未填写密码则使用已保存的密码
+func Ping(c *gin.Context) {
+	var form DatasourceForm
+	json := utils.JsonResponse{}
+	if err := c.ShouldBind(&form); err != nil {
+		result := json.CommonFailure(i18n.T(c, "form_validation_failed"))
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	datasourceModel := parseDatasource(form)
+	if form.Id > 0 && datasourceModel.Password == "" {
+		existing, err := datasourceModel.Detail(form.Id)
+		if err != nil {
+			result := json.CommonFailure(i18n.T(c, "operation_failed"), err)
+			c.String(http.StatusOK, result)
+			return
+		}
+		datasourceModel.Password = existing.Password
+	}
+	if err := service.PingDatasource(*datasourceModel); err != nil {
+		result := json.CommonFailure(i18n.T(c, "connection_failed")+"-"+err.Error(), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	result := json.Success(i18n.T(c, "connection_success"), nil)
+	c.String(http.StatusOK, result)
+}
+
+// Remove 删除数据源
+func Remove(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	json := utils.JsonResponse{}
+	taskSqlModel := new(models.TaskSql)
+	exist, err := taskSqlModel.DatasourceIdExist(id)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "operation_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+	if exist {
+		result := json.CommonFailure(i18n.T(c, "datasource_in_use_cannot_delete"))
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	datasourceModel := new(models.Datasource)
+	_, err = datasourceModel.Delete(id)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "operation_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	result := json.Success(i18n.T(c, "operation_success"), nil)
+	c.String(http.StatusOK, result)
+}
+
+func parseDatasource(form DatasourceForm) *models.Datasource {
+	datasourceModel := new(models.Datasource)
+	datasourceModel.Name = strings.TrimSpace(form.Name)
+	datasourceModel.Engine = form.Engine
+	datasourceModel.Database = strings.TrimSpace(form.Database)
+	datasourceModel.Remark = strings.TrimSpace(form.Remark)
+	// sqlite只使用数据库文件路径
+	if form.Engine != "sqlite" {
+		datasourceModel.Host = strings.TrimSpace(form.Host)
+		datasourceModel.Port = form.Port
+		datasourceModel.User = strings.TrimSpace(form.User)
+		datasourceModel.Password = form.Password
+	}
+
+	return datasourceModel
+}
+
+// 解析查询参数
+func parseQueryParams(c *gin.Context) models.CommonMap {
+	var params = models.CommonMap{}
+	params["Name"] = strings.TrimSpace(c.Query("name"))
+	base.ParsePageAndPageSize(c, params)
+
+	return params
+}
@@ -2,6 +2,7 @@ package host
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -13,6 +14,7 @@ import (
 	"github.com/gocronx-team/gocron/internal/modules/rpc/client"
 	"github.com/gocronx-team/gocron/internal/modules/rpc/grpcpool"
 	"github.com/gocronx-team/gocron/internal/modules/rpc/proto"
+	"github.com/gocronx-team/gocron/internal/modules/sshclient"
 	"github.com/gocronx-team/gocron/internal/modules/utils"
 	"github.com/gocronx-team/gocron/internal/routers/base"
 	"github.com/gocronx-team/gocron/internal/service"
@@ -20,6 +22,7 @@ import (
 
 const testConnectionCommand = "echo hello"
 const testConnectionTimeout = 5
+const defaultSshPort = 22
 
 // Index 主机列表
 func Index(c *gin.Context) {
@@ -73,11 +76,18 @@ func Detail(c *gin.Context) {
 }
 
 type HostForm struct {
-	Id     int16  `form:"id" json:"id"`
-	Name   string `form:"name" json:"name" binding:"required,max=64"`
-	Alias  string `form:"alias" json:"alias" binding:"required,max=32"`
-	Port   int    `form:"port" json:"port" binding:"required,min=1,max=65535"`
-	Remark string `form:"remark" json:"remark"`
+	Id      int16  `form:"id" json:"id"`
+	Name    string `form:"name" json:"name" binding:"required,max=64"`
+	Alias   string `form:"alias" json:"alias" binding:"required,max=32"`
+	Port    int    `form:"port" json:"port" binding:"required,min=1,max=65535"`
+	Remark  string `form:"remark" json:"remark"`
+	Labels  string `form:"labels" json:"labels" binding:"max=255"`
+	SshPort int    `form:"ssh_port" json:"ssh_port" binding:"omitempty,min=1,max=65535"`
+	SshUser string `form:"ssh_user" json:"ssh_user" binding:"max=64"`
+	SshAuth int8   `form:"ssh_auth" json:"ssh_auth" binding:"omitempty,oneof=1 2"`
+	SshPass string `form:"ssh_password" json:"ssh_password" binding:"max=255"`
+	SshKey  string `form:"ssh_key" json:"ssh_key" binding:"max=16384"`
+	HostKey string `form:"host_key" json:"host_key" binding:"max=1024"`
 }
 
 // Store 保存、修改主机信息
@@ -104,11 +114,54 @@ func Store(c *gin.Context) {
 		c.String(http.StatusOK, result)
 		return
 	}
+	labels, err := models.ParseLabels(form.Labels)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "invalid_host_labels"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
 
 	hostModel.Name = strings.TrimSpace(form.Name)
 	hostModel.Alias = strings.TrimSpace(form.Alias)
 	hostModel.Port = form.Port
 	hostModel.Remark = strings.TrimSpace(form.Remark)
+	hostModel.Labels = labels.String()
+	hostModel.SshPort = form.SshPort
+	if hostModel.SshPort == 0 {
+		hostModel.SshPort = defaultSshPort
+	}
+	hostModel.SshUser = strings.TrimSpace(form.SshUser)
+	hostModel.SshAuth = form.SshAuth
+	if hostModel.SshAuth == 0 {
+		hostModel.SshAuth = models.HostSshAuthPassword
+	}
+	hostModel.SshPass = form.SshPass
+	hostModel.HostKey = strings.TrimSpace(form.HostKey)
+	if hostModel.SshAuth == models.HostSshAuthKey {
+		hostModel.SshKey = strings.TrimSpace(form.SshKey)
+	}
+	if hostModel.HostKey != "" {
+		if err = sshclient.ValidateHostKey(hostModel.HostKey); err != nil {
+			result := json.CommonFailure(i18n.T(c, "invalid_ssh_host_key"), err)
+			c.String(http.StatusOK, result)
+			return
+		}
+	}
+	// 私钥及私钥密码需同时修改
+	if hostModel.SshAuth == models.HostSshAuthKey && hostModel.SshKey == "" && hostModel.SshPass != "" {
+		result := json.CommonFailure(i18n.T(c, "invalid_ssh_private_key"))
+		c.String(http.StatusOK, result)
+		return
+	}
+	if hostModel.SshKey != "" {
+		if err = sshclient.ValidatePrivateKey(hostModel.SshKey, hostModel.SshPass); err != nil {
+			result := json.CommonFailure(i18n.T(c, "invalid_ssh_private_key"), err)
+			c.String(http.StatusOK, result)
+			return
+		}
+	}
+	// 未填写密码、私钥时保留原有的密码、私钥
+	keepSecret := hostModel.SshPass == "" && hostModel.SshKey == ""
 	isCreate := false
 	oldHostModel := new(models.Host)
 
@@ -119,7 +172,7 @@ func Store(c *gin.Context) {
 			c.String(http.StatusOK, result)
 			return
 		}
-		_, err = hostModel.UpdateBean(id)
+		_, err = hostModel.UpdateBean(id, keepSecret)
 	} else {
 		isCreate = true
 		id, err = hostModel.Create()
@@ -162,12 +215,12 @@ func Remove(c *gin.Context) {
 		return
 	}
 	if id > math.MaxInt16 || id < math.MinInt16 {
-		result=json.CommonFailure(i18n.T(c,"param_error"),fmt.Errorf("host id %d over int16"),id)
-		c.String(http.StatusOK,result)
+		result = json.CommonFailure(i18n.T(c, "param_error"), fmt.Errorf("host id %d over int16", id))
+		c.String(http.StatusOK, result)
 		return
 	}
 	taskHostModel := new(models.TaskHost)
-	exist, err := taskHostModel.HostIdExist(id)
+	exist, err := taskHostModel.HostIdExist(int16(id))
 	if err != nil {
 		result = json.CommonFailure(i18n.T(c, "operation_failed"), err)
 		c.String(http.StatusOK, result)
@@ -226,6 +279,49 @@ func Ping(c *gin.Context) {
 	c.String(http.StatusOK, result)
 }
 
+// SshPing 测试主机是否可通过SSH连接
+func SshPing(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	hostModel := new(models.Host)
+	err := hostModel.Find(id)
+	json := utils.JsonResponse{}
+	var result string
+	if err != nil || hostModel.Id <= 0 {
+		result = json.CommonFailure(i18n.T(c, "host_not_exist"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	taskReq := &rpc.TaskRequest{}
+	taskReq.Command = testConnectionCommand
+	taskReq.Timeout = testConnectionTimeout
+	output, err := sshclient.Exec(service.SSHConfig(*hostModel), taskReq)
+	if err != nil {
+		result = json.CommonFailure(i18n.T(c, "connection_failed")+"-"+err.Error()+" "+output, err)
+	} else {
+		result = json.Success(i18n.T(c, "connection_success"), nil)
+	}
+	c.String(http.StatusOK, result)
+}
+
+// ScanHostKey 获取主机的SSH公钥, 保存主机前由用户确认
+func ScanHostKey(c *gin.Context) {
+	json := utils.JsonResponse{}
+	name := strings.TrimSpace(c.PostForm("name"))
+	port, err := strconv.Atoi(c.DefaultPostForm("ssh_port", strconv.Itoa(defaultSshPort)))
+	if name == "" || err != nil || port <= 0 || port > 65535 {
+		c.String(http.StatusOK, json.CommonFailure(i18n.T(c, "param_error"), err))
+		return
+	}
+	hostKey, err := sshclient.ScanHostKey(name, port)
+	if err != nil {
+		c.String(http.StatusOK, json.CommonFailure(i18n.T(c, "ssh_host_key_scan_failed")+"-"+err.Error(), err))
+		return
+	}
+
+	c.String(http.StatusOK, json.Success(utils.SuccessContent, map[string]string{"host_key": hostKey}))
+}
+
 // 解析查询参数
 func parseQueryParams(c *gin.Context) models.CommonMap {
 	var params = models.CommonMap{}
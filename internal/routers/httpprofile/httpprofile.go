@@ -0,0 +1,164 @@
+package httpprofile
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/routers/base"
+	"github.com/gocronx-team/gocron/internal/service"
+)
+
+type ProfileForm struct {
+	Id           int                       `form:"id" json:"id"`
+	Name         string                    `form:"name" json:"name" binding:"required,max=32"`
+	CaCert       string                    `form:"ca_cert" json:"ca_cert" binding:"max=65535"`
+	ClientCert   string                    `form:"client_cert" json:"client_cert" binding:"max=65535"`
+	ClientKey    string                    `form:"client_key" json:"client_key" binding:"max=65535"` // 修改时为空则保留原有的私钥
+	SkipVerify   int8                      `form:"skip_verify" json:"skip_verify" binding:"oneof=0 1"`
+	ProxyUrl     string                    `form:"proxy_url" json:"proxy_url" binding:"max=255"`
+	Redirect     models.HttpRedirectPolicy `form:"redirect" json:"redirect" binding:"required,oneof=1 2"`
+	MaxRedirects int16                     `form:"max_redirects" json:"max_redirects" binding:"min=0,max=100"`
+	Remark       string                    `form:"remark" json:"remark" binding:"max=100"`
+}
+
+// Index HTTP客户端配置列表
+func Index(c *gin.Context) {
+	profileModel := new(models.HttpProfile)
+	queryParams := parseQueryParams(c)
+	total, err := profileModel.Total(queryParams)
+	if err != nil {
+		logger.Error(err)
+	}
+	profiles, err := profileModel.List(queryParams)
+	if err != nil {
+		logger.Error(err)
+	}
+
+	jsonResp := utils.JsonResponse{}
+	result := jsonResp.Success(utils.SuccessContent, map[string]interface{}{
+		"total": total,
+		"data":  profiles,
+	})
+	c.String(http.StatusOK, result)
+}
+
+// All 获取所有HTTP客户端配置
+func All(c *gin.Context) {
+	profileModel := new(models.HttpProfile)
+	profiles, err := profileModel.List(models.CommonMap{})
+	if err != nil {
+		logger.Error(err)
+	}
+
+	jsonResp := utils.JsonResponse{}
+	result := jsonResp.Success(utils.SuccessContent, profiles)
+	c.String(http.StatusOK, result)
+}
+
+// Store 保存、修改HTTP客户端配置
+func Store(c *gin.Context) {
+	var form ProfileForm
+	json := utils.JsonResponse{}
+	if err := c.ShouldBind(&form); err != nil {
+		result := json.CommonFailure(i18n.T(c, "form_validation_failed"))
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	profileModel := new(models.HttpProfile)
+	profileModel.Name = strings.TrimSpace(form.Name)
+	profileModel.CaCert = strings.TrimSpace(form.CaCert)
+	profileModel.ClientCert = strings.TrimSpace(form.ClientCert)
+	profileModel.ClientKey = strings.TrimSpace(form.ClientKey)
+	profileModel.SkipVerify = form.SkipVerify
+	profileModel.ProxyUrl = strings.TrimSpace(form.ProxyUrl)
+	profileModel.Redirect = form.Redirect
+	profileModel.MaxRedirects = form.MaxRedirects
+	profileModel.Remark = strings.TrimSpace(form.Remark)
+	nameExists, err := profileModel.NameExists(profileModel.Name, form.Id)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "operation_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+	if nameExists {
+		result := json.CommonFailure(i18n.T(c, "http_profile_name_exists"))
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	// 修改时未填写私钥则保留原有的私钥, 清空客户端证书时同时清空私钥
+	keepKey := form.Id > 0 && profileModel.ClientKey == "" && profileModel.ClientCert != ""
+	if keepKey {
+		existing, err := profileModel.Detail(form.Id)
+		if err != nil {
+			result := json.CommonFailure(i18n.T(c, "operation_failed"), err)
+			c.String(http.StatusOK, result)
+			return
+		}
+		profileModel.ClientKey = existing.ClientKey
+	}
+	if err = service.HttpClientOptions(*profileModel).Validate(); err != nil {
+		result := json.CommonFailure(i18n.T(c, "invalid_http_profile"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	if form.Id > 0 {
+		_, err = profileModel.UpdateBean(form.Id, keepKey)
+	} else {
+		_, err = profileModel.Create()
+	}
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "save_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	result := json.Success(i18n.T(c, "save_success"), nil)
+	c.String(http.StatusOK, result)
+}
+
+// Remove 删除HTTP客户端配置
+func Remove(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	json := utils.JsonResponse{}
+	taskHttpModel := new(models.TaskHttp)
+	exist, err := taskHttpModel.ProfileIdExist(id)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "operation_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+	if exist {
+		result := json.CommonFailure(i18n.T(c, "http_profile_in_use_cannot_delete"))
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	profileModel := new(models.HttpProfile)
+	_, err = profileModel.Delete(id)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "operation_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	result := json.Success(i18n.T(c, "operation_success"), nil)
+	c.String(http.StatusOK, result)
+}
+
+// 解析查询参数
+func parseQueryParams(c *gin.Context) models.CommonMap {
+	var params = models.CommonMap{}
+	params["Name"] = strings.TrimSpace(c.Query("name"))
+	base.ParsePageAndPageSize(c, params)
+
+	return params
+}
@@ -14,13 +14,18 @@ import (
 	"github.com/gocronx-team/gocron/internal/modules/logger"
 	"github.com/gocronx-team/gocron/internal/modules/utils"
 	"github.com/gocronx-team/gocron/internal/routers/agent"
+	"github.com/gocronx-team/gocron/internal/routers/calendar"
+	"github.com/gocronx-team/gocron/internal/routers/concurrency"
+	"github.com/gocronx-team/gocron/internal/routers/datasource"
 	"github.com/gocronx-team/gocron/internal/routers/host"
+	"github.com/gocronx-team/gocron/internal/routers/httpprofile"
 	"github.com/gocronx-team/gocron/internal/routers/install"
 	"github.com/gocronx-team/gocron/internal/routers/loginlog"
 	"github.com/gocronx-team/gocron/internal/routers/manage"
 	"github.com/gocronx-team/gocron/internal/routers/task"
 	"github.com/gocronx-team/gocron/internal/routers/tasklog"
 	"github.com/gocronx-team/gocron/internal/routers/user"
+	"github.com/gocronx-team/gocron/internal/routers/workflow"
 	"github.com/rakyll/statik/fs"
 
 	_ "github.com/gocronx-team/gocron/internal/statik"
@@ -83,6 +88,7 @@ func Register(r *gin.Engine) {
 		taskGroup.GET("/log", tasklog.Index)
 		taskGroup.POST("/log/clear", tasklog.Clear)
 		taskGroup.POST("/log/stop", tasklog.Stop)
+		taskGroup.GET("/log/hosts/:id", tasklog.Hosts)
 		taskGroup.POST("/remove/:id", task.Remove)
 		taskGroup.POST("/enable/:id", task.Enable)
 		taskGroup.POST("/disable/:id", task.Disable)
@@ -90,6 +96,13 @@ func Register(r *gin.Engine) {
 		taskGroup.POST("/batch-disable", task.BatchDisable)
 		taskGroup.POST("/batch-remove", task.BatchRemove)
 		taskGroup.GET("/run/:id", task.Run)
+		taskGroup.POST("/run/:id", task.RunWithOverride)
+		taskGroup.POST("/log/replay/:id", task.Replay)
+		taskGroup.POST("/ping-token/reset/:id", task.ResetPingToken)
+		taskGroup.GET("/cron/preview", task.PreviewCron)
+		taskGroup.GET("/backfill", task.Backfills)
+		taskGroup.POST("/backfill/:id", task.Backfill)
+		taskGroup.POST("/backfill/cancel/:id", task.CancelBackfill)
 	}
 
 	// 主机
@@ -100,9 +113,63 @@ func Register(r *gin.Engine) {
 		hostGroup.GET("", host.Index)
 		hostGroup.GET("/all", host.All)
 		hostGroup.GET("/ping/:id", host.Ping)
+		hostGroup.GET("/ssh-ping/:id", host.SshPing)
+		hostGroup.POST("/ssh-host-key", host.ScanHostKey)
 		hostGroup.POST("/remove/:id", host.Remove)
 	}
 
+	// 日历
+	calendarGroup := api.Group("/calendar")
+	{
+		calendarGroup.GET("", calendar.Index)
+		calendarGroup.GET("/all", calendar.All)
+		calendarGroup.GET("/:id", calendar.Detail)
+		calendarGroup.POST("/store", calendar.Store)
+		calendarGroup.POST("/import", calendar.Import)
+		calendarGroup.POST("/remove/:id", calendar.Remove)
+	}
+
+	// 并发限制组
+	concurrencyGroup := api.Group("/concurrency-group")
+	{
+		concurrencyGroup.GET("", concurrency.Index)
+		concurrencyGroup.GET("/all", concurrency.All)
+		concurrencyGroup.POST("/store", concurrency.Store)
+		concurrencyGroup.POST("/remove/:id", concurrency.Remove)
+	}
+
+	// HTTP客户端配置
+	httpProfileGroup := api.Group("/http-profile")
+	{
+		httpProfileGroup.GET("", httpprofile.Index)
+		httpProfileGroup.GET("/all", httpprofile.All)
+		httpProfileGroup.POST("/store", httpprofile.Store)
+		httpProfileGroup.POST("/remove/:id", httpprofile.Remove)
+	}
+
+	// 数据源
+	datasourceGroup := api.Group("/datasource")
+	{
+		datasourceGroup.GET("", datasource.Index)
+		datasourceGroup.GET("/all", datasource.All)
+		datasourceGroup.POST("/store", datasource.Store)
+		datasourceGroup.POST("/ping", datasource.Ping)
+		datasourceGroup.POST("/remove/:id", datasource.Remove)
+	}
+
+	// 工作流
+	workflowGroup := api.Group("/workflow")
+	{
+		workflowGroup.GET("", workflow.Index)
+		workflowGroup.GET("/runs", workflow.Runs)
+		workflowGroup.GET("/:id", workflow.Detail)
+		workflowGroup.POST("/store", workflow.Store)
+		workflowGroup.POST("/remove/:id", workflow.Remove)
+		workflowGroup.POST("/enable/:id", workflow.Enable)
+		workflowGroup.POST("/disable/:id", workflow.Disable)
+		workflowGroup.GET("/run/:id", workflow.Run)
+	}
+
 	// Agent注册
 	agentGroup := api.Group("/agent")
 	{
@@ -139,6 +206,12 @@ func Register(r *gin.Engine) {
 		systemGroup.POST("/log-retention", manage.UpdateLogRetentionDays)
 	}
 
+	// 被动心跳任务的心跳地址, 通过地址中的密钥认证
+	api.GET("/ping/:token", task.Ping)
+	api.POST("/ping/:token", task.Ping)
+	// 异步HTTP任务的回调地址, 通过地址中的回调令牌认证
+	api.POST("/callback/:token", task.Callback)
+
 	// API
 	v1Group := api.Group("/v1")
 	v1Group.Use(apiAuth)
@@ -275,8 +348,9 @@ func userAuth(c *gin.Context) {
 		}
 	}
 
-	// v1 API接口使用单独的认证
-	if strings.HasPrefix(uri, "/v1") {
+	// v1 API接口使用单独的认证, 心跳地址使用任务的心跳密钥认证, 回调地址使用回调令牌认证
+	if strings.HasPrefix(uri, "/v1") || strings.HasPrefix(uri, "/api/ping/") ||
+		strings.HasPrefix(uri, "/api/callback/") {
 		c.Next()
 		return
 	}
@@ -322,7 +396,8 @@ func urlAuth(c *gin.Context) {
 		return
 	}
 	uri := strings.TrimRight(path, "/")
-	if strings.HasPrefix(uri, "/v1") {
+	if strings.HasPrefix(uri, "/v1") || strings.HasPrefix(uri, "/api/ping/") ||
+		strings.HasPrefix(uri, "/api/callback/") || strings.HasPrefix(uri, "/api/task/log/hosts/") {
 		c.Next()
 		return
 	}
@@ -0,0 +1,111 @@
+package task
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/routers/base"
+	"github.com/gocronx-team/gocron/internal/service"
+)
+
+// 回填参数, 时间按任务时区表示
+type BackfillForm struct {
+	StartTime   string `form:"start_time" json:"start_time" binding:"required"`
+	EndTime     string `form:"end_time" json:"end_time" binding:"required"`
+	Parallelism int    `form:"parallelism" json:"parallelism" binding:"min=0,max=10"`
+}
+
+// Backfill 按任务表达式回填历史时间范围内的执行
+func Backfill(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	json := utils.JsonResponse{}
+	var form BackfillForm
+	if err := c.ShouldBind(&form); err != nil {
+		c.String(http.StatusOK, json.CommonFailure(i18n.T(c, "form_validation_failed")))
+		return
+	}
+	taskModel := new(models.Task)
+	task, err := taskModel.Detail(id)
+	if err != nil || task.Id <= 0 {
+		c.String(http.StatusOK, json.CommonFailure(i18n.T(c, "get_task_detail_failed"), err))
+		return
+	}
+	location, err := time.LoadLocation(task.Timezone)
+	if err != nil {
+		c.String(http.StatusOK, json.CommonFailure(i18n.T(c, "invalid_timezone"), err))
+		return
+	}
+	start, startErr := time.ParseInLocation(models.DefaultTimeFormat, strings.TrimSpace(form.StartTime), location)
+	end, endErr := time.ParseInLocation(models.DefaultTimeFormat, strings.TrimSpace(form.EndTime), location)
+	if startErr != nil || endErr != nil || start.After(end) || end.After(time.Now()) {
+		c.String(http.StatusOK, json.CommonFailure(i18n.T(c, "backfill_invalid_time_range")))
+		return
+	}
+
+	backfillId, err := service.ServiceTask.Backfill(task, start, end, form.Parallelism)
+	if err != nil {
+		var key string
+		switch {
+		case errors.Is(err, service.ErrBackfillNotSupported):
+			key = "backfill_not_supported"
+		case errors.Is(err, service.ErrBackfillNoOccurrence):
+			key = "backfill_no_occurrence"
+		case errors.Is(err, service.ErrBackfillTooMany):
+			key = "backfill_too_many"
+		default:
+			key = "operation_failed"
+		}
+		c.String(http.StatusOK, json.CommonFailure(i18n.T(c, key), err))
+		return
+	}
+
+	c.String(http.StatusOK, json.Success(i18n.T(c, "backfill_started"), map[string]int64{"id": backfillId}))
+}
+
+// Backfills 任务回填记录
+func Backfills(c *gin.Context) {
+	backfillModel := new(models.TaskBackfill)
+	params := models.CommonMap{}
+	taskId, _ := strconv.Atoi(c.Query("task_id"))
+	status, _ := strconv.Atoi(c.Query("status"))
+	params["TaskId"] = taskId
+	if status >= 0 {
+		status -= 1
+	}
+	params["Status"] = status
+	base.ParsePageAndPageSize(c, params)
+	total, err := backfillModel.Total(params)
+	if err != nil {
+		logger.Error(err)
+	}
+	backfills, err := backfillModel.List(params)
+	if err != nil {
+		logger.Error(err)
+	}
+
+	json := utils.JsonResponse{}
+	c.String(http.StatusOK, json.Success(utils.SuccessContent, map[string]interface{}{
+		"total": total,
+		"data":  backfills,
+	}))
+}
+
+// CancelBackfill 取消执行中的回填
+func CancelBackfill(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	json := utils.JsonResponse{}
+	if !service.ServiceTask.CancelBackfill(id) {
+		c.String(http.StatusOK, json.CommonFailure(i18n.T(c, "backfill_not_running")))
+		return
+	}
+
+	c.String(http.StatusOK, json.Success(i18n.T(c, "operation_success"), nil))
+}
@@ -0,0 +1,44 @@
+package task
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/service"
+)
+
+// 回调请求内容的最大长度
+const maxCallbackBodySize = 1024 * 1024
+
+// 异步HTTP任务的回调内容, 支持JSON及表单
+type CallbackForm struct {
+	Status string `form:"status" json:"status" binding:"required,oneof=success failure"`
+	Output string `form:"output" json:"output"`
+}
+
+// Callback 异步HTTP任务的回调地址, 通过地址中的令牌认证, 按回调的状态、输出结束执行
+func Callback(c *gin.Context) {
+	json := utils.JsonResponse{}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxCallbackBodySize)
+	var form CallbackForm
+	if err := c.ShouldBind(&form); err != nil {
+		c.String(http.StatusBadRequest, json.CommonFailure(i18n.T(c, "form_validation_failed"), err))
+		return
+	}
+	token := c.Param("token")
+	updated, err := service.ServiceTask.Callback(token, form.Status == "success", form.Output)
+	if err != nil {
+		logger.Errorf("异步HTTP任务回调#更新任务日志失败#%s", err)
+		c.String(http.StatusInternalServerError, json.CommonFailure(i18n.T(c, "operation_failed"), err))
+		return
+	}
+	if !updated {
+		c.String(http.StatusNotFound, json.Failure(utils.NotFound, i18n.T(c, "task_callback_not_found")))
+		return
+	}
+
+	c.String(http.StatusOK, json.Success(utils.SuccessContent, nil))
+}
@@ -0,0 +1,27 @@
+package task
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/service"
+)
+
+// PreviewCron 解析表达式, 返回按时区计算的之后几次执行时间、表达式说明及配置提示
+func PreviewCron(c *gin.Context) {
+	json := utils.JsonResponse{}
+	spec := c.Query("spec")
+	timezone := strings.TrimSpace(c.Query("timezone"))
+	count, _ := strconv.Atoi(c.Query("count"))
+	preview, err := service.PreviewSchedule(spec, timezone, count, i18n.GetLocale(c))
+	if err != nil {
+		c.String(http.StatusOK, json.CommonFailure(i18n.T(c, "crontab_parse_failed"), err))
+		return
+	}
+
+	c.String(http.StatusOK, json.Success(utils.SuccessContent, preview))
+}
@@ -0,0 +1,100 @@
+package task
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/service"
+)
+
+// 心跳请求附带内容的最大长度, 超出部分丢弃
+const maxPingBodySize = 64 * 1024
+
+// 获取任务已有的心跳密钥, 新建任务或没有密钥时生成
+func taskPingToken(id int) (string, error) {
+	if id > 0 {
+		taskModel := new(models.Task)
+		task, err := taskModel.Detail(id)
+		if err != nil {
+			return "", err
+		}
+		if task.PingToken != "" {
+			return task.PingToken, nil
+		}
+	}
+
+	return generatePingToken()
+}
+
+func generatePingToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Ping 被动心跳任务的心跳地址, 通过地址中的密钥认证, POST请求的内容作为执行输出
+func Ping(c *gin.Context) {
+	json := utils.JsonResponse{}
+	taskModel := new(models.Task)
+	task, err := taskModel.DetailByPingToken(c.Param("token"))
+	if err != nil {
+		c.String(http.StatusInternalServerError, json.CommonFailure(i18n.T(c, "operation_failed"), err))
+		return
+	}
+	if task.Id == 0 {
+		c.String(http.StatusNotFound, json.Failure(utils.NotFound, i18n.T(c, "heartbeat_task_not_found")))
+		return
+	}
+	if task.Status != models.Enabled {
+		c.String(http.StatusOK, json.CommonFailure(i18n.T(c, "heartbeat_task_disabled")))
+		return
+	}
+	var output string
+	if c.Request.Method == http.MethodPost && c.Request.Body != nil {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPingBodySize))
+		if err != nil {
+			logger.Warnf("心跳请求#读取请求内容失败#任务ID-%d#%s", task.Id, err)
+		}
+		output = string(body)
+	}
+	if _, err = service.ServiceTask.Ping(task, output); err != nil {
+		logger.Errorf("心跳请求#写入任务日志失败#任务ID-%d#%s", task.Id, err)
+		c.String(http.StatusInternalServerError, json.CommonFailure(i18n.T(c, "operation_failed"), err))
+		return
+	}
+
+	c.String(http.StatusOK, json.Success(utils.SuccessContent, nil))
+}
+
+// ResetPingToken 重新生成心跳地址的密钥, 原地址失效
+func ResetPingToken(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	json := utils.JsonResponse{}
+	taskModel := new(models.Task)
+	task, err := taskModel.Detail(id)
+	if err != nil || task.Id == 0 || task.Protocol != models.TaskHeartbeat {
+		c.String(http.StatusOK, json.CommonFailure(i18n.T(c, "get_task_detail_failed"), err))
+		return
+	}
+	token, err := generatePingToken()
+	if err == nil {
+		_, err = taskModel.Update(id, models.CommonMap{"ping_token": token})
+	}
+	if err != nil {
+		c.String(http.StatusOK, json.CommonFailure(i18n.T(c, "operation_failed"), err))
+		return
+	}
+
+	c.String(http.StatusOK, json.Success(i18n.T(c, "operation_success"), map[string]string{"ping_token": token}))
+}
@@ -1,12 +1,15 @@
 package task
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gocronx-team/cron"
 	"github.com/gocronx-team/gocron/internal/models"
 	"github.com/gocronx-team/gocron/internal/modules/i18n"
 	"github.com/gocronx-team/gocron/internal/modules/logger"
@@ -16,26 +19,64 @@ import (
 )
 
 type TaskForm struct {
-	Id               int                         `form:"id" json:"id"`
-	Level            models.TaskLevel            `form:"level" json:"level" binding:"required,oneof=1 2"`
-	DependencyStatus models.TaskDependencyStatus `form:"dependency_status" json:"dependency_status"`
-	DependencyTaskId string                      `form:"dependency_task_id" json:"dependency_task_id"`
-	Name             string                      `form:"name" json:"name" binding:"required,max=32"`
-	Spec             string                      `form:"spec" json:"spec"`
-	Protocol         models.TaskProtocol         `form:"protocol" json:"protocol" binding:"oneof=1 2"`
-	Command          string                      `form:"command" json:"command" binding:"required,max=256"`
-	HttpMethod       models.TaskHTTPMethod       `form:"http_method" json:"http_method" binding:"oneof=1 2"`
-	Timeout          int                         `form:"timeout" json:"timeout" binding:"min=0,max=86400"`
-	Multi            int8                        `form:"multi" json:"multi" binding:"oneof=1 2"`
-	RetryTimes       int8                        `form:"retry_times" json:"retry_times"`
-	RetryInterval    int16                       `form:"retry_interval" json:"retry_interval"`
-	HostId           string                      `form:"host_id" json:"host_id"`
-	Tag              string                      `form:"tag" json:"tag"`
-	Remark           string                      `form:"remark" json:"remark"`
-	NotifyStatus     int8                        `form:"notify_status" json:"notify_status" binding:"required,oneof=1 2 3 4"`
-	NotifyType       int8                        `form:"notify_type" json:"notify_type" binding:"required,oneof=1 2 3 4"`
-	NotifyReceiverId string                      `form:"notify_receiver_id" json:"notify_receiver_id"`
-	NotifyKeyword    string                      `form:"notify_keyword" json:"notify_keyword"`
+	Id               int                        `form:"id" json:"id"`
+	Level            models.TaskLevel           `form:"level" json:"level" binding:"required,oneof=1 2"`
+	Name             string                     `form:"name" json:"name" binding:"required,max=32"`
+	Spec             string                     `form:"spec" json:"spec"`
+	Timezone         string                     `form:"timezone" json:"timezone" binding:"max=64"`
+	RunAt            string                     `form:"run_at" json:"run_at"`
+	OnceAction       models.TaskOnceAction      `form:"once_action" json:"once_action" binding:"omitempty,oneof=1 2 3"`
+	Protocol         models.TaskProtocol        `form:"protocol" json:"protocol" binding:"oneof=1 2 3 4 5"`
+	Command          string                     `form:"command" json:"command" binding:"max=8192"`
+	HttpMethod       models.TaskHTTPMethod      `form:"http_method" json:"http_method" binding:"oneof=1 2 3 4 5"`
+	HttpHeaders      string                     `form:"http_headers" json:"http_headers" binding:"max=4096"`
+	HttpBodyType     models.TaskHttpBodyType    `form:"http_body_type" json:"http_body_type" binding:"omitempty,oneof=1 2 3 4"`
+	HttpBody         string                     `form:"http_body" json:"http_body" binding:"max=65535"`
+	HttpAuthType     models.TaskHttpAuthType    `form:"http_auth_type" json:"http_auth_type" binding:"omitempty,oneof=1 2 3"`
+	HttpAuthUser     string                     `form:"http_auth_user" json:"http_auth_user" binding:"max=128"`
+	HttpAuthSecret   string                     `form:"http_auth_secret" json:"http_auth_secret" binding:"max=1024"`
+	HttpStatusCodes  string                     `form:"http_status_codes" json:"http_status_codes" binding:"max=128"`
+	HttpAssertRegex  string                     `form:"http_assert_regex" json:"http_assert_regex" binding:"max=512"`
+	HttpJsonPath     string                     `form:"http_json_path" json:"http_json_path" binding:"max=255"`
+	HttpJsonValue    string                     `form:"http_json_value" json:"http_json_value" binding:"max=512"`
+	HttpProfileId    int                        `form:"http_profile_id" json:"http_profile_id" binding:"min=0"`
+	HttpAsync        int8                       `form:"http_async" json:"http_async" binding:"oneof=0 1"`
+	HttpDeadline     int                        `form:"http_deadline" json:"http_deadline" binding:"min=0,max=604800"`
+	SqlDatasourceId  int                        `form:"sql_datasource_id" json:"sql_datasource_id" binding:"min=0"`
+	SqlStatements    string                     `form:"sql_statements" json:"sql_statements" binding:"max=65535"`
+	SqlFailOnRows    int8                       `form:"sql_fail_on_rows" json:"sql_fail_on_rows" binding:"oneof=0 1"`
+	Timeout          int                        `form:"timeout" json:"timeout" binding:"min=0,max=86400"`
+	Multi            int8                       `form:"multi" json:"multi" binding:"oneof=1 2"`
+	OverlapPolicy    models.TaskOverlapPolicy   `form:"overlap_policy" json:"overlap_policy" binding:"omitempty,oneof=1 2 3"`
+	HostStrategy     models.TaskHostStrategy    `form:"host_strategy" json:"host_strategy" binding:"omitempty,oneof=1 2 3 4 5"`
+	RetryTimes       int8                       `form:"retry_times" json:"retry_times"`
+	RetryInterval    int16                      `form:"retry_interval" json:"retry_interval"`
+	RetryStrategy    models.TaskRetryStrategy   `form:"retry_strategy" json:"retry_strategy" binding:"oneof=0 1 2 3"`
+	RetryMaxInterval int                        `form:"retry_max_interval" json:"retry_max_interval" binding:"min=0,max=86400"`
+	RetryJitter      int8                       `form:"retry_jitter" json:"retry_jitter" binding:"min=0,max=100"`
+	RetryOn          string                     `form:"retry_on" json:"retry_on" binding:"max=128"`
+	MisfirePolicy    models.TaskMisfirePolicy   `form:"misfire_policy" json:"misfire_policy" binding:"omitempty,oneof=1 2 3"`
+	MisfireMaxRuns   int16                      `form:"misfire_max_runs" json:"misfire_max_runs" binding:"min=0,max=100"`
+	RerunInterrupted int8                       `form:"rerun_interrupted" json:"rerun_interrupted" binding:"oneof=0 1"`
+	SlaMaxDuration   int                        `form:"sla_max_duration" json:"sla_max_duration" binding:"min=0,max=86400"`
+	SlaDeadline      string                     `form:"sla_deadline" json:"sla_deadline"`
+	PingGrace        int                        `form:"ping_grace" json:"ping_grace" binding:"min=0,max=86400"`
+	HostId           string                     `form:"host_id" json:"host_id"`
+	HostSelector     string                     `form:"host_selector" json:"host_selector" binding:"max=255"`
+	RollingBatch     int16                      `form:"rolling_batch" json:"rolling_batch" binding:"min=0,max=1000"`
+	RollingPause     int                        `form:"rolling_pause" json:"rolling_pause" binding:"min=0,max=3600"`
+	RollingMaxFail   int16                      `form:"rolling_max_fail" json:"rolling_max_fail" binding:"min=0,max=1000"`
+	RollingFailRate  int8                       `form:"rolling_fail_rate" json:"rolling_fail_rate" binding:"min=0,max=100"`
+	HostSuccessRule  models.TaskHostSuccessRule `form:"host_success_rule" json:"host_success_rule" binding:"omitempty,oneof=1 2 3"`
+	ExcludeCalendars string                     `form:"exclude_calendar_ids" json:"exclude_calendar_ids"`
+	IncludeCalendars string                     `form:"include_calendar_ids" json:"include_calendar_ids"`
+	LimitGroupIds    string                     `form:"concurrency_group_ids" json:"concurrency_group_ids"`
+	Tag              string                     `form:"tag" json:"tag"`
+	Remark           string                     `form:"remark" json:"remark"`
+	NotifyStatus     int8                       `form:"notify_status" json:"notify_status" binding:"required,oneof=1 2 3 4"`
+	NotifyType       int8                       `form:"notify_type" json:"notify_type" binding:"required,oneof=1 2 3 4"`
+	NotifyReceiverId string                     `form:"notify_receiver_id" json:"notify_receiver_id"`
+	NotifyKeyword    string                     `form:"notify_keyword" json:"notify_keyword"`
 }
 
 // 首页
@@ -52,6 +93,8 @@ func Index(c *gin.Context) {
 	}
 	for i, item := range tasks {
 		tasks[i].NextRunTime = models.NextRunTime(service.ServiceTask.NextRunTime(item))
+		// 普通用户可访问任务列表, 心跳地址的密钥只在任务详情中返回
+		tasks[i].PingToken = ""
 	}
 	jsonResp := utils.JsonResponse{}
 	result := jsonResp.Success(utils.SuccessContent, map[string]interface{}{
@@ -102,38 +145,103 @@ func Store(c *gin.Context) {
 		return
 	}
 
-	if form.Protocol == models.TaskRPC && form.HostId == "" {
+	// RPC、SSH任务指定主机或使用标签选择器
+	var hostSelector string
+	if form.Protocol.OnHosts() && strings.TrimSpace(form.HostSelector) != "" {
+		labels, err := models.ParseLabels(form.HostSelector)
+		if err != nil || len(labels) == 0 {
+			result := json.CommonFailure(i18n.T(c, "invalid_host_selector"), err)
+			c.String(http.StatusOK, result)
+			return
+		}
+		hostSelector = labels.String()
+	}
+	if form.Protocol.OnHosts() && hostSelector == "" && form.HostId == "" {
 		result := json.CommonFailure(i18n.T(c, "select_hostname"))
 		c.String(http.StatusOK, result)
 		return
 	}
 
+	// SQL任务的命令为语句的摘要
+	if form.Protocol == models.TaskSQL {
+		form.Command = service.SqlCommand(form.SqlStatements)
+	}
+	if form.Protocol != models.TaskHeartbeat && strings.TrimSpace(form.Command) == "" {
+		result := json.CommonFailure(i18n.T(c, "command_required"))
+		c.String(http.StatusOK, result)
+		return
+	}
+	// HTTP任务的请求地址保存在请求定义中, 其他任务的命令不超过256个字符
+	if form.Protocol != models.TaskHTTP && utf8.RuneCountInString(strings.TrimSpace(form.Command)) > 256 {
+		result := json.CommonFailure(i18n.T(c, "command_too_long"))
+		c.String(http.StatusOK, result)
+		return
+	}
+
 	taskModel.Name = form.Name
 	taskModel.Protocol = form.Protocol
 	taskModel.Command = strings.TrimSpace(form.Command)
+	taskModel.HostSelector = hostSelector
 	taskModel.Timeout = form.Timeout
 	taskModel.Tag = form.Tag
 	taskModel.Remark = form.Remark
 	taskModel.Multi = form.Multi
 	taskModel.RetryTimes = form.RetryTimes
 	taskModel.RetryInterval = form.RetryInterval
+	taskModel.RetryStrategy = form.RetryStrategy
+	taskModel.RetryMaxInterval = form.RetryMaxInterval
+	taskModel.RetryJitter = form.RetryJitter
+	taskModel.RetryOn = strings.TrimSpace(form.RetryOn)
 	if taskModel.Multi != 1 {
 		taskModel.Multi = 0
 	}
+	taskModel.OverlapPolicy = form.OverlapPolicy
+	if taskModel.OverlapPolicy == 0 {
+		taskModel.OverlapPolicy = models.TaskOverlapSkip
+	}
+	taskModel.HostStrategy = form.HostStrategy
+	if taskModel.HostStrategy == 0 {
+		taskModel.HostStrategy = models.TaskHostAll
+	}
+	taskModel.HostSuccessRule = form.HostSuccessRule
+	if taskModel.HostSuccessRule == 0 {
+		taskModel.HostSuccessRule = models.TaskHostSuccessAll
+	}
+	// 滚动执行只用于所有主机执行的RPC、SSH任务
+	if form.Protocol.OnHosts() && taskModel.HostStrategy == models.TaskHostAll {
+		taskModel.RollingBatch = form.RollingBatch
+		taskModel.RollingPause = form.RollingPause
+		taskModel.RollingMaxFail = form.RollingMaxFail
+		taskModel.RollingFailRate = form.RollingFailRate
+	}
+	taskModel.MisfirePolicy = form.MisfirePolicy
+	if taskModel.MisfirePolicy == 0 {
+		taskModel.MisfirePolicy = models.TaskMisfireSkip
+	}
+	taskModel.MisfireMaxRuns = form.MisfireMaxRuns
+	taskModel.RerunInterrupted = form.RerunInterrupted
+	taskModel.SlaMaxDuration = form.SlaMaxDuration
+	taskModel.SlaDeadline = strings.TrimSpace(form.SlaDeadline)
+	taskModel.PingGrace = form.PingGrace
 	taskModel.NotifyStatus = form.NotifyStatus - 1
 	taskModel.NotifyType = form.NotifyType - 1
 	taskModel.NotifyReceiverId = form.NotifyReceiverId
 	taskModel.NotifyKeyword = form.NotifyKeyword
 	taskModel.Spec = form.Spec
+	taskModel.Timezone = strings.TrimSpace(form.Timezone)
+	taskModel.RunAt = strings.TrimSpace(form.RunAt)
+	taskModel.OnceAction = form.OnceAction
+	if taskModel.OnceAction == 0 {
+		taskModel.OnceAction = models.TaskOnceKeep
+	}
 	taskModel.Level = form.Level
-	taskModel.DependencyStatus = form.DependencyStatus
-	taskModel.DependencyTaskId = strings.TrimSpace(form.DependencyTaskId)
 	if taskModel.NotifyStatus > 0 && taskModel.NotifyType != 3 && taskModel.NotifyReceiverId == "" {
 		result := json.CommonFailure(i18n.T(c, "select_at_least_one_receiver"))
 		c.String(http.StatusOK, result)
 		return
 	}
 	taskModel.HttpMethod = form.HttpMethod
+	var httpRequest models.TaskHttp
 	if taskModel.Protocol == models.TaskHTTP {
 		command := strings.ToLower(taskModel.Command)
 		if !strings.HasPrefix(command, "http://") && !strings.HasPrefix(command, "https://") {
@@ -141,8 +249,79 @@ func Store(c *gin.Context) {
 			c.String(http.StatusOK, result)
 			return
 		}
-		if taskModel.Timeout > 300 {
-			result := json.CommonFailure(i18n.T(c, "http_task_timeout_max_300"))
+		httpRequest = parseHttpRequest(form)
+		httpRequest.Url = taskModel.Command
+		// 编辑时未填写认证密码或令牌则保留原有的, 认证方式改变时需重新填写
+		if id > 0 && httpRequest.AuthType != models.TaskHttpAuthNone && httpRequest.AuthSecret == "" {
+			taskHttpModel := new(models.TaskHttp)
+			if old, _ := taskHttpModel.GetByTaskId(id); old != nil && old.AuthType == httpRequest.AuthType {
+				httpRequest.AuthSecret = old.AuthSecret
+			}
+		}
+		if err = service.ValidateHttpRequest(httpRequest); err != nil {
+			result := json.CommonFailure(i18n.T(c, "invalid_http_request"), err)
+			c.String(http.StatusOK, result)
+			return
+		}
+		if httpRequest.ProfileId > 0 {
+			profileModel := new(models.HttpProfile)
+			if _, err = profileModel.Detail(httpRequest.ProfileId); err != nil {
+				result := json.CommonFailure(i18n.T(c, "http_profile_not_exist"), err)
+				c.String(http.StatusOK, result)
+				return
+			}
+		}
+	}
+
+	var sqlExec models.TaskSql
+	if taskModel.Protocol == models.TaskSQL {
+		sqlExec = models.TaskSql{
+			DatasourceId: form.SqlDatasourceId,
+			Statements:   strings.TrimSpace(form.SqlStatements),
+			FailOnRows:   form.SqlFailOnRows,
+		}
+		if err = service.ValidateSqlStatements(sqlExec.Statements); err != nil {
+			result := json.CommonFailure(i18n.T(c, "invalid_sql_statements"), err)
+			c.String(http.StatusOK, result)
+			return
+		}
+		datasourceModel := new(models.Datasource)
+		if datasource, err := datasourceModel.Detail(sqlExec.DatasourceId); err != nil || datasource.Id == 0 {
+			result := json.CommonFailure(i18n.T(c, "datasource_not_exist"), err)
+			c.String(http.StatusOK, result)
+			return
+		}
+	}
+
+	if taskModel.Protocol == models.TaskHeartbeat {
+		if taskModel.Level != models.TaskLevelParent {
+			result := json.CommonFailure(i18n.T(c, "heartbeat_task_must_be_parent"))
+			c.String(http.StatusOK, result)
+			return
+		}
+		taskModel.Command = ""
+		taskModel.PingToken, err = taskPingToken(id)
+		if err != nil {
+			result := json.CommonFailure(i18n.T(c, "save_failed"), err)
+			c.String(http.StatusOK, result)
+			return
+		}
+	} else {
+		taskModel.PingGrace = 0
+	}
+
+	if taskModel.SlaDeadline != "" {
+		if _, err = time.Parse(models.SlaDeadlineFormat, taskModel.SlaDeadline); err != nil {
+			result := json.CommonFailure(i18n.T(c, "invalid_sla_deadline"))
+			c.String(http.StatusOK, result)
+			return
+		}
+	}
+
+	// SQL任务的语句已校验, 命令为语句的摘要, 可能截断了模板
+	if taskModel.Protocol != models.TaskSQL {
+		if err = service.ValidateCommandTemplate(taskModel.Command); err != nil {
+			result := json.CommonFailure(i18n.T(c, "invalid_command_template"), err)
 			c.String(http.StatusOK, result)
 			return
 		}
@@ -160,36 +339,60 @@ func Store(c *gin.Context) {
 		return
 	}
 
-	if taskModel.DependencyStatus != models.TaskDependencyStatusStrong &&
-		taskModel.DependencyStatus != models.TaskDependencyStatusWeak {
-		result := json.CommonFailure(i18n.T(c, "select_dependency"))
-		c.String(http.StatusOK, result)
-		return
+	for _, errorType := range strings.Split(taskModel.RetryOn, ",") {
+		if errorType != "" && !utils.InStringSlice(models.TaskRetryableErrors, errorType) {
+			result := json.CommonFailure(i18n.T(c, "invalid_retry_on"))
+			c.String(http.StatusOK, result)
+			return
+		}
 	}
 
 	if taskModel.Level == models.TaskLevelParent {
-		err = utils.PanicToError(func() {
-			cron.Parse(form.Spec)
-		})
-		if err != nil {
-			result := json.CommonFailure(i18n.T(c, "crontab_parse_failed"), err)
+		if _, err = time.LoadLocation(taskModel.Timezone); err != nil {
+			result := json.CommonFailure(i18n.T(c, "invalid_timezone"), err)
 			c.String(http.StatusOK, result)
 			return
 		}
+		if taskModel.IsOnce() {
+			if err = validateRunAt(id, taskModel); err != nil {
+				result := json.CommonFailure(i18n.T(c, "invalid_run_at"), err)
+				c.String(http.StatusOK, result)
+				return
+			}
+			taskModel.Spec = ""
+		} else {
+			if _, err = service.ValidateSchedule(form.Spec, taskModel.Timezone); err != nil {
+				result := json.CommonFailure(i18n.T(c, "crontab_parse_failed"), err)
+				c.String(http.StatusOK, result)
+				return
+			}
+		}
 	} else {
-		taskModel.DependencyTaskId = ""
 		taskModel.Spec = ""
+		taskModel.Timezone = ""
+		taskModel.RunAt = ""
 	}
 
-	if id > 0 && taskModel.DependencyTaskId != "" {
-		dependencyTaskIds := strings.Split(taskModel.DependencyTaskId, ",")
-		if utils.InStringSlice(dependencyTaskIds, strconv.Itoa(id)) {
-			result := json.CommonFailure(i18n.T(c, "cannot_set_self_as_child"))
+	var taskCalendars []models.TaskCalendar
+	if taskModel.Level == models.TaskLevelParent && !taskModel.IsOnce() {
+		taskCalendars, err = parseTaskCalendars(form)
+		if err != nil {
+			result := json.CommonFailure(i18n.T(c, "calendar_not_exist"), err)
 			c.String(http.StatusOK, result)
 			return
 		}
 	}
 
+	limitGroupIds, err := parseLimitGroupIds(form.LimitGroupIds)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "concurrency_group_not_exist"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	if taskModel.Protocol == models.TaskHTTP {
+		taskModel.Command = service.HttpCommand(httpRequest.Url)
+	}
 	if id == 0 {
 		taskModel.Status = models.Running
 		id, err = taskModel.Create()
@@ -204,7 +407,7 @@ func Store(c *gin.Context) {
 	}
 
 	taskHostModel := new(models.TaskHost)
-	if form.Protocol == models.TaskRPC {
+	if form.Protocol.OnHosts() && hostSelector == "" {
 		hostIdStrList := strings.Split(form.HostId, ",")
 		hostIds := make([]int, len(hostIdStrList))
 		for i, hostIdStr := range hostIdStrList {
@@ -214,6 +417,22 @@ func Store(c *gin.Context) {
 	} else {
 		_ = taskHostModel.Remove(id)
 	}
+	taskHttpModel := new(models.TaskHttp)
+	if form.Protocol == models.TaskHTTP {
+		_ = taskHttpModel.Save(id, httpRequest)
+	} else {
+		_ = taskHttpModel.Remove(id)
+	}
+	taskSqlModel := new(models.TaskSql)
+	if form.Protocol == models.TaskSQL {
+		_ = taskSqlModel.Save(id, sqlExec)
+	} else {
+		_ = taskSqlModel.Remove(id)
+	}
+	taskCalendarModel := new(models.TaskCalendar)
+	_ = taskCalendarModel.Add(id, taskCalendars)
+	taskConcurrencyGroupModel := new(models.TaskConcurrencyGroup)
+	_ = taskConcurrencyGroupModel.Add(id, limitGroupIds)
 
 	status, _ := taskModel.GetStatus(id)
 	if status == models.Enabled && taskModel.Level == models.TaskLevelParent {
@@ -229,13 +448,13 @@ func Remove(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
 	json := utils.JsonResponse{}
 	taskModel := new(models.Task)
-	_, err := taskModel.Delete(id)
+	err := taskModel.Remove(id)
 	var result string
-	if err != nil {
+	if errors.Is(err, models.ErrTaskInWorkflow) {
+		result = json.CommonFailure(i18n.T(c, "task_in_workflow_cannot_delete"))
+	} else if err != nil {
 		result = json.CommonFailure(utils.FailureContent, err)
 	} else {
-		taskHostModel := new(models.TaskHost)
-		_ = taskHostModel.Remove(id)
 		service.ServiceTask.Remove(id)
 		result = json.Success(utils.SuccessContent, nil)
 	}
@@ -261,6 +480,8 @@ func Run(c *gin.Context) {
 	var result string
 	if err != nil || task.Id <= 0 {
 		result = json.CommonFailure(i18n.T(c, "get_task_detail_failed"), err)
+	} else if task.Protocol == models.TaskHeartbeat {
+		result = json.CommonFailure(i18n.T(c, "heartbeat_task_cannot_run"))
 	} else {
 		task.Spec = i18n.T(c, "manual_run")
 		service.ServiceTask.Run(task)
@@ -269,6 +490,101 @@ func Run(c *gin.Context) {
 	c.String(http.StatusOK, result)
 }
 
+// 手动运行时覆盖的参数
+type RunForm struct {
+	Args    string            `json:"args" binding:"max=256"`
+	Env     map[string]string `json:"env"`
+	HostIds []int             `json:"host_ids"`
+	Timeout int               `json:"timeout" binding:"min=0,max=86400"`
+}
+
+// 使用覆盖的参数手动运行任务
+func RunWithOverride(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	json := utils.JsonResponse{}
+	var form RunForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		result := json.CommonFailure(i18n.T(c, "form_validation_failed"))
+		c.String(http.StatusOK, result)
+		return
+	}
+	taskModel := new(models.Task)
+	task, err := taskModel.Detail(id)
+	if err != nil || task.Id <= 0 {
+		result := json.CommonFailure(i18n.T(c, "get_task_detail_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+	override := models.TaskRunOverride{
+		Args:    strings.TrimSpace(form.Args),
+		Env:     form.Env,
+		HostIds: form.HostIds,
+		Timeout: form.Timeout,
+	}
+	runTaskWithOverride(c, task, &override)
+}
+
+// 使用任务日志中的参数重新执行
+func Replay(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	json := utils.JsonResponse{}
+	taskLogModel := new(models.TaskLog)
+	taskLog, err := taskLogModel.Detail(id)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "operation_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+	override, err := taskLog.RunOverride()
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "operation_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+	taskModel := new(models.Task)
+	task, err := taskModel.Detail(taskLog.TaskId)
+	if err != nil || task.Id <= 0 {
+		result := json.CommonFailure(i18n.T(c, "get_task_detail_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+	runTaskWithOverride(c, task, override)
+}
+
+func runTaskWithOverride(c *gin.Context, task models.Task, override *models.TaskRunOverride) {
+	json := utils.JsonResponse{}
+	if task.Protocol == models.TaskHeartbeat {
+		c.String(http.StatusOK, json.CommonFailure(i18n.T(c, "heartbeat_task_cannot_run")))
+		return
+	}
+	task.Spec = i18n.T(c, "manual_run")
+	if override == nil {
+		service.ServiceTask.Run(task)
+		c.String(http.StatusOK, json.Success(i18n.T(c, "task_started_check_log"), nil))
+		return
+	}
+	if err := service.ValidateRunOverride(task, *override); err != nil {
+		var key string
+		switch {
+		case errors.Is(err, service.ErrRunOverrideRPCOnly):
+			key = "run_override_rpc_only"
+		case errors.Is(err, service.ErrRunOverrideInvalidEnv):
+			key = "run_override_invalid_env"
+		case errors.Is(err, service.ErrRunOverrideHost):
+			key = "run_override_host_not_in_task"
+		case errors.Is(err, service.ErrCommandTemplate):
+			c.String(http.StatusOK, json.CommonFailure(i18n.T(c, "invalid_command_template"), err))
+			return
+		default:
+			key = "run_override_command_too_long"
+		}
+		c.String(http.StatusOK, json.CommonFailure(i18n.T(c, key)))
+		return
+	}
+	service.ServiceTask.RunWithOverride(task, *override)
+	c.String(http.StatusOK, json.Success(i18n.T(c, "task_started_check_log"), nil))
+}
+
 // 批量启用任务
 func BatchEnable(c *gin.Context) {
 	batchChangeStatus(c, models.Enabled)
@@ -329,13 +645,11 @@ func BatchRemove(c *gin.Context) {
 
 	json := utils.JsonResponse{}
 	taskModel := new(models.Task)
-	taskHostModel := new(models.TaskHost)
 	successCount := 0
 	for _, id := range form.Ids {
-		_, err := taskModel.Delete(id)
-		if err == nil {
+		// 被工作流引用的任务不删除
+		if err := taskModel.Remove(id); err == nil {
 			successCount++
-			_ = taskHostModel.Remove(id)
 			service.ServiceTask.Remove(id)
 		}
 	}
@@ -369,6 +683,115 @@ func changeStatus(c *gin.Context, status models.Status) {
 	c.String(http.StatusOK, result)
 }
 
+// 校验一次性任务的执行时间, 新建或修改执行时间时必须晚于当前时间
+func validateRunAt(id int, taskModel models.Task) error {
+	runAt, err := service.ParseRunAt(taskModel.RunAt, taskModel.Timezone)
+	if err != nil {
+		return err
+	}
+	if runAt.After(time.Now()) {
+		return nil
+	}
+	if id > 0 {
+		task, err := taskModel.Detail(id)
+		if err == nil && task.RunAt == taskModel.RunAt && task.Timezone == taskModel.Timezone {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("run time %s has passed", taskModel.RunAt)
+}
+
+// HTTP任务的请求定义
+func parseHttpRequest(form TaskForm) models.TaskHttp {
+	def := models.TaskHttp{
+		Headers:     strings.TrimSpace(form.HttpHeaders),
+		BodyType:    form.HttpBodyType,
+		Body:        form.HttpBody,
+		AuthType:    form.HttpAuthType,
+		AuthUser:    strings.TrimSpace(form.HttpAuthUser),
+		AuthSecret:  strings.TrimSpace(form.HttpAuthSecret),
+		StatusCodes: strings.ReplaceAll(form.HttpStatusCodes, " ", ""),
+		AssertRegex: form.HttpAssertRegex,
+		JsonPath:    strings.TrimSpace(form.HttpJsonPath),
+		JsonValue:   form.HttpJsonValue,
+		ProfileId:   form.HttpProfileId,
+		Async:       form.HttpAsync,
+		Deadline:    form.HttpDeadline,
+	}
+	if def.Async == 0 {
+		def.Deadline = 0
+	}
+	if def.BodyType == 0 {
+		def.BodyType = models.TaskHttpBodyNone
+	}
+	if def.BodyType == models.TaskHttpBodyNone {
+		def.Body = ""
+	}
+	if def.AuthType == 0 {
+		def.AuthType = models.TaskHttpAuthNone
+	}
+	switch def.AuthType {
+	case models.TaskHttpAuthNone:
+		def.AuthUser, def.AuthSecret = "", ""
+	case models.TaskHttpAuthBearer:
+		def.AuthUser = ""
+	}
+	if def.JsonPath == "" {
+		def.JsonValue = ""
+	}
+
+	return def
+}
+
+// 解析任务关联的日历, 日历ID多个用逗号分隔
+func parseTaskCalendars(form TaskForm) ([]models.TaskCalendar, error) {
+	taskCalendars := make([]models.TaskCalendar, 0)
+	calendarModel := new(models.Calendar)
+	modes := []models.TaskCalendarMode{models.TaskCalendarExclude, models.TaskCalendarInclude}
+	for i, ids := range []string{form.ExcludeCalendars, form.IncludeCalendars} {
+		mode := modes[i]
+		for _, idStr := range strings.Split(ids, ",") {
+			idStr = strings.TrimSpace(idStr)
+			if idStr == "" {
+				continue
+			}
+			calendarId, err := strconv.Atoi(idStr)
+			if err != nil {
+				return nil, err
+			}
+			if _, err = calendarModel.Detail(calendarId); err != nil {
+				return nil, fmt.Errorf("calendar id %d: %w", calendarId, err)
+			}
+			taskCalendars = append(taskCalendars, models.TaskCalendar{CalendarId: calendarId, Mode: mode})
+		}
+	}
+
+	return taskCalendars, nil
+}
+
+// 解析任务加入的并发限制组, 多个用逗号分隔
+func parseLimitGroupIds(ids string) ([]int, error) {
+	groupIds := make([]int, 0)
+	groupModel := new(models.ConcurrencyGroup)
+	for _, idStr := range strings.Split(ids, ",") {
+		idStr = strings.TrimSpace(idStr)
+		if idStr == "" {
+			continue
+		}
+		groupId, err := strconv.Atoi(idStr)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = groupModel.Detail(groupId); err != nil {
+			return nil, fmt.Errorf("concurrency group id %d: %w", groupId, err)
+		}
+		groupIds = append(groupIds, groupId)
+	}
+
+	return groupIds, nil
+}
+
 // 添加任务到定时器
 func addTaskToTimer(id int) {
 	taskModel := new(models.Task)
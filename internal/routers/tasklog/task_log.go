@@ -64,33 +64,58 @@ func Stop(c *gin.Context) {
 		c.String(http.StatusOK, result)
 		return
 	}
-	taskModel := new(models.Task)
-	task, err := taskModel.Detail(taskId)
 	json := utils.JsonResponse{}
 	var result string
+	// 排队中的任务直接取消
+	if service.ServiceTask.CancelQueued(id) {
+		result = json.Success(i18n.T(c, "stop_task_sent"), nil)
+		c.String(http.StatusOK, result)
+		return
+	}
+	taskModel := new(models.Task)
+	task, err := taskModel.Detail(taskId)
 	if err != nil {
 		result = json.CommonFailure(i18n.T(c, "get_task_info_failed")+"#"+err.Error(), err)
 		c.String(http.StatusOK, result)
 		return
 	}
-	if task.Protocol != models.TaskRPC {
-		result = json.CommonFailure(i18n.T(c, "only_shell_task_can_stop"))
+	if task.Protocol == models.TaskSQL {
+		service.ServiceTask.StopSQL(id)
+		result = json.Success(i18n.T(c, "stop_task_sent"), nil)
 		c.String(http.StatusOK, result)
 		return
 	}
-	if len(task.Hosts) == 0 {
-		result = json.CommonFailure(i18n.T(c, "task_node_list_empty"))
+	if !task.Protocol.OnHosts() {
+		result = json.CommonFailure(i18n.T(c, "only_shell_task_can_stop"))
 		c.String(http.StatusOK, result)
 		return
 	}
-	for _, host := range task.Hosts {
-		service.ServiceTask.Stop(host.Name, host.Port, id)
+	if err = service.ServiceTask.StopOnHosts(task, id); err != nil {
+		logger.Error(err)
+		result = json.CommonFailure(utils.FailureContent, err)
+		c.String(http.StatusOK, result)
+		return
 	}
 
 	result = json.Success(i18n.T(c, "stop_task_sent"), nil)
 	c.String(http.StatusOK, result)
 }
 
+// Hosts 任务日志在每台主机上的执行记录
+func Hosts(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	hostLogModel := new(models.TaskHostLog)
+	hostLogs, err := hostLogModel.ListByTaskLogId(id)
+	json := utils.JsonResponse{}
+	if err != nil {
+		logger.Error(err)
+		c.String(http.StatusOK, json.CommonFailure(utils.FailureContent, err))
+		return
+	}
+
+	c.String(http.StatusOK, json.Success(utils.SuccessContent, hostLogs))
+}
+
 // 删除N个月前的日志
 func Remove(c *gin.Context) {
 	month, _ := strconv.Atoi(c.Param("id"))
@@ -119,6 +144,10 @@ func parseQueryParams(c *gin.Context) models.CommonMap {
 	status, _ := strconv.Atoi(c.Query("status"))
 	params["TaskId"] = taskId
 	params["Protocol"] = protocol
+	workflowRunId, _ := strconv.ParseInt(c.Query("workflow_run_id"), 10, 64)
+	params["WorkflowRunId"] = workflowRunId
+	backfillId, _ := strconv.ParseInt(c.Query("backfill_id"), 10, 64)
+	params["BackfillId"] = backfillId
 	if status >= 0 {
 		status -= 1
 	}
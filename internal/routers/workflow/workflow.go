@@ -0,0 +1,304 @@
+package workflow
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/routers/base"
+	"github.com/gocronx-team/gocron/internal/service"
+)
+
+type NodeForm struct {
+	NodeKey  string                  `form:"node_key" json:"node_key" binding:"required,max=32"`
+	Name     string                  `form:"name" json:"name" binding:"max=32"`
+	TaskId   int                     `form:"task_id" json:"task_id" binding:"min=0"`
+	JoinMode models.WorkflowJoinMode `form:"join_mode" json:"join_mode" binding:"omitempty,oneof=1 2"`
+}
+
+type EdgeForm struct {
+	FromKey   string                       `form:"from_key" json:"from_key" binding:"required,max=32"`
+	ToKey     string                       `form:"to_key" json:"to_key" binding:"required,max=32"`
+	Condition models.WorkflowEdgeCondition `form:"condition" json:"condition" binding:"omitempty,oneof=1 2 3"`
+}
+
+type WorkflowForm struct {
+	Id       int        `form:"id" json:"id"`
+	Name     string     `form:"name" json:"name" binding:"required,max=32"`
+	Spec     string     `form:"spec" json:"spec" binding:"max=64"`
+	Timezone string     `form:"timezone" json:"timezone" binding:"max=64"`
+	Remark   string     `form:"remark" json:"remark" binding:"max=100"`
+	Nodes    []NodeForm `form:"nodes" json:"nodes" binding:"dive"`
+	Edges    []EdgeForm `form:"edges" json:"edges" binding:"dive"`
+}
+
+// Index 工作流列表
+func Index(c *gin.Context) {
+	workflowModel := new(models.Workflow)
+	queryParams := parseQueryParams(c)
+	total, err := workflowModel.Total(queryParams)
+	if err != nil {
+		logger.Error(err)
+	}
+	workflows, err := workflowModel.List(queryParams)
+	if err != nil {
+		logger.Error(err)
+	}
+	for i, item := range workflows {
+		workflows[i].NextRunTime = models.NextRunTime(service.ServiceWorkflow.NextRunTime(item))
+	}
+
+	jsonResp := utils.JsonResponse{}
+	result := jsonResp.Success(utils.SuccessContent, map[string]interface{}{
+		"total": total,
+		"data":  workflows,
+	})
+	c.String(http.StatusOK, result)
+}
+
+// Detail 工作流详情
+func Detail(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	workflowModel := new(models.Workflow)
+	workflow, err := workflowModel.Detail(id)
+	jsonResp := utils.JsonResponse{}
+	var result string
+	if err != nil || workflow.Id == 0 {
+		logger.Errorf("获取工作流详情失败#工作流id-%d", id)
+		result = jsonResp.Success(utils.SuccessContent, nil)
+	} else {
+		result = jsonResp.Success(utils.SuccessContent, workflow)
+	}
+	c.String(http.StatusOK, result)
+}
+
+// Store 保存、修改工作流
+func Store(c *gin.Context) {
+	var form WorkflowForm
+	json := utils.JsonResponse{}
+	if err := c.ShouldBind(&form); err != nil {
+		result := json.CommonFailure(i18n.T(c, "form_validation_failed"))
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	workflowModel := new(models.Workflow)
+	workflowModel.Name = strings.TrimSpace(form.Name)
+	workflowModel.Spec = strings.TrimSpace(form.Spec)
+	workflowModel.Timezone = strings.TrimSpace(form.Timezone)
+	workflowModel.Remark = strings.TrimSpace(form.Remark)
+	if _, err := time.LoadLocation(workflowModel.Timezone); err != nil {
+		result := json.CommonFailure(i18n.T(c, "invalid_timezone"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+	if workflowModel.Spec != "" {
+		if _, err := service.ValidateSchedule(workflowModel.Spec, workflowModel.Timezone); err != nil {
+			result := json.CommonFailure(i18n.T(c, "crontab_parse_failed"), err)
+			c.String(http.StatusOK, result)
+			return
+		}
+	}
+
+	taskModel := new(models.Task)
+	for _, item := range form.Nodes {
+		node := models.WorkflowNode{
+			NodeKey:  strings.TrimSpace(item.NodeKey),
+			Name:     strings.TrimSpace(item.Name),
+			TaskId:   item.TaskId,
+			JoinMode: item.JoinMode,
+		}
+		if node.JoinMode == 0 {
+			node.JoinMode = models.WorkflowJoinAll
+		}
+		if !node.IsJoin() {
+			task, err := taskModel.Detail(node.TaskId)
+			if err != nil || task.Id == 0 {
+				result := json.CommonFailure(i18n.T(c, "workflow_task_not_exist"), err)
+				c.String(http.StatusOK, result)
+				return
+			}
+			if task.Protocol == models.TaskHeartbeat {
+				result := json.CommonFailure(i18n.T(c, "workflow_task_heartbeat_not_allowed"))
+				c.String(http.StatusOK, result)
+				return
+			}
+		}
+		workflowModel.Nodes = append(workflowModel.Nodes, node)
+	}
+	for _, item := range form.Edges {
+		edge := models.WorkflowEdge{
+			FromKey:   strings.TrimSpace(item.FromKey),
+			ToKey:     strings.TrimSpace(item.ToKey),
+			Condition: item.Condition,
+		}
+		if edge.Condition == 0 {
+			edge.Condition = models.WorkflowEdgeOnSuccess
+		}
+		workflowModel.Edges = append(workflowModel.Edges, edge)
+	}
+	if err := service.ValidateWorkflow(*workflowModel); err != nil {
+		key := "workflow_graph_invalid"
+		if errors.Is(err, service.ErrWorkflowCycle) {
+			key = "workflow_has_cycle"
+		}
+		result := json.CommonFailure(i18n.T(c, key), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	nameExists, err := workflowModel.NameExists(workflowModel.Name, form.Id)
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "operation_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+	if nameExists {
+		result := json.CommonFailure(i18n.T(c, "workflow_name_exists"))
+		c.String(http.StatusOK, result)
+		return
+	}
+
+	id := form.Id
+	if id > 0 {
+		_, err = workflowModel.UpdateBean(id)
+	} else {
+		workflowModel.Status = models.Enabled
+		id, err = workflowModel.Create()
+	}
+	if err != nil {
+		result := json.CommonFailure(i18n.T(c, "save_failed"), err)
+		c.String(http.StatusOK, result)
+		return
+	}
+	addWorkflowToTimer(id)
+
+	result := json.Success(i18n.T(c, "save_success"), nil)
+	c.String(http.StatusOK, result)
+}
+
+// Remove 删除工作流
+func Remove(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	json := utils.JsonResponse{}
+	workflowModel := new(models.Workflow)
+	_, err := workflowModel.Delete(id)
+	var result string
+	if err != nil {
+		result = json.CommonFailure(utils.FailureContent, err)
+	} else {
+		service.ServiceWorkflow.Remove(id)
+		result = json.Success(utils.SuccessContent, nil)
+	}
+	c.String(http.StatusOK, result)
+}
+
+// Enable 启用工作流
+func Enable(c *gin.Context) {
+	changeStatus(c, models.Enabled)
+}
+
+// Disable 禁用工作流
+func Disable(c *gin.Context) {
+	changeStatus(c, models.Disabled)
+}
+
+// Run 手动运行工作流
+func Run(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	json := utils.JsonResponse{}
+	workflowModel := new(models.Workflow)
+	workflow, err := workflowModel.Detail(id)
+	var result string
+	if err != nil || workflow.Id <= 0 {
+		result = json.CommonFailure(i18n.T(c, "workflow_not_exist"), err)
+	} else {
+		service.ServiceWorkflow.Run(workflow.Id)
+		result = json.Success(i18n.T(c, "workflow_started_check_runs"), nil)
+	}
+	c.String(http.StatusOK, result)
+}
+
+// Runs 工作流执行记录
+func Runs(c *gin.Context) {
+	runModel := new(models.WorkflowRun)
+	params := models.CommonMap{}
+	workflowId, _ := strconv.Atoi(c.Query("workflow_id"))
+	status, _ := strconv.Atoi(c.Query("status"))
+	params["WorkflowId"] = workflowId
+	if status >= 0 {
+		status -= 1
+	}
+	params["Status"] = status
+	base.ParsePageAndPageSize(c, params)
+	total, err := runModel.Total(params)
+	if err != nil {
+		logger.Error(err)
+	}
+	runs, err := runModel.List(params)
+	if err != nil {
+		logger.Error(err)
+	}
+
+	jsonResp := utils.JsonResponse{}
+	result := jsonResp.Success(utils.SuccessContent, map[string]interface{}{
+		"total": total,
+		"data":  runs,
+	})
+	c.String(http.StatusOK, result)
+}
+
+// 改变工作流状态
+func changeStatus(c *gin.Context, status models.Status) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	json := utils.JsonResponse{}
+	workflowModel := new(models.Workflow)
+	_, err := workflowModel.Update(id, models.CommonMap{
+		"status": status,
+	})
+	var result string
+	if err != nil {
+		result = json.CommonFailure(utils.FailureContent, err)
+	} else {
+		if status == models.Enabled {
+			addWorkflowToTimer(id)
+		} else {
+			service.ServiceWorkflow.Remove(id)
+		}
+		result = json.Success(utils.SuccessContent, nil)
+	}
+	c.String(http.StatusOK, result)
+}
+
+// 添加工作流到定时器, 未启用或没有表达式时只从定时器移除
+func addWorkflowToTimer(id int) {
+	workflowModel := new(models.Workflow)
+	workflow, err := workflowModel.Detail(id)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+
+	service.ServiceWorkflow.RemoveAndAdd(workflow)
+}
+
+// 解析查询参数
+func parseQueryParams(c *gin.Context) models.CommonMap {
+	var params = models.CommonMap{}
+	status, _ := strconv.Atoi(c.Query("status"))
+	params["Name"] = strings.TrimSpace(c.Query("name"))
+	if status >= 0 {
+		status -= 1
+	}
+	params["Status"] = status
+	base.ParsePageAndPageSize(c, params)
+
+	return params
+}
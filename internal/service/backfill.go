@@ -0,0 +1,364 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+)
+
+// 任务回填
+// 按任务表达式(任务时区)计算[开始时间, 结束时间]内的执行时间点, 每个时间点作为计划执行时间执行一次,
+// 命令模板中的ScheduledTime为该时间点, 执行时间点被日历阻止时跳过
+// 可指定并发数, 单实例运行的任务逐个执行, 与定时执行冲突时排队等待
+// 回填可取消, 取消后不再开始新的执行, 并按实际执行的主机及协议停止执行中的任务
+// 取消请求写入回填记录, 执行回填的节点定期检查, 高可用模式下任意节点均可取消
+
+const (
+	MaxBackfillOccurrences = 1000
+	MaxBackfillParallelism = 10
+)
+
+// 检查回填是否被取消的间隔
+const backfillCancelCheckInterval = 5 * time.Second
+
+var (
+	ErrBackfillNotSupported = errors.New("只有使用表达式调度的主任务支持回填")
+	ErrBackfillNoOccurrence = errors.New("时间范围内没有需要执行的时间点")
+	ErrBackfillTooMany      = fmt.Errorf("时间范围内的执行时间点超过%d个", MaxBackfillOccurrences)
+
+	// 执行中的回填, 回填记录ID => *runningBackfill
+	runningBackfills sync.Map
+
+	unfinishedBackfillLogsFunc = new(models.TaskLog).UnfinishedByBackfill
+)
+
+type runningBackfill struct {
+	cancel chan struct{}
+	once   sync.Once
+	// 执行中的任务, 任务日志ID => *instanceRun
+	runs sync.Map
+}
+
+// 不再开始新的执行, 并停止执行中及排队中的任务
+func (running *runningBackfill) stop(backfillId int64) {
+	running.once.Do(func() {
+		close(running.cancel)
+		logger.Infof("取消回填#回填记录ID-%d", backfillId)
+		logs, err := unfinishedBackfillLogsFunc(backfillId)
+		if err != nil {
+			logger.Errorf("取消回填#获取执行中的任务日志失败#回填记录ID-%d#%s", backfillId, err)
+			return
+		}
+		for _, taskLog := range logs {
+			if ServiceTask.CancelQueued(taskLog.Id) {
+				continue
+			}
+			if run, ok := running.runs.Load(taskLog.Id); ok {
+				stopInstanceRun(run.(*instanceRun))
+			}
+		}
+	})
+}
+
+func (running *runningBackfill) canceled() bool {
+	select {
+	case <-running.cancel:
+		return true
+	default:
+		return false
+	}
+}
+
+// 记录回填中执行的任务, 取消时按选中的主机及协议停止
+type backfillHandler struct {
+	Handler
+	running *runningBackfill
+}
+
+func (h backfillHandler) Run(taskModel models.Task, taskUniqueId int64) (string, error) {
+	h.running.runs.Store(taskUniqueId, &instanceRun{taskLogId: taskUniqueId, hosts: taskModel.Hosts, protocol: taskModel.Protocol})
+	defer h.running.runs.Delete(taskUniqueId)
+	if h.running.canceled() {
+		return "", newTaskError(errors.New("回填已取消"), models.TaskErrorOther)
+	}
+
+	return h.Handler.Run(taskModel, taskUniqueId)
+}
+
+// 单次执行的结果
+type backfillOutcome int8
+
+const (
+	backfillSucceeded backfillOutcome = iota
+	backfillFailed
+	backfillSkipped
+)
+
+// 回填执行进度
+type backfillCounts struct {
+	Succeeded int
+	Failed    int
+	Skipped   int
+}
+
+func (c backfillCounts) done() int {
+	return c.Succeeded + c.Failed + c.Skipped
+}
+
+// BackfillTimes 计算[start, end]内任务的执行时间点
+func BackfillTimes(taskModel models.Task, start, end time.Time) ([]time.Time, error) {
+	if taskModel.Level != models.TaskLevelParent || taskModel.IsOnce() || taskModel.Protocol == models.TaskHeartbeat {
+		return nil, ErrBackfillNotSupported
+	}
+	schedule, err := parseSchedule(taskModel.Spec, taskModel.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	times := make([]time.Time, 0)
+	for next := schedule.Next(start.Add(-time.Second)); !next.IsZero() && !next.After(end); next = schedule.Next(next) {
+		if len(times) >= MaxBackfillOccurrences {
+			return nil, ErrBackfillTooMany
+		}
+		times = append(times, next)
+	}
+	if len(times) == 0 {
+		return nil, ErrBackfillNoOccurrence
+	}
+
+	return times, nil
+}
+
+// Backfill 创建回填记录并在后台执行, 返回回填记录ID
+func (task Task) Backfill(taskModel models.Task, start, end time.Time, parallelism int) (int64, error) {
+	times, err := BackfillTimes(taskModel, start, end)
+	if err != nil {
+		return 0, err
+	}
+	handler := createHandler(taskModel)
+	if handler == nil {
+		return 0, ErrBackfillNotSupported
+	}
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	if parallelism > MaxBackfillParallelism {
+		parallelism = MaxBackfillParallelism
+	}
+	if taskModel.Multi == 0 {
+		parallelism = 1
+	}
+	backfillModel := &models.TaskBackfill{
+		TaskId:      taskModel.Id,
+		Name:        taskModel.Name,
+		RangeStart:  models.LocalTime(start),
+		RangeEnd:    models.LocalTime(end),
+		Parallelism: int8(parallelism),
+		Occurrences: len(times),
+		Status:      models.Running,
+		StartTime:   models.LocalTime(time.Now()),
+	}
+	backfillId, err := backfillModel.Create()
+	if err != nil {
+		return 0, err
+	}
+	task.dispatch(models.SchedulerRequestBackfill, backfillId, nil, func() {
+		startBackfill(handler, taskModel, backfillId, times, parallelism)
+	})
+
+	return backfillId, nil
+}
+
+// 调度节点执行非调度节点创建的回填
+func (task Task) resumeBackfill(backfillId int64) {
+	backfillModel := new(models.TaskBackfill)
+	backfill, err := backfillModel.Detail(backfillId)
+	if err != nil || backfill.Id == 0 || backfill.Status != models.Running {
+		logger.Errorf("回填#获取回填记录失败#回填记录ID-%d#%v", backfillId, err)
+		return
+	}
+	taskModel := new(models.Task)
+	item, err := taskModel.Detail(backfill.TaskId)
+	if err == nil && item.Id == 0 {
+		err = errors.New("任务不存在")
+	}
+	var times []time.Time
+	if err == nil {
+		times, err = BackfillTimes(item, time.Time(backfill.RangeStart), time.Time(backfill.RangeEnd))
+	}
+	handler := createHandler(item)
+	if err == nil && handler == nil {
+		err = ErrBackfillNotSupported
+	}
+	if err != nil {
+		logger.Errorf("回填#开始执行失败#回填记录ID-%d#%s", backfillId, err)
+		_, err = backfillModel.Update(backfillId, models.CommonMap{
+			"status":   models.Failure,
+			"result":   fmt.Sprintf("开始执行失败: %s", err),
+			"end_time": time.Now(),
+		})
+		if err != nil {
+			logger.Errorf("回填#更新回填记录失败#回填记录ID-%d#%s", backfillId, err)
+		}
+		return
+	}
+	startBackfill(handler, item, backfillId, times, int(backfill.Parallelism))
+}
+
+func startBackfill(handler Handler, taskModel models.Task, backfillId int64, times []time.Time, parallelism int) {
+	if taskModel.Multi == 0 {
+		// 与定时执行冲突时排队等待, 不跳过回填的执行
+		taskModel.OverlapPolicy = models.TaskOverlapQueue
+	}
+	running := &runningBackfill{cancel: make(chan struct{})}
+	runningBackfills.Store(backfillId, running)
+	go runBackfill(backfillHandler{Handler: handler, running: running}, taskModel, backfillId, times, parallelism, running)
+}
+
+// CancelBackfill 取消回填, 写入取消请求并停止本节点上执行的回填, 其他节点上执行的回填由该节点检查后停止
+// 回填不在执行中时返回false
+func (task Task) CancelBackfill(backfillId int64) bool {
+	backfillModel := new(models.TaskBackfill)
+	ok, err := backfillModel.RequestCancel(backfillId)
+	if err != nil {
+		logger.Errorf("取消回填#更新回填记录失败#回填记录ID-%d#%s", backfillId, err)
+		return false
+	}
+	if !ok {
+		return false
+	}
+	if value, exist := runningBackfills.Load(backfillId); exist {
+		value.(*runningBackfill).stop(backfillId)
+	}
+
+	return true
+}
+
+// 定期检查回填记录中的取消请求, done关闭时退出
+func watchBackfillCancel(backfillId int64, running *runningBackfill, done <-chan struct{}) {
+	ticker := time.NewTicker(backfillCancelCheckInterval)
+	defer ticker.Stop()
+	backfillModel := new(models.TaskBackfill)
+	for {
+		select {
+		case <-done:
+			return
+		case <-running.cancel:
+			return
+		case <-ticker.C:
+		}
+		backfill, err := backfillModel.Detail(backfillId)
+		if err != nil {
+			logger.Errorf("回填#获取回填记录失败#回填记录ID-%d#%s", backfillId, err)
+			continue
+		}
+		if backfill.Canceled == 1 {
+			running.stop(backfillId)
+			return
+		}
+	}
+}
+
+func runBackfill(handler Handler, taskModel models.Task, backfillId int64, times []time.Time,
+	parallelism int, running *runningBackfill) {
+	defer runningBackfills.Delete(backfillId)
+	done := make(chan struct{})
+	defer close(done)
+	go watchBackfillCancel(backfillId, running, done)
+	logger.Infof("开始回填#任务ID-%d#回填记录ID-%d#执行次数-%d#并发数-%d", taskModel.Id, backfillId, len(times), parallelism)
+
+	backfillModel := new(models.TaskBackfill)
+	runOccurrence := func(scheduledTime time.Time) backfillOutcome {
+		trigger := jobTrigger{Type: models.TaskLogTriggerBackfill, ScheduledTime: scheduledTime, BackfillId: backfillId}
+		if skipByCalendar(taskModel, trigger) {
+			return backfillSkipped
+		}
+		taskResult, ok := runJob(handler, taskModel, trigger)
+		switch {
+		case !ok:
+			return backfillSkipped
+		case taskResult.Err != nil:
+			return backfillFailed
+		}
+		return backfillSucceeded
+	}
+	progress := func(counts backfillCounts) {
+		_, err := backfillModel.Update(backfillId, models.CommonMap{
+			"succeeded": counts.Succeeded,
+			"failed":    counts.Failed,
+			"skipped":   counts.Skipped,
+		})
+		if err != nil {
+			logger.Errorf("回填#更新回填记录失败#回填记录ID-%d#%s", backfillId, err)
+		}
+	}
+	counts, canceled := executeBackfill(times, parallelism, running.cancel, runOccurrence, progress)
+
+	status := models.Finish
+	result := fmt.Sprintf("共%d次, 成功%d次, 失败%d次, 跳过%d次", len(times), counts.Succeeded, counts.Failed, counts.Skipped)
+	switch {
+	case canceled:
+		status = models.Cancel
+		result += fmt.Sprintf(", 已取消, 未执行%d次", len(times)-counts.done())
+	case counts.Failed > 0:
+		status = models.Failure
+	}
+	_, err := backfillModel.Update(backfillId, models.CommonMap{
+		"status":   status,
+		"result":   result,
+		"end_time": time.Now(),
+	})
+	if err != nil {
+		logger.Errorf("回填#更新回填记录失败#回填记录ID-%d#%s", backfillId, err)
+	}
+	logger.Infof("回填完成#任务ID-%d#回填记录ID-%d#%s", taskModel.Id, backfillId, result)
+}
+
+// 按时间顺序执行, 同时执行的数量不超过parallelism, 每次执行结束后通过progress报告进度
+// cancel关闭后不再开始新的执行, 等待执行中的结束后返回
+func executeBackfill(times []time.Time, parallelism int, cancel <-chan struct{},
+	runOccurrence func(time.Time) backfillOutcome, progress func(backfillCounts)) (counts backfillCounts, canceled bool) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, parallelism)
+	for _, scheduledTime := range times {
+		select {
+		case <-cancel:
+			canceled = true
+		case slots <- struct{}{}:
+			// 同时满足时优先取消
+			select {
+			case <-cancel:
+				canceled = true
+				<-slots
+			default:
+			}
+		}
+		if canceled {
+			break
+		}
+		wg.Add(1)
+		go func(scheduledTime time.Time) {
+			defer wg.Done()
+			outcome := runOccurrence(scheduledTime)
+			<-slots
+			mu.Lock()
+			defer mu.Unlock()
+			switch outcome {
+			case backfillSucceeded:
+				counts.Succeeded++
+			case backfillFailed:
+				counts.Failed++
+			default:
+				counts.Skipped++
+			}
+			progress(counts)
+		}(scheduledTime)
+	}
+	wg.Wait()
+
+	return counts, canceled
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+func TestBackfillTimes(t *testing.T) {
+	location, _ := time.LoadLocation("Asia/Shanghai")
+	task := models.Task{Level: models.TaskLevelParent, Spec: "0 0 2 * * *", Timezone: "Asia/Shanghai"}
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, location)
+	end := time.Date(2024, 3, 14, 2, 0, 0, 0, location)
+
+	times, err := BackfillTimes(task, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(times) != 14 {
+		t.Fatalf("expected 14 daily runs, got %d", len(times))
+	}
+	if !times[0].Equal(start.Add(2*time.Hour)) || !times[13].Equal(end) {
+		t.Fatalf("unexpected range %s - %s", times[0], times[13])
+	}
+
+	// 开始时间正好是执行时间点时包含在内
+	if times, _ = BackfillTimes(task, end, end); len(times) != 1 {
+		t.Fatalf("expected boundary run to be included, got %v", times)
+	}
+	if _, err = BackfillTimes(task, start, start.Add(time.Hour)); !errors.Is(err, ErrBackfillNoOccurrence) {
+		t.Fatalf("expected no occurrence error, got %v", err)
+	}
+	task.Spec = "* * * * * *"
+	if _, err = BackfillTimes(task, start, end); !errors.Is(err, ErrBackfillTooMany) {
+		t.Fatalf("expected too many error, got %v", err)
+	}
+	for _, unsupported := range []models.Task{
+		{Level: models.TaskLevelChild},
+		{Level: models.TaskLevelParent, RunAt: "2024-03-01 00:00:00"},
+		{Level: models.TaskLevelParent, Spec: "0 0 2 * * *", Protocol: models.TaskHeartbeat},
+	} {
+		if _, err = BackfillTimes(unsupported, start, end); !errors.Is(err, ErrBackfillNotSupported) {
+			t.Fatalf("expected unsupported error for %+v, got %v", unsupported, err)
+		}
+	}
+}
+
+func TestExecuteBackfill(t *testing.T) {
+	base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	times := make([]time.Time, 6)
+	for i := range times {
+		times[i] = base.AddDate(0, 0, i)
+	}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	runOccurrence := func(scheduledTime time.Time) backfillOutcome {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		switch scheduledTime.Day() {
+		case 2:
+			return backfillFailed
+		case 3:
+			return backfillSkipped
+		}
+		return backfillSucceeded
+	}
+	reports := 0
+	counts, canceled := executeBackfill(times, 2, make(chan struct{}), runOccurrence, func(backfillCounts) {
+		reports++
+	})
+	if canceled || counts.Succeeded != 4 || counts.Failed != 1 || counts.Skipped != 1 {
+		t.Fatalf("unexpected result %+v canceled=%v", counts, canceled)
+	}
+	if maxRunning != 2 || reports != len(times) {
+		t.Fatalf("expected parallelism 2 and a report per run, got %d and %d", maxRunning, reports)
+	}
+}
+
+func TestExecuteBackfillCancel(t *testing.T) {
+	base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	times := []time.Time{base, base.AddDate(0, 0, 1), base.AddDate(0, 0, 2)}
+	cancel := make(chan struct{})
+	started := 0
+	counts, canceled := executeBackfill(times, 1, cancel, func(time.Time) backfillOutcome {
+		started++
+		if started == 1 {
+			close(cancel)
+		}
+		return backfillSucceeded
+	}, func(backfillCounts) {})
+	if !canceled || started != 1 || counts.done() != 1 {
+		t.Fatalf("expected backfill to stop after cancel, started=%d counts=%+v canceled=%v", started, counts, canceled)
+	}
+}
+
+func TestRunningBackfillStop(t *testing.T) {
+	originalLogs, originalSSH, originalRPC := unfinishedBackfillLogsFunc, sshCancelFunc, rpcCancelFunc
+	defer func() {
+		unfinishedBackfillLogsFunc, sshCancelFunc, rpcCancelFunc = originalLogs, originalSSH, originalRPC
+	}()
+	unfinishedBackfillLogsFunc = func(backfillId int64) ([]models.TaskLog, error) {
+		return []models.TaskLog{{Id: 11}, {Id: 12}}, nil
+	}
+	canceled := make([]string, 0)
+	sshCancelFunc = func(ip string, port int, id int64) bool {
+		canceled = append(canceled, fmt.Sprintf("ssh-%s:%d-%d", ip, port, id))
+		return true
+	}
+	rpcCancelFunc = func(ip string, port int, id int64) bool {
+		canceled = append(canceled, fmt.Sprintf("rpc-%s:%d-%d", ip, port, id))
+		return true
+	}
+
+	running := &runningBackfill{cancel: make(chan struct{})}
+	hosts := testHosts("a", "b")
+	hosts[1].SshPort = 22
+	// 执行时按选中的主机记录
+	task := models.Task{Protocol: models.TaskSSH, Hosts: hosts[1:]}
+	handler := backfillHandler{Handler: handlerFunc(func(taskModel models.Task, taskUniqueId int64) (string, error) {
+		running.stop(1)
+		return "", nil
+	}), running: running}
+	queued := make(chan struct{})
+	queuedJobs.Store(int64(12), queued)
+	if _, err := handler.Run(task, 11); err != nil {
+		t.Fatal(err)
+	}
+	if len(canceled) != 1 || canceled[0] != "ssh-b:22-11" {
+		t.Fatalf("expected ssh cancel on selected host, got %v", canceled)
+	}
+	select {
+	case <-queued:
+	default:
+		t.Fatal("expected queued run to be canceled")
+	}
+	if _, ok := running.runs.Load(int64(11)); ok {
+		t.Fatal("expected finished run to be removed")
+	}
+	// 取消后不再执行
+	if _, err := handler.Run(task, 13); err == nil {
+		t.Fatal("expected canceled backfill not to run")
+	}
+	running.stop(1)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gocronx-team/cron"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+)
+
+// 任务日历
+// 排除日历中的日期不执行, 关联了限定日历时只在限定日历中的日期执行
+// 日期按任务时区计算, 定时触发、错过补偿被日历阻止时写入跳过状态的任务日志, 手动运行不受日历限制
+
+// 计算下次执行时间时最多跳过的天数
+const calendarSkipDaysLimit = 366 * 5
+
+// 返回阻止在t执行的日历名称, 允许执行时返回空字符串
+func calendarBlockedBy(taskModel models.Task, t time.Time) string {
+	if len(taskModel.Calendars) == 0 {
+		return ""
+	}
+	location, err := loadTaskLocation(taskModel.Timezone)
+	if err != nil {
+		location = time.Local
+	}
+	date := t.In(location).Format(models.CalendarDateFormat)
+
+	includeNames := make([]string, 0)
+	included := false
+	for _, calendar := range taskModel.Calendars {
+		contains := calendarContains(calendar, date)
+		switch calendar.Mode {
+		case models.TaskCalendarInclude:
+			includeNames = append(includeNames, calendar.Name)
+			included = included || contains
+		default:
+			if contains {
+				return calendar.Name
+			}
+		}
+	}
+	if len(includeNames) > 0 && !included {
+		return strings.Join(includeNames, ",")
+	}
+
+	return ""
+}
+
+func calendarContains(calendar models.TaskCalendarDetail, date string) bool {
+	for _, item := range calendar.Dates {
+		if item.Contains(date) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 从next开始跳过日历阻止的日期, 返回第一个允许执行的时间
+func skipCalendarBlocked(taskModel models.Task, schedule cron.Schedule, next time.Time) time.Time {
+	if len(taskModel.Calendars) == 0 {
+		return next
+	}
+	location, err := loadTaskLocation(taskModel.Timezone)
+	if err != nil {
+		location = time.Local
+	}
+	for i := 0; i < calendarSkipDaysLimit && !next.IsZero(); i++ {
+		if calendarBlockedBy(taskModel, next) == "" {
+			return next
+		}
+		// 从下一天零点开始继续计算
+		local := next.In(location)
+		nextDay := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, location)
+		next = schedule.Next(nextDay.Add(-time.Second))
+	}
+
+	return time.Time{}
+}
+
+// 被日历阻止时写入跳过日志, 返回是否跳过本次执行
+func skipByCalendar(taskModel models.Task, trigger jobTrigger) bool {
+	calendarName := calendarBlockedBy(taskModel, trigger.ScheduledTime)
+	if calendarName == "" {
+		return false
+	}
+	logger.Infof("任务被日历阻止, 跳过本次执行#ID-%d#名称-%s#日历-%s", taskModel.Id, taskModel.Name, calendarName)
+	taskLogModel := newTaskLog(taskModel, trigger, models.Skipped)
+	taskLogModel.Result = fmt.Sprintf("日历[%s]不允许执行, 跳过本次执行", calendarName)
+	taskLogModel.EndTime = taskLogModel.StartTime
+	if _, err := taskLogModel.Create(); err != nil {
+		logger.Error("任务跳过#写入任务日志失败-", err)
+	}
+
+	return true
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+func calendarTask(calendars ...models.TaskCalendarDetail) models.Task {
+	return models.Task{Id: 1, Spec: "0 0 9 * * *", Timezone: "Asia/Shanghai", Calendars: calendars}
+}
+
+func testCalendar(name string, mode models.TaskCalendarMode, ranges ...[2]string) models.TaskCalendarDetail {
+	detail := models.TaskCalendarDetail{Name: name}
+	detail.Mode = mode
+	for _, item := range ranges {
+		detail.Dates = append(detail.Dates, models.CalendarDate{StartDate: item[0], EndDate: item[1]})
+	}
+
+	return detail
+}
+
+func TestCalendarBlockedByExclude(t *testing.T) {
+	shanghai := mustLoadLocation(t, "Asia/Shanghai")
+	taskModel := calendarTask(testCalendar("holidays", models.TaskCalendarExclude, [2]string{"2024-10-01", "2024-10-07"}))
+
+	if name := calendarBlockedBy(taskModel, time.Date(2024, 10, 3, 9, 0, 0, 0, shanghai)); name != "holidays" {
+		t.Fatalf("expected blocked by holidays, got %q", name)
+	}
+	if name := calendarBlockedBy(taskModel, time.Date(2024, 10, 8, 9, 0, 0, 0, shanghai)); name != "" {
+		t.Fatalf("expected allowed, got %q", name)
+	}
+	// 按任务时区判断日期, UTC 2024-09-30 16:00 在上海已是10月1日
+	if name := calendarBlockedBy(taskModel, time.Date(2024, 9, 30, 16, 0, 0, 0, time.UTC)); name != "holidays" {
+		t.Fatalf("expected date evaluated in task time zone, got %q", name)
+	}
+}
+
+func TestCalendarBlockedByInclude(t *testing.T) {
+	shanghai := mustLoadLocation(t, "Asia/Shanghai")
+	taskModel := calendarTask(
+		testCalendar("month-end", models.TaskCalendarInclude, [2]string{"2024-01-31", "2024-01-31"}),
+		testCalendar("quarter-end", models.TaskCalendarInclude, [2]string{"2024-03-29", "2024-03-29"}),
+	)
+
+	if name := calendarBlockedBy(taskModel, time.Date(2024, 3, 29, 9, 0, 0, 0, shanghai)); name != "" {
+		t.Fatalf("expected allowed by quarter-end, got %q", name)
+	}
+	if name := calendarBlockedBy(taskModel, time.Date(2024, 2, 1, 9, 0, 0, 0, shanghai)); name != "month-end,quarter-end" {
+		t.Fatalf("expected blocked by include calendars, got %q", name)
+	}
+}
+
+func TestSkipCalendarBlocked(t *testing.T) {
+	shanghai := mustLoadLocation(t, "Asia/Shanghai")
+	taskModel := calendarTask(testCalendar("holidays", models.TaskCalendarExclude, [2]string{"2024-10-01", "2024-10-07"}))
+	schedule, err := parseTaskSchedule(taskModel)
+	if err != nil {
+		t.Fatalf("parse schedule failed: %v", err)
+	}
+
+	next := schedule.Next(time.Date(2024, 9, 30, 12, 0, 0, 0, shanghai))
+	next = skipCalendarBlocked(taskModel, schedule, next)
+	expected := time.Date(2024, 10, 8, 9, 0, 0, 0, shanghai)
+	if !next.Equal(expected) {
+		t.Fatalf("expected %s, got %s", expected, next.In(shanghai))
+	}
+}
+
+func TestSkipCalendarBlockedGivesUp(t *testing.T) {
+	shanghai := mustLoadLocation(t, "Asia/Shanghai")
+	taskModel := calendarTask(testCalendar("never", models.TaskCalendarInclude))
+	schedule, _ := parseTaskSchedule(taskModel)
+
+	next := skipCalendarBlocked(taskModel, schedule, schedule.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, shanghai)))
+	if !next.IsZero() {
+		t.Fatalf("expected zero time when no date is allowed, got %s", next)
+	}
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+)
+
+var (
+	// 并发限制组的名额
+	groupLimiter = newConcurrencyLimiter()
+
+	// 排队中的任务, 任务日志ID => 取消通道
+	queuedJobs sync.Map
+)
+
+// 并发名额, 同一key的运行数不能超过limit
+type concurrencySlot struct {
+	key   string
+	limit int
+}
+
+type concurrencyLimiter struct {
+	mu      sync.Mutex
+	running map[string]int
+	// 名额释放时关闭并替换, 通知等待者重新尝试
+	changed chan struct{}
+}
+
+func newConcurrencyLimiter() *concurrencyLimiter {
+	return &concurrencyLimiter{
+		running: make(map[string]int),
+		changed: make(chan struct{}),
+	}
+}
+
+// 所有名额都有空余时一次性占用, 否则返回名额变化通知
+func (l *concurrencyLimiter) tryAcquire(slots []concurrencySlot) (bool, <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, slot := range slots {
+		if l.running[slot.key] >= slot.limit {
+			return false, l.changed
+		}
+	}
+	for _, slot := range slots {
+		l.running[slot.key]++
+	}
+
+	return true, nil
+}
+
+// 等待并占用名额, cancel关闭时放弃等待并返回false
+func (l *concurrencyLimiter) acquire(slots []concurrencySlot, cancel <-chan struct{}) bool {
+	for {
+		ok, changed := l.tryAcquire(slots)
+		if ok {
+			return true
+		}
+		select {
+		case <-changed:
+		case <-cancel:
+			return false
+		}
+	}
+}
+
+func (l *concurrencyLimiter) release(slots []concurrencySlot) {
+	if len(slots) == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, slot := range slots {
+		l.running[slot.key]--
+		if l.running[slot.key] <= 0 {
+			delete(l.running, slot.key)
+		}
+	}
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// 根据任务所属的组生成需要占用的名额, 按主机限制时占用本次执行的主机的名额
+// 故障转移可能使用任意一台主机, 占用所有主机的名额
+func buildConcurrencySlots(taskModel models.Task, groups []models.ConcurrencyGroup) []concurrencySlot {
+	slots := make([]concurrencySlot, 0, len(groups))
+	for _, group := range groups {
+		if group.MaxConcurrency <= 0 {
+			continue
+		}
+		switch group.Scope {
+		case models.ConcurrencyScopeHost:
+			for _, host := range taskModel.Hosts {
+				slots = append(slots, concurrencySlot{
+					key:   fmt.Sprintf("group-%d-host-%d", group.Id, host.HostId),
+					limit: group.MaxConcurrency,
+				})
+			}
+		case models.ConcurrencyScopeTask:
+			slots = append(slots, concurrencySlot{
+				key:   fmt.Sprintf("group-%d-task-%d", group.Id, taskModel.Id),
+				limit: group.MaxConcurrency,
+			})
+		default:
+			slots = append(slots, concurrencySlot{
+				key:   fmt.Sprintf("group-%d", group.Id),
+				limit: group.MaxConcurrency,
+			})
+		}
+	}
+
+	return slots
+}
+
+func concurrencySlots(taskModel models.Task) []concurrencySlot {
+	groupModel := new(models.ConcurrencyGroup)
+	groups, err := groupModel.GetByTask(taskModel.Id, taskModel.Tag)
+	if err != nil {
+		logger.Errorf("获取任务并发限制组失败#任务ID-%d#%s", taskModel.Id, err)
+		return nil
+	}
+
+	return buildConcurrencySlots(taskModel, groups)
+}
+
+// 获取并发限制组及全局并发队列的名额, 名额不足时任务日志标记为排队中并等待
+// 排队中被取消时返回false
+func acquireConcurrency(taskModel models.Task, taskLogId int64) (release func(), ok bool) {
+	slots := concurrencySlots(taskModel)
+	cancel := make(chan struct{})
+	queued := false
+	markQueued := func() {
+		if queued {
+			return
+		}
+		queued = true
+		queuedJobs.Store(taskLogId, cancel)
+		logger.Infof("并发数已满, 任务排队等待#任务ID-%d#taskLogId-%d", taskModel.Id, taskLogId)
+		updateQueuedTaskLog(taskLogId, models.CommonMap{"status": models.Queued})
+	}
+
+	if acquired, _ := groupLimiter.tryAcquire(slots); !acquired {
+		markQueued()
+		if !groupLimiter.acquire(slots, cancel) {
+			cancelQueuedTaskLog(taskLogId)
+			return nil, false
+		}
+	}
+	if !concurrencyQueue.TryAdd() {
+		markQueued()
+		if !concurrencyQueue.AddOrCancel(cancel) {
+			groupLimiter.release(slots)
+			cancelQueuedTaskLog(taskLogId)
+			return nil, false
+		}
+	}
+	release = func() {
+		concurrencyQueue.Done()
+		groupLimiter.release(slots)
+	}
+	if queued {
+		// 已被取消但同时获得了名额, 按取消处理
+		if _, exist := queuedJobs.LoadAndDelete(taskLogId); !exist {
+			release()
+			cancelQueuedTaskLog(taskLogId)
+			return nil, false
+		}
+		logger.Infof("任务结束排队, 开始执行#任务ID-%d#taskLogId-%d", taskModel.Id, taskLogId)
+		updateQueuedTaskLog(taskLogId, models.CommonMap{
+			"status":     models.Running,
+			"start_time": time.Now(),
+		})
+	}
+
+	return release, true
+}
+
+// 取消排队中的任务, 任务不在排队中时返回false
+func (task Task) CancelQueued(taskLogId int64) bool {
+	cancel, ok := queuedJobs.LoadAndDelete(taskLogId)
+	if !ok {
+		return false
+	}
+	close(cancel.(chan struct{}))
+
+	return true
+}
+
+func cancelQueuedTaskLog(taskLogId int64) {
+	logger.Infof("排队中的任务被取消#taskLogId-%d", taskLogId)
+	updateQueuedTaskLog(taskLogId, models.CommonMap{
+		"status":   models.Cancel,
+		"result":   "排队中手动停止",
+		"end_time": time.Now(),
+	})
+}
+
+func updateQueuedTaskLog(taskLogId int64, data models.CommonMap) {
+	taskLogModel := new(models.TaskLog)
+	if _, err := taskLogModel.Update(taskLogId, data); err != nil {
+		logger.Errorf("更新任务日志失败#taskLogId-%d#%s", taskLogId, err)
+	}
+}
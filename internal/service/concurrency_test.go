@@ -0,0 +1,124 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+func TestConcurrencyLimiterWaitsForRelease(t *testing.T) {
+	limiter := newConcurrencyLimiter()
+	slots := []concurrencySlot{{key: "group-1", limit: 1}}
+	if !limiter.acquire(slots, nil) {
+		t.Fatal("expected first acquire to succeed")
+	}
+	if ok, _ := limiter.tryAcquire(slots); ok {
+		t.Fatal("expected limit to be reached")
+	}
+
+	acquired := make(chan bool)
+	go func() {
+		acquired <- limiter.acquire(slots, nil)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("expected second acquire to wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	limiter.release(slots)
+	select {
+	case ok := <-acquired:
+		if !ok {
+			t.Fatal("expected second acquire to succeed after release")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("second acquire was not woken up")
+	}
+}
+
+func TestConcurrencyLimiterCancel(t *testing.T) {
+	limiter := newConcurrencyLimiter()
+	slots := []concurrencySlot{{key: "group-1", limit: 1}}
+	limiter.acquire(slots, nil)
+
+	cancel := make(chan struct{})
+	acquired := make(chan bool)
+	go func() {
+		acquired <- limiter.acquire(slots, cancel)
+	}()
+	close(cancel)
+	if <-acquired {
+		t.Fatal("expected cancelled acquire to return false")
+	}
+	if limiter.running["group-1"] != 1 {
+		t.Fatalf("expected cancelled waiter not to hold a slot, got %d", limiter.running["group-1"])
+	}
+}
+
+func TestConcurrencyLimiterAcquiresAllSlotsAtOnce(t *testing.T) {
+	limiter := newConcurrencyLimiter()
+	limiter.acquire([]concurrencySlot{{key: "group-2-host-1", limit: 1}}, nil)
+
+	slots := []concurrencySlot{{key: "group-1", limit: 2}, {key: "group-2-host-1", limit: 1}}
+	if ok, _ := limiter.tryAcquire(slots); ok {
+		t.Fatal("expected acquire to fail when one slot is full")
+	}
+	if limiter.running["group-1"] != 0 {
+		t.Fatal("expected no partial acquisition")
+	}
+}
+
+func TestBuildConcurrencySlots(t *testing.T) {
+	task := models.Task{Id: 7, Hosts: []models.TaskHostDetail{
+		{TaskHost: models.TaskHost{HostId: 1}},
+		{TaskHost: models.TaskHost{HostId: 2}},
+	}}
+	groups := []models.ConcurrencyGroup{
+		{Id: 1, MaxConcurrency: 2, Scope: models.ConcurrencyScopeGroup},
+		{Id: 2, MaxConcurrency: 5, Scope: models.ConcurrencyScopeHost},
+		{Id: 3, MaxConcurrency: 1, Scope: models.ConcurrencyScopeTask},
+		{Id: 4, MaxConcurrency: 0, Scope: models.ConcurrencyScopeGroup},
+	}
+	slots := buildConcurrencySlots(task, groups)
+	expected := []concurrencySlot{
+		{key: "group-1", limit: 2},
+		{key: "group-2-host-1", limit: 5},
+		{key: "group-2-host-2", limit: 5},
+		{key: "group-3-task-7", limit: 1},
+	}
+	if len(slots) != len(expected) {
+		t.Fatalf("expected %d slots, got %+v", len(expected), slots)
+	}
+	for i := range expected {
+		if slots[i] != expected[i] {
+			t.Fatalf("slot %d: expected %+v, got %+v", i, expected[i], slots[i])
+		}
+	}
+}
+
+func TestBuildConcurrencySlotsForSelectedHost(t *testing.T) {
+	original := hostSelect
+	defer func() { hostSelect = original }()
+	hostSelect = newTestHostSelector()
+
+	groups := []models.ConcurrencyGroup{{Id: 2, MaxConcurrency: 1, Scope: models.ConcurrencyScopeHost}}
+	task := models.Task{Id: 7, Protocol: models.TaskRPC, Hosts: testHosts("a", "b", "c"), HostStrategy: models.TaskHostRoundRobin}
+	for _, expected := range []string{"group-2-host-1", "group-2-host-2"} {
+		selected := selectRunHosts(task)
+		slots := buildConcurrencySlots(selected, groups)
+		if len(slots) != 1 || slots[0].key != expected {
+			t.Fatalf("expected only slot %s, got %+v", expected, slots)
+		}
+		// 已选定主机的执行不再重复选择
+		if hosts := hostSelect.candidates(selected); len(hosts) != 1 || hosts[0].HostId != selected.Hosts[0].HostId {
+			t.Fatalf("expected selected host to be kept, got %+v", hosts)
+		}
+	}
+
+	task.HostStrategy = models.TaskHostFailover
+	if slots := buildConcurrencySlots(selectRunHosts(task), groups); len(slots) != 3 {
+		t.Fatalf("expected failover to reserve all hosts, got %+v", slots)
+	}
+}
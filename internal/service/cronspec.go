@@ -0,0 +1,351 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocronx-team/cron"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+)
+
+// 表达式校验及预览
+// 保存任务、工作流前校验表达式, 预览接口返回之后的执行时间、表达式说明及可能不符合预期的配置提示
+
+const (
+	defaultPreviewCount = 5
+	maxPreviewCount     = 50
+	// 计算执行间隔时使用的执行时间数量
+	previewIntervalSamples = 10
+)
+
+var (
+	ErrSpecEmpty      = errors.New("表达式不能为空")
+	ErrSpecZeroStep   = errors.New("表达式步长不能为0")
+	ErrSpecNeverFires = errors.New("表达式没有可执行的时间")
+)
+
+// 表达式预览结果
+type SchedulePreview struct {
+	NextTimes   []string `json:"next_times"`
+	Description string   `json:"description"`
+	Warnings    []string `json:"warnings"`
+}
+
+// ValidateSchedule 校验表达式, 返回按时区解析后的执行计划
+func ValidateSchedule(spec, timezone string) (cron.Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, ErrSpecEmpty
+	}
+	// 步长为0时解析会陷入死循环
+	if !strings.HasPrefix(spec, "@") {
+		for _, field := range strings.Fields(spec) {
+			for _, expr := range strings.Split(field, ",") {
+				parts := strings.Split(expr, "/")
+				if len(parts) == 2 && strings.TrimLeft(parts[1], "0") == "" {
+					return nil, ErrSpecZeroStep
+				}
+			}
+		}
+	}
+	schedule, err := parseSchedule(spec, timezone)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return nil, ErrSpecNeverFires
+	}
+
+	return schedule, nil
+}
+
+// PreviewSchedule 计算表达式之后count次执行时间(按时区表示), 并生成指定语言的说明和提示
+func PreviewSchedule(spec, timezone string, count int, locale i18n.Locale) (SchedulePreview, error) {
+	preview := SchedulePreview{NextTimes: []string{}, Warnings: []string{}}
+	schedule, err := ValidateSchedule(spec, timezone)
+	if err != nil {
+		return preview, err
+	}
+	location, err := loadTaskLocation(timezone)
+	if err != nil {
+		return preview, err
+	}
+	if count <= 0 {
+		count = defaultPreviewCount
+	}
+	if count > maxPreviewCount {
+		count = maxPreviewCount
+	}
+	samples := count
+	if samples < previewIntervalSamples {
+		samples = previewIntervalSamples
+	}
+	spec = strings.TrimSpace(spec)
+	fireTimes := make([]time.Time, 0, samples)
+	for next := schedule.Next(time.Now()); !next.IsZero() && len(fireTimes) < samples; next = schedule.Next(next) {
+		fireTimes = append(fireTimes, next)
+	}
+	for i := 0; i < len(fireTimes) && i < count; i++ {
+		preview.NextTimes = append(preview.NextTimes, fireTimes[i].In(location).Format(models.DefaultTimeFormat))
+	}
+	preview.Description = describeSchedule(spec, schedule, locale)
+	for _, key := range scheduleWarnings(spec, schedule, fireTimes) {
+		preview.Warnings = append(preview.Warnings, i18n.Translate(locale, key))
+	}
+
+	return preview, nil
+}
+
+// 可能不符合预期的配置, 返回提示信息的翻译key
+func scheduleWarnings(spec string, schedule cron.Schedule, fireTimes []time.Time) []string {
+	warnings := make([]string, 0)
+	if !strings.HasPrefix(spec, "@") && len(strings.Fields(spec)) == 5 {
+		warnings = append(warnings, "cron_warning_five_fields")
+	}
+	var minInterval time.Duration
+	for i := 1; i < len(fireTimes); i++ {
+		interval := fireTimes[i].Sub(fireTimes[i-1])
+		if minInterval == 0 || interval < minInterval {
+			minInterval = interval
+		}
+	}
+	switch {
+	case minInterval == 0:
+	case minInterval <= time.Second:
+		warnings = append(warnings, "cron_warning_every_second")
+	case minInterval < time.Minute:
+		warnings = append(warnings, "cron_warning_sub_minute")
+	}
+	if specSchedule := unwrapSpecSchedule(schedule); specSchedule != nil &&
+		specSchedule.Dom&scheduleStarBit == 0 && specSchedule.Dow&scheduleStarBit == 0 {
+		warnings = append(warnings, "cron_warning_dom_or_dow")
+	}
+
+	return warnings
+}
+
+func unwrapSpecSchedule(schedule cron.Schedule) *cron.SpecSchedule {
+	switch s := schedule.(type) {
+	case *cron.SpecSchedule:
+		return s
+	case *zoneSchedule:
+		return s.spec
+	}
+
+	return nil
+}
+
+// 表达式中的一个字段
+type specField struct {
+	star   bool  // 包含*
+	step   int   // 包含*时的步长
+	values []int // 匹配的值
+}
+
+func newSpecField(bits uint64, min, max int) specField {
+	field := specField{star: bits&scheduleStarBit > 0}
+	for i := min; i <= max; i++ {
+		if bits&(1<<uint(i)) > 0 {
+			field.values = append(field.values, i)
+		}
+	}
+	if field.star && len(field.values) > 1 {
+		field.step = field.values[1] - field.values[0]
+	}
+
+	return field
+}
+
+// 每个值都匹配
+func (f specField) every() bool {
+	return f.star && f.step == 1
+}
+
+func (f specField) single() bool {
+	return !f.star && len(f.values) == 1
+}
+
+// 连续3个及以上的值合并为范围, 如 1-5,7
+func formatFieldValues(values []int, name func(int) string) string {
+	parts := make([]string, 0)
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			parts = append(parts, name(values[i])+"-"+name(values[j]))
+		} else {
+			for k := i; k <= j; k++ {
+				parts = append(parts, name(values[k]))
+			}
+		}
+		i = j + 1
+	}
+
+	return strings.Join(parts, ",")
+}
+
+var (
+	zhWeekdays = []string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+	enWeekdays = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+)
+
+// 生成表达式说明
+func describeSchedule(spec string, schedule cron.Schedule, locale i18n.Locale) string {
+	if delay, ok := schedule.(cron.ConstantDelaySchedule); ok {
+		if locale == i18n.EnUS {
+			return "Every " + delay.Delay.String()
+		}
+		return "每隔" + delay.Delay.String() + "执行一次"
+	}
+	specSchedule := unwrapSpecSchedule(schedule)
+	if specSchedule == nil {
+		return spec
+	}
+	second := newSpecField(specSchedule.Second, 0, 59)
+	minute := newSpecField(specSchedule.Minute, 0, 59)
+	hour := newSpecField(specSchedule.Hour, 0, 23)
+	dom := newSpecField(specSchedule.Dom, 1, 31)
+	month := newSpecField(specSchedule.Month, 1, 12)
+	dow := newSpecField(specSchedule.Dow, 0, 6)
+	if locale == i18n.EnUS {
+		return describeScheduleEn(second, minute, hour, dom, month, dow)
+	}
+
+	return describeScheduleZh(second, minute, hour, dom, month, dow)
+}
+
+// 秒、分固定且小时不超过6个时返回具体的时间点
+func fixedClockTimes(second, minute, hour specField) []string {
+	if !second.single() || !minute.single() || hour.star || len(hour.values) > 6 {
+		return nil
+	}
+	times := make([]string, 0, len(hour.values))
+	for _, h := range hour.values {
+		times = append(times, fmt.Sprintf("%02d:%02d:%02d", h, minute.values[0], second.values[0]))
+	}
+
+	return times
+}
+
+func describeScheduleZh(second, minute, hour, dom, month, dow specField) string {
+	number := strconv.Itoa
+	parts := make([]string, 0)
+	if !month.star {
+		parts = append(parts, "每年"+formatFieldValues(month.values, number)+"月")
+	}
+	weekday := func(v int) string {
+		return zhWeekdays[v]
+	}
+	switch {
+	case !dom.star && !dow.star:
+		parts = append(parts, "每月"+formatFieldValues(dom.values, number)+"号或每周"+
+			strings.TrimPrefix(formatFieldValues(dow.values, weekday), "周"))
+	case !dom.star:
+		parts = append(parts, "每月"+formatFieldValues(dom.values, number)+"号")
+	case !dow.star:
+		parts = append(parts, "每"+formatFieldValues(dow.values, weekday))
+	case !hour.star || !month.star:
+		parts = append(parts, "每天")
+	}
+
+	if times := fixedClockTimes(second, minute, hour); times != nil {
+		parts = append(parts, strings.Join(times, ","))
+		return strings.Join(parts, " ")
+	}
+	switch {
+	case hour.every():
+		if !minute.star {
+			parts = append(parts, "每小时")
+		}
+	case hour.star:
+		parts = append(parts, fmt.Sprintf("每隔%d小时", hour.step))
+	default:
+		parts = append(parts, formatFieldValues(hour.values, number)+"点")
+	}
+	switch {
+	case minute.every():
+		if !second.star {
+			parts = append(parts, "每分钟")
+		}
+	case minute.star:
+		parts = append(parts, fmt.Sprintf("每隔%d分钟", minute.step))
+	default:
+		parts = append(parts, "第"+formatFieldValues(minute.values, number)+"分")
+	}
+	switch {
+	case second.every():
+		parts = append(parts, "每秒")
+	case second.star:
+		parts = append(parts, fmt.Sprintf("每隔%d秒", second.step))
+	default:
+		parts = append(parts, "第"+formatFieldValues(second.values, number)+"秒")
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func describeScheduleEn(second, minute, hour, dom, month, dow specField) string {
+	number := strconv.Itoa
+	parts := make([]string, 0)
+	if times := fixedClockTimes(second, minute, hour); times != nil {
+		parts = append(parts, "At "+strings.Join(times, ", "))
+	} else {
+		switch {
+		case second.every():
+			parts = append(parts, "Every second")
+		case second.star:
+			parts = append(parts, fmt.Sprintf("Every %d seconds", second.step))
+		default:
+			parts = append(parts, "At second "+formatFieldValues(second.values, number))
+		}
+		switch {
+		case minute.every():
+			if !second.star {
+				parts = append(parts, "every minute")
+			}
+		case minute.star:
+			parts = append(parts, fmt.Sprintf("every %d minutes", minute.step))
+		default:
+			parts = append(parts, "at minute "+formatFieldValues(minute.values, number))
+		}
+		switch {
+		case hour.every():
+			if !minute.star {
+				parts = append(parts, "every hour")
+			}
+		case hour.star:
+			parts = append(parts, fmt.Sprintf("every %d hours", hour.step))
+		default:
+			parts = append(parts, "during hour "+formatFieldValues(hour.values, number))
+		}
+	}
+
+	weekday := func(v int) string {
+		return enWeekdays[v]
+	}
+	switch {
+	case !dom.star && !dow.star:
+		parts = append(parts, "on day "+formatFieldValues(dom.values, number)+
+			" of the month or on "+formatFieldValues(dow.values, weekday))
+	case !dom.star:
+		parts = append(parts, "on day "+formatFieldValues(dom.values, number)+" of the month")
+	case !dow.star:
+		parts = append(parts, "on "+formatFieldValues(dow.values, weekday))
+	case !hour.star || !month.star:
+		parts = append(parts, "every day")
+	}
+	if !month.star {
+		monthName := func(v int) string {
+			return time.Month(v).String()
+		}
+		parts = append(parts, "in "+formatFieldValues(month.values, monthName))
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/modules/i18n"
+)
+
+func TestValidateSchedule(t *testing.T) {
+	if _, err := ValidateSchedule("  ", ""); !errors.Is(err, ErrSpecEmpty) {
+		t.Fatalf("expected empty spec error, got %v", err)
+	}
+	if _, err := ValidateSchedule("*/0 * * * * *", ""); !errors.Is(err, ErrSpecZeroStep) {
+		t.Fatalf("expected zero step error, got %v", err)
+	}
+	if _, err := ValidateSchedule("0 0 0 30 2 *", ""); !errors.Is(err, ErrSpecNeverFires) {
+		t.Fatalf("expected never fires error, got %v", err)
+	}
+	if _, err := ValidateSchedule("0 0 61 * * *", ""); err == nil {
+		t.Fatal("expected invalid hour to fail")
+	}
+	if _, err := ValidateSchedule("0 0 8 * * *", "Invalid/Zone"); err == nil {
+		t.Fatal("expected invalid timezone to fail")
+	}
+	for _, spec := range []string{"0 */5 * * * *", "0 0 8 * *", "@every 30m", "@daily"} {
+		if _, err := ValidateSchedule(spec, "Asia/Shanghai"); err != nil {
+			t.Fatalf("expected %q to be valid, got %v", spec, err)
+		}
+	}
+}
+
+func TestPreviewSchedule(t *testing.T) {
+	preview, err := PreviewSchedule("0 30 8 * * *", "Asia/Shanghai", 3, i18n.ZhCN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(preview.NextTimes) != 3 {
+		t.Fatalf("expected 3 fire times, got %v", preview.NextTimes)
+	}
+	for _, next := range preview.NextTimes {
+		if !strings.HasSuffix(next, " 08:30:00") {
+			t.Fatalf("expected fire time in task timezone, got %s", next)
+		}
+	}
+	if preview.Description != "每天 08:30:00" {
+		t.Fatalf("unexpected description %q", preview.Description)
+	}
+	if len(preview.Warnings) != 0 {
+		t.Fatalf("unexpected warnings %v", preview.Warnings)
+	}
+
+	preview, err = PreviewSchedule("@every 1h", "", 100, i18n.EnUS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(preview.NextTimes) != maxPreviewCount {
+		t.Fatalf("expected count to be limited to %d, got %d", maxPreviewCount, len(preview.NextTimes))
+	}
+	if preview.Description != "Every 1h0m0s" {
+		t.Fatalf("unexpected description %q", preview.Description)
+	}
+}
+
+func TestDescribeSchedule(t *testing.T) {
+	tests := []struct {
+		spec string
+		zh   string
+		en   string
+	}{
+		{"* * * * * *", "每秒", "Every second"},
+		{"0 */5 * * * *", "每隔5分钟 第0秒", "At second 0, every 5 minutes"},
+		{"0 0 9-17 * * 1-5", "每周一-周五 9-17点 第0分 第0秒", "At second 0, at minute 0, during hour 9-17, on Monday-Friday"},
+		{"0 0 1 1,15 * *", "每月1,15号 01:00:00", "At 01:00:00, on day 1,15 of the month"},
+		{"0 0 0 1 6 *", "每年6月 每月1号 00:00:00", "At 00:00:00, on day 1 of the month, in June"},
+	}
+	for _, test := range tests {
+		schedule, err := ValidateSchedule(test.spec, "")
+		if err != nil {
+			t.Fatalf("parse %q: %v", test.spec, err)
+		}
+		if got := describeSchedule(test.spec, schedule, i18n.ZhCN); got != test.zh {
+			t.Errorf("%q zh: expected %q, got %q", test.spec, test.zh, got)
+		}
+		if got := describeSchedule(test.spec, schedule, i18n.EnUS); got != test.en {
+			t.Errorf("%q en: expected %q, got %q", test.spec, test.en, got)
+		}
+	}
+}
+
+func TestScheduleWarnings(t *testing.T) {
+	warnings := func(spec string) []string {
+		schedule, err := ValidateSchedule(spec, "")
+		if err != nil {
+			t.Fatalf("parse %q: %v", spec, err)
+		}
+		fireTimes := make([]time.Time, 0)
+		for next := schedule.Next(time.Now()); len(fireTimes) < previewIntervalSamples; next = schedule.Next(next) {
+			fireTimes = append(fireTimes, next)
+		}
+		return scheduleWarnings(spec, schedule, fireTimes)
+	}
+	has := func(list []string, key string) bool {
+		for _, item := range list {
+			if item == key {
+				return true
+			}
+		}
+		return false
+	}
+
+	if list := warnings("* * * * * *"); !has(list, "cron_warning_every_second") {
+		t.Fatalf("expected every second warning, got %v", list)
+	}
+	if list := warnings("*/15 * * * * *"); !has(list, "cron_warning_sub_minute") || has(list, "cron_warning_every_second") {
+		t.Fatalf("expected sub minute warning, got %v", list)
+	}
+	if list := warnings("0 30 8 * *"); !has(list, "cron_warning_five_fields") {
+		t.Fatalf("expected five fields warning, got %v", list)
+	}
+	if list := warnings("0 0 8 1 * 1"); !has(list, "cron_warning_dom_or_dow") {
+		t.Fatalf("expected dom or dow warning, got %v", list)
+	}
+	if list := warnings("0 0 8 * * *"); len(list) != 0 {
+		t.Fatalf("expected no warnings, got %v", list)
+	}
+}
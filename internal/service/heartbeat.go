@@ -0,0 +1,175 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gocronx-team/cron"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+)
+
+// 被动心跳任务
+// 任务不执行命令, 由外部系统(如其他服务器上的crontab)执行任务后请求心跳地址, 每次请求写入一条任务日志
+// 任务表达式为预期收到心跳的时间点, 到达时间点并等待宽限时间后检查, 两次检查之间没有收到心跳时按任务配置发送通知
+// 宽限时间使用定时器等待, 不阻塞调度器; 到期时已不是调度节点则不检查, 任务删除、修改时停止等待中的定时器
+
+// 查找上次执行时间时最多向前查找的时间
+const heartbeatLookbackLimit = 366 * 24 * time.Hour
+
+var heartbeatWaits heartbeatTimers
+
+// 等待宽限时间的定时器, 任务ID作为Key
+// 宽限时间可能超过执行间隔, 同一任务可同时有多个定时器
+type heartbeatTimers struct {
+	mu sync.Mutex
+	m  map[int]map[*heartbeatWait]struct{}
+}
+
+type heartbeatWait struct {
+	timer *time.Timer // 读写需持有heartbeatTimers.mu
+}
+
+// 宽限时间结束后执行fn, 定时器被停止时不执行
+func (h *heartbeatTimers) start(taskId int, grace time.Duration, fn func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.m == nil {
+		h.m = make(map[int]map[*heartbeatWait]struct{})
+	}
+	if h.m[taskId] == nil {
+		h.m[taskId] = make(map[*heartbeatWait]struct{})
+	}
+	wait := &heartbeatWait{}
+	wait.timer = time.AfterFunc(grace, func() {
+		if h.done(taskId, wait) {
+			fn()
+		}
+	})
+	h.m[taskId][wait] = struct{}{}
+}
+
+// 定时器到期, 已被停止时返回false
+func (h *heartbeatTimers) done(taskId int, wait *heartbeatWait) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.m[taskId][wait]; !ok {
+		return false
+	}
+	delete(h.m[taskId], wait)
+	if len(h.m[taskId]) == 0 {
+		delete(h.m, taskId)
+	}
+
+	return true
+}
+
+// 停止任务等待中的定时器
+func (h *heartbeatTimers) stop(taskId int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for wait := range h.m[taskId] {
+		wait.timer.Stop()
+	}
+	delete(h.m, taskId)
+}
+
+// 停止所有等待中的定时器
+func (h *heartbeatTimers) stopAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, waits := range h.m {
+		for wait := range waits {
+			wait.timer.Stop()
+		}
+	}
+	h.m = nil
+}
+
+// 创建检查心跳的Job
+func createHeartbeatJob(taskModel models.Task) cron.FuncJob {
+	schedule, err := parseTaskSchedule(taskModel)
+	if err != nil {
+		logger.Errorf("创建心跳检查Job失败#ID-%d#%s", taskModel.Id, err)
+		return nil
+	}
+
+	return func() {
+		expected := time.Now().Truncate(time.Second)
+		trigger := jobTrigger{Type: models.TaskLogTriggerCron, ScheduledTime: expected}
+		if skipByCalendar(taskModel, trigger) {
+			return
+		}
+		heartbeatWaits.start(taskModel.Id, heartbeatGrace(taskModel), func() {
+			if !ServiceTask.IsScheduler() {
+				return
+			}
+			checkHeartbeat(taskModel, schedule, trigger)
+			ServiceTask.finishOnce(taskModel)
+		})
+	}
+}
+
+func heartbeatGrace(taskModel models.Task) time.Duration {
+	return time.Duration(taskModel.PingGrace) * time.Second
+}
+
+// 检查上一个预期时间点的宽限时间结束后到本次宽限时间结束前是否收到心跳, 未收到时写入失败日志并发送通知
+func checkHeartbeat(taskModel models.Task, schedule cron.Schedule, trigger jobTrigger) {
+	grace := heartbeatGrace(taskModel)
+	windowEnd := trigger.ScheduledTime.Add(grace)
+	var windowStart time.Time
+	if previous := previousFireTime(schedule, trigger.ScheduledTime); !previous.IsZero() {
+		windowStart = previous.Add(grace)
+	}
+	taskLogModel := new(models.TaskLog)
+	pinged, err := taskLogModel.PingedBetween(taskModel.Id, windowStart, windowEnd)
+	if err != nil {
+		logger.Errorf("心跳检查#获取心跳记录失败#任务ID-%d#%s", taskModel.Id, err)
+		return
+	}
+	if pinged {
+		return
+	}
+
+	message := fmt.Sprintf("在%s前未收到心跳请求", windowEnd.Format(models.DefaultTimeFormat))
+	logger.Warnf("心跳检查#任务ID-%d#名称-%s#%s", taskModel.Id, taskModel.Name, message)
+	taskResult := TaskResult{Result: message, Err: fmt.Errorf("%s", message)}
+	taskLogId, err := createTaskLog(taskModel, trigger, models.Running)
+	if err != nil {
+		logger.Errorf("心跳检查#写入任务日志失败#任务ID-%d#%s", taskModel.Id, err)
+	} else if _, err = updateTaskLog(taskLogId, taskResult); err != nil {
+		logger.Errorf("心跳检查#更新任务日志失败#任务ID-%d#%s", taskModel.Id, err)
+	}
+	SendNotification(taskModel, taskResult)
+}
+
+// 计算t之前最近一次执行时间, 没有时返回零值
+func previousFireTime(schedule cron.Schedule, t time.Time) time.Time {
+	for lookback := time.Minute; lookback <= heartbeatLookbackLimit; lookback *= 2 {
+		previous := schedule.Next(t.Add(-lookback))
+		if previous.IsZero() || !previous.Before(t) {
+			continue
+		}
+		for {
+			next := schedule.Next(previous)
+			if next.IsZero() || !next.Before(t) {
+				return previous
+			}
+			previous = next
+		}
+	}
+
+	return time.Time{}
+}
+
+// Ping 记录被动心跳任务收到的心跳请求, output为请求中附带的内容
+// 只写入任务日志, 不执行任务, 任意节点均可处理; 是否收到心跳由调度节点检查
+func (task Task) Ping(taskModel models.Task, output string) (int64, error) {
+	taskLogModel := newTaskLog(taskModel, jobTrigger{Type: models.TaskLogTriggerPing}, models.Finish)
+	taskLogModel.Result = output
+	taskLogModel.EndTime = taskLogModel.StartTime
+
+	return taskLogModel.Create()
+}
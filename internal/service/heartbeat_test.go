@@ -0,0 +1,73 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+func TestPreviousFireTime(t *testing.T) {
+	cases := []struct {
+		spec     string
+		now      time.Time
+		expected time.Time
+	}{
+		{"0 */5 * * * *", time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local), time.Date(2024, 3, 1, 9, 55, 0, 0, time.Local)},
+		{"0 30 2 * * *", time.Date(2024, 3, 1, 2, 30, 0, 0, time.Local), time.Date(2024, 2, 29, 2, 30, 0, 0, time.Local)},
+		{"0 0 0 1 * *", time.Date(2024, 3, 15, 0, 0, 0, 0, time.Local), time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)},
+	}
+	for _, item := range cases {
+		schedule, err := parseSchedule(item.spec, "")
+		if err != nil {
+			t.Fatalf("parse %s failed: %v", item.spec, err)
+		}
+		if got := previousFireTime(schedule, item.now); !got.Equal(item.expected) {
+			t.Fatalf("spec %s: expected %s, got %s", item.spec, item.expected, got)
+		}
+	}
+}
+
+func TestPreviousFireTimeOnce(t *testing.T) {
+	runAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	task := models.Task{RunAt: runAt.Format(models.DefaultTimeFormat)}
+	schedule, err := parseTaskSchedule(task)
+	if err != nil {
+		t.Fatalf("parse once schedule failed: %v", err)
+	}
+	if previous := previousFireTime(schedule, runAt); !previous.IsZero() {
+		t.Fatalf("expected no previous fire time for once task, got %s", previous)
+	}
+	if previous := previousFireTime(schedule, runAt.Add(time.Minute)); !previous.Equal(runAt) {
+		t.Fatalf("expected previous fire time %s, got %s", runAt, previous)
+	}
+}
+
+func TestHeartbeatTimersStop(t *testing.T) {
+	var waits heartbeatTimers
+	fired := make(chan int, 3)
+	waits.start(1, 20*time.Millisecond, func() { fired <- 1 })
+	waits.start(1, 20*time.Millisecond, func() { fired <- 1 })
+	waits.start(2, 20*time.Millisecond, func() { fired <- 2 })
+	// 任务删除、修改时停止等待中的定时器, 其他任务不受影响
+	waits.stop(1)
+	select {
+	case taskId := <-fired:
+		if taskId != 2 {
+			t.Fatalf("expected stopped timers not to fire, got task %d", taskId)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected timer of other task to fire")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if len(fired) != 0 || len(waits.m) != 0 {
+		t.Fatalf("expected no pending timers, fired=%d pending=%d", len(fired), len(waits.m))
+	}
+
+	waits.start(3, 10*time.Millisecond, func() { fired <- 3 })
+	waits.stopAll()
+	time.Sleep(30 * time.Millisecond)
+	if len(fired) != 0 {
+		t.Fatal("expected all timers to be stopped")
+	}
+}
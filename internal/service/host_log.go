@@ -0,0 +1,164 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	rpcClient "github.com/gocronx-team/gocron/internal/modules/rpc/client"
+	"github.com/gocronx-team/gocron/internal/modules/sshclient"
+)
+
+// 每台主机的执行记录
+// RPC任务在每台主机上的每次执行(包括重试)写入task_host_log, 任务日志的状态按任务的成功规则由各主机的结果决定
+// 只在一台主机上执行时任务日志记录该主机的输出, 多台主机执行时只记录各主机的执行状态, 输出见主机执行记录
+
+const (
+	// 主机执行记录错误信息的最大长度
+	maxHostLogErrorLength = 512
+	// 任务日志中执行主机的最大长度
+	maxTaskLogHostnameLength = 128
+)
+
+var (
+	createHostLogFunc = createHostLog
+	finishHostLogFunc = finishHostLog
+	listHostLogsFunc  = listHostLogs
+)
+
+func createHostLog(taskModel models.Task, taskLogId int64, host models.TaskHostDetail) int64 {
+	attempt := taskModel.RunAttempt
+	if attempt < 1 {
+		attempt = 1
+	}
+	hostLog := &models.TaskHostLog{
+		TaskLogId: taskLogId,
+		TaskId:    taskModel.Id,
+		HostId:    host.HostId,
+		Alias:     host.Alias,
+		Name:      host.Name,
+		Port:      host.Port,
+		Attempt:   attempt,
+		Status:    models.Running,
+		StartTime: models.LocalTime(time.Now()),
+	}
+	hostLogId, err := hostLog.Create()
+	if err != nil {
+		logger.Errorf("创建主机执行记录失败#日志ID-%d#主机-%s:%d#%s", taskLogId, host.Name, host.Port, err)
+	}
+
+	return hostLogId
+}
+
+func listHostLogs(taskLogId int64) ([]models.TaskHostLog, error) {
+	hostLogModel := new(models.TaskHostLog)
+
+	return hostLogModel.ListByTaskLogId(taskLogId)
+}
+
+// 主机执行失败时的状态, 手动停止为已取消
+func hostLogStatus(err error) models.Status {
+	if errors.Is(err, rpcClient.ErrCanceled) || errors.Is(err, sshclient.ErrCanceled) {
+		return models.Cancel
+	}
+
+	return models.Failure
+}
+
+func finishHostLog(hostLogId int64, output string, err error) {
+	if hostLogId <= 0 {
+		return
+	}
+	data := models.CommonMap{
+		"status":   models.Finish,
+		"output":   output,
+		"end_time": time.Now(),
+	}
+	if err != nil {
+		data["status"] = hostLogStatus(err)
+		data["error_type"] = strings.Join(taskErrorClasses(err), ",")
+		message := err.Error()
+		if len([]rune(message)) > maxHostLogErrorLength {
+			message = string([]rune(message)[:maxHostLogErrorLength])
+		}
+		data["error"] = message
+	}
+	hostLogModel := new(models.TaskHostLog)
+	if _, updateErr := hostLogModel.Update(hostLogId, data); updateErr != nil {
+		logger.Errorf("更新主机执行记录失败#记录ID-%d#%s", hostLogId, updateErr)
+	}
+}
+
+// 按任务的成功规则汇总多台主机的执行结果, total为需要执行的主机数, 未执行的主机视为未成功
+func hostsResultError(taskModel models.Task, results []TaskResult, total int) error {
+	// 只有一台主机时直接使用该主机的错误
+	if total == 1 && len(results) == 1 {
+		return results[0].Err
+	}
+	succeeded := 0
+	errorClasses := make([]string, 0)
+	for _, taskResult := range results {
+		if taskResult.Err == nil {
+			succeeded++
+			continue
+		}
+		errorClasses = append(errorClasses, taskErrorClasses(taskResult.Err)...)
+	}
+	var satisfied bool
+	var rule string
+	switch taskModel.HostSuccessRule {
+	case models.TaskHostSuccessAny:
+		satisfied, rule = succeeded > 0, "任意一台主机成功"
+	case models.TaskHostSuccessQuorum:
+		satisfied, rule = succeeded*2 > total, "超过半数主机成功"
+	default:
+		satisfied, rule = succeeded == total, "所有主机成功"
+	}
+	if satisfied {
+		return nil
+	}
+
+	return newTaskError(fmt.Errorf("%d台主机中%d台执行成功, 不满足[%s]", total, succeeded, rule), errorClasses...)
+}
+
+// 任务日志记录的执行主机, 主机别名按逗号分隔, 超出长度时只记录第一台主机及主机数量
+func taskLogHostname(hosts []models.TaskHostDetail) string {
+	if len(hosts) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		names = append(names, host.Alias)
+	}
+	hostname := strings.Join(names, ", ")
+	if utf8.RuneCountInString(hostname) <= maxTaskLogHostnameLength {
+		return hostname
+	}
+
+	return fmt.Sprintf("%s等%d台主机", names[0], len(hosts))
+}
+
+// 主机的执行状态, 失败时附带错误信息的第一行
+func hostResultLine(host models.TaskHostDetail, taskResult TaskResult) string {
+	if taskResult.Err == nil {
+		return fmt.Sprintf("[%s] 执行成功", host.Alias)
+	}
+	message := strings.SplitN(strings.TrimSpace(taskResult.Err.Error()), "\n", 2)[0]
+
+	return fmt.Sprintf("[%s] 执行失败: %s", host.Alias, message)
+}
+
+// 多台主机执行结果的摘要, 每台主机一行
+func hostsResultSummary(hosts []models.TaskHostDetail, results []TaskResult) string {
+	var builder strings.Builder
+	for i, taskResult := range results {
+		builder.WriteString(hostResultLine(hosts[i], taskResult))
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	rpcClient "github.com/gocronx-team/gocron/internal/modules/rpc/client"
+	pb "github.com/gocronx-team/gocron/internal/modules/rpc/proto"
+	"github.com/gocronx-team/gocron/internal/modules/sshclient"
+)
+
+// 主机执行记录
+type testHostLog struct {
+	host    string
+	attempt int
+	output  string
+	err     error
+}
+
+// 替换主机执行记录的读写, 返回记录的结果
+func stubHostLogs(t *testing.T) func() []testHostLog {
+	t.Helper()
+	originalCreate, originalFinish := createHostLogFunc, finishHostLogFunc
+	t.Cleanup(func() { createHostLogFunc, finishHostLogFunc = originalCreate, originalFinish })
+	var mu sync.Mutex
+	logs := make([]testHostLog, 0)
+	createHostLogFunc = func(taskModel models.Task, taskLogId int64, host models.TaskHostDetail) int64 {
+		mu.Lock()
+		defer mu.Unlock()
+		logs = append(logs, testHostLog{host: host.Name, attempt: taskModel.RunAttempt})
+		return int64(len(logs))
+	}
+	finishHostLogFunc = func(hostLogId int64, output string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		logs[hostLogId-1].output, logs[hostLogId-1].err = output, err
+	}
+
+	return func() []testHostLog {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]testHostLog(nil), logs...)
+	}
+}
+
+func TestRPCHandlerHostLogs(t *testing.T) {
+	hostLogs := stubHostLogs(t)
+	originalExec := rpcExecFunc
+	defer func() { rpcExecFunc = originalExec }()
+	rpcExecFunc = func(ip string, port int, taskReq *pb.TaskRequest) (string, error) {
+		if ip == "b" {
+			return "", rpcClient.ErrUnavailable
+		}
+		return "done on " + ip, nil
+	}
+
+	task := models.Task{Id: 1, Protocol: models.TaskRPC, Hosts: testHosts("a", "b", "c"), RunAttempt: 2}
+	handler := new(RPCHandler)
+	output, err := handler.Run(task, 1)
+	if err == nil || !strings.Contains(err.Error(), "3台主机中2台执行成功") {
+		t.Fatalf("expected all rule to fail, got %v", err)
+	}
+	// 结果按主机顺序
+	if strings.Index(output, "done on a") > strings.Index(output, "done on c") {
+		t.Fatalf("expected output in host order, got %q", output)
+	}
+	if classes := taskErrorClasses(err); len(classes) != 1 || classes[0] != models.TaskErrorUnavailable {
+		t.Fatalf("expected error class of failed host, got %v", classes)
+	}
+	logs := hostLogs()
+	if len(logs) != 3 {
+		t.Fatalf("expected a host log per host, got %+v", logs)
+	}
+	for _, hostLog := range logs {
+		if hostLog.attempt != 2 {
+			t.Fatalf("expected attempt to be recorded, got %+v", hostLog)
+		}
+		if (hostLog.host == "b") != (hostLog.err != nil) {
+			t.Fatalf("unexpected host log %+v", hostLog)
+		}
+		if hostLog.host == "a" && hostLog.output != "done on a" {
+			t.Fatalf("expected raw output in host log, got %q", hostLog.output)
+		}
+	}
+
+	for _, rule := range []models.TaskHostSuccessRule{models.TaskHostSuccessAny, models.TaskHostSuccessQuorum} {
+		task.HostSuccessRule = rule
+		if _, err = handler.Run(task, 2); err != nil {
+			t.Fatalf("expected rule %d to succeed, got %v", rule, err)
+		}
+	}
+}
+
+func TestHostsResultError(t *testing.T) {
+	failed := TaskResult{Err: newTaskError(errors.New("exit status 1"), models.TaskErrorExit)}
+	succeeded := TaskResult{}
+	cases := []struct {
+		rule    models.TaskHostSuccessRule
+		results []TaskResult
+		total   int
+		success bool
+	}{
+		{models.TaskHostSuccessAll, []TaskResult{succeeded, succeeded}, 2, true},
+		{models.TaskHostSuccessAll, []TaskResult{succeeded, failed}, 2, false},
+		{models.TaskHostSuccessAny, []TaskResult{failed, succeeded}, 2, true},
+		{models.TaskHostSuccessAny, []TaskResult{failed, failed}, 2, false},
+		{models.TaskHostSuccessQuorum, []TaskResult{succeeded, succeeded, failed}, 3, true},
+		{models.TaskHostSuccessQuorum, []TaskResult{succeeded, failed}, 2, false},
+		// 未执行的主机视为未成功
+		{models.TaskHostSuccessQuorum, []TaskResult{succeeded, succeeded}, 5, false},
+	}
+	for _, c := range cases {
+		err := hostsResultError(models.Task{HostSuccessRule: c.rule}, c.results, c.total)
+		if (err == nil) != c.success {
+			t.Fatalf("rule %d with %d results of %d hosts: expected success=%v, got %v",
+				c.rule, len(c.results), c.total, c.success, err)
+		}
+	}
+	// 只有一台主机时保留原始错误
+	if err := hostsResultError(models.Task{}, []TaskResult{failed}, 1); err != failed.Err {
+		t.Fatalf("expected original error for single host, got %v", err)
+	}
+}
+
+func TestTaskLogHostname(t *testing.T) {
+	if hostname := taskLogHostname(testHosts("web-1", "web-2")); hostname != "web-1, web-2" {
+		t.Fatalf("unexpected hostname %q", hostname)
+	}
+	names := make([]string, 30)
+	for i := range names {
+		names[i] = fmt.Sprintf("web-%02d", i+1)
+	}
+	if hostname := taskLogHostname(testHosts(names...)); hostname != "web-01等30台主机" {
+		t.Fatalf("expected summary for too many hosts, got %q", hostname)
+	}
+	if hostname := taskLogHostname(nil); hostname != "" {
+		t.Fatalf("expected empty hostname, got %q", hostname)
+	}
+}
+
+func TestHostLogStatus(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected models.Status
+	}{
+		{newTaskError(rpcClient.ErrCanceled, classifyRPCError(rpcClient.ErrCanceled)), models.Cancel},
+		{newTaskError(sshclient.ErrCanceled, classifySSHError(sshclient.ErrCanceled)), models.Cancel},
+		{newTaskError(sshclient.ErrTimeout, classifySSHError(sshclient.ErrTimeout)), models.Failure},
+	}
+	for i, test := range tests {
+		if status := hostLogStatus(test.err); status != test.expected {
+			t.Errorf("case %d: expected status %d, got %d", i, test.expected, status)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	rpcClient "github.com/gocronx-team/gocron/internal/modules/rpc/client"
+	"github.com/gocronx-team/gocron/internal/modules/sshclient"
+)
+
+// RPC任务执行主机选择
+// 使用标签选择器的任务每次执行时重新匹配主机, 新注册的主机标签匹配时自动参与执行
+// 除所有主机执行外, 每次执行只在一台主机上运行, 选中的主机写入任务日志
+// 故障转移按主机顺序执行, 主机不可用时在同一次执行中使用下一台主机
+// 单台主机执行的策略在占用并发名额前选定主机, 按主机限制并发时只占用选中主机的名额
+
+var (
+	hostSelect = &hostSelector{
+		roundRobin: make(map[int]int),
+		lastUsed:   make(map[hostUseKey]time.Time),
+	}
+
+	// 随机数函数, 测试时可替换
+	randIntnFunc          = rand.Intn
+	updateTaskLogHostFunc = updateTaskLogHost
+	selectorHostsFunc     = new(models.TaskHost).GetHostsBySelector
+)
+
+type hostUseKey struct {
+	taskId int
+	hostId int16
+}
+
+type hostSelector struct {
+	mu sync.Mutex
+	// 任务ID => 下次轮询的位置
+	roundRobin map[int]int
+	// 主机最近一次被选中执行的时间
+	lastUsed map[hostUseKey]time.Time
+}
+
+// 使用标签选择器的任务按选择器匹配当前的主机, 匹配失败时使用已有的主机
+func resolveSelectorHosts(taskModel models.Task) models.Task {
+	if !taskModel.Protocol.OnHosts() || taskModel.HostSelector == "" {
+		return taskModel
+	}
+	hosts, err := selectorHostsFunc(taskModel.Id, taskModel.HostSelector)
+	if err != nil {
+		logger.Errorf("按标签选择器获取主机失败#任务ID-%d#选择器-%s#%s", taskModel.Id, taskModel.HostSelector, err)
+		return taskModel
+	}
+	taskModel.Hosts = hosts
+
+	return taskModel
+}
+
+// 单台主机执行的策略选定本次执行的主机, 故障转移及所有主机执行时保持不变
+func selectRunHosts(taskModel models.Task) models.Task {
+	if !taskModel.Protocol.OnHosts() || len(taskModel.Hosts) <= 1 {
+		return taskModel
+	}
+	switch taskModel.HostStrategy {
+	case models.TaskHostRandom, models.TaskHostRoundRobin, models.TaskHostLeastRecent:
+		taskModel.Hosts = hostSelect.candidates(taskModel)
+	}
+
+	return taskModel
+}
+
+// 按策略返回候选主机, 故障转移返回按顺序排列的所有主机, 其他策略返回一台主机
+// 只有一台主机时直接返回, 已选定主机的执行不再重复选择
+func (s *hostSelector) candidates(taskModel models.Task) []models.TaskHostDetail {
+	hosts := taskModel.Hosts
+	if len(hosts) <= 1 {
+		return hosts
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var selected models.TaskHostDetail
+	switch taskModel.HostStrategy {
+	case models.TaskHostFailover:
+		return hosts
+	case models.TaskHostRandom:
+		selected = hosts[randIntnFunc(len(hosts))]
+	case models.TaskHostRoundRobin:
+		index := s.roundRobin[taskModel.Id] % len(hosts)
+		s.roundRobin[taskModel.Id] = index + 1
+		selected = hosts[index]
+	case models.TaskHostLeastRecent:
+		// 从未执行过的主机优先, 时间相同时按主机顺序
+		selected = hosts[0]
+		oldest := s.lastUsed[hostUseKey{taskModel.Id, selected.HostId}]
+		for _, host := range hosts[1:] {
+			usedAt := s.lastUsed[hostUseKey{taskModel.Id, host.HostId}]
+			if usedAt.Before(oldest) {
+				selected, oldest = host, usedAt
+			}
+		}
+	default:
+		return hosts
+	}
+	s.lastUsed[hostUseKey{taskModel.Id, selected.HostId}] = time.Now()
+
+	return []models.TaskHostDetail{selected}
+}
+
+// 在选中的主机上执行, 故障转移时主机不可用则使用下一台
+func runOnSelectedHost(taskModel models.Task, taskUniqueId int64) (string, error) {
+	candidates := hostSelect.candidates(taskModel)
+	output := ""
+	var taskResult TaskResult
+	for i, host := range candidates {
+		updateTaskLogHostFunc(taskUniqueId, host)
+		taskResult = execOnHost(taskModel, taskUniqueId, host)
+		if taskModel.HostStrategy != models.TaskHostFailover || i == len(candidates)-1 ||
+			!isHostUnavailable(taskResult.Err) {
+			output += taskResult.Result
+			break
+		}
+		logger.Warnf("主机不可用, 使用下一台主机执行#任务ID-%d#主机-%s", taskModel.Id, host.Name)
+		output += hostResultLine(host, taskResult) + ", 使用下一台主机\n"
+	}
+
+	return output, taskResult.Err
+}
+
+// 任务日志记录实际执行的主机
+func updateTaskLogHost(taskLogId int64, host models.TaskHostDetail) {
+	taskLogModel := new(models.TaskLog)
+	_, err := taskLogModel.Update(taskLogId, models.CommonMap{
+		"hostname": taskLogHostname([]models.TaskHostDetail{host}),
+	})
+	if err != nil {
+		logger.Errorf("更新任务日志执行主机失败#日志ID-%d#%s", taskLogId, err)
+	}
+}
+
+// RPC、SSH无法连接主机
+func isHostUnavailable(err error) bool {
+	return errors.Is(err, rpcClient.ErrUnavailable) || errors.Is(err, sshclient.ErrUnavailable)
+}
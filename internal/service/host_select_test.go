@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	rpcClient "github.com/gocronx-team/gocron/internal/modules/rpc/client"
+	pb "github.com/gocronx-team/gocron/internal/modules/rpc/proto"
+	"github.com/gocronx-team/gocron/internal/modules/sshclient"
+)
+
+func testHosts(names ...string) []models.TaskHostDetail {
+	hosts := make([]models.TaskHostDetail, len(names))
+	for i, name := range names {
+		hosts[i].HostId = int16(i + 1)
+		hosts[i].Name = name
+		hosts[i].Alias = name
+		hosts[i].Port = 5921
+	}
+	return hosts
+}
+
+func newTestHostSelector() *hostSelector {
+	return &hostSelector{roundRobin: make(map[int]int), lastUsed: make(map[hostUseKey]time.Time)}
+}
+
+func TestHostSelectorCandidates(t *testing.T) {
+	selector := newTestHostSelector()
+	task := models.Task{Id: 1, Hosts: testHosts("a", "b", "c")}
+
+	task.HostStrategy = models.TaskHostRoundRobin
+	picked := make([]string, 0)
+	for i := 0; i < 4; i++ {
+		picked = append(picked, selector.candidates(task)[0].Name)
+	}
+	if strings.Join(picked, "") != "abca" {
+		t.Fatalf("unexpected round robin order %v", picked)
+	}
+
+	// 最久未执行: a刚被选中, b和c中b更早
+	task.HostStrategy = models.TaskHostLeastRecent
+	if name := selector.candidates(task)[0].Name; name != "b" {
+		t.Fatalf("expected least recently used host b, got %s", name)
+	}
+	other := models.Task{Id: 2, Hosts: task.Hosts, HostStrategy: models.TaskHostLeastRecent}
+	if name := selector.candidates(other)[0].Name; name != "a" {
+		t.Fatalf("expected unused host a for another task, got %s", name)
+	}
+
+	originalRandIntn := randIntnFunc
+	defer func() { randIntnFunc = originalRandIntn }()
+	randIntnFunc = func(n int) int { return n - 1 }
+	task.HostStrategy = models.TaskHostRandom
+	if hosts := selector.candidates(task); len(hosts) != 1 || hosts[0].Name != "c" {
+		t.Fatalf("unexpected random host %+v", hosts)
+	}
+
+	for _, strategy := range []models.TaskHostStrategy{models.TaskHostFailover, models.TaskHostAll} {
+		task.HostStrategy = strategy
+		if hosts := selector.candidates(task); len(hosts) != 3 {
+			t.Fatalf("expected all hosts for strategy %d, got %+v", strategy, hosts)
+		}
+	}
+}
+
+func TestRunOnSelectedHostFailover(t *testing.T) {
+	stubHostLogs(t)
+	originalExec, originalUpdate, originalSelect := rpcExecFunc, updateTaskLogHostFunc, hostSelect
+	defer func() {
+		rpcExecFunc, updateTaskLogHostFunc, hostSelect = originalExec, originalUpdate, originalSelect
+	}()
+	hostSelect = newTestHostSelector()
+	executed := make([]string, 0)
+	rpcExecFunc = func(ip string, port int, taskReq *pb.TaskRequest) (string, error) {
+		executed = append(executed, ip)
+		switch ip {
+		case "a":
+			return "", rpcClient.ErrUnavailable
+		case "b":
+			return "", errors.New("exit status 1")
+		}
+		return "ok", nil
+	}
+	recorded := make([]string, 0)
+	updateTaskLogHostFunc = func(taskLogId int64, host models.TaskHostDetail) {
+		recorded = append(recorded, host.Name)
+	}
+
+	task := models.Task{Id: 1, Hosts: testHosts("a", "b", "c"), HostStrategy: models.TaskHostFailover}
+	output, err := runOnSelectedHost(task, 1)
+	// 只有主机不可用时转移, 命令执行失败不再使用下一台
+	if err == nil || strings.Join(executed, "") != "ab" || strings.Join(recorded, "") != "ab" {
+		t.Fatalf("unexpected failover executed=%v recorded=%v err=%v", executed, recorded, err)
+	}
+	if !strings.HasPrefix(output, "[a] 执行失败") || !strings.HasSuffix(output, "exit status 1\n") {
+		t.Fatalf("expected output of both hosts, got %q", output)
+	}
+
+	executed, recorded = executed[:0], recorded[:0]
+	task.HostStrategy = models.TaskHostRoundRobin
+	if _, err = runOnSelectedHost(task, 2); !errors.Is(err, rpcClient.ErrUnavailable) || len(executed) != 1 {
+		t.Fatalf("expected single host run without failover, executed=%v err=%v", executed, err)
+	}
+	if len(recorded) != 1 || recorded[0] != "a" {
+		t.Fatalf("expected selected host to be recorded, got %v", recorded)
+	}
+
+	// SSH任务无法连接主机时同样转移
+	originalSSH, originalHost := sshExecFunc, sshHostFunc
+	defer func() { sshExecFunc, sshHostFunc = originalSSH, originalHost }()
+	sshHostFunc = func(id int16) (models.Host, error) {
+		return models.Host{Id: id, Name: string(rune('a' + id - 1)), SshPort: 22}, nil
+	}
+	sshExecFunc = func(cfg sshclient.Config, taskReq *pb.TaskRequest) (string, error) {
+		executed = append(executed, cfg.Host)
+		if cfg.Host == "a" {
+			return "", fmt.Errorf("%w: dial timeout", sshclient.ErrUnavailable)
+		}
+		return "ok", nil
+	}
+	executed, recorded = executed[:0], recorded[:0]
+	task.Protocol, task.HostStrategy = models.TaskSSH, models.TaskHostFailover
+	if output, err = runOnSelectedHost(task, 3); err != nil || strings.Join(executed, "") != "ab" || !strings.HasSuffix(output, "ok") {
+		t.Fatalf("expected ssh failover, executed=%v output=%q err=%v", executed, output, err)
+	}
+}
+
+func TestResolveSelectorHosts(t *testing.T) {
+	original := selectorHostsFunc
+	defer func() { selectorHostsFunc = original }()
+	selectorHostsFunc = func(taskId int, selector string) ([]models.TaskHostDetail, error) {
+		if selector != "role=db" {
+			return nil, errors.New("invalid selector")
+		}
+		return testHosts("db-1", "db-2"), nil
+	}
+
+	task := models.Task{Id: 1, Protocol: models.TaskRPC, HostSelector: "role=db", Hosts: testHosts("old")}
+	if hosts := resolveSelectorHosts(task).Hosts; len(hosts) != 2 || hosts[0].Name != "db-1" {
+		t.Fatalf("expected selector hosts, got %+v", hosts)
+	}
+	// 匹配失败时保留已有的主机
+	task.HostSelector = "role"
+	if hosts := resolveSelectorHosts(task).Hosts; len(hosts) != 1 || hosts[0].Name != "old" {
+		t.Fatalf("expected existing hosts on error, got %+v", hosts)
+	}
+	task.HostSelector = ""
+	if hosts := resolveSelectorHosts(task).Hosts; len(hosts) != 1 {
+		t.Fatalf("expected explicit hosts to be kept, got %+v", hosts)
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/app"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+)
+
+// 异步HTTP任务
+// 发送请求前为本次执行生成回调令牌, 通过请求头及命令模板变量传给远程系统
+// 请求成功后任务保持执行中, 远程系统POST回调地址后按回调的状态、输出结束执行
+// 超过等待时间未回调时执行失败, 服务重启后等待中的执行标记为中断
+// 高可用模式下回调可能由其他节点接收, 执行任务的节点定时查询任务日志获取回调结果
+
+const (
+	HttpCallbackUrlHeader   = "X-Gocron-Callback-Url"
+	HttpCallbackTokenHeader = "X-Gocron-Callback-Token"
+	// 回调地址, 令牌前为配置的对外访问地址
+	HttpCallbackPath = "/api/callback/"
+)
+
+var (
+	waitCallbackFunc    = new(models.TaskLog).WaitCallback
+	finishCallbackFunc  = new(models.TaskLog).FinishCallback
+	expireCallbackFunc  = new(models.TaskLog).ExpireCallback
+	callbackLogFunc     = new(models.TaskLog).Detail
+	callbackBaseUrlFunc = func() string {
+		if app.Setting == nil {
+			return ""
+		}
+		return app.Setting.CallbackUrl
+	}
+	// 查询任务日志获取回调结果的间隔
+	callbackPollInterval = 2 * time.Second
+)
+
+// 本节点等待中的回调, 收到回调后立即结束等待, 令牌 => chan struct{}
+var callbackWaiters sync.Map
+
+type httpCallback struct {
+	taskLogId int64
+	Token     string
+	Url       string // 未配置对外访问地址时为空
+	notify    chan struct{}
+}
+
+// 生成回调令牌并保存到任务日志, 结束等待后需调用close
+func newHttpCallback(taskLogId int64) (*httpCallback, error) {
+	if taskLogId <= 0 {
+		return nil, errors.New("任务日志写入失败, 无法等待回调")
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	callback := &httpCallback{
+		taskLogId: taskLogId,
+		Token:     hex.EncodeToString(b),
+		notify:    make(chan struct{}, 1),
+	}
+	if baseUrl := callbackBaseUrlFunc(); baseUrl != "" {
+		callback.Url = baseUrl + HttpCallbackPath + callback.Token
+	}
+	// 远程系统可能在响应请求前就回调, 发送请求前保存令牌
+	if err := waitCallbackFunc(taskLogId, callback.Token); err != nil {
+		return nil, fmt.Errorf("保存回调令牌失败: %s", err)
+	}
+	callbackWaiters.Store(callback.Token, callback.notify)
+
+	return callback, nil
+}
+
+func (callback *httpCallback) close() {
+	callbackWaiters.Delete(callback.Token)
+}
+
+// 等待回调, deadline为最长等待时间(秒)
+func (callback *httpCallback) wait(deadline int) (string, error) {
+	if deadline <= 0 {
+		deadline = models.TaskHttpDefaultDeadline
+	}
+	timeoutErr := newTaskError(fmt.Errorf("超过%d秒未收到回调", deadline), models.TaskErrorTimeout)
+	timer := time.NewTimer(time.Duration(deadline) * time.Second)
+	defer timer.Stop()
+	ticker := time.NewTicker(callbackPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-callback.notify:
+		case <-ticker.C:
+		case <-timer.C:
+			expired, err := expireCallbackFunc(callback.taskLogId, callback.Token)
+			if err != nil {
+				return "", newTaskError(fmt.Errorf("更新回调状态失败: %s", err), models.TaskErrorOther)
+			}
+			if expired {
+				return "", timeoutErr
+			}
+			// 到达等待时间前已收到回调
+			if output, done, err := callback.result(); done {
+				return output, err
+			}
+			return "", timeoutErr
+		}
+		if output, done, err := callback.result(); done {
+			return output, err
+		}
+	}
+}
+
+// 查询回调结果, done为false时继续等待
+func (callback *httpCallback) result() (output string, done bool, err error) {
+	taskLog, err := callbackLogFunc(callback.taskLogId)
+	if err != nil {
+		logger.Warnf("异步HTTP任务#查询回调结果失败#taskLogId-%d#%s", callback.taskLogId, err)
+		return "", false, nil
+	}
+	// 执行已被中断、取消或替换
+	if taskLog.Status != models.Running || taskLog.CallbackToken != callback.Token {
+		return "", true, errors.New("执行已结束, 不再等待回调")
+	}
+	switch taskLog.Callback {
+	case models.TaskLogCallbackSuccess:
+		return taskLog.Result, true, nil
+	case models.TaskLogCallbackFailure:
+		return taskLog.Result, true, newTaskError(errors.New("远程系统回调执行失败"), models.TaskErrorExit)
+	case models.TaskLogCallbackExpired:
+		return "", true, newTaskError(errors.New("超过等待时间未收到回调"), models.TaskErrorTimeout)
+	}
+
+	return "", false, nil
+}
+
+// Callback 异步HTTP任务的回调, 返回令牌对应的执行是否仍在等待回调
+func (task Task) Callback(token string, success bool, output string) (bool, error) {
+	state := models.TaskLogCallbackSuccess
+	if !success {
+		state = models.TaskLogCallbackFailure
+	}
+	updated, err := finishCallbackFunc(token, state, output)
+	if err != nil || !updated {
+		return false, err
+	}
+	if notify, ok := callbackWaiters.Load(token); ok {
+		select {
+		case notify.(chan struct{}) <- struct{}{}:
+		default:
+		}
+	}
+
+	return true, nil
+}
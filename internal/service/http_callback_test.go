@@ -0,0 +1,186 @@
+package service
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+)
+
+// 内存中的任务日志, 替代数据库
+type fakeCallbackLogs struct {
+	mu   sync.Mutex
+	logs map[int64]*models.TaskLog
+}
+
+func stubCallbackLogs(t *testing.T, baseUrl string) *fakeCallbackLogs {
+	store := &fakeCallbackLogs{logs: make(map[int64]*models.TaskLog)}
+	originalWait, originalFinish, originalExpire := waitCallbackFunc, finishCallbackFunc, expireCallbackFunc
+	originalLog, originalBaseUrl, originalInterval := callbackLogFunc, callbackBaseUrlFunc, callbackPollInterval
+	t.Cleanup(func() {
+		waitCallbackFunc, finishCallbackFunc, expireCallbackFunc = originalWait, originalFinish, originalExpire
+		callbackLogFunc, callbackBaseUrlFunc, callbackPollInterval = originalLog, originalBaseUrl, originalInterval
+	})
+	callbackPollInterval = 10 * time.Millisecond
+	callbackBaseUrlFunc = func() string { return baseUrl }
+	waitCallbackFunc = func(id int64, token string) error {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		taskLog, ok := store.logs[id]
+		if !ok {
+			taskLog = &models.TaskLog{Id: id, Status: models.Running}
+			store.logs[id] = taskLog
+		}
+		taskLog.CallbackToken, taskLog.Callback = token, models.TaskLogCallbackPending
+		return nil
+	}
+	finishCallbackFunc = func(token string, callback models.TaskLogCallback, result string) (bool, error) {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		for _, taskLog := range store.logs {
+			if token != "" && taskLog.CallbackToken == token && taskLog.Status == models.Running &&
+				taskLog.Callback == models.TaskLogCallbackPending {
+				taskLog.Callback, taskLog.Result = callback, result
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	expireCallbackFunc = func(id int64, token string) (bool, error) {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		taskLog := store.logs[id]
+		if taskLog.CallbackToken != token || taskLog.Callback != models.TaskLogCallbackPending {
+			return false, nil
+		}
+		taskLog.Callback = models.TaskLogCallbackExpired
+		return true, nil
+	}
+	callbackLogFunc = func(id int64) (models.TaskLog, error) {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return *store.logs[id], nil
+	}
+
+	return store
+}
+
+func (store *fakeCallbackLogs) setStatus(id int64, status models.Status) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.logs[id].Status = status
+}
+
+func asyncHttpTask(deadline int) models.Task {
+	return models.Task{
+		Id:         1,
+		Command:    "http://example.com/jobs?token=${{ .CallbackToken }}",
+		HttpMethod: models.TaskHttpMethodPost,
+		Http: &models.TaskHttp{
+			BodyType: models.TaskHttpBodyJson,
+			Body:     `{"callback":"${{ .CallbackUrl }}"}`,
+			AuthType: models.TaskHttpAuthNone,
+			Async:    1,
+			Deadline: deadline,
+		},
+	}
+}
+
+func TestHTTPHandlerRunAsyncCallback(t *testing.T) {
+	stubCallbackLogs(t, "https://gocron.example.com")
+	original := httpDoFunc
+	defer func() { httpDoFunc = original }()
+
+	var capturedUrl, capturedBody string
+	var capturedHeader http.Header
+	success := true
+	httpDoFunc = func(method, url string, header http.Header, body string, timeout int, opts *httpclient.Options) httpclient.ResponseWrapper {
+		capturedUrl, capturedHeader, capturedBody = url, header, body
+		// 远程系统在响应请求前回调
+		token := header.Get(HttpCallbackTokenHeader)
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			_, _ = ServiceTask.Callback(token, success, "processed 10 rows")
+		}()
+		return httpclient.ResponseWrapper{StatusCode: http.StatusAccepted, Body: "accepted"}
+	}
+
+	handler := &HTTPHandler{}
+	result, err := handler.Run(asyncHttpTask(60), 1)
+	if err != nil || result != "processed 10 rows" {
+		t.Fatalf("unexpected result %q err=%v", result, err)
+	}
+	token := capturedHeader.Get(HttpCallbackTokenHeader)
+	callbackUrl := "https://gocron.example.com" + HttpCallbackPath + token
+	if len(token) != 64 || capturedHeader.Get(HttpCallbackUrlHeader) != callbackUrl {
+		t.Fatalf("unexpected callback headers %v", capturedHeader)
+	}
+	if capturedUrl != "http://example.com/jobs?token="+token || capturedBody != `{"callback":"`+callbackUrl+`"}` {
+		t.Fatalf("unexpected request url=%s body=%s", capturedUrl, capturedBody)
+	}
+	if _, ok := callbackWaiters.Load(token); ok {
+		t.Fatal("expected waiter to be removed after callback")
+	}
+	// 已结束等待的令牌不能再次回调
+	if updated, _ := ServiceTask.Callback(token, true, "again"); updated {
+		t.Fatal("expected repeated callback to be ignored")
+	}
+
+	success = false
+	result, err = handler.Run(asyncHttpTask(60), 2)
+	if err == nil || result != "processed 10 rows" || taskErrorClasses(err)[0] != models.TaskErrorExit {
+		t.Fatalf("expected callback failure, got %q err=%v", result, err)
+	}
+}
+
+func TestHTTPHandlerRunAsyncDeadline(t *testing.T) {
+	store := stubCallbackLogs(t, "")
+	original := httpDoFunc
+	defer func() { httpDoFunc = original }()
+
+	var capturedHeader http.Header
+	httpDoFunc = func(method, url string, header http.Header, body string, timeout int, opts *httpclient.Options) httpclient.ResponseWrapper {
+		capturedHeader = header
+		return httpclient.ResponseWrapper{StatusCode: http.StatusAccepted}
+	}
+
+	handler := &HTTPHandler{}
+	_, err := handler.Run(asyncHttpTask(1), 1)
+	if err == nil || taskErrorClasses(err)[0] != models.TaskErrorTimeout {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	// 未配置对外访问地址时只发送令牌
+	if capturedHeader.Get(HttpCallbackUrlHeader) != "" || capturedHeader.Get(HttpCallbackTokenHeader) == "" {
+		t.Fatalf("unexpected callback headers %v", capturedHeader)
+	}
+	if updated, _ := ServiceTask.Callback(capturedHeader.Get(HttpCallbackTokenHeader), true, "late"); updated {
+		t.Fatal("expected callback after deadline to be ignored")
+	}
+
+	// 请求失败时不等待回调
+	httpDoFunc = func(method, url string, header http.Header, body string, timeout int, opts *httpclient.Options) httpclient.ResponseWrapper {
+		return httpclient.ResponseWrapper{StatusCode: http.StatusInternalServerError}
+	}
+	if _, err = handler.Run(asyncHttpTask(60), 2); err == nil || taskErrorClasses(err)[0] != models.TaskErrorHTTP5xx {
+		t.Fatalf("expected 5xx error, got %v", err)
+	}
+
+	// 执行被中断后不再等待回调
+	httpDoFunc = func(method, url string, header http.Header, body string, timeout int, opts *httpclient.Options) httpclient.ResponseWrapper {
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			store.setStatus(3, models.Interrupted)
+		}()
+		return httpclient.ResponseWrapper{StatusCode: http.StatusAccepted}
+	}
+	if _, err = handler.Run(asyncHttpTask(60), 3); err == nil {
+		t.Fatal("expected error for interrupted run")
+	}
+
+	if _, err = handler.Run(asyncHttpTask(60), 0); err == nil {
+		t.Fatal("expected error without task log")
+	}
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+)
+
+// HTTP任务按请求定义执行
+// 请求地址、请求体支持命令模板, 未设置超时时间时最长执行HttpExecTimeout秒
+// 响应状态码不在允许的状态码中, 或响应内容断言失败时任务执行失败
+// 引用了HTTP客户端配置的任务按配置的证书、代理、重定向策略发送请求
+// 异步任务请求成功后等待远程系统回调, 见http_callback.go
+
+var (
+	httpDoFunc      = httpclient.Do
+	httpProfileFunc = new(models.HttpProfile).Detail
+)
+
+var httpMethodNames = map[models.TaskHTTPMethod]string{
+	models.TaskHTTPMethodGet:    http.MethodGet,
+	models.TaskHttpMethodPost:   http.MethodPost,
+	models.TaskHttpMethodPut:    http.MethodPut,
+	models.TaskHttpMethodPatch:  http.MethodPatch,
+	models.TaskHttpMethodDelete: http.MethodDelete,
+}
+
+var jsonPathIndexPattern = regexp.MustCompile(`\[(\d+)\]`)
+
+// ValidateHttpRequest 保存任务时校验请求定义
+func ValidateHttpRequest(def models.TaskHttp) error {
+	if _, err := models.ParseHttpHeaders(def.Headers); err != nil {
+		return err
+	}
+	if _, err := models.ParseHttpStatusCodes(def.StatusCodes); err != nil {
+		return err
+	}
+	if def.AssertRegex != "" {
+		if _, err := regexp.Compile(def.AssertRegex); err != nil {
+			return fmt.Errorf("断言正则错误: %s", err)
+		}
+	}
+	if err := ValidateCommandTemplate(def.Body); err != nil {
+		return err
+	}
+	if def.BodyType == models.TaskHttpBodyJson && !isCommandTemplate(def.Body) && !json.Valid([]byte(def.Body)) {
+		return errors.New("请求体不是有效的JSON")
+	}
+	if def.AuthType == models.TaskHttpAuthBasic && def.AuthUser == "" {
+		return errors.New("basic认证用户名不能为空")
+	}
+	if def.AuthType == models.TaskHttpAuthBearer && def.AuthSecret == "" {
+		return errors.New("bearer认证令牌不能为空")
+	}
+
+	return nil
+}
+
+func runHttpRequest(taskModel models.Task, taskUniqueId int64) (string, error) {
+	def := taskModel.Http
+	if def == nil {
+		def = &models.TaskHttp{BodyType: models.TaskHttpBodyNone, AuthType: models.TaskHttpAuthNone}
+	}
+	timeout := taskModel.Timeout
+	if timeout <= 0 {
+		timeout = HttpExecTimeout
+	}
+	var callback *httpCallback
+	if def.Async == 1 {
+		var err error
+		if callback, err = newHttpCallback(taskUniqueId); err != nil {
+			return "", newTaskError(err, models.TaskErrorOther)
+		}
+		defer callback.close()
+		taskModel.RunCallbackUrl = callback.Url
+		taskModel.RunCallbackToken = callback.Token
+	}
+	url, err := renderCommand(taskModel, taskUniqueId, nil)
+	if err != nil {
+		return "", err
+	}
+	body := ""
+	if def.BodyType != models.TaskHttpBodyNone && def.Body != "" {
+		bodyTask := taskModel
+		bodyTask.Command = def.Body
+		if body, err = renderCommand(bodyTask, taskUniqueId, nil); err != nil {
+			return "", err
+		}
+	}
+	header, err := buildHttpHeader(def)
+	if err != nil {
+		return "", err
+	}
+	codes, err := models.ParseHttpStatusCodes(def.StatusCodes)
+	if err != nil {
+		return "", err
+	}
+	opts, err := profileClientOptions(def.ProfileId)
+	if err != nil {
+		return "", err
+	}
+	if callback != nil {
+		if callback.Url != "" {
+			header.Set(HttpCallbackUrlHeader, callback.Url)
+		}
+		header.Set(HttpCallbackTokenHeader, callback.Token)
+	}
+	method, ok := httpMethodNames[taskModel.HttpMethod]
+	if !ok {
+		method = http.MethodGet
+	}
+
+	resp := httpDoFunc(method, url, header, body, timeout, opts)
+	if resp.StatusCode == 0 {
+		return resp.Body, newTaskError(fmt.Errorf("HTTP请求失败-->%s", resp.Body),
+			classifyHTTPError(resp.StatusCode, resp.Err))
+	}
+	if !codes.Contains(resp.StatusCode) {
+		return resp.Body, newTaskError(fmt.Errorf("HTTP状态码%d不在允许的状态码[%s]中", resp.StatusCode, statusCodesText(def)),
+			classifyHTTPError(resp.StatusCode, resp.Err))
+	}
+	if err = assertHttpResponse(def, resp.Body); err != nil {
+		return resp.Body, newTaskError(err, models.TaskErrorAssert)
+	}
+	if callback != nil {
+		logger.Infof("异步HTTP任务#等待回调#任务ID-%d#taskLogId-%d", taskModel.Id, taskUniqueId)
+		return callback.wait(def.Deadline)
+	}
+
+	return resp.Body, nil
+}
+
+// 任务引用的HTTP客户端配置, 未引用时使用默认配置
+func profileClientOptions(profileId int) (*httpclient.Options, error) {
+	if profileId <= 0 {
+		return nil, nil
+	}
+	profile, err := httpProfileFunc(profileId)
+	if err != nil {
+		return nil, fmt.Errorf("获取HTTP客户端配置失败, ID-%d: %s", profileId, err)
+	}
+
+	return HttpClientOptions(profile), nil
+}
+
+// HttpClientOptions HTTP客户端配置对应的请求配置
+func HttpClientOptions(profile models.HttpProfile) *httpclient.Options {
+	return &httpclient.Options{
+		CaCert:       profile.CaCert,
+		ClientCert:   profile.ClientCert,
+		ClientKey:    profile.ClientKey,
+		SkipVerify:   profile.SkipVerify == 1,
+		ProxyUrl:     profile.ProxyUrl,
+		NoRedirect:   profile.Redirect == models.HttpRedirectNone,
+		MaxRedirects: int(profile.MaxRedirects),
+	}
+}
+
+// 请求头, 未设置Content-Type、Authorization时按请求体类型、认证方式设置
+func buildHttpHeader(def *models.TaskHttp) (http.Header, error) {
+	header, err := models.ParseHttpHeaders(def.Headers)
+	if err != nil {
+		return nil, err
+	}
+	if header.Get("Content-Type") == "" {
+		switch def.BodyType {
+		case models.TaskHttpBodyJson:
+			header.Set("Content-Type", "application/json")
+		case models.TaskHttpBodyForm:
+			header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if header.Get("Authorization") == "" {
+		switch def.AuthType {
+		case models.TaskHttpAuthBasic:
+			credential := base64.StdEncoding.EncodeToString([]byte(def.AuthUser + ":" + def.AuthSecret))
+			header.Set("Authorization", "Basic "+credential)
+		case models.TaskHttpAuthBearer:
+			header.Set("Authorization", "Bearer "+def.AuthSecret)
+		}
+	}
+
+	return header, nil
+}
+
+func statusCodesText(def *models.TaskHttp) string {
+	if def.StatusCodes == "" {
+		return models.TaskHttpDefaultStatusCodes
+	}
+	return def.StatusCodes
+}
+
+// 响应内容断言, 正则匹配、JSON字段等于指定值
+func assertHttpResponse(def *models.TaskHttp, body string) error {
+	if def.AssertRegex != "" {
+		pattern, err := regexp.Compile(def.AssertRegex)
+		if err != nil {
+			return fmt.Errorf("断言正则错误: %s", err)
+		}
+		if !pattern.MatchString(body) {
+			return fmt.Errorf("响应内容不匹配正则[%s]", def.AssertRegex)
+		}
+	}
+	if def.JsonPath != "" {
+		value, err := jsonPathValue(body, def.JsonPath)
+		if err != nil {
+			return err
+		}
+		if value != def.JsonValue {
+			return fmt.Errorf("响应JSON字段[%s]的值为%s, 期望%s", def.JsonPath, value, def.JsonValue)
+		}
+	}
+
+	return nil
+}
+
+// 按路径获取JSON字段的值, 路径格式为 data.items.0.status 或 $.data.items[0].status
+// 字符串返回原值, 其他类型返回JSON文本
+func jsonPathValue(body, path string) (string, error) {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var current interface{}
+	fieldPath := path
+	if err := decoder.Decode(&current); err != nil {
+		return "", fmt.Errorf("响应内容不是有效的JSON: %s", err)
+	}
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = jsonPathIndexPattern.ReplaceAllString(path, ".$1")
+	for _, key := range strings.Split(path, ".") {
+		if key == "" {
+			continue
+		}
+		found := false
+		switch node := current.(type) {
+		case map[string]interface{}:
+			current, found = node[key]
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err == nil && index >= 0 && index < len(node) {
+				current, found = node[index], true
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("响应JSON中不存在字段[%s]", fieldPath)
+		}
+	}
+	if text, ok := current.(string); ok {
+		return text, nil
+	}
+	value, err := json.Marshal(current)
+	if err != nil {
+		return "", err
+	}
+
+	return string(value), nil
+}
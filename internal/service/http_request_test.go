@@ -0,0 +1,187 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+)
+
+func TestHTTPHandlerRunWithRequestDefinition(t *testing.T) {
+	original := httpDoFunc
+	defer func() { httpDoFunc = original }()
+
+	var capturedMethod, capturedBody string
+	var capturedHeader http.Header
+	var capturedTimeout int
+	response := httpclient.ResponseWrapper{StatusCode: http.StatusAccepted, Body: `{"data":{"items":[{"status":"ok","count":2}]}}`}
+	httpDoFunc = func(method, url string, header http.Header, body string, timeout int, opts *httpclient.Options) httpclient.ResponseWrapper {
+		capturedMethod, capturedHeader, capturedBody, capturedTimeout = method, header, body, timeout
+		return response
+	}
+
+	task := models.Task{
+		Id:         1,
+		Command:    "http://example.com/jobs",
+		Timeout:    1000,
+		HttpMethod: models.TaskHttpMethodPut,
+		Http: &models.TaskHttp{
+			Headers:     "X-Source: gocron",
+			BodyType:    models.TaskHttpBodyJson,
+			Body:        `{"task":${{ .TaskId }}}`,
+			AuthType:    models.TaskHttpAuthBearer,
+			AuthSecret:  "secret",
+			StatusCodes: "200-299",
+			AssertRegex: `"status":"ok"`,
+			JsonPath:    "$.data.items[0].count",
+			JsonValue:   "2",
+		},
+	}
+	handler := &HTTPHandler{}
+	result, err := handler.Run(task, 1)
+	if err != nil || result != response.Body {
+		t.Fatalf("unexpected result %q err=%v", result, err)
+	}
+	if capturedMethod != http.MethodPut || capturedBody != `{"task":1}` || capturedTimeout != 1000 {
+		t.Fatalf("unexpected request method=%s body=%s timeout=%d", capturedMethod, capturedBody, capturedTimeout)
+	}
+	if capturedHeader.Get("Authorization") != "Bearer secret" || capturedHeader.Get("Content-Type") != "application/json" ||
+		capturedHeader.Get("X-Source") != "gocron" {
+		t.Fatalf("unexpected headers %v", capturedHeader)
+	}
+
+	// 断言失败
+	task.Http.JsonValue = "3"
+	if _, err = handler.Run(task, 1); err == nil || taskErrorClasses(err)[0] != models.TaskErrorAssert {
+		t.Fatalf("expected assert error, got %v", err)
+	}
+	task.Http.JsonValue = "2"
+	task.Http.AssertRegex = "failed"
+	if _, err = handler.Run(task, 1); err == nil || taskErrorClasses(err)[0] != models.TaskErrorAssert {
+		t.Fatalf("expected assert error, got %v", err)
+	}
+
+	// 状态码不在允许的状态码中
+	task.Http.StatusCodes = "200"
+	if _, err = handler.Run(task, 1); err == nil {
+		t.Fatal("expected error for status code not accepted")
+	}
+	response = httpclient.ResponseWrapper{StatusCode: http.StatusServiceUnavailable, Body: "busy"}
+	task.Http.StatusCodes = ""
+	if _, err = handler.Run(task, 1); err == nil || taskErrorClasses(err)[0] != models.TaskErrorHTTP5xx {
+		t.Fatalf("expected 5xx error, got %v", err)
+	}
+	response = httpclient.ResponseWrapper{Body: "执行HTTP请求错误-refused", Err: errors.New("refused")}
+	if _, err = handler.Run(task, 1); err == nil || taskErrorClasses(err)[0] != models.TaskErrorUnavailable {
+		t.Fatalf("expected unavailable error, got %v", err)
+	}
+}
+
+func TestBuildHttpHeader(t *testing.T) {
+	def := &models.TaskHttp{
+		BodyType: models.TaskHttpBodyForm,
+		AuthType: models.TaskHttpAuthBasic,
+		AuthUser: "admin",
+		Headers:  "Content-Type: text/plain",
+	}
+	header, err := buildHttpHeader(def)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 已设置的请求头优先
+	if header.Get("Content-Type") != "text/plain" || header.Get("Authorization") != "Basic YWRtaW46" {
+		t.Fatalf("unexpected headers %v", header)
+	}
+}
+
+func TestJsonPathValue(t *testing.T) {
+	body := `{"code":0,"ok":true,"data":{"name":"gocron","list":[1,{"id":12345678901234567890}],"empty":null}}`
+	cases := map[string]string{
+		"code":             "0",
+		"$.ok":             "true",
+		"data.name":        "gocron",
+		"data.list.0":      "1",
+		"data.list[1].id":  "12345678901234567890",
+		"$.data.empty":     "null",
+		"data.list":        `[1,{"id":12345678901234567890}]`,
+		"$":                `{"code":0,"data":{"empty":null,"list":[1,{"id":12345678901234567890}],"name":"gocron"},"ok":true}`,
+		"data.list[0]":     "1",
+		"$['data']":        "",
+		"data.missing":     "",
+		"data.list.2":      "",
+		"data.name.length": "",
+	}
+	for path, expected := range cases {
+		value, err := jsonPathValue(body, path)
+		if expected == "" {
+			if err == nil {
+				t.Fatalf("expected error for path %s, got %s", path, value)
+			}
+			continue
+		}
+		if err != nil || value != expected {
+			t.Fatalf("path %s expected %s, got %s err=%v", path, expected, value, err)
+		}
+	}
+	if _, err := jsonPathValue("not json", "a"); err == nil {
+		t.Fatal("expected error for invalid json")
+	}
+}
+
+func TestValidateHttpRequest(t *testing.T) {
+	valid := models.TaskHttp{BodyType: models.TaskHttpBodyJson, Body: `{"date":"${{ .ScheduledTime | date "YYYYMMDD" }}"}`}
+	if err := ValidateHttpRequest(valid); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	invalid := []models.TaskHttp{
+		{Headers: "bad header"},
+		{StatusCodes: "2xx"},
+		{AssertRegex: "("},
+		{BodyType: models.TaskHttpBodyJson, Body: "{"},
+		{AuthType: models.TaskHttpAuthBasic},
+		{AuthType: models.TaskHttpAuthBearer},
+	}
+	for _, def := range invalid {
+		if err := ValidateHttpRequest(def); err == nil {
+			t.Fatalf("expected error for %+v", def)
+		}
+	}
+}
+
+func TestHTTPHandlerRunWithProfile(t *testing.T) {
+	originalDo, originalProfile := httpDoFunc, httpProfileFunc
+	defer func() { httpDoFunc, httpProfileFunc = originalDo, originalProfile }()
+
+	var capturedOpts *httpclient.Options
+	httpDoFunc = func(method, url string, header http.Header, body string, timeout int, opts *httpclient.Options) httpclient.ResponseWrapper {
+		capturedOpts = opts
+		return httpclient.ResponseWrapper{StatusCode: http.StatusOK, Body: "ok"}
+	}
+	httpProfileFunc = func(id int) (models.HttpProfile, error) {
+		if id != 3 {
+			return models.HttpProfile{}, errors.New("record not found")
+		}
+		return models.HttpProfile{Id: 3, ProxyUrl: "http://proxy:3128", SkipVerify: 1, Redirect: models.HttpRedirectNone}, nil
+	}
+
+	handler := &HTTPHandler{}
+	task := models.Task{Command: "https://internal.example", Http: &models.TaskHttp{ProfileId: 3}}
+	if _, err := handler.Run(task, 1); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if capturedOpts == nil || capturedOpts.ProxyUrl != "http://proxy:3128" || !capturedOpts.SkipVerify || !capturedOpts.NoRedirect {
+		t.Fatalf("unexpected client options %+v", capturedOpts)
+	}
+
+	task.Http.ProfileId = 0
+	if _, err := handler.Run(task, 1); err != nil || capturedOpts != nil {
+		t.Fatalf("expected default client without profile, opts=%+v err=%v", capturedOpts, err)
+	}
+	task.Http.ProfileId = 4
+	capturedOpts = nil
+	if _, err := handler.Run(task, 1); err == nil {
+		t.Fatal("expected error for missing profile")
+	}
+}
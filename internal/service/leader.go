@@ -0,0 +1,202 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+)
+
+// 高可用调度
+// 多个实例连接同一数据库时, 通过租约选举唯一的调度节点, 只有调度节点把任务添加到定时器
+// 调度节点每隔 ttl/3 续约一次, 宕机后其他实例最长在 ttl + ttl/3 内接管
+// 非调度节点修改任务后递增租约中的版本号, 调度节点检测到变化后重新加载全部任务
+// 非调度节点收到的手动运行、回填请求写入调度请求表, 调度节点续约后取出执行
+// 租约过期时间使用数据库时间计算, 不受各实例时钟偏差影响
+
+// 版本号从0开始递增, 设置为该值时下次续约后强制重新加载任务
+const reloadRevision int64 = -1
+
+type schedulerLeaseStore interface {
+	TryAcquire(name, holder string, ttl time.Duration) (bool, error)
+	Release(name, holder string) error
+	GetRevision(name string) (int64, error)
+	BumpRevision(name string) error
+}
+
+type leaderElector struct {
+	store  schedulerLeaseStore
+	nodeId string
+	ttl    time.Duration
+
+	// 成为调度节点、失去调度节点身份、任务有变更时的回调, 在持有写锁时执行
+	// onElected、onChanged 返回错误时, 下次续约后重新执行onChanged
+	onElected func() error
+	onDemoted func()
+	onChanged func() error
+	// 调度节点每次续约后的回调, 在持有读锁时执行, 用于处理非调度节点写入的调度请求
+	onRenewed func()
+
+	mu        sync.RWMutex
+	leader    bool
+	lastRenew time.Time
+	revision  int64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newLeaderElector(store schedulerLeaseStore, nodeId string, ttl time.Duration) *leaderElector {
+	if nodeId == "" {
+		hostname, _ := os.Hostname()
+		nodeId = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	return &leaderElector{
+		store:     store,
+		nodeId:    nodeId,
+		ttl:       ttl,
+		onElected: func() error { return nil },
+		onDemoted: func() {},
+		onChanged: func() error { return nil },
+		onRenewed: func() {},
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// 是否为调度节点
+func (e *leaderElector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.leader
+}
+
+// 当前节点为调度节点时执行fn, 执行期间不会失去调度节点身份
+func (e *leaderElector) runIfLeader(fn func()) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if !e.leader {
+		return false
+	}
+	fn()
+
+	return true
+}
+
+// 通知调度节点任务有变更
+func (e *leaderElector) notifyChanged() {
+	err := e.store.BumpRevision(models.SchedulerLeaseName)
+	if err != nil {
+		logger.Errorf("高可用#通知调度节点任务变更失败#节点-%s#%s", e.nodeId, err)
+	}
+}
+
+func (e *leaderElector) run() {
+	logger.Infof("高可用#开始竞争调度节点#节点-%s#租约有效期-%s", e.nodeId, e.ttl)
+	e.tick()
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.tick()
+		case <-e.stop:
+			e.resign()
+			close(e.done)
+			return
+		}
+	}
+}
+
+// 续约或竞争租约
+func (e *leaderElector) tick() {
+	now := time.Now()
+	acquired, err := e.store.TryAcquire(models.SchedulerLeaseName, e.nodeId, e.ttl)
+	if err != nil {
+		logger.Errorf("高可用#续约调度租约失败#节点-%s#%s", e.nodeId, err)
+		// 无法确认租约时, 在租约过期前主动放弃调度, 避免多个节点同时调度
+		if e.IsLeader() && now.Sub(e.lastRenew) >= e.ttl*2/3 {
+			e.demote()
+		}
+		return
+	}
+	if !acquired {
+		if e.IsLeader() {
+			e.demote()
+		}
+		return
+	}
+	e.lastRenew = now
+	if !e.IsLeader() {
+		e.elect()
+	} else {
+		e.checkRevision()
+	}
+	e.runIfLeader(e.onRenewed)
+}
+
+func (e *leaderElector) elect() {
+	revision, err := e.store.GetRevision(models.SchedulerLeaseName)
+	if err != nil {
+		logger.Errorf("高可用#获取任务变更版本号失败#节点-%s#%s", e.nodeId, err)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.leader = true
+	e.revision = revision
+	logger.Infof("高可用#当前节点成为调度节点#节点-%s", e.nodeId)
+	if err := e.onElected(); err != nil {
+		logger.Errorf("高可用#加载任务失败, 等待下次重试#节点-%s#%s", e.nodeId, err)
+		e.revision = reloadRevision
+	}
+}
+
+func (e *leaderElector) demote() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.leader = false
+	logger.Warnf("高可用#当前节点失去调度节点身份#节点-%s", e.nodeId)
+	e.onDemoted()
+}
+
+func (e *leaderElector) checkRevision() {
+	revision, err := e.store.GetRevision(models.SchedulerLeaseName)
+	if err != nil {
+		logger.Errorf("高可用#获取任务变更版本号失败#节点-%s#%s", e.nodeId, err)
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if revision == e.revision {
+		return
+	}
+	logger.Infof("高可用#任务有变更, 重新加载#节点-%s#版本号-%d", e.nodeId, revision)
+	e.revision = revision
+	if err := e.onChanged(); err != nil {
+		logger.Errorf("高可用#重新加载任务失败, 等待下次重试#节点-%s#%s", e.nodeId, err)
+		e.revision = reloadRevision
+	}
+}
+
+// 主动释放租约, 其他实例可立即接管
+func (e *leaderElector) resign() {
+	if !e.IsLeader() {
+		return
+	}
+	e.demote()
+	err := e.store.Release(models.SchedulerLeaseName, e.nodeId)
+	if err != nil {
+		logger.Errorf("高可用#释放调度租约失败#节点-%s#%s", e.nodeId, err)
+	}
+}
+
+// 停止竞争并释放租约
+func (e *leaderElector) Stop() {
+	close(e.stop)
+	<-e.done
+}
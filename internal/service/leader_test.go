@@ -0,0 +1,225 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 内存实现的租约存储, 模拟多个节点竞争同一租约
+type fakeLeaseStore struct {
+	mu        sync.Mutex
+	holder    string
+	expiresAt time.Time
+	revision  int64
+	err       error
+}
+
+func (s *fakeLeaseStore) TryAcquire(name, holder string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return false, s.err
+	}
+	now := time.Now()
+	if s.holder != "" && s.holder != holder && now.Before(s.expiresAt) {
+		return false, nil
+	}
+	s.holder = holder
+	s.expiresAt = now.Add(ttl)
+
+	return true, nil
+}
+
+func (s *fakeLeaseStore) Release(name, holder string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.holder == holder {
+		s.holder = ""
+	}
+
+	return nil
+}
+
+func (s *fakeLeaseStore) GetRevision(name string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.revision, nil
+}
+
+func (s *fakeLeaseStore) BumpRevision(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revision++
+
+	return nil
+}
+
+func (s *fakeLeaseStore) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expiresAt = time.Now().Add(-time.Second)
+}
+
+type electorCalls struct {
+	elected, demoted, changed, renewed int
+}
+
+func newTestElector(store schedulerLeaseStore, nodeId string) (*leaderElector, *electorCalls) {
+	calls := &electorCalls{}
+	e := newLeaderElector(store, nodeId, 3*time.Second)
+	e.onElected = func() error { calls.elected++; return nil }
+	e.onDemoted = func() { calls.demoted++ }
+	e.onChanged = func() error { calls.changed++; return nil }
+	e.onRenewed = func() { calls.renewed++ }
+
+	return e, calls
+}
+
+func TestLeaderElectorOnlyOneLeader(t *testing.T) {
+	store := &fakeLeaseStore{}
+	a, aCalls := newTestElector(store, "a")
+	b, bCalls := newTestElector(store, "b")
+
+	a.tick()
+	b.tick()
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("expected only a to be leader, a=%v b=%v", a.IsLeader(), b.IsLeader())
+	}
+	if aCalls.elected != 1 || bCalls.elected != 0 {
+		t.Fatalf("unexpected elected calls a=%d b=%d", aCalls.elected, bCalls.elected)
+	}
+
+	// 续约不会重复触发选举回调
+	a.tick()
+	if aCalls.elected != 1 {
+		t.Fatalf("renew should not trigger onElected again, got %d", aCalls.elected)
+	}
+}
+
+func TestLeaderElectorFollowerTakesOverExpiredLease(t *testing.T) {
+	store := &fakeLeaseStore{}
+	a, aCalls := newTestElector(store, "a")
+	b, _ := newTestElector(store, "b")
+	a.tick()
+	b.tick()
+
+	// a 宕机, 租约过期后 b 接管
+	store.expire()
+	b.tick()
+	if !b.IsLeader() {
+		t.Fatal("expected b to take over expired lease")
+	}
+
+	// a 恢复后发现租约已被占用, 放弃调度
+	a.tick()
+	if a.IsLeader() {
+		t.Fatal("expected a to step down")
+	}
+	if aCalls.demoted != 1 {
+		t.Fatalf("expected a demoted once, got %d", aCalls.demoted)
+	}
+}
+
+func TestLeaderElectorStepsDownWhenRenewFails(t *testing.T) {
+	store := &fakeLeaseStore{}
+	a, calls := newTestElector(store, "a")
+	a.tick()
+
+	store.err = errors.New("db down")
+	a.tick()
+	if !a.IsLeader() {
+		t.Fatal("leader should keep scheduling while lease is still valid")
+	}
+
+	a.lastRenew = time.Now().Add(-a.ttl)
+	a.tick()
+	if a.IsLeader() || calls.demoted != 1 {
+		t.Fatalf("expected leader to step down before lease expires, leader=%v demoted=%d", a.IsLeader(), calls.demoted)
+	}
+}
+
+func TestLeaderElectorReloadsOnRevisionChange(t *testing.T) {
+	store := &fakeLeaseStore{}
+	a, calls := newTestElector(store, "a")
+	b, _ := newTestElector(store, "b")
+	a.tick()
+	b.tick()
+
+	a.tick()
+	if calls.changed != 0 {
+		t.Fatalf("expected no reload without changes, got %d", calls.changed)
+	}
+
+	// 非调度节点修改任务
+	b.notifyChanged()
+	a.tick()
+	if calls.changed != 1 {
+		t.Fatalf("expected reload after follower change, got %d", calls.changed)
+	}
+}
+
+func TestLeaderElectorRetriesFailedLoad(t *testing.T) {
+	store := &fakeLeaseStore{}
+	a, calls := newTestElector(store, "a")
+	a.onElected = func() error { return errors.New("load failed") }
+	a.tick()
+
+	a.tick()
+	if calls.changed != 1 {
+		t.Fatalf("expected reload retry after failed load, got %d", calls.changed)
+	}
+}
+
+func TestLeaderElectorStopReleasesLease(t *testing.T) {
+	store := &fakeLeaseStore{}
+	a, calls := newTestElector(store, "a")
+	b, _ := newTestElector(store, "b")
+	go a.run()
+	deadline := time.Now().Add(time.Second)
+	for !a.IsLeader() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	a.Stop()
+	if a.IsLeader() || calls.demoted != 1 {
+		t.Fatalf("expected a to resign on stop, leader=%v demoted=%d", a.IsLeader(), calls.demoted)
+	}
+
+	b.tick()
+	if !b.IsLeader() {
+		t.Fatal("expected b to acquire released lease immediately")
+	}
+}
+
+func TestLeaderElectorHandlesRequestsOnlyOnLeader(t *testing.T) {
+	store := &fakeLeaseStore{}
+	a, aCalls := newTestElector(store, "a")
+	b, bCalls := newTestElector(store, "b")
+	a.tick()
+	b.tick()
+	a.tick()
+	if aCalls.renewed != 2 || bCalls.renewed != 0 {
+		t.Fatalf("expected only leader to handle requests, a=%d b=%d", aCalls.renewed, bCalls.renewed)
+	}
+}
+
+func TestDispatchRunsOnLeader(t *testing.T) {
+	original := elector
+	defer func() { elector = original }()
+	// 未开启高可用时直接执行
+	elector = nil
+	runs := 0
+	ServiceTask.dispatch(0, 1, nil, func() { runs++ })
+	if runs != 1 {
+		t.Fatalf("expected request to run without HA, got %d runs", runs)
+	}
+
+	elector, _ = newTestElector(&fakeLeaseStore{}, "a")
+	elector.tick()
+	ServiceTask.dispatch(0, 1, nil, func() { runs++ })
+	if runs != 2 {
+		t.Fatalf("expected request to run on leader, got %d runs", runs)
+	}
+}
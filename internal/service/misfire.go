@@ -0,0 +1,106 @@
+package service
+
+import (
+	"time"
+
+	"github.com/gocronx-team/cron"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+)
+
+// 错过执行补偿
+// 服务停止期间错过的执行时间点, 在启动(或成为调度节点)时根据任务的错过执行策略补偿执行
+// 以任务最近一次定时执行的计划执行时间为基准, 不受重试、排队等待等导致的开始时间延迟影响, 没有执行记录的任务不补偿(一次性任务以创建时间为基准)
+
+// 计算错过次数时最多遍历的时间点数量, 避免秒级任务长时间停机后遍历过久
+const misfireScanLimit = 100000
+
+// 按错过执行策略补偿执行任务
+func (task Task) catchUpMisfire(taskModel models.Task) {
+	limit := misfireRunLimit(taskModel)
+	if limit <= 0 {
+		return
+	}
+	schedule, err := parseTaskSchedule(taskModel)
+	if err != nil {
+		logger.Errorf("错过执行补偿#解析任务表达式失败#ID-%d#%s", taskModel.Id, err)
+		return
+	}
+	taskLogModel := new(models.TaskLog)
+	lastTime, err := taskLogModel.LastScheduledTime(taskModel.Id)
+	if err != nil {
+		logger.Errorf("错过执行补偿#获取最近执行时间失败#ID-%d#%s", taskModel.Id, err)
+		return
+	}
+	if lastTime.IsZero() && taskModel.IsOnce() {
+		// 一次性任务以创建时间为基准, 停机期间错过的唯一一次执行也能补偿
+		lastTime = taskModel.CreatedAt
+	}
+	if lastTime.IsZero() {
+		return
+	}
+	fireTimes, total := missedFireTimes(schedule, lastTime, time.Now(), limit)
+	if total == 0 {
+		return
+	}
+	logger.Infof("错过执行补偿#ID-%d#名称-%s#错过次数-%d#补偿次数-%d", taskModel.Id, taskModel.Name, total, len(fireTimes))
+
+	// 补偿执行依次进行, 避免同一任务同时运行多个实例
+	handler := createHandler(taskModel)
+	if handler == nil {
+		return
+	}
+	go func() {
+		ran := false
+		for _, fireTime := range fireTimes {
+			trigger := jobTrigger{Type: models.TaskLogTriggerMisfire, ScheduledTime: fireTime}
+			if skipByCalendar(taskModel, trigger) {
+				continue
+			}
+			if _, ok := runJob(handler, taskModel, trigger); ok {
+				ran = true
+			}
+		}
+		if ran {
+			task.finishOnce(taskModel)
+		}
+	}()
+}
+
+// 错过执行时最多补偿的次数, 0表示不补偿
+func misfireRunLimit(taskModel models.Task) int {
+	switch taskModel.MisfirePolicy {
+	case models.TaskMisfireRunOnce:
+		return 1
+	case models.TaskMisfireRunAll:
+		if taskModel.MisfireMaxRuns > 0 {
+			return int(taskModel.MisfireMaxRuns)
+		}
+		return models.DefaultMisfireMaxRuns
+	default:
+		return 0
+	}
+}
+
+// 计算last之后、now之前错过的执行时间点
+// 返回最近的limit个时间点(按时间先后排序)和错过的总次数
+func missedFireTimes(schedule cron.Schedule, last, now time.Time, limit int) ([]time.Time, int) {
+	if limit <= 0 {
+		return nil, 0
+	}
+	fireTimes := make([]time.Time, 0, limit)
+	total := 0
+	for next := schedule.Next(last); !next.IsZero() && next.Before(now); next = schedule.Next(next) {
+		total++
+		if len(fireTimes) == limit {
+			fireTimes = append(fireTimes[1:], next)
+		} else {
+			fireTimes = append(fireTimes, next)
+		}
+		if total >= misfireScanLimit {
+			break
+		}
+	}
+
+	return fireTimes, total
+}
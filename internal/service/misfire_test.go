@@ -0,0 +1,78 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/cron"
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+func TestMissedFireTimesKeepsLatest(t *testing.T) {
+	schedule := cron.Parse("0 * * * * *")
+	last := time.Date(2024, 1, 1, 10, 0, 5, 0, time.Local)
+	now := time.Date(2024, 1, 1, 10, 5, 30, 0, time.Local)
+
+	fireTimes, total := missedFireTimes(schedule, last, now, 3)
+	if total != 5 {
+		t.Fatalf("expected 5 missed runs, got %d", total)
+	}
+	expected := []time.Time{
+		time.Date(2024, 1, 1, 10, 3, 0, 0, time.Local),
+		time.Date(2024, 1, 1, 10, 4, 0, 0, time.Local),
+		time.Date(2024, 1, 1, 10, 5, 0, 0, time.Local),
+	}
+	if len(fireTimes) != len(expected) {
+		t.Fatalf("expected %d fire times, got %v", len(expected), fireTimes)
+	}
+	for i := range expected {
+		if !fireTimes[i].Equal(expected[i]) {
+			t.Fatalf("fire time %d: expected %s, got %s", i, expected[i], fireTimes[i])
+		}
+	}
+}
+
+func TestMissedFireTimesNothingMissed(t *testing.T) {
+	schedule := cron.Parse("0 0 * * * *")
+	last := time.Date(2024, 1, 1, 10, 0, 1, 0, time.Local)
+	now := time.Date(2024, 1, 1, 10, 59, 59, 0, time.Local)
+
+	fireTimes, total := missedFireTimes(schedule, last, now, 10)
+	if total != 0 || len(fireTimes) != 0 {
+		t.Fatalf("expected nothing missed, got total=%d fireTimes=%v", total, fireTimes)
+	}
+}
+
+func TestMissedFireTimesScanLimit(t *testing.T) {
+	schedule := cron.Parse("* * * * * *")
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.Local)
+	last := now.AddDate(0, 0, -7)
+
+	fireTimes, total := missedFireTimes(schedule, last, now, 2)
+	if total != misfireScanLimit {
+		t.Fatalf("expected scan to stop at %d, got %d", misfireScanLimit, total)
+	}
+	if len(fireTimes) != 2 {
+		t.Fatalf("expected 2 fire times, got %d", len(fireTimes))
+	}
+}
+
+func TestMisfireRunLimit(t *testing.T) {
+	cases := []struct {
+		policy  models.TaskMisfirePolicy
+		maxRuns int16
+		want    int
+	}{
+		{0, 0, 0},
+		{models.TaskMisfireSkip, 5, 0},
+		{models.TaskMisfireRunOnce, 5, 1},
+		{models.TaskMisfireRunAll, 5, 5},
+		{models.TaskMisfireRunAll, 0, models.DefaultMisfireMaxRuns},
+	}
+	for _, c := range cases {
+		got := misfireRunLimit(models.Task{MisfirePolicy: c.policy, MisfireMaxRuns: c.maxRuns})
+		if got != c.want {
+			t.Fatalf("policy=%d maxRuns=%d: expected %d, got %d", c.policy, c.maxRuns, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gocronx-team/cron"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+)
+
+// 一次性任务
+// 在RunAt指定的时间(按任务时区解析)执行一次, 不使用任务表达式
+// 定时触发或错过补偿执行后, 按任务配置保留、禁用或删除任务, 手动运行不处理
+// 被重叠策略跳过、合并到排队中的执行或未能开始执行时不处理, 任务仍等待下次执行
+
+// 只在指定时间执行一次
+type onceSchedule struct {
+	at time.Time
+}
+
+// 返回t之后的执行时间, 已过执行时间时返回零值, 调度器不再执行
+func (s onceSchedule) Next(t time.Time) time.Time {
+	if s.at.After(t) {
+		return s.at.In(time.Local)
+	}
+
+	return time.Time{}
+}
+
+// ParseRunAt 按时区解析一次性任务的执行时间, timezone为空时使用服务器本地时区
+func ParseRunAt(runAt, timezone string) (time.Time, error) {
+	location, err := loadTaskLocation(timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.ParseInLocation(models.DefaultTimeFormat, runAt, location)
+}
+
+func parseOnceSchedule(taskModel models.Task) (cron.Schedule, error) {
+	at, err := ParseRunAt(taskModel.RunAt, taskModel.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	return onceSchedule{at: at}, nil
+}
+
+// 一次性任务执行后按配置禁用或删除任务
+func (task Task) finishOnce(taskModel models.Task) {
+	if !taskModel.IsOnce() {
+		return
+	}
+	switch taskModel.OnceAction {
+	case models.TaskOnceDisable:
+		if _, err := taskModel.Disable(taskModel.Id); err != nil {
+			logger.Errorf("一次性任务#禁用任务失败#ID-%d#%s", taskModel.Id, err)
+			return
+		}
+		logger.Infof("一次性任务执行完成, 已禁用任务#ID-%d#名称-%s", taskModel.Id, taskModel.Name)
+	case models.TaskOnceDelete:
+		err := taskModel.Remove(taskModel.Id)
+		if errors.Is(err, models.ErrTaskInWorkflow) {
+			// 被工作流引用的任务不能删除, 改为禁用
+			if _, err = taskModel.Disable(taskModel.Id); err != nil {
+				logger.Errorf("一次性任务#禁用任务失败#ID-%d#%s", taskModel.Id, err)
+				return
+			}
+			logger.Infof("一次性任务执行完成, 任务被工作流引用, 已禁用任务#ID-%d#名称-%s", taskModel.Id, taskModel.Name)
+			break
+		}
+		if err != nil {
+			logger.Errorf("一次性任务#删除任务失败#ID-%d#%s", taskModel.Id, err)
+			return
+		}
+		logger.Infof("一次性任务执行完成, 已删除任务#ID-%d#名称-%s", taskModel.Id, taskModel.Name)
+	default:
+		return
+	}
+	task.Remove(taskModel.Id)
+}
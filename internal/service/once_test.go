@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+func TestOnceScheduleFiresOnce(t *testing.T) {
+	shanghai := mustLoadLocation(t, "Asia/Shanghai")
+	taskModel := models.Task{Id: 1, RunAt: "2024-06-01 08:30:00", Timezone: "Asia/Shanghai"}
+	schedule, err := parseTaskSchedule(taskModel)
+	if err != nil {
+		t.Fatalf("parse schedule failed: %v", err)
+	}
+
+	expected := time.Date(2024, 6, 1, 8, 30, 0, 0, shanghai)
+	next := schedule.Next(time.Date(2024, 5, 1, 0, 0, 0, 0, shanghai))
+	if !next.Equal(expected) {
+		t.Fatalf("expected %s, got %s", expected, next)
+	}
+	if next.Location() != time.Local {
+		t.Fatalf("expected next run time in local time zone, got %s", next.Location())
+	}
+	if next = schedule.Next(expected); !next.IsZero() {
+		t.Fatalf("expected no run after fire time, got %s", next)
+	}
+}
+
+func TestOnceScheduleInvalidRunAt(t *testing.T) {
+	taskModel := models.Task{Id: 1, RunAt: "2024-06-01T08:30", Spec: "* * * * * *"}
+	if _, err := parseTaskSchedule(taskModel); err == nil {
+		t.Fatal("expected invalid run time to fail")
+	}
+}
+
+func TestOnceScheduleMissedFireTime(t *testing.T) {
+	shanghai := mustLoadLocation(t, "Asia/Shanghai")
+	taskModel := models.Task{Id: 1, RunAt: "2024-06-01 08:30:00", Timezone: "Asia/Shanghai"}
+	schedule, _ := parseTaskSchedule(taskModel)
+
+	created := time.Date(2024, 5, 1, 0, 0, 0, 0, shanghai)
+	fireTimes, total := missedFireTimes(schedule, created, time.Date(2024, 6, 2, 0, 0, 0, 0, shanghai), 10)
+	if total != 1 || len(fireTimes) != 1 {
+		t.Fatalf("expected exactly one missed run, got %d %v", total, fireTimes)
+	}
+	// 已执行过的一次性任务不再补偿
+	_, total = missedFireTimes(schedule, fireTimes[0], time.Date(2024, 6, 2, 0, 0, 0, 0, shanghai), 10)
+	if total != 0 {
+		t.Fatalf("expected no missed run after execution, got %d", total)
+	}
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	rpcClient "github.com/gocronx-team/gocron/internal/modules/rpc/client"
+	"github.com/gocronx-team/gocron/internal/modules/sshclient"
+)
+
+// 单实例运行
+// 同一任务同时只有一个实例在执行, 上次执行未结束时按任务配置的策略处理, 处理结果写入任务日志:
+//   - 跳过: 写入已取消日志, 本次不执行
+//   - 排队: 写入排队中日志, 上次执行结束后开始执行; 已有等待执行的实例时合并到该实例, 本次写入已取消日志
+//   - 替换: 停止上次执行并立即开始本次执行, 上次执行的日志标记为已取消
+// RPC、SSH任务向主机发送停止请求, 滚动执行不再执行剩余的批次; SQL任务取消正在执行的语句
+// HTTP任务无法中断请求, 替换时上次的请求继续执行直到结束, 结果不再写入任务日志
+
+var (
+	ErrRunReplaced = errors.New("已被新的执行替换")
+
+	rpcCancelFunc         = rpcClient.Cancel
+	sshCancelFunc         = sshclient.Cancel
+	createInstanceLogFunc = createInstanceTaskLog
+	updateInstanceLogFunc = updateQueuedTaskLog
+)
+
+// 单实例运行任务的一次执行
+type instanceRun struct {
+	taskLogId  int64
+	hosts      []models.TaskHostDetail
+	protocol   models.TaskProtocol
+	start      chan struct{} // 排队中的执行轮到执行时关闭
+	replacedBy int64         // 替换本次执行的任务日志ID, 读写需持有Instance.mu
+}
+
+// 同一任务执行中及等待执行的实例
+type instanceState struct {
+	running *instanceRun
+	pending *instanceRun
+}
+
+// 任务ID作为Key
+type Instance struct {
+	mu sync.Mutex
+	m  map[int]*instanceState
+}
+
+// 开始执行前按任务配置的策略处理执行中的实例, 返回本次执行, 本次不执行时ok为false
+// 排队时阻塞到上次执行结束或排队被取消
+func (i *Instance) begin(taskModel models.Task, trigger jobTrigger) (run *instanceRun, ok bool) {
+	i.mu.Lock()
+	if i.m == nil {
+		i.m = make(map[int]*instanceState)
+	}
+	state := i.m[taskModel.Id]
+	if state == nil {
+		run, ok = newInstanceRun(taskModel, trigger, models.Running, "")
+		if ok {
+			i.m[taskModel.Id] = &instanceState{running: run}
+		}
+		i.mu.Unlock()
+		return run, ok
+	}
+
+	running := state.running
+	switch taskModel.OverlapPolicy {
+	case models.TaskOverlapQueue:
+		if state.pending != nil {
+			pendingId := state.pending.taskLogId
+			i.mu.Unlock()
+			logger.Infof("任务已有等待执行的实例, 合并到该次执行#ID-%d#taskLogId-%d", taskModel.Id, pendingId)
+			newInstanceRun(taskModel, trigger, models.Cancel,
+				fmt.Sprintf("已有等待执行的实例(日志ID-%d), 合并到该次执行", pendingId))
+			return nil, false
+		}
+		run, ok = newInstanceRun(taskModel, trigger, models.Queued,
+			fmt.Sprintf("等待上次执行(日志ID-%d)结束", running.taskLogId))
+		if ok {
+			run.start = make(chan struct{})
+			state.pending = run
+		}
+		i.mu.Unlock()
+		if !ok {
+			return nil, false
+		}
+		logger.Infof("任务已在运行中, 排队等待#ID-%d#taskLogId-%d", taskModel.Id, run.taskLogId)
+		return run, i.waitPending(taskModel.Id, run)
+	case models.TaskOverlapReplace:
+		run, ok = newInstanceRun(taskModel, trigger, models.Running, "")
+		if ok {
+			running.replacedBy = run.taskLogId
+			state.running = run
+		}
+		i.mu.Unlock()
+		if ok {
+			logger.Infof("任务已在运行中, 停止上次执行#ID-%d#taskLogId-%d#新taskLogId-%d",
+				taskModel.Id, running.taskLogId, run.taskLogId)
+			stopInstanceRun(running)
+		}
+		return run, ok
+	default:
+		i.mu.Unlock()
+		logger.Infof("任务已在运行中，取消本次执行#ID-%d", taskModel.Id)
+		newInstanceRun(taskModel, trigger, models.Cancel,
+			fmt.Sprintf("上次执行(日志ID-%d)未结束, 跳过本次执行", running.taskLogId))
+		return nil, false
+	}
+}
+
+// 等待上次执行结束, 排队中被取消时返回false
+func (i *Instance) waitPending(taskId int, run *instanceRun) bool {
+	cancel := make(chan struct{})
+	queuedJobs.Store(run.taskLogId, cancel)
+	select {
+	case <-run.start:
+	case <-cancel:
+	}
+	if _, exist := queuedJobs.LoadAndDelete(run.taskLogId); !exist {
+		i.mu.Lock()
+		if state := i.m[taskId]; state != nil && state.pending == run {
+			state.pending = nil
+		} else {
+			// 已被取消但同时轮到执行, 按取消处理
+			i.finishLocked(taskId, run)
+		}
+		i.mu.Unlock()
+		logger.Infof("排队中的任务被取消#taskLogId-%d", run.taskLogId)
+		updateInstanceLogFunc(run.taskLogId, models.CommonMap{
+			"status":   models.Cancel,
+			"result":   "排队中手动停止",
+			"end_time": time.Now(),
+		})
+		return false
+	}
+	logger.Infof("上次执行已结束, 开始执行#任务ID-%d#taskLogId-%d", taskId, run.taskLogId)
+	updateInstanceLogFunc(run.taskLogId, models.CommonMap{
+		"status":     models.Running,
+		"result":     "",
+		"start_time": time.Now(),
+	})
+
+	return true
+}
+
+// 执行结束, 有等待执行的实例时通知其开始执行
+func (i *Instance) finish(taskId int, run *instanceRun) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.finishLocked(taskId, run)
+}
+
+func (i *Instance) finishLocked(taskId int, run *instanceRun) {
+	state := i.m[taskId]
+	if state == nil || state.running != run {
+		return
+	}
+	if state.pending == nil {
+		delete(i.m, taskId)
+		return
+	}
+	state.running = state.pending
+	state.pending = nil
+	close(state.running.start)
+}
+
+// 返回替换本次执行的任务日志ID, 未被替换时返回0
+func (i *Instance) replacedBy(run *instanceRun) int64 {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return run.replacedBy
+}
+
+func newInstanceRun(taskModel models.Task, trigger jobTrigger, status models.Status, result string) (*instanceRun, bool) {
+	taskLogId, err := createInstanceLogFunc(taskModel, trigger, status, result)
+	if err != nil {
+		logger.Error("任务开始执行#写入任务日志失败-", err)
+		return nil, false
+	}
+	if status == models.Cancel {
+		return nil, false
+	}
+
+	return &instanceRun{taskLogId: taskLogId, hosts: taskModel.Hosts, protocol: taskModel.Protocol}, true
+}
+
+func createInstanceTaskLog(taskModel models.Task, trigger jobTrigger, status models.Status, result string) (int64, error) {
+	taskLogModel := newTaskLog(taskModel, trigger, status)
+	taskLogModel.Result = result
+	if status == models.Cancel {
+		taskLogModel.EndTime = taskLogModel.StartTime
+	}
+
+	return taskLogModel.Create()
+}
+
+// 停止被替换的执行, 排队等待并发名额时直接取消
+func stopInstanceRun(run *instanceRun) {
+	if ServiceTask.CancelQueued(run.taskLogId) {
+		return
+	}
+	if run.protocol == models.TaskSQL {
+		cancelSqlRun(run.taskLogId)
+		return
+	}
+	cancelRollingRun(run.taskLogId)
+	for _, host := range run.hosts {
+		if run.protocol == models.TaskSSH {
+			sshCancelFunc(host.Name, host.SshPort, run.taskLogId)
+		} else {
+			rpcCancelFunc(host.Name, host.Port, run.taskLogId)
+		}
+	}
+}
+
+// 被替换的执行不再重试
+type instanceHandler struct {
+	Handler
+	run *instanceRun
+}
+
+func (h instanceHandler) Run(taskModel models.Task, taskUniqueId int64) (string, error) {
+	if runInstance.replacedBy(h.run) > 0 {
+		return "", newTaskError(ErrRunReplaced, "")
+	}
+
+	return h.Handler.Run(taskModel, taskUniqueId)
+}
+
+// 被替换的执行结束后标记为已取消, 不发送通知、不执行子任务
+func finishReplacedRun(taskLogId, replacedBy int64, taskResult TaskResult) {
+	result := fmt.Sprintf("已被新的执行(日志ID-%d)替换", replacedBy)
+	if taskResult.Result != "" {
+		result += "\n" + taskResult.Result
+	}
+	updateInstanceLogFunc(taskLogId, models.CommonMap{
+		"status":      models.Cancel,
+		"result":      result,
+		"retry_times": taskResult.RetryTimes,
+		"attempts":    encodeTaskAttempts(taskResult.Attempts),
+		"end_time":    time.Now(),
+	})
+}
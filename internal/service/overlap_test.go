@@ -0,0 +1,234 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+type instanceLogRecorder struct {
+	mu      sync.Mutex
+	lastId  int64
+	created map[int64]models.Status
+	results map[int64]string
+	updates map[int64]models.CommonMap
+}
+
+func mockInstanceLogs(t *testing.T) *instanceLogRecorder {
+	recorder := &instanceLogRecorder{
+		created: make(map[int64]models.Status),
+		results: make(map[int64]string),
+		updates: make(map[int64]models.CommonMap),
+	}
+	originalCreate, originalUpdate, originalCancel := createInstanceLogFunc, updateInstanceLogFunc, rpcCancelFunc
+	t.Cleanup(func() {
+		createInstanceLogFunc, updateInstanceLogFunc, rpcCancelFunc = originalCreate, originalUpdate, originalCancel
+	})
+	createInstanceLogFunc = func(taskModel models.Task, trigger jobTrigger, status models.Status, result string) (int64, error) {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		recorder.lastId++
+		recorder.created[recorder.lastId] = status
+		recorder.results[recorder.lastId] = result
+		return recorder.lastId, nil
+	}
+	updateInstanceLogFunc = func(taskLogId int64, data models.CommonMap) {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		recorder.updates[taskLogId] = data
+	}
+
+	return recorder
+}
+
+func (r *instanceLogRecorder) log(id int64) (models.Status, string, models.CommonMap) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.created[id], r.results[id], r.updates[id]
+}
+
+func TestInstanceSkip(t *testing.T) {
+	recorder := mockInstanceLogs(t)
+	var instances Instance
+	task := models.Task{Id: 1, OverlapPolicy: models.TaskOverlapSkip}
+
+	first, ok := instances.begin(task, jobTrigger{})
+	if !ok || first.taskLogId != 1 {
+		t.Fatalf("expected first run to start, got %+v ok=%v", first, ok)
+	}
+	if _, ok = instances.begin(task, jobTrigger{}); ok {
+		t.Fatal("expected overlapping run to be skipped")
+	}
+	status, result, _ := recorder.log(2)
+	if status != models.Cancel || !strings.Contains(result, "日志ID-1") {
+		t.Fatalf("expected canceled log referencing running log, got status=%d result=%q", status, result)
+	}
+
+	instances.finish(task.Id, first)
+	if _, ok = instances.begin(task, jobTrigger{}); !ok {
+		t.Fatal("expected run to start after previous run finished")
+	}
+}
+
+func TestInstanceQueue(t *testing.T) {
+	recorder := mockInstanceLogs(t)
+	var instances Instance
+	task := models.Task{Id: 2, OverlapPolicy: models.TaskOverlapQueue}
+
+	first, _ := instances.begin(task, jobTrigger{})
+	started := make(chan *instanceRun)
+	go func() {
+		run, ok := instances.begin(task, jobTrigger{})
+		if !ok {
+			run = nil
+		}
+		started <- run
+	}()
+	waitFor(t, func() bool {
+		status, _, _ := recorder.log(2)
+		return status == models.Queued
+	})
+
+	// 已有等待执行的实例时合并
+	if _, ok := instances.begin(task, jobTrigger{}); ok {
+		t.Fatal("expected third run to be merged into pending run")
+	}
+	status, result, _ := recorder.log(3)
+	if status != models.Cancel || !strings.Contains(result, "日志ID-2") {
+		t.Fatalf("expected merged log referencing pending run, got status=%d result=%q", status, result)
+	}
+	select {
+	case <-started:
+		t.Fatal("expected pending run to wait for running run")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	instances.finish(task.Id, first)
+	second := <-started
+	if second == nil || second.taskLogId != 2 {
+		t.Fatalf("expected pending run to start, got %+v", second)
+	}
+	if _, _, update := recorder.log(2); update["status"] != models.Running {
+		t.Fatalf("expected pending log to be marked running, got %+v", update)
+	}
+	instances.finish(task.Id, second)
+	if len(instances.m) != 0 {
+		t.Fatalf("expected no running instance, got %+v", instances.m)
+	}
+}
+
+func TestInstanceQueueCancel(t *testing.T) {
+	recorder := mockInstanceLogs(t)
+	var instances Instance
+	task := models.Task{Id: 3, OverlapPolicy: models.TaskOverlapQueue}
+
+	first, _ := instances.begin(task, jobTrigger{})
+	done := make(chan bool)
+	go func() {
+		_, ok := instances.begin(task, jobTrigger{})
+		done <- ok
+	}()
+	waitFor(t, func() bool {
+		_, exist := queuedJobs.Load(int64(2))
+		return exist
+	})
+	if !ServiceTask.CancelQueued(2) {
+		t.Fatal("expected pending run to be canceled")
+	}
+	if <-done {
+		t.Fatal("expected canceled run not to start")
+	}
+	if _, _, update := recorder.log(2); update["status"] != models.Cancel {
+		t.Fatalf("expected canceled log, got %+v", update)
+	}
+
+	// 取消后可以重新排队
+	go func() {
+		_, ok := instances.begin(task, jobTrigger{})
+		done <- ok
+	}()
+	waitFor(t, func() bool {
+		status, _, _ := recorder.log(3)
+		return status == models.Queued
+	})
+	instances.finish(task.Id, first)
+	if !<-done {
+		t.Fatal("expected queued run to start after cancel")
+	}
+}
+
+func TestInstanceReplace(t *testing.T) {
+	recorder := mockInstanceLogs(t)
+	var canceled []int64
+	rpcCancelFunc = func(ip string, port int, id int64) bool {
+		canceled = append(canceled, id)
+		return true
+	}
+	task := models.Task{
+		Id:            4,
+		OverlapPolicy: models.TaskOverlapReplace,
+		Hosts:         []models.TaskHostDetail{{Name: "127.0.0.1", Port: 5921}},
+	}
+
+	first, _ := runInstance.begin(task, jobTrigger{})
+	second, ok := runInstance.begin(task, jobTrigger{})
+	if !ok || second.taskLogId != 2 {
+		t.Fatalf("expected replacing run to start, got %+v ok=%v", second, ok)
+	}
+	if len(canceled) != 1 || canceled[0] != first.taskLogId {
+		t.Fatalf("expected previous run to be stopped, got %v", canceled)
+	}
+	if runInstance.replacedBy(first) != second.taskLogId || runInstance.replacedBy(second) != 0 {
+		t.Fatal("expected previous run to be marked as replaced")
+	}
+
+	// 被替换的执行不再重试
+	called := false
+	handler := instanceHandler{Handler: handlerFunc(func(models.Task, int64) (string, error) {
+		called = true
+		return "", nil
+	}), run: first}
+	if _, err := handler.Run(task, first.taskLogId); !errors.Is(err, ErrRunReplaced) || called || shouldRetry(task, err) {
+		t.Fatalf("expected replaced run to stop without retry, err=%v called=%v", err, called)
+	}
+
+	finishReplacedRun(first.taskLogId, second.taskLogId, TaskResult{Result: "partial"})
+	if _, _, update := recorder.log(1); update["status"] != models.Cancel ||
+		!strings.Contains(update["result"].(string), "日志ID-2") {
+		t.Fatalf("expected replaced log to be canceled, got %+v", update)
+	}
+
+	// 被替换的执行结束不影响新的执行
+	runInstance.finish(task.Id, first)
+	if _, ok = runInstance.begin(models.Task{Id: 4, OverlapPolicy: models.TaskOverlapSkip}, jobTrigger{}); ok {
+		t.Fatal("expected replacing run to still be running")
+	}
+	runInstance.finish(task.Id, second)
+	third, ok := runInstance.begin(task, jobTrigger{})
+	if !ok {
+		t.Fatal("expected run to start after replacing run finished")
+	}
+	runInstance.finish(task.Id, third)
+}
+
+type handlerFunc func(models.Task, int64) (string, error)
+
+func (f handlerFunc) Run(taskModel models.Task, taskUniqueId int64) (string, error) {
+	return f(taskModel, taskUniqueId)
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
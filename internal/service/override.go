@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+// 手动运行时覆盖任务参数
+// 覆盖的参数只对本次执行生效, 保存在任务日志中, 可按日志中的参数重新执行
+// 追加的参数按空白分隔后逐个加引号, 不会被shell解析; 参数中的模板分隔符被转义, 不会被渲染
+
+// 任务日志中命令字段的最大长度
+const maxCommandLength = 256
+
+var (
+	ErrRunOverrideRPCOnly       = errors.New("环境变量及主机只支持RPC、SSH任务")
+	ErrRunOverrideInvalidEnv    = errors.New("环境变量名称无效")
+	ErrRunOverrideHost          = errors.New("主机不属于该任务")
+	ErrRunOverrideCommandLength = errors.New("追加参数后命令超出长度限制")
+	ErrRunOverrideSQLArgs       = errors.New("SQL任务不支持追加参数")
+)
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// 使用覆盖的参数运行任务
+func (task Task) RunWithOverride(taskModel models.Task, override models.TaskRunOverride) {
+	task.runManual(taskModel, &override)
+}
+
+// ValidateRunOverride 校验覆盖的参数是否适用于任务
+func ValidateRunOverride(taskModel models.Task, override models.TaskRunOverride) error {
+	if !taskModel.Protocol.OnHosts() && (len(override.Env) > 0 || len(override.HostIds) > 0) {
+		return ErrRunOverrideRPCOnly
+	}
+	// SQL任务的命令为语句的摘要, 不能追加参数
+	if taskModel.Protocol == models.TaskSQL {
+		if override.Args != "" {
+			return ErrRunOverrideSQLArgs
+		}
+		return nil
+	}
+	for name := range override.Env {
+		if !envNamePattern.MatchString(name) {
+			return ErrRunOverrideInvalidEnv
+		}
+	}
+	for _, hostId := range override.HostIds {
+		found := false
+		for _, host := range taskModel.Hosts {
+			if int(host.HostId) == hostId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrRunOverrideHost
+		}
+	}
+	// HTTP任务的请求地址不受命令长度限制, 任务日志只记录地址的摘要
+	command := applyRunOverride(taskModel, &override).Command
+	if taskModel.Protocol != models.TaskHTTP && utf8.RuneCountInString(command) > maxCommandLength {
+		return ErrRunOverrideCommandLength
+	}
+	if err := ValidateCommandTemplate(command); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// 应用手动运行时覆盖的参数
+func applyRunOverride(taskModel models.Task, override *models.TaskRunOverride) models.Task {
+	if override == nil {
+		return taskModel
+	}
+	if override.Args != "" {
+		if taskModel.Protocol == models.TaskHTTP {
+			separator := "?"
+			if strings.Contains(taskModel.Command, "?") {
+				separator = "&"
+			}
+			taskModel.Command += separator + escapeCommandTemplate(override.Args)
+		} else {
+			for _, arg := range strings.Fields(override.Args) {
+				taskModel.Command += " " + shellQuote(escapeCommandTemplate(arg))
+			}
+		}
+	}
+	if len(override.Env) > 0 {
+		taskModel.RunEnv = override.Env
+	}
+	if len(override.HostIds) > 0 {
+		hosts := make([]models.TaskHostDetail, 0, len(override.HostIds))
+		for _, host := range taskModel.Hosts {
+			for _, hostId := range override.HostIds {
+				if int(host.HostId) == hostId {
+					hosts = append(hosts, host)
+					break
+				}
+			}
+		}
+		taskModel.Hosts = hosts
+	}
+	if override.Timeout > 0 {
+		taskModel.Timeout = override.Timeout
+	}
+
+	return taskModel
+}
+
+// 命令的摘要, 超出任务、任务日志命令字段的长度时截断
+func commandSummary(command string) string {
+	if utf8.RuneCountInString(command) <= maxCommandLength {
+		return command
+	}
+
+	return string([]rune(command)[:maxCommandLength-3]) + "..."
+}
+
+// HttpCommand HTTP任务的命令, 为请求地址的摘要
+func HttpCommand(url string) string {
+	return commandSummary(url)
+}
+
+// shell单引号转义
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
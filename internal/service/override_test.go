@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+func overrideTask(protocol models.TaskProtocol, command string, hostIds ...int16) models.Task {
+	task := models.Task{Id: 1, Protocol: protocol, Command: command, Timeout: 60}
+	for _, hostId := range hostIds {
+		task.Hosts = append(task.Hosts, models.TaskHostDetail{TaskHost: models.TaskHost{HostId: hostId}})
+	}
+	return task
+}
+
+func TestApplyRunOverride(t *testing.T) {
+	task := overrideTask(models.TaskRPC, "backup.sh", 1, 2, 3)
+	override := &models.TaskRunOverride{
+		Args:    "--full",
+		Env:     map[string]string{"TARGET": "db1"},
+		HostIds: []int{3, 1},
+		Timeout: 600,
+	}
+	result := applyRunOverride(task, override)
+	if result.Command != "backup.sh '--full'" || result.Timeout != 600 || result.RunEnv["TARGET"] != "db1" {
+		t.Fatalf("unexpected override result %+v", result)
+	}
+	if len(result.Hosts) != 2 || result.Hosts[0].HostId != 1 || result.Hosts[1].HostId != 3 {
+		t.Fatalf("expected hosts 1 and 3, got %+v", result.Hosts)
+	}
+	if task.Command != "backup.sh" || len(task.Hosts) != 3 {
+		t.Fatal("expected original task to be unchanged")
+	}
+
+	// 追加的参数不会被shell解析
+	quoted := applyRunOverride(task, &models.TaskRunOverride{Args: "a;rm $(id) it's"}).Command
+	if quoted != `backup.sh 'a;rm' '$(id)' 'it'\''s'` {
+		t.Fatalf("unexpected quoted command %s", quoted)
+	}
+
+	// 追加的参数不会被渲染为模板
+	injected := applyRunOverride(task, &models.TaskRunOverride{Args: `${{"\x27"}};id;#`})
+	if command, err := renderCommand(injected, 1, nil); err != nil || command != `backup.sh '${{"\x27"}};id;#'` {
+		t.Fatalf("expected template in args to stay literal, got %s err=%v", command, err)
+	}
+	templated := overrideTask(models.TaskRPC, "backup.sh ${{ .TaskLogId }}")
+	injected = applyRunOverride(templated, &models.TaskRunOverride{Args: "a${{.TaskId}} ${{"})
+	if command, err := renderCommand(injected, 7, nil); err != nil || command != `backup.sh 7 'a${{.TaskId}}' '${{'` {
+		t.Fatalf("expected only task template to be rendered, got %s err=%v", command, err)
+	}
+
+	httpTask := overrideTask(models.TaskHTTP, "http://example.com/job")
+	if got := applyRunOverride(httpTask, &models.TaskRunOverride{Args: "a=1"}).Command; got != "http://example.com/job?a=1" {
+		t.Fatalf("unexpected http command %s", got)
+	}
+	httpTask.Command = "http://example.com/job?x=1"
+	if got := applyRunOverride(httpTask, &models.TaskRunOverride{Args: "a=1"}).Command; got != "http://example.com/job?x=1&a=1" {
+		t.Fatalf("unexpected http command %s", got)
+	}
+	if got := applyRunOverride(httpTask, nil); got.Command != httpTask.Command {
+		t.Fatal("expected nil override to keep task unchanged")
+	}
+}
+
+func TestValidateRunOverride(t *testing.T) {
+	rpcTask := overrideTask(models.TaskRPC, "backup.sh", 1, 2)
+	httpTask := overrideTask(models.TaskHTTP, "http://example.com/job")
+	// SQL任务的命令为截断的语句摘要, 可能包含不完整的模板
+	sqlTask := overrideTask(models.TaskSQL, "SELECT '${{ .ScheduledTime")
+	tests := []struct {
+		task     models.Task
+		override models.TaskRunOverride
+		expected error
+	}{
+		{rpcTask, models.TaskRunOverride{Args: "--full", Env: map[string]string{"A_1": "x"}, HostIds: []int{2}}, nil},
+		{httpTask, models.TaskRunOverride{Args: "a=1", Timeout: 10}, nil},
+		{httpTask, models.TaskRunOverride{Env: map[string]string{"A": "x"}}, ErrRunOverrideRPCOnly},
+		{httpTask, models.TaskRunOverride{HostIds: []int{1}}, ErrRunOverrideRPCOnly},
+		{rpcTask, models.TaskRunOverride{Env: map[string]string{"1A": "x"}}, ErrRunOverrideInvalidEnv},
+		{rpcTask, models.TaskRunOverride{HostIds: []int{3}}, ErrRunOverrideHost},
+		{rpcTask, models.TaskRunOverride{Args: strings.Repeat("a", 250)}, ErrRunOverrideCommandLength},
+		// HTTP任务的请求地址保存在请求定义中, 不限制长度
+		{httpTask, models.TaskRunOverride{Args: "a=" + strings.Repeat("a", 300)}, nil},
+		{sqlTask, models.TaskRunOverride{Timeout: 10}, nil},
+		{sqlTask, models.TaskRunOverride{Args: "1"}, ErrRunOverrideSQLArgs},
+	}
+	for i, test := range tests {
+		if err := ValidateRunOverride(test.task, test.override); !errors.Is(err, test.expected) {
+			t.Errorf("case %d: expected %v, got %v", i, test.expected, err)
+		}
+	}
+}
+
+func TestNewTaskLogStoresOverride(t *testing.T) {
+	task := overrideTask(models.TaskRPC, "backup.sh", 1)
+	override := &models.TaskRunOverride{Args: "--full", HostIds: []int{1}}
+	taskLog := newTaskLog(task, jobTrigger{Type: models.TaskLogTriggerManual, Override: override}, models.Running)
+	stored, err := taskLog.RunOverride()
+	if err != nil || stored == nil || stored.Args != "--full" || len(stored.HostIds) != 1 {
+		t.Fatalf("expected override to be stored, got %q err=%v", taskLog.Overrides, err)
+	}
+
+	taskLog = newTaskLog(task, jobTrigger{Type: models.TaskLogTriggerCron}, models.Running)
+	if stored, _ := taskLog.RunOverride(); stored != nil || taskLog.Overrides != "" {
+		t.Fatalf("expected no override, got %q", taskLog.Overrides)
+	}
+
+	// 日志中的命令为摘要
+	httpTask := overrideTask(models.TaskHTTP, "http://example.com/job?q="+strings.Repeat("a", 300))
+	taskLog = newTaskLog(httpTask, jobTrigger{Type: models.TaskLogTriggerCron}, models.Running)
+	if len(taskLog.Command) != maxCommandLength || !strings.HasSuffix(taskLog.Command, "...") {
+		t.Fatalf("unexpected log command %q", taskLog.Command)
+	}
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	rpcClient "github.com/gocronx-team/gocron/internal/modules/rpc/client"
+)
+
+// 启动时核对未结束的任务日志
+// 服务异常退出后, 执行中、排队中的任务日志会一直保持该状态
+// 启动时按主机执行记录向实际执行的主机确认RPC任务是否仍在执行, 其余标记为中断, 任务开启了中断后重新执行时重新执行一次
+// 仍在主机上执行的任务定期再次确认, 执行结束后同样标记为中断(执行结果已无法获取)
+// 高可用模式下由成为调度节点的节点核对, 任务可能正在其他节点上执行, 只处理主机确认已不在执行的任务日志
+// 刚开始执行的任务可能尚未下发到主机, 高可用模式下跳过宽限期内开始的任务日志
+
+const (
+	// 再次确认仍在执行的任务的间隔
+	reconcileInterval = time.Minute
+	// 高可用模式下跳过该时间内开始的任务日志
+	reconcileGracePeriod = time.Minute
+)
+
+var rpcRunningFunc = rpcClient.Running
+
+type taskLogState int8
+
+const (
+	taskLogStopped taskLogState = iota // 已不在执行
+	taskLogAlive                       // 仍在主机上执行
+	taskLogUnknown                     // 无法确认, 如HTTP任务、SSH任务、排队中的任务、主机不支持查询
+)
+
+// 读取未结束的任务日志, 需在添加任务到调度器前调用, 避免读取到本次启动后产生的日志
+func (task Task) unfinishedTaskLogs() []models.TaskLog {
+	taskLogModel := new(models.TaskLog)
+	logs, err := taskLogModel.UnfinishedList()
+	if err != nil {
+		logger.Errorf("核对任务日志#获取未结束的任务日志失败#%s", err)
+		return nil
+	}
+
+	return logs
+}
+
+// 核对未结束的任务日志, 直到所有仍在执行的任务都执行结束
+func (task Task) reconcileTaskLogs(logs []models.TaskLog) {
+	ha := elector != nil
+	if !ha {
+		// 工作流、回填由服务进程执行, 非高可用模式下执行中的记录均已中断
+		runModel := new(models.WorkflowRun)
+		if _, err := runModel.InterruptRunning("服务重启, 工作流执行中断"); err != nil {
+			logger.Errorf("核对任务日志#更新工作流执行记录失败#%s", err)
+		}
+		backfillModel := new(models.TaskBackfill)
+		if _, err := backfillModel.InterruptRunning("服务重启, 回填执行中断"); err != nil {
+			logger.Errorf("核对任务日志#更新任务回填记录失败#%s", err)
+		}
+	}
+	if ha {
+		logs = startedBefore(logs, time.Now().Add(-reconcileGracePeriod))
+	}
+	if len(logs) == 0 {
+		return
+	}
+	logger.Infof("核对任务日志#未结束的任务日志数量-%d", len(logs))
+	reason := "服务重启, 任务执行中断"
+	rerun := true
+	for {
+		// 核对期间不再是调度节点时由新的调度节点处理
+		if ha && !elector.IsLeader() {
+			return
+		}
+		states := taskLogStates(logs, taskLogHosts(logs))
+		alive := make([]models.TaskLog, 0)
+		for _, taskLog := range logs {
+			switch states[taskLog.Id] {
+			case taskLogAlive:
+				// 高可用模式下由执行任务的节点更新日志
+				if !ha {
+					alive = append(alive, taskLog)
+				}
+			case taskLogUnknown:
+				if ha {
+					logger.Infof("核对任务日志#无法确认任务是否仍在执行, 跳过#taskLogId-%d", taskLog.Id)
+					continue
+				}
+				task.interruptTaskLog(taskLog, reason, rerun)
+			default:
+				task.interruptTaskLog(taskLog, reason, rerun)
+			}
+		}
+		if len(alive) == 0 {
+			return
+		}
+		logs = alive
+		reason = "服务重启后任务在主机上执行结束, 无法获取执行结果"
+		rerun = false
+		sleepFunc(reconcileInterval)
+	}
+}
+
+// 返回在cutoff之前开始的任务日志
+func startedBefore(logs []models.TaskLog, cutoff time.Time) []models.TaskLog {
+	result := make([]models.TaskLog, 0, len(logs))
+	for _, taskLog := range logs {
+		if time.Time(taskLog.StartTime).Before(cutoff) {
+			result = append(result, taskLog)
+		}
+	}
+
+	return result
+}
+
+// 标记任务日志为中断, rerun为true时按任务配置重新执行
+func (task Task) interruptTaskLog(taskLog models.TaskLog, reason string, rerun bool) {
+	taskLogModel := new(models.TaskLog)
+	updated, err := taskLogModel.Interrupt(taskLog.Id, reason)
+	if err != nil {
+		logger.Errorf("核对任务日志#更新任务日志失败#taskLogId-%d#%s", taskLog.Id, err)
+		return
+	}
+	// 日志已被其他节点处理或任务已执行结束
+	if !updated {
+		return
+	}
+	logger.Infof("核对任务日志#任务执行中断#任务ID-%d#taskLogId-%d", taskLog.TaskId, taskLog.Id)
+	if rerun {
+		task.rerunInterrupted(taskLog)
+	}
+}
+
+// 重新执行中断的任务, 工作流节点及重新执行时再次中断的任务不重新执行
+func (task Task) rerunInterrupted(taskLog models.TaskLog) {
+	if taskLog.WorkflowRunId > 0 || taskLog.TriggerType == models.TaskLogTriggerRerun {
+		return
+	}
+	taskModel := new(models.Task)
+	taskDetail, err := taskModel.Detail(taskLog.TaskId)
+	if err != nil || taskDetail.Id == 0 || taskDetail.RerunInterrupted != 1 {
+		return
+	}
+	scheduledTime := time.Time(taskLog.StartTime)
+	if taskLog.ScheduledTime != nil {
+		scheduledTime = time.Time(*taskLog.ScheduledTime)
+	}
+	override, err := taskLog.RunOverride()
+	if err != nil {
+		logger.Errorf("核对任务日志#解析覆盖参数失败#taskLogId-%d#%s", taskLog.Id, err)
+	}
+	logger.Infof("核对任务日志#重新执行中断的任务#任务ID-%d#taskLogId-%d", taskDetail.Id, taskLog.Id)
+	task.runWithTrigger(taskDetail, jobTrigger{Type: models.TaskLogTriggerRerun, ScheduledTime: scheduledTime, Override: override})
+}
+
+// 获取RPC任务日志执行中的主机, 按主机执行记录获取, 任务按标签选择主机或主机有变更时也是实际执行的主机
+func taskLogHosts(logs []models.TaskLog) map[int64][]models.TaskHostLog {
+	taskLogIds := make([]int64, 0, len(logs))
+	for _, taskLog := range logs {
+		if taskLog.Protocol == models.TaskRPC && taskLog.Status == models.Running {
+			taskLogIds = append(taskLogIds, taskLog.Id)
+		}
+	}
+	if len(taskLogIds) == 0 {
+		return nil
+	}
+	hostLogModel := new(models.TaskHostLog)
+	hostLogs, err := hostLogModel.RunningByTaskLogIds(taskLogIds)
+	if err != nil {
+		logger.Errorf("核对任务日志#获取主机执行记录失败#%s", err)
+		return nil
+	}
+	hosts := make(map[int64][]models.TaskHostLog)
+	for _, hostLog := range hostLogs {
+		hosts[hostLog.TaskLogId] = append(hosts[hostLog.TaskLogId], hostLog)
+	}
+
+	return hosts
+}
+
+// 向主机确认任务是否仍在执行, 任一主机仍在执行即为执行中
+// 没有执行中的主机记录时(如等待重试)无法确认
+func taskLogStates(logs []models.TaskLog, hosts map[int64][]models.TaskHostLog) map[int64]taskLogState {
+	type hostKey struct {
+		name string
+		port int
+	}
+	states := make(map[int64]taskLogState, len(logs))
+	hostLogIds := make(map[hostKey][]int64)
+	for _, taskLog := range logs {
+		taskHosts := hosts[taskLog.Id]
+		if taskLog.Protocol != models.TaskRPC || taskLog.Status != models.Running || len(taskHosts) == 0 {
+			states[taskLog.Id] = taskLogUnknown
+			continue
+		}
+		states[taskLog.Id] = taskLogStopped
+		for _, host := range taskHosts {
+			key := hostKey{host.Name, host.Port}
+			hostLogIds[key] = append(hostLogIds[key], taskLog.Id)
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for key, ids := range hostLogIds {
+		wg.Add(1)
+		go func(key hostKey, ids []int64) {
+			defer wg.Done()
+			runningIds, err := rpcRunningFunc(key.name, key.port, ids)
+			mu.Lock()
+			defer mu.Unlock()
+			// 无法连接的主机上任务已不在执行
+			if err != nil && !errors.Is(err, rpcClient.ErrUnavailable) {
+				logger.Warnf("核对任务日志#查询主机执行中的任务失败#主机-%s:%d#%s", key.name, key.port, err)
+				for _, id := range ids {
+					if states[id] != taskLogAlive {
+						states[id] = taskLogUnknown
+					}
+				}
+				return
+			}
+			for _, id := range runningIds {
+				states[id] = taskLogAlive
+			}
+		}(key, ids)
+	}
+	wg.Wait()
+
+	return states
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	rpcClient "github.com/gocronx-team/gocron/internal/modules/rpc/client"
+)
+
+func TestTaskLogStates(t *testing.T) {
+	originalRunning := rpcRunningFunc
+	defer func() { rpcRunningFunc = originalRunning }()
+	rpcRunningFunc = func(ip string, port int, ids []int64) ([]int64, error) {
+		switch ip {
+		case "alive":
+			return ids, nil
+		case "down":
+			return nil, rpcClient.ErrUnavailable
+		case "old":
+			return nil, errors.New("unknown method Running")
+		}
+		return nil, nil
+	}
+	host := func(name string) models.TaskHostLog {
+		return models.TaskHostLog{Name: name, Port: 5921, Status: models.Running}
+	}
+	// 按任务日志的主机执行记录确认, 与任务当前的主机无关
+	hosts := map[int64][]models.TaskHostLog{
+		1: {host("idle")},
+		2: {host("idle"), host("alive")},
+		3: {host("down")},
+		4: {host("old")},
+		5: {host("old"), host("alive")},
+		9: {host("alive")},
+	}
+	logs := []models.TaskLog{
+		{Id: 1, TaskId: 1, Protocol: models.TaskRPC, Status: models.Running},
+		{Id: 2, TaskId: 2, Protocol: models.TaskRPC, Status: models.Running},
+		{Id: 3, TaskId: 3, Protocol: models.TaskRPC, Status: models.Running},
+		{Id: 4, TaskId: 4, Protocol: models.TaskRPC, Status: models.Running},
+		{Id: 5, TaskId: 5, Protocol: models.TaskRPC, Status: models.Running},
+		{Id: 6, TaskId: 1, Protocol: models.TaskRPC, Status: models.Queued},
+		{Id: 7, TaskId: 6, Protocol: models.TaskHTTP, Status: models.Running},
+		{Id: 8, TaskId: 7, Protocol: models.TaskRPC, Status: models.Running},
+		{Id: 9, TaskId: 1, Protocol: models.TaskRPC, Status: models.Running},
+	}
+	expected := map[int64]taskLogState{
+		1: taskLogStopped,
+		2: taskLogAlive,
+		3: taskLogStopped,
+		4: taskLogUnknown,
+		5: taskLogAlive,
+		6: taskLogUnknown,
+		7: taskLogUnknown,
+		8: taskLogUnknown,
+		9: taskLogAlive,
+	}
+	states := taskLogStates(logs, hosts)
+	for id, state := range expected {
+		if states[id] != state {
+			t.Errorf("log %d: expected state %d, got %d", id, state, states[id])
+		}
+	}
+}
+
+func TestStartedBefore(t *testing.T) {
+	now := time.Now()
+	logs := []models.TaskLog{
+		{Id: 1, StartTime: models.LocalTime(now.Add(-2 * reconcileGracePeriod))},
+		{Id: 2, StartTime: models.LocalTime(now.Add(-reconcileGracePeriod / 2))},
+		{Id: 3, StartTime: models.LocalTime(now)},
+	}
+	result := startedBefore(logs, now.Add(-reconcileGracePeriod))
+	if len(result) != 1 || result[0].Id != 1 {
+		t.Fatalf("expected only log 1 outside grace period, got %+v", result)
+	}
+}
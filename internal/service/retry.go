@@ -0,0 +1,209 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	rpcClient "github.com/gocronx-team/gocron/internal/modules/rpc/client"
+	"github.com/gocronx-team/gocron/internal/modules/sshclient"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+)
+
+// 重试间隔默认基数, 未配置重试间隔时使用
+const defaultRetryInterval = time.Minute
+
+// 随机数函数, 测试时可替换
+var jitterFunc = rand.Float64
+
+// 带错误类型的任务执行错误, 多主机执行时包含每台失败主机的错误类型
+type taskError struct {
+	classes []string
+	err     error
+}
+
+func (e *taskError) Error() string {
+	return e.err.Error()
+}
+
+func (e *taskError) Unwrap() error {
+	return e.err
+}
+
+func newTaskError(err error, classes ...string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &taskError{classes: classes, err: err}
+}
+
+// 单次执行结果
+type TaskAttempt struct {
+	Attempt   int    `json:"attempt"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	Output    string `json:"output"`
+	Error     string `json:"error"`
+	ErrorType string `json:"error_type"`
+}
+
+func newTaskAttempt(attempt int, startTime time.Time, output string, err error) TaskAttempt {
+	taskAttempt := TaskAttempt{
+		Attempt:   attempt,
+		StartTime: startTime.Format(models.DefaultTimeFormat),
+		EndTime:   time.Now().Format(models.DefaultTimeFormat),
+		Output:    output,
+	}
+	if err != nil {
+		taskAttempt.Error = err.Error()
+		taskAttempt.ErrorType = strings.Join(taskErrorClasses(err), ",")
+	}
+
+	return taskAttempt
+}
+
+// 序列化每次执行结果, 未发生重试时返回空字符串
+func encodeTaskAttempts(attempts []TaskAttempt) string {
+	if len(attempts) <= 1 {
+		return ""
+	}
+	data, err := json.Marshal(attempts)
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
+
+// 获取错误类型, 未分类的错误为other
+func taskErrorClasses(err error) []string {
+	var te *taskError
+	if errors.As(err, &te) && len(te.classes) > 0 {
+		return te.classes
+	}
+
+	return []string{models.TaskErrorOther}
+}
+
+// RPC调用错误分类, 手动停止返回空字符串
+func classifyRPCError(err error) string {
+	var execErr *rpcClient.ExecError
+	switch {
+	case errors.Is(err, rpcClient.ErrCanceled):
+		return ""
+	case errors.Is(err, rpcClient.ErrUnavailable):
+		return models.TaskErrorUnavailable
+	case errors.Is(err, rpcClient.ErrTimeout):
+		return models.TaskErrorTimeout
+	case errors.As(err, &execErr):
+		return models.TaskErrorExit
+	}
+
+	return models.TaskErrorOther
+}
+
+// SSH执行错误分类
+func classifySSHError(err error) string {
+	var execErr *sshclient.ExecError
+	switch {
+	case errors.Is(err, sshclient.ErrCanceled):
+		return ""
+	case errors.Is(err, sshclient.ErrUnavailable):
+		return models.TaskErrorUnavailable
+	case errors.Is(err, sshclient.ErrTimeout):
+		return models.TaskErrorTimeout
+	case errors.As(err, &execErr):
+		return models.TaskErrorExit
+	}
+
+	return models.TaskErrorOther
+}
+
+// HTTP请求错误分类
+func classifyHTTPError(statusCode int, err error) string {
+	if statusCode == 0 {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return models.TaskErrorTimeout
+		}
+		if err != nil {
+			return models.TaskErrorUnavailable
+		}
+		return models.TaskErrorOther
+	}
+	if statusCode >= http.StatusInternalServerError {
+		return models.TaskErrorHTTP5xx
+	}
+	if statusCode >= http.StatusBadRequest {
+		return models.TaskErrorHTTP4xx
+	}
+
+	return models.TaskErrorOther
+}
+
+// 是否需要重试, 手动停止、命令模板渲染失败不重试, 未配置错误类型时所有失败都重试
+func shouldRetry(taskModel models.Task, err error) bool {
+	classes := taskErrorClasses(err)
+	for _, class := range classes {
+		if class == "" || class == models.TaskErrorTemplate {
+			return false
+		}
+	}
+	if taskModel.RetryOn == "" {
+		return true
+	}
+	retryOn := strings.Split(taskModel.RetryOn, ",")
+	for _, class := range classes {
+		if !utils.InStringSlice(retryOn, class) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// 计算第retry次重试前的等待时间
+func retryDelay(taskModel models.Task, retry int) time.Duration {
+	interval := time.Duration(taskModel.RetryInterval) * time.Second
+	if taskModel.RetryStrategy == models.TaskRetryDefault {
+		if interval > 0 {
+			return interval
+		}
+		// 默认重试间隔时间，每次递增1分钟
+		return time.Duration(retry) * time.Minute
+	}
+	if interval <= 0 {
+		interval = defaultRetryInterval
+	}
+
+	delay := interval
+	switch taskModel.RetryStrategy {
+	case models.TaskRetryLinear:
+		delay = interval * time.Duration(retry)
+	case models.TaskRetryExponential:
+		delay = interval
+		for i := 1; i < retry && delay < 24*time.Hour; i++ {
+			delay *= 2
+		}
+	}
+	if taskModel.RetryJitter > 0 {
+		// 在[-jitter%, +jitter%]范围内随机浮动
+		ratio := float64(taskModel.RetryJitter) / 100 * (jitterFunc()*2 - 1)
+		delay += time.Duration(float64(delay) * ratio)
+	}
+	maxInterval := time.Duration(taskModel.RetryMaxInterval) * time.Second
+	if maxInterval > 0 && delay > maxInterval {
+		delay = maxInterval
+	}
+	if delay < 0 {
+		delay = 0
+	}
+
+	return delay
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	rpcClient "github.com/gocronx-team/gocron/internal/modules/rpc/client"
+)
+
+func TestRetryDelayStrategies(t *testing.T) {
+	originalJitter := jitterFunc
+	defer func() { jitterFunc = originalJitter }()
+	jitterFunc = func() float64 { return 1 }
+
+	cases := []struct {
+		name     string
+		task     models.Task
+		retry    int
+		expected time.Duration
+	}{
+		{"default without interval", models.Task{}, 3, 3 * time.Minute},
+		{"default with interval", models.Task{RetryInterval: 10}, 3, 10 * time.Second},
+		{"fixed", models.Task{RetryStrategy: models.TaskRetryFixed, RetryInterval: 5}, 4, 5 * time.Second},
+		{"linear", models.Task{RetryStrategy: models.TaskRetryLinear, RetryInterval: 5}, 3, 15 * time.Second},
+		{"exponential", models.Task{RetryStrategy: models.TaskRetryExponential, RetryInterval: 2}, 4, 16 * time.Second},
+		{"exponential capped", models.Task{RetryStrategy: models.TaskRetryExponential, RetryInterval: 2, RetryMaxInterval: 10}, 4, 10 * time.Second},
+		{"exponential default base", models.Task{RetryStrategy: models.TaskRetryExponential}, 2, 2 * time.Minute},
+		{"jitter", models.Task{RetryStrategy: models.TaskRetryFixed, RetryInterval: 10, RetryJitter: 50}, 1, 15 * time.Second},
+		{"jitter capped", models.Task{RetryStrategy: models.TaskRetryFixed, RetryInterval: 10, RetryJitter: 50, RetryMaxInterval: 12}, 1, 12 * time.Second},
+	}
+	for _, item := range cases {
+		if delay := retryDelay(item.task, item.retry); delay != item.expected {
+			t.Fatalf("%s: expected %s, got %s", item.name, item.expected, delay)
+		}
+	}
+
+	jitterFunc = func() float64 { return 0 }
+	task := models.Task{RetryStrategy: models.TaskRetryFixed, RetryInterval: 10, RetryJitter: 50}
+	if delay := retryDelay(task, 1); delay != 5*time.Second {
+		t.Fatalf("expected negative jitter to shorten delay to 5s, got %s", delay)
+	}
+}
+
+func TestShouldRetryByErrorType(t *testing.T) {
+	unavailable := newTaskError(rpcClient.ErrUnavailable, classifyRPCError(rpcClient.ErrUnavailable))
+	exit := newTaskError(&rpcClient.ExecError{Message: "exit status 1"}, classifyRPCError(&rpcClient.ExecError{Message: "exit status 1"}))
+	canceled := newTaskError(rpcClient.ErrCanceled, classifyRPCError(rpcClient.ErrCanceled))
+	http5xx := newTaskError(errors.New("HTTP状态码非200-->502"), classifyHTTPError(http.StatusBadGateway, nil))
+	http4xx := newTaskError(errors.New("HTTP状态码非200-->404"), classifyHTTPError(http.StatusNotFound, nil))
+	mixed := newTaskError(rpcClient.ErrTimeout, models.TaskErrorTimeout, models.TaskErrorExit)
+
+	transient := models.Task{RetryOn: "unavailable,timeout,http_5xx"}
+	cases := []struct {
+		name     string
+		task     models.Task
+		err      error
+		expected bool
+	}{
+		{"all failures retried by default", models.Task{}, exit, true},
+		{"unclassified error retried by default", models.Task{}, errors.New("boom"), true},
+		{"manual stop never retried", models.Task{}, canceled, false},
+		{"unavailable is transient", transient, unavailable, true},
+		{"non-zero exit is not transient", transient, exit, false},
+		{"http 5xx is transient", transient, http5xx, true},
+		{"http 4xx is not transient", transient, http4xx, false},
+		{"any non-transient host stops retry", transient, mixed, false},
+		{"unclassified error not in list", transient, errors.New("boom"), false},
+	}
+	for _, item := range cases {
+		if actual := shouldRetry(item.task, item.err); actual != item.expected {
+			t.Fatalf("%s: expected %v, got %v", item.name, item.expected, actual)
+		}
+	}
+}
+
+func TestExecJobStopsOnNonRetryableError(t *testing.T) {
+	originalSleep := sleepFunc
+	defer func() { sleepFunc = originalSleep }()
+	sleepFunc = func(d time.Duration) {}
+
+	handler := &fakeHandler{
+		results: []handlerResponse{
+			{result: "first", err: newTaskError(rpcClient.ErrUnavailable, models.TaskErrorUnavailable)},
+			{result: "second", err: newTaskError(&rpcClient.ExecError{Message: "exit status 2"}, models.TaskErrorExit)},
+			{result: "third", err: nil},
+		},
+	}
+	task := models.Task{Id: 3, RetryTimes: 3, RetryOn: models.TaskErrorUnavailable}
+	result := execJob(handler, task, 1)
+	if result.Err == nil || result.Result != "second" {
+		t.Fatalf("expected to stop at non-retryable error, got %+v", result)
+	}
+	if handler.callCount != 2 || result.RetryTimes != 1 {
+		t.Fatalf("expected 2 calls and 1 retry, got calls=%d retries=%d", handler.callCount, result.RetryTimes)
+	}
+
+	var attempts []TaskAttempt
+	if err := json.Unmarshal([]byte(encodeTaskAttempts(result.Attempts)), &attempts); err != nil {
+		t.Fatalf("decode attempts failed: %v", err)
+	}
+	if len(attempts) != 2 || attempts[0].Output != "first" || attempts[0].ErrorType != models.TaskErrorUnavailable ||
+		attempts[1].Output != "second" || attempts[1].ErrorType != models.TaskErrorExit {
+		t.Fatalf("unexpected attempts %+v", attempts)
+	}
+	if encodeTaskAttempts(result.Attempts[:1]) != "" {
+		t.Fatal("expected single attempt not to be recorded")
+	}
+}
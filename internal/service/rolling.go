@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+)
+
+// 滚动执行
+// 按主机顺序每批在RollingBatch台主机上同时执行, 一批结束后间隔RollingPause秒执行下一批
+// 失败主机数(或比例)超过阈值时停止执行剩余的主机, 每批各主机的执行状态写入任务日志
+// 手动停止、被替换或回填取消时不再执行剩余的批次, 批次间的等待立即结束
+
+var (
+	// 滚动执行中的任务, 任务日志ID => 停止通道
+	rollingRuns sync.Map
+
+	rollingPauseFunc = waitRollingPause
+)
+
+func runRolling(taskModel models.Task, taskUniqueId int64) (string, error) {
+	hosts := taskModel.Hosts
+	batchSize := int(taskModel.RollingBatch)
+	batchCount := (len(hosts) + batchSize - 1) / batchSize
+	var output strings.Builder
+	results := make([]TaskResult, 0, len(hosts))
+	errorClasses := make([]string, 0)
+	failed := 0
+	cancel := make(chan struct{})
+	rollingRuns.Store(taskUniqueId, cancel)
+	defer rollingRuns.Delete(taskUniqueId)
+	stopped := func(remaining int) (string, error) {
+		logger.Infof("滚动执行手动停止#任务ID-%d#剩余主机数-%d", taskModel.Id, remaining)
+		output.WriteString(fmt.Sprintf("手动停止, 剩余的%d台主机不再执行\n", remaining))
+		return output.String(), newTaskError(fmt.Errorf("滚动执行手动停止, 剩余%d台主机未执行", remaining), "")
+	}
+	for batch, start := 1, 0; start < len(hosts); batch, start = batch+1, start+batchSize {
+		if start > 0 && taskModel.RollingPause > 0 {
+			logger.Infof("滚动执行#任务ID-%d#等待%d秒后执行第%d批", taskModel.Id, taskModel.RollingPause, batch)
+			if !rollingPauseFunc(time.Duration(taskModel.RollingPause)*time.Second, cancel) {
+				return stopped(len(hosts) - start)
+			}
+		}
+		end := start + batchSize
+		if end > len(hosts) {
+			end = len(hosts)
+		}
+		names := make([]string, 0, end-start)
+		for _, host := range hosts[start:end] {
+			names = append(names, host.Alias)
+		}
+		output.WriteString(fmt.Sprintf("批次 %d/%d: [%s]\n", batch, batchCount, strings.Join(names, ", ")))
+
+		batchFailed := 0
+		for i, taskResult := range execOnHosts(taskModel, taskUniqueId, hosts[start:end]) {
+			output.WriteString(hostResultLine(hosts[start+i], taskResult) + "\n")
+			results = append(results, taskResult)
+			if taskResult.Err != nil {
+				batchFailed++
+				errorClasses = append(errorClasses, taskErrorClasses(taskResult.Err)...)
+			}
+		}
+		failed += batchFailed
+		output.WriteString(fmt.Sprintf("批次 %d/%d 结束, 成功%d台, 失败%d台\n\n",
+			batch, batchCount, end-start-batchFailed, batchFailed))
+
+		remaining := len(hosts) - end
+		if remaining > 0 && rollingCanceled(cancel) {
+			return stopped(remaining)
+		}
+		if remaining > 0 && rollingFailExceeded(taskModel, failed, len(hosts)) {
+			logger.Warnf("滚动执行中止#任务ID-%d#失败主机数-%d#剩余主机数-%d", taskModel.Id, failed, remaining)
+			output.WriteString(fmt.Sprintf("失败主机数%d超过阈值, 停止执行剩余的%d台主机\n", failed, remaining))
+			return output.String(), newTaskError(fmt.Errorf("滚动执行中止, 失败主机数%d超过阈值", failed), errorClasses...)
+		}
+	}
+
+	return output.String(), hostsResultError(taskModel, results, len(hosts))
+}
+
+// 失败主机数是否超过阈值, 设置了失败比例时按比例判断
+func rollingFailExceeded(taskModel models.Task, failed, total int) bool {
+	if taskModel.RollingFailRate > 0 {
+		return failed*100 > int(taskModel.RollingFailRate)*total
+	}
+
+	return failed > int(taskModel.RollingMaxFail)
+}
+
+// 停止滚动执行, 不再执行剩余的批次, 任务不在滚动执行中时返回false
+func cancelRollingRun(taskLogId int64) bool {
+	cancel, ok := rollingRuns.LoadAndDelete(taskLogId)
+	if !ok {
+		return false
+	}
+	logger.Infof("停止滚动执行#taskLogId-%d", taskLogId)
+	close(cancel.(chan struct{}))
+
+	return true
+}
+
+func rollingCanceled(cancel <-chan struct{}) bool {
+	select {
+	case <-cancel:
+		return true
+	default:
+		return false
+	}
+}
+
+// 等待批次间隔, 等待中被停止时返回false
+func waitRollingPause(pause time.Duration, cancel <-chan struct{}) bool {
+	timer := time.NewTimer(pause)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return !rollingCanceled(cancel)
+	case <-cancel:
+		return false
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	rpcClient "github.com/gocronx-team/gocron/internal/modules/rpc/client"
+	pb "github.com/gocronx-team/gocron/internal/modules/rpc/proto"
+)
+
+func TestRunRolling(t *testing.T) {
+	stubHostLogs(t)
+	originalExec, originalPause := rpcExecFunc, rollingPauseFunc
+	defer func() { rpcExecFunc, rollingPauseFunc = originalExec, originalPause }()
+	var mu sync.Mutex
+	executed := make([]string, 0)
+	rpcExecFunc = func(ip string, port int, taskReq *pb.TaskRequest) (string, error) {
+		mu.Lock()
+		executed = append(executed, ip)
+		mu.Unlock()
+		if ip == "b" || ip == "c" {
+			return "", errors.New("exit status 1")
+		}
+		return "ok", nil
+	}
+	pauses := make([]time.Duration, 0)
+	rollingPauseFunc = func(d time.Duration, cancel <-chan struct{}) bool {
+		pauses = append(pauses, d)
+		return true
+	}
+
+	task := models.Task{Id: 1, Hosts: testHosts("a", "b", "c", "d", "e"), RollingBatch: 2, RollingPause: 30, RollingMaxFail: 1}
+	output, err := runRolling(task, 1)
+	// 第2批结束时失败2台, 超过阈值1, 不再执行e
+	if err == nil || len(executed) != 4 || strings.Contains(strings.Join(executed, ""), "e") {
+		t.Fatalf("expected rollout to stop after second batch, executed=%v err=%v", executed, err)
+	}
+	if len(pauses) != 1 || pauses[0] != 30*time.Second {
+		t.Fatalf("expected one pause between batches, got %v", pauses)
+	}
+	for _, expected := range []string{"批次 1/3: [a, b]", "批次 1/3 结束, 成功1台, 失败1台", "批次 2/3 结束, 成功1台, 失败1台", "停止执行剩余的1台主机"} {
+		if !strings.Contains(output, expected) {
+			t.Fatalf("expected %q in output:\n%s", expected, output)
+		}
+	}
+
+	// 按比例: 失败2/5=40%, 未超过50%
+	executed, pauses = executed[:0], pauses[:0]
+	task.RollingFailRate = 50
+	output, err = runRolling(task, 2)
+	if err == nil || len(executed) != 5 || strings.Contains(output, "停止执行") {
+		t.Fatalf("expected all batches to run, executed=%v output=%s", executed, output)
+	}
+	if len(pauses) != 2 {
+		t.Fatalf("expected two pauses, got %v", pauses)
+	}
+}
+
+func TestRunRollingStop(t *testing.T) {
+	stubHostLogs(t)
+	originalExec := rpcExecFunc
+	defer func() { rpcExecFunc = originalExec }()
+	executed := make([]string, 0)
+	rpcExecFunc = func(ip string, port int, taskReq *pb.TaskRequest) (string, error) {
+		executed = append(executed, ip)
+		return "ok", nil
+	}
+
+	task := models.Task{Id: 1, Hosts: testHosts("a", "b", "c"), RollingBatch: 1, RollingPause: 3600}
+	done := make(chan error)
+	go func() {
+		_, err := runRolling(task, 1)
+		done <- err
+	}()
+	// 批次间等待时停止, 不再执行剩余的批次
+	for !cancelRollingRun(1) {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case err := <-done:
+		if err == nil || shouldRetry(task, err) || strings.Join(executed, "") != "a" {
+			t.Fatalf("expected rollout to stop after first batch, executed=%v err=%v", executed, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected pause to be interrupted")
+	}
+
+	// 批次执行中停止, 没有等待间隔时同样不再执行下一批
+	executed = executed[:0]
+	task.RollingPause = 0
+	rpcExecFunc = func(ip string, port int, taskReq *pb.TaskRequest) (string, error) {
+		executed = append(executed, ip)
+		stopInstanceRun(&instanceRun{taskLogId: 2, protocol: models.TaskRPC})
+		return "", rpcClient.ErrCanceled
+	}
+	output, err := runRolling(task, 2)
+	if err == nil || strings.Join(executed, "") != "a" || !strings.Contains(output, "剩余的2台主机不再执行") {
+		t.Fatalf("expected rollout to stop, executed=%v output=%s err=%v", executed, output, err)
+	}
+}
+
+func TestRollingFailExceeded(t *testing.T) {
+	cases := []struct {
+		maxFail  int16
+		failRate int8
+		failed   int
+		total    int
+		exceeded bool
+	}{
+		{0, 0, 0, 10, false},
+		{0, 0, 1, 10, true},
+		{2, 0, 2, 10, false},
+		{2, 0, 3, 10, true},
+		{0, 20, 2, 10, false},
+		{0, 20, 3, 10, true},
+		{5, 10, 2, 10, true},
+	}
+	for _, c := range cases {
+		task := models.Task{RollingMaxFail: c.maxFail, RollingFailRate: c.failRate}
+		if exceeded := rollingFailExceeded(task, c.failed, c.total); exceeded != c.exceeded {
+			t.Fatalf("case %+v expected exceeded=%v", c, c.exceeded)
+		}
+	}
+}
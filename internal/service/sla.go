@@ -0,0 +1,163 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+	"github.com/gocronx-team/gocron/internal/modules/notify"
+)
+
+// SLA监控
+// 任务可配置预期最长执行时间、每天最晚完成时间, 调度节点定期检查任务日志
+// 执行时间超出预期、到达最晚完成时间仍未执行成功时, 通过任务配置的通知方式发送通知
+// 当天没有需要执行的时间点(如被日历阻止)时不检查最晚完成时间, 服务停止期间到达的最晚完成时间不检查
+
+// SLA检查间隔
+const slaCheckInterval = time.Minute
+
+// 定期检查SLA, 高可用模式下只在调度节点上检查
+func (task Task) runSlaChecker() {
+	last := time.Now()
+	ticker := time.NewTicker(slaCheckInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		if task.IsScheduler() {
+			task.checkSla(last, now)
+		}
+		last = now
+	}
+}
+
+// 检查执行中的任务是否超时, 最晚完成时间在(from, to]内的任务是否已执行成功
+func (task Task) checkSla(from, to time.Time) {
+	taskModel := new(models.Task)
+	tasks, err := taskModel.SlaList()
+	if err != nil {
+		logger.Errorf("SLA检查#获取任务列表失败#%s", err)
+		return
+	}
+	durationTasks := make(map[int]models.Task)
+	taskIds := make([]int, 0)
+	for _, item := range tasks {
+		if item.SlaMaxDuration > 0 {
+			durationTasks[item.Id] = item
+			taskIds = append(taskIds, item.Id)
+		}
+		if item.SlaDeadline != "" && item.Status == models.Enabled {
+			checkSlaDeadline(item, from, to)
+		}
+	}
+
+	taskLogModel := new(models.TaskLog)
+	logs, err := taskLogModel.SlaRunningList(taskIds)
+	if err != nil {
+		logger.Errorf("SLA检查#获取执行中的任务日志失败#%s", err)
+		return
+	}
+	for _, taskLog := range logs {
+		checkSlaDuration(durationTasks[taskLog.TaskId], taskLog.Id, to.Sub(time.Time(taskLog.StartTime)))
+	}
+}
+
+// 执行时间超出预期时发送通知, 每条任务日志只通知一次
+func checkSlaDuration(taskModel models.Task, taskLogId int64, elapsed time.Duration) {
+	maxDuration := time.Duration(taskModel.SlaMaxDuration) * time.Second
+	if maxDuration <= 0 || elapsed <= maxDuration {
+		return
+	}
+	taskLogModel := new(models.TaskLog)
+	updated, err := taskLogModel.MarkSlaNotified(taskLogId)
+	if err != nil {
+		logger.Errorf("SLA检查#更新任务日志失败#taskLogId-%d#%s", taskLogId, err)
+		return
+	}
+	if !updated {
+		return
+	}
+	sendSlaNotification(taskModel, fmt.Sprintf("任务执行时间超过预期, 已执行%s, 预期最长执行时间%s, 任务日志ID-%d",
+		elapsed.Truncate(time.Second), maxDuration, taskLogId))
+}
+
+// 最晚完成时间在(from, to]内且当天未执行成功时发送通知
+func checkSlaDeadline(taskModel models.Task, from, to time.Time) {
+	deadline, ok := slaDeadlineBetween(taskModel, from, to)
+	if !ok {
+		return
+	}
+	location := deadline.Location()
+	dayStart := time.Date(deadline.Year(), deadline.Month(), deadline.Day(), 0, 0, 0, 0, location)
+	if !expectedToRunBefore(taskModel, dayStart, deadline) {
+		return
+	}
+	taskLogModel := new(models.TaskLog)
+	finished, err := taskLogModel.FinishedBetween(taskModel.Id, dayStart, deadline)
+	if err != nil {
+		logger.Errorf("SLA检查#获取任务执行记录失败#任务ID-%d#%s", taskModel.Id, err)
+		return
+	}
+	if finished {
+		return
+	}
+	sendSlaNotification(taskModel, fmt.Sprintf("任务在%s前未执行成功", deadline.Format("2006-01-02 15:04")))
+}
+
+// 返回落在(from, to]内的最晚完成时间, 以任务时区表示
+func slaDeadlineBetween(taskModel models.Task, from, to time.Time) (time.Time, bool) {
+	clock, err := time.Parse(models.SlaDeadlineFormat, taskModel.SlaDeadline)
+	if err != nil {
+		return time.Time{}, false
+	}
+	location, err := loadTaskLocation(taskModel.Timezone)
+	if err != nil {
+		location = time.Local
+	}
+	// 检查间隔跨过零点时, 前一天和当天的最晚完成时间都可能落在区间内
+	for _, t := range []time.Time{from.In(location), to.In(location)} {
+		deadline := time.Date(t.Year(), t.Month(), t.Day(), clock.Hour(), clock.Minute(), 0, 0, location)
+		if deadline.After(from) && !deadline.After(to) {
+			return deadline, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// 任务当天在最晚完成时间前是否有需要执行的时间点, 子任务由父任务触发, 始终需要执行
+func expectedToRunBefore(taskModel models.Task, dayStart, deadline time.Time) bool {
+	if taskModel.Level != models.TaskLevelParent {
+		return true
+	}
+	schedule, err := parseTaskSchedule(taskModel)
+	if err != nil {
+		return false
+	}
+	next := schedule.Next(dayStart.Add(-time.Second))
+	if next.IsZero() || next.After(deadline) {
+		return false
+	}
+
+	return calendarBlockedBy(taskModel, next) == ""
+}
+
+// 通过任务配置的通知方式发送SLA告警, 任务未开启通知时只记录日志
+func sendSlaNotification(taskModel models.Task, output string) {
+	logger.Warnf("SLA告警#任务ID-%d#名称-%s#%s", taskModel.Id, taskModel.Name, output)
+	if taskModel.NotifyStatus == 0 {
+		return
+	}
+	if taskModel.NotifyType != 3 && taskModel.NotifyReceiverId == "" {
+		return
+	}
+	msg := notify.Message{
+		"task_type":        taskModel.NotifyType,
+		"task_receiver_id": taskModel.NotifyReceiverId,
+		"name":             taskModel.Name,
+		"output":           output,
+		"status":           "SLA告警",
+		"task_id":          taskModel.Id,
+		"remark":           taskModel.Remark,
+	}
+	notifyPushFunc(msg)
+}
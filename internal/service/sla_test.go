@@ -0,0 +1,75 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/notify"
+)
+
+func TestSlaDeadlineBetween(t *testing.T) {
+	task := models.Task{SlaDeadline: "06:30", Timezone: "Asia/Shanghai"}
+	location, _ := time.LoadLocation("Asia/Shanghai")
+	expected := time.Date(2024, 3, 2, 6, 30, 0, 0, location)
+
+	deadline, ok := slaDeadlineBetween(task, expected.Add(-time.Minute), expected)
+	if !ok || !deadline.Equal(expected) {
+		t.Fatalf("expected deadline %s, got %s ok=%v", expected, deadline, ok)
+	}
+	if _, ok = slaDeadlineBetween(task, expected, expected.Add(time.Minute)); ok {
+		t.Fatal("expected deadline at window start to be excluded")
+	}
+
+	// 检查间隔跨过零点时使用前一天的最晚完成时间
+	task.SlaDeadline = "23:59"
+	from := time.Date(2024, 3, 1, 23, 58, 30, 0, location)
+	deadline, ok = slaDeadlineBetween(task, from, from.Add(time.Minute))
+	if !ok || deadline.Day() != 1 {
+		t.Fatalf("expected deadline on previous day, got %s ok=%v", deadline, ok)
+	}
+}
+
+func TestExpectedToRunBefore(t *testing.T) {
+	dayStart := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	deadline := dayStart.Add(6 * time.Hour)
+	task := models.Task{Level: models.TaskLevelParent, Spec: "0 0 5 * * *"}
+	if !expectedToRunBefore(task, dayStart, deadline) {
+		t.Fatal("expected task scheduled at 05:00 to run before 06:00")
+	}
+	task.Spec = "0 0 7 * * *"
+	if expectedToRunBefore(task, dayStart, deadline) {
+		t.Fatal("expected task scheduled at 07:00 not to be checked")
+	}
+	task.Spec = "0 0 5 * * *"
+	task.Calendars = []models.TaskCalendarDetail{{
+		TaskCalendar: models.TaskCalendar{Mode: models.TaskCalendarExclude},
+		Name:         "holiday",
+		Dates:        []models.CalendarDate{{StartDate: "2024-03-01", EndDate: "2024-03-01"}},
+	}}
+	if expectedToRunBefore(task, dayStart, deadline) {
+		t.Fatal("expected task blocked by calendar not to be checked")
+	}
+	child := models.Task{Level: models.TaskLevelChild}
+	if !expectedToRunBefore(child, dayStart, deadline) {
+		t.Fatal("expected child task to be checked")
+	}
+}
+
+func TestSendSlaNotification(t *testing.T) {
+	original := notifyPushFunc
+	defer func() { notifyPushFunc = original }()
+	var messages []notify.Message
+	notifyPushFunc = func(msg notify.Message) {
+		messages = append(messages, msg)
+	}
+
+	sendSlaNotification(models.Task{Id: 1, NotifyStatus: 0}, "overrun")
+	if len(messages) != 0 {
+		t.Fatal("expected no notification when task notification is disabled")
+	}
+	sendSlaNotification(models.Task{Id: 1, Name: "report", NotifyStatus: 1, NotifyType: 3}, "overrun")
+	if len(messages) != 1 || messages[0]["output"] != "overrun" || messages[0]["status"] != "SLA告警" {
+		t.Fatalf("unexpected messages %+v", messages)
+	}
+}
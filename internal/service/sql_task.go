@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+)
+
+// SQL任务
+// 在数据源上按顺序执行语句, 所有语句使用同一个连接, 任一语句失败时停止执行
+// 查询语句记录返回行数及前几行数据, 其他语句记录影响行数
+// 执行中的SQL任务按任务日志ID记录取消函数, 手动停止、单实例替换时取消正在执行的语句
+
+// 未设置超时时间时的默认值
+const SqlExecTimeout = 300
+
+const (
+	sqlPreviewRows = 20   // 查询结果最多记录的行数
+	sqlPreviewSize = 4096 // 每条语句的查询结果最多记录的字节数
+	sqlSummarySize = 80   // 执行结果中语句摘要的最大长度
+)
+
+// 测试数据源连接的超时时间
+const datasourcePingTimeout = 10 * time.Second
+
+var (
+	sqlDatasourceFunc = new(models.Datasource).Detail
+	sqlOpenFunc       = func(ds models.Datasource) (*sql.DB, error) {
+		return ds.Open()
+	}
+
+	// 执行中的SQL任务, 任务日志ID => 取消函数
+	sqlRuns sync.Map
+)
+
+// 返回数据的语句, 按查询执行并记录结果, 其他语句记录影响行数
+var sqlQueryKeywords = []string{"select", "with", "show", "explain", "describe", "desc", "pragma", "values", "table"}
+
+type SQLHandler struct{}
+
+func (h *SQLHandler) Run(taskModel models.Task, taskUniqueId int64) (string, error) {
+	def := taskModel.Sql
+	if def == nil {
+		return "", newTaskError(errors.New("任务未配置SQL语句"), models.TaskErrorOther)
+	}
+	ds, err := sqlDatasourceFunc(def.DatasourceId)
+	if err != nil || ds.Id == 0 {
+		return "", newTaskError(fmt.Errorf("数据源不存在#ID-%d", def.DatasourceId), models.TaskErrorOther)
+	}
+	statementsTask := taskModel
+	statementsTask.Command = def.Statements
+	text, err := renderCommand(statementsTask, taskUniqueId, nil)
+	if err != nil {
+		return "", err
+	}
+	statements := SplitSqlStatements(text)
+	if len(statements) == 0 {
+		return "", newTaskError(errors.New("任务未配置SQL语句"), models.TaskErrorOther)
+	}
+	timeout := taskModel.Timeout
+	if timeout <= 0 {
+		timeout = SqlExecTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+	sqlRuns.Store(taskUniqueId, cancel)
+	defer sqlRuns.Delete(taskUniqueId)
+
+	logger.Infof("SQL任务开始执行#任务ID-%d#数据源-%s#语句数量-%d", taskModel.Id, ds.Name, len(statements))
+	db, err := sqlOpenFunc(ds)
+	if err != nil {
+		return "", newTaskError(fmt.Errorf("连接数据源失败: %s", err), models.TaskErrorUnavailable)
+	}
+	defer db.Close()
+	// 语句使用同一个连接, SET等会话设置对后续语句生效
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return "", newTaskError(fmt.Errorf("连接数据源失败: %s", sqlContextError(ctx, err)), models.TaskErrorUnavailable)
+	}
+	defer conn.Close()
+
+	var output strings.Builder
+	for i, statement := range statements {
+		fmt.Fprintf(&output, "[%d] %s\n", i+1, sqlSummary(statement, sqlSummarySize))
+		if !isSqlQuery(statement) {
+			result, err := conn.ExecContext(ctx, statement)
+			if err != nil {
+				return output.String(), sqlStatementError(ctx, i, err)
+			}
+			affected, err := result.RowsAffected()
+			if err != nil {
+				output.WriteString("执行成功\n")
+			} else {
+				fmt.Fprintf(&output, "影响行数: %d\n", affected)
+			}
+			continue
+		}
+		rows, err := conn.QueryContext(ctx, statement)
+		if err != nil {
+			return output.String(), sqlStatementError(ctx, i, err)
+		}
+		count, err := writeSqlRows(&output, rows)
+		if err != nil {
+			return output.String(), sqlStatementError(ctx, i, err)
+		}
+		fmt.Fprintf(&output, "返回行数: %d\n", count)
+		if def.FailOnRows == 1 && count > 0 {
+			return output.String(), newTaskError(fmt.Errorf("第%d条语句返回%d行数据", i+1, count), models.TaskErrorAssert)
+		}
+	}
+
+	return output.String(), nil
+}
+
+// 取消执行中的SQL任务, 任务不在本节点执行时返回false
+func cancelSqlRun(taskLogId int64) bool {
+	cancel, ok := sqlRuns.Load(taskLogId)
+	if !ok {
+		return false
+	}
+	logger.Infof("停止执行中的SQL任务#taskLogId-%d", taskLogId)
+	cancel.(context.CancelFunc)()
+
+	return true
+}
+
+// 执行超时时返回超时错误, 驱动返回的错误可能不包含超时原因
+func sqlContextError(ctx context.Context, err error) error {
+	if ctx.Err() != nil && !errors.Is(err, ctx.Err()) {
+		return fmt.Errorf("%w: %s", ctx.Err(), err)
+	}
+
+	return err
+}
+
+func sqlStatementError(ctx context.Context, index int, err error) error {
+	err = sqlContextError(ctx, err)
+	class := models.TaskErrorExit
+	if errors.Is(err, context.DeadlineExceeded) {
+		class = models.TaskErrorTimeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return newTaskError(fmt.Errorf("第%d条语句执行被手动停止", index+1), class)
+	}
+
+	return newTaskError(fmt.Errorf("第%d条语句执行失败: %s", index+1, err), class)
+}
+
+// 记录查询结果, 返回总行数, 超出限制的行只计数
+func writeSqlRows(output *strings.Builder, rows *sql.Rows) (int, error) {
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	var preview strings.Builder
+	preview.WriteString(strings.Join(columns, "\t") + "\n")
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	count := 0
+	truncated := false
+	for rows.Next() {
+		count++
+		if count > sqlPreviewRows || truncated {
+			continue
+		}
+		if err = rows.Scan(pointers...); err != nil {
+			return count, err
+		}
+		fields := make([]string, len(values))
+		for i, value := range values {
+			fields[i] = formatSqlValue(value)
+		}
+		line := strings.Join(fields, "\t") + "\n"
+		if preview.Len()+len(line) > sqlPreviewSize {
+			truncated = true
+			continue
+		}
+		preview.WriteString(line)
+	}
+	if err = rows.Err(); err != nil {
+		return count, err
+	}
+	if count > 0 {
+		output.WriteString(preview.String())
+	}
+	if count > sqlPreviewRows || truncated {
+		output.WriteString("...\n")
+	}
+
+	return count, nil
+}
+
+func formatSqlValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(models.DefaultTimeFormat)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// 语句是否返回数据
+func isSqlQuery(statement string) bool {
+	statement = strings.TrimLeft(stripSqlComments(statement), " \t\r\n(")
+	end := strings.IndexFunc(statement, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	})
+	if end >= 0 {
+		statement = statement[:end]
+	}
+	keyword := strings.ToLower(statement)
+	for _, item := range sqlQueryKeywords {
+		if keyword == item {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 去掉语句开头的注释
+func stripSqlComments(statement string) string {
+	for {
+		statement = strings.TrimSpace(statement)
+		switch {
+		case strings.HasPrefix(statement, "--"):
+			end := strings.Index(statement, "\n")
+			if end < 0 {
+				return ""
+			}
+			statement = statement[end+1:]
+		case strings.HasPrefix(statement, "/*"):
+			end := strings.Index(statement, "*/")
+			if end < 0 {
+				return ""
+			}
+			statement = statement[end+2:]
+		default:
+			return statement
+		}
+	}
+}
+
+// PingDatasource 测试数据源连接
+func PingDatasource(ds models.Datasource) error {
+	db, err := sqlOpenFunc(ds)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), datasourcePingTimeout)
+	defer cancel()
+
+	return db.PingContext(ctx)
+}
+
+// SplitSqlStatements 按分号拆分语句, 忽略引号及注释中的分号, 去掉空语句
+func SplitSqlStatements(text string) []string {
+	statements := make([]string, 0)
+	var quote rune
+	inLineComment, inBlockComment := false, false
+	start := 0
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		switch {
+		case inLineComment:
+			inLineComment = r != '\n'
+		case inBlockComment:
+			if r == '*' && next == '/' {
+				inBlockComment = false
+				i++
+			}
+		case quote != 0:
+			if r == '\\' && quote != '`' {
+				i++
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '-' && next == '-':
+			inLineComment = true
+			i++
+		case r == '/' && next == '*':
+			inBlockComment = true
+			i++
+		case r == ';':
+			statements = appendSqlStatement(statements, string(runes[start:i]))
+			start = i + 1
+		}
+	}
+	if start < len(runes) {
+		statements = appendSqlStatement(statements, string(runes[start:]))
+	}
+
+	return statements
+}
+
+func appendSqlStatement(statements []string, statement string) []string {
+	statement = strings.TrimSpace(statement)
+	if stripSqlComments(statement) == "" {
+		return statements
+	}
+
+	return append(statements, statement)
+}
+
+// ValidateSqlStatements 校验SQL任务的语句
+func ValidateSqlStatements(text string) error {
+	if len(SplitSqlStatements(text)) == 0 {
+		return errors.New("SQL语句不能为空")
+	}
+
+	return ValidateCommandTemplate(text)
+}
+
+// SqlCommand SQL任务的命令, 为语句的摘要
+func SqlCommand(text string) string {
+	return sqlSummary(text, maxCommandLength)
+}
+
+// 合并空白字符, 超出长度时截断
+func sqlSummary(statement string, limit int) string {
+	summary := strings.Join(strings.Fields(statement), " ")
+	if utf8.RuneCountInString(summary) <= limit {
+		return summary
+	}
+
+	return string([]rune(summary)[:limit-3]) + "..."
+}
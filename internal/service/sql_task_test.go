@@ -0,0 +1,139 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+// 创建临时的sqlite数据源, 替代数据源查询
+func stubSqlDatasource(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "report.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec("CREATE TABLE orders (id INTEGER PRIMARY KEY, status TEXT, note TEXT);" +
+		"INSERT INTO orders (status, note) VALUES ('paid', 'a;b'), ('pending', NULL), ('pending', 'c')")
+	_ = db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := sqlDatasourceFunc
+	t.Cleanup(func() { sqlDatasourceFunc = original })
+	sqlDatasourceFunc = func(id int) (models.Datasource, error) {
+		if id != 1 {
+			return models.Datasource{}, errors.New("record not found")
+		}
+		return models.Datasource{Id: 1, Name: "report", Engine: "sqlite", Database: path}, nil
+	}
+
+	return path
+}
+
+func sqlTask(statements string, failOnRows int8) models.Task {
+	return models.Task{Id: 1, Protocol: models.TaskSQL, Sql: &models.TaskSql{DatasourceId: 1, Statements: statements, FailOnRows: failOnRows}}
+}
+
+func TestSQLHandlerRun(t *testing.T) {
+	stubSqlDatasource(t)
+	handler := new(SQLHandler)
+
+	output, err := handler.Run(sqlTask(`
+		-- 清理过期订单
+		UPDATE orders SET status = 'expired' WHERE status = 'pending';
+		SELECT id, status, note FROM orders WHERE note LIKE '%;%' OR note IS NULL ORDER BY id;`, 0), 1)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for _, expected := range []string{"影响行数: 2", "id\tstatus\tnote\n1\tpaid\ta;b\n2\texpired\tNULL\n", "返回行数: 2"} {
+		if !strings.Contains(output, expected) {
+			t.Fatalf("expected %q in output %q", expected, output)
+		}
+	}
+
+	// 查询返回数据时执行失败, 后续语句不再执行
+	output, err = handler.Run(sqlTask("SELECT id FROM orders WHERE status = 'expired'; DELETE FROM orders", 1), 2)
+	if err == nil || taskErrorClasses(err)[0] != models.TaskErrorAssert || strings.Contains(output, "[2]") {
+		t.Fatalf("expected assert error, got %q err=%v", output, err)
+	}
+	if _, err = handler.Run(sqlTask("SELECT id FROM orders WHERE status = 'unknown'", 1), 3); err != nil {
+		t.Fatalf("expected empty result to succeed, got %v", err)
+	}
+
+	output, err = handler.Run(sqlTask("UPDATE orders SET status = 'done'; SELECT * FROM missing; DELETE FROM orders", 0), 4)
+	if err == nil || taskErrorClasses(err)[0] != models.TaskErrorExit || !strings.Contains(err.Error(), "第2条语句") ||
+		strings.Contains(output, "[3]") {
+		t.Fatalf("expected statement error, got %q err=%v", output, err)
+	}
+
+	// 语句支持命令模板
+	task := sqlTask(`SELECT '${{ .ScheduledTime.Format "2006-01-02" }}' AS day`, 0)
+	task.RunScheduledTime = time.Date(2024, 5, 6, 1, 0, 0, 0, time.Local)
+	if output, err = handler.Run(task, 5); err != nil || !strings.Contains(output, "day\n2024-05-06\n") {
+		t.Fatalf("unexpected template output %q err=%v", output, err)
+	}
+
+	task = sqlTask("SELECT 1", 0)
+	task.Sql.DatasourceId = 2
+	if _, err = handler.Run(task, 6); err == nil {
+		t.Fatal("expected missing datasource error")
+	}
+	if _, err = handler.Run(models.Task{Protocol: models.TaskSQL}, 7); err == nil {
+		t.Fatal("expected missing definition error")
+	}
+}
+
+func TestSQLHandlerPreviewLimit(t *testing.T) {
+	stubSqlDatasource(t)
+	statement := "WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 100) SELECT i FROM n"
+	output, err := new(SQLHandler).Run(sqlTask(statement, 0), 1)
+	if err != nil || !strings.Contains(output, "\n20\n...\n返回行数: 100") || strings.Contains(output, "\n21\n") {
+		t.Fatalf("expected bounded preview, got %q err=%v", output, err)
+	}
+}
+
+func TestSQLHandlerCancel(t *testing.T) {
+	stubSqlDatasource(t)
+	go func() {
+		for !cancelSqlRun(8) {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	statement := "WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n) SELECT count(*) FROM n"
+	_, err := new(SQLHandler).Run(sqlTask(statement, 0), 8)
+	if err == nil || !strings.Contains(err.Error(), "手动停止") {
+		t.Fatalf("expected canceled error, got %v", err)
+	}
+	if cancelSqlRun(8) {
+		t.Fatal("expected finished run to be unregistered")
+	}
+}
+
+func TestSplitSqlStatements(t *testing.T) {
+	statements := SplitSqlStatements(`
+		UPDATE a SET note = 'x;y', other = "it\"s;" ; -- comment; here
+		/* block; comment */
+		;
+		SELECT 1`)
+	// 只有注释的语句被忽略
+	if len(statements) != 2 || statements[0] != `UPDATE a SET note = 'x;y', other = "it\"s;"` || statements[1] != "SELECT 1" {
+		t.Fatalf("unexpected statements %q", statements)
+	}
+	if isSqlQuery(statements[0]) || !isSqlQuery("/* report */ -- daily\n select 1") || !isSqlQuery("(select 1) union (select 2)") {
+		t.Fatal("unexpected query detection")
+	}
+	if err := ValidateSqlStatements(" -- only comment\n ; "); err == nil {
+		t.Fatal("expected error for empty statements")
+	}
+	if command := SqlCommand("SELECT\n  1;\n" + strings.Repeat("x", 300)); len(command) != maxCommandLength ||
+		!strings.HasPrefix(command, "SELECT 1; x") {
+		t.Fatalf("unexpected command %q", command)
+	}
+}
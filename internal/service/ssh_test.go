@@ -0,0 +1,77 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	pb "github.com/gocronx-team/gocron/internal/modules/rpc/proto"
+	"github.com/gocronx-team/gocron/internal/modules/sshclient"
+)
+
+func TestSSHHandlerRun(t *testing.T) {
+	hostLogs := stubHostLogs(t)
+	originalExec, originalHost := sshExecFunc, sshHostFunc
+	defer func() { sshExecFunc, sshHostFunc = originalExec, originalHost }()
+	sshHostFunc = func(id int16) (models.Host, error) {
+		host := models.Host{Id: id, Name: string(rune('a' + id - 1)), SshPort: 2200 + int(id), SshUser: "deploy",
+			SshPass: "secret", SshKey: "key", HostKey: "ssh-ed25519 AAAA"}
+		if id == 2 {
+			host.SshAuth = models.HostSshAuthKey
+		}
+		return host, nil
+	}
+	configs := make(map[string]sshclient.Config)
+	sshExecFunc = func(cfg sshclient.Config, taskReq *pb.TaskRequest) (string, error) {
+		configs[cfg.Host] = cfg
+		if cfg.Host == "c" {
+			return "", sshclient.ErrTimeout
+		}
+		return "done on " + cfg.Host, nil
+	}
+
+	task := models.Task{Id: 1, Protocol: models.TaskSSH, Hosts: testHosts("a", "b", "c"), Command: "uptime"}
+	handler := new(SSHHandler)
+	output, err := handler.Run(task, 1)
+	// 多台主机执行时任务日志只记录各主机的状态
+	if err == nil || !strings.Contains(output, "[a] 执行成功\n[b] 执行成功\n[c] 执行失败") || strings.Contains(output, "done on") {
+		t.Fatalf("unexpected output %q err=%v", output, err)
+	}
+	if classes := taskErrorClasses(err); len(classes) != 1 || classes[0] != models.TaskErrorTimeout {
+		t.Fatalf("expected timeout class, got %v", classes)
+	}
+	// 按主机保存的认证方式连接, 密码认证时不使用私钥
+	if configs["a"].Port != 2201 || configs["a"].PrivateKey != "" || configs["a"].Password != "secret" {
+		t.Fatalf("unexpected password config %+v", configs["a"])
+	}
+	if configs["b"].PrivateKey != "key" || configs["b"].User != "deploy" || configs["b"].HostKey != "ssh-ed25519 AAAA" {
+		t.Fatalf("unexpected key config %+v", configs["b"])
+	}
+	if logs := hostLogs(); len(logs) != 3 {
+		t.Fatalf("expected a host log per host, got %+v", logs)
+	}
+
+	sshHostFunc = func(id int16) (models.Host, error) {
+		return models.Host{}, errors.New("record not found")
+	}
+	task.Hosts = testHosts("a")
+	if _, err = handler.Run(task, 2); err == nil || !strings.Contains(err.Error(), "获取主机信息失败") {
+		t.Fatalf("expected host lookup error, got %v", err)
+	}
+}
+
+func TestClassifySSHError(t *testing.T) {
+	cases := map[error]string{
+		sshclient.ErrTimeout:                     models.TaskErrorTimeout,
+		sshclient.ErrCanceled:                    "",
+		sshclient.ErrUnavailable:                 models.TaskErrorUnavailable,
+		&sshclient.ExecError{Message: "exit 1"}:  models.TaskErrorExit,
+		errors.New("ssh: handshake failed: EOF"): models.TaskErrorOther,
+	}
+	for err, class := range cases {
+		if got := classifySSHError(err); got != class {
+			t.Fatalf("expected %s for %v, got %s", class, err, got)
+		}
+	}
+}
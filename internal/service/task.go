@@ -1,6 +1,7 @@
 package service
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -17,7 +18,7 @@ import (
 	"github.com/gocronx-team/gocron/internal/modules/notify"
 	rpcClient "github.com/gocronx-team/gocron/internal/modules/rpc/client"
 	pb "github.com/gocronx-team/gocron/internal/modules/rpc/proto"
-	"github.com/gocronx-team/gocron/internal/modules/utils"
+	"github.com/gocronx-team/gocron/internal/modules/sshclient"
 )
 
 var (
@@ -25,11 +26,16 @@ var (
 )
 
 var (
+	rpcExecFunc        = rpcClient.Exec
+	sshExecFunc        = sshclient.Exec
+	sshHostFunc        = findHost
 	httpGetFunc        = httpclient.Get
 	httpPostParamsFunc = httpclient.PostParams
 	notifyPushFunc     = notify.Push
 	sleepFunc          = time.Sleep
 
+	updateOrphanedTaskLogFunc = updateOrphanedTaskLog
+
 	// 定时任务调度管理器
 	serviceCron *cron.Cron
 
@@ -41,6 +47,9 @@ var (
 
 	// 并发队列, 限制同时运行的任务数量
 	concurrencyQueue ConcurrencyQueue
+
+	// 高可用模式下的调度节点选举, 未开启时为nil
+	elector *leaderElector
 )
 
 // 并发队列
@@ -48,8 +57,24 @@ type ConcurrencyQueue struct {
 	queue chan struct{}
 }
 
-func (cq *ConcurrencyQueue) Add() {
-	cq.queue <- struct{}{}
+// 队列未满时加入, 否则立即返回false
+func (cq *ConcurrencyQueue) TryAdd() bool {
+	select {
+	case cq.queue <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// 等待加入队列, cancel关闭时放弃等待并返回false
+func (cq *ConcurrencyQueue) AddOrCancel(cancel <-chan struct{}) bool {
+	select {
+	case cq.queue <- struct{}{}:
+		return true
+	case <-cancel:
+		return false
+	}
 }
 
 func (cq *ConcurrencyQueue) Done() {
@@ -81,32 +106,22 @@ func (tc *TaskCount) Wait() {
 	close(tc.exit)
 }
 
-// 任务ID作为Key
-type Instance struct {
-	m sync.Map
-}
-
-// 是否有任务处于运行中
-func (i *Instance) has(key int) bool {
-	_, ok := i.m.Load(key)
-
-	return ok
-}
-
-func (i *Instance) add(key int) {
-	i.m.Store(key, struct{}{})
-}
-
-func (i *Instance) done(key int) {
-	i.m.Delete(key)
-}
-
 type Task struct{}
 
 type TaskResult struct {
 	Result     string
 	Err        error
 	RetryTimes int8
+	Attempts   []TaskAttempt // 每次执行的结果
+}
+
+// 任务触发来源, 写入任务日志
+type jobTrigger struct {
+	Type          models.TaskLogTrigger
+	ScheduledTime time.Time               // 计划执行时间
+	WorkflowRunId int64                   // 工作流执行记录ID, 非工作流节点为0
+	BackfillId    int64                   // 回填记录ID, 非回填执行为0
+	Override      *models.TaskRunOverride // 手动运行时覆盖的参数
 }
 
 // 初始化任务, 从数据库取出所有任务, 添加到定时任务并运行
@@ -117,6 +132,38 @@ func (task Task) Initialize() {
 	taskCount = TaskCount{sync.WaitGroup{}, make(chan struct{})}
 	go taskCount.Wait()
 
+	if app.Setting.HA.Enable {
+		// 高可用模式下由选举出的调度节点加载任务
+		elector = newLeaderElector(new(models.SchedulerLease), app.Setting.HA.NodeId,
+			time.Duration(app.Setting.HA.LeaseTTL)*time.Second)
+		// 成为调度节点时补偿停机期间错过的执行并核对未结束的任务日志, 任务变更重新加载时不补偿
+		elector.onElected = func() error {
+			unfinishedLogs := task.unfinishedTaskLogs()
+			err := task.loadTasks(true)
+			go task.reconcileTaskLogs(unfinishedLogs)
+			return err
+		}
+		elector.onDemoted = task.clearTasks
+		elector.onChanged = func() error {
+			task.clearTasks()
+			return task.loadTasks(false)
+		}
+		elector.onRenewed = task.handleSchedulerRequests
+		go elector.run()
+		go task.runSlaChecker()
+		return
+	}
+
+	unfinishedLogs := task.unfinishedTaskLogs()
+	if err := task.loadTasks(true); err != nil {
+		logger.Fatalf("定时任务初始化#获取任务列表错误: %s", err)
+	}
+	go task.reconcileTaskLogs(unfinishedLogs)
+	go task.runSlaChecker()
+}
+
+// 从数据库加载所有激活任务到调度器, checkMisfire为true时按错过执行策略补偿执行
+func (task Task) loadTasks(checkMisfire bool) error {
 	logger.Info("开始初始化定时任务")
 	taskModel := new(models.Task)
 	taskNum := 0
@@ -126,22 +173,152 @@ func (task Task) Initialize() {
 	for page < maxPage {
 		taskList, err := taskModel.ActiveList(page, pageSize)
 		if err != nil {
-			logger.Fatalf("定时任务初始化#获取任务列表错误: %s", err)
+			return err
 		}
 		if len(taskList) == 0 {
 			break
 		}
 		for _, item := range taskList {
 			logger.Infof("添加任务到调度器#ID-%d#名称-%s#协议-%d#主机数量-%d", item.Id, item.Name, item.Protocol, len(item.Hosts))
-			task.Add(item)
+			task.add(item)
+			if checkMisfire {
+				task.catchUpMisfire(item)
+			}
 			taskNum++
 		}
 		page++
 	}
 	logger.Infof("定时任务初始化完成, 共%d个定时任务添加到调度器", taskNum)
 
+	if err := ServiceWorkflow.loadWorkflows(); err != nil {
+		return err
+	}
+
 	// 添加日志自动清理任务
 	task.initLogCleanupTask()
+
+	return nil
+}
+
+// 移除调度器中的所有任务
+func (task Task) clearTasks() {
+	for _, entry := range serviceCron.Entries() {
+		serviceCron.RemoveJob(entry.Name)
+	}
+	heartbeatWaits.stopAll()
+	logger.Info("已移除调度器中的所有任务")
+}
+
+// 当前节点是否负责调度, 非高可用模式下始终为true
+func (task Task) IsScheduler() bool {
+	return elector == nil || elector.IsLeader()
+}
+
+// 在调度节点上执行fn, 非调度节点通知调度节点重新加载任务
+func (task Task) runOnScheduler(fn func()) {
+	if elector == nil {
+		fn()
+		return
+	}
+	if !elector.runIfLeader(fn) {
+		elector.notifyChanged()
+	}
+}
+
+// 停止主机上执行中的任务请求的参数, 端口为实际连接的端口
+type schedulerStopPayload struct {
+	Protocol models.TaskProtocol `json:"protocol"`
+	Hosts    []schedulerStopHost `json:"hosts"`
+}
+
+type schedulerStopHost struct {
+	Name string `json:"name"`
+	Port int    `json:"port"`
+}
+
+// 手动运行请求的参数
+type schedulerRunPayload struct {
+	Spec     string                  `json:"spec"`
+	Override *models.TaskRunOverride `json:"override,omitempty"`
+}
+
+// 在调度节点上执行手动运行、回填等请求, 非调度节点写入调度请求, 由调度节点取出后执行
+func (task Task) dispatch(requestType models.SchedulerRequestType, targetId int64, payload interface{}, fn func()) {
+	if elector == nil {
+		fn()
+		return
+	}
+	if elector.runIfLeader(fn) {
+		return
+	}
+	request := &models.SchedulerRequest{Type: requestType, TargetId: targetId}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			logger.Errorf("高可用#调度请求参数序列化失败#类型-%d#ID-%d#%s", requestType, targetId, err)
+			return
+		}
+		request.Payload = string(data)
+	}
+	if _, err := request.Create(); err != nil {
+		logger.Errorf("高可用#写入调度请求失败#类型-%d#ID-%d#%s", requestType, targetId, err)
+		return
+	}
+	logger.Infof("高可用#已转交调度节点执行#类型-%d#ID-%d", requestType, targetId)
+}
+
+// 处理非调度节点写入的调度请求
+func (task Task) handleSchedulerRequests() {
+	requestModel := new(models.SchedulerRequest)
+	requests, err := requestModel.Pending(100)
+	if err != nil {
+		logger.Errorf("高可用#获取调度请求失败#%s", err)
+		return
+	}
+	for _, request := range requests {
+		claimed, err := requestModel.Claim(request.Id)
+		if err != nil {
+			logger.Errorf("高可用#取出调度请求失败#ID-%d#%s", request.Id, err)
+			continue
+		}
+		if claimed {
+			task.handleSchedulerRequest(request)
+		}
+	}
+}
+
+func (task Task) handleSchedulerRequest(request models.SchedulerRequest) {
+	trigger := jobTrigger{Type: models.TaskLogTriggerManual, ScheduledTime: request.CreatedAt}
+	switch request.Type {
+	case models.SchedulerRequestRun:
+		payload := schedulerRunPayload{}
+		if err := json.Unmarshal([]byte(request.Payload), &payload); err != nil {
+			logger.Errorf("高可用#调度请求参数解析失败#ID-%d#%s", request.Id, err)
+			return
+		}
+		taskModel := new(models.Task)
+		item, err := taskModel.Detail(int(request.TargetId))
+		if err != nil || item.Id == 0 {
+			logger.Errorf("高可用#手动运行#获取任务详情失败#任务ID-%d#%v", request.TargetId, err)
+			return
+		}
+		item.Spec = payload.Spec
+		trigger.Override = payload.Override
+		task.runWithTrigger(item, trigger)
+	case models.SchedulerRequestWorkflowRun:
+		go ServiceWorkflow.run(int(request.TargetId), trigger)
+	case models.SchedulerRequestBackfill:
+		task.resumeBackfill(request.TargetId)
+	case models.SchedulerRequestStopSql:
+		cancelSqlRun(request.TargetId)
+	case models.SchedulerRequestStopHosts:
+		payload := schedulerStopPayload{}
+		if err := json.Unmarshal([]byte(request.Payload), &payload); err != nil {
+			logger.Errorf("高可用#调度请求参数解析失败#ID-%d#%s", request.Id, err)
+			return
+		}
+		stopOnHosts(request.TargetId, payload)
+	}
 }
 
 // 初始化日志清理任务
@@ -166,6 +343,14 @@ func (task Task) initLogCleanupTask() {
 			} else {
 				logger.Infof("自动清理%d天前的数据库日志, 删除%d条记录", days, count)
 			}
+			workflowRunModel := new(models.WorkflowRun)
+			if _, err = workflowRunModel.RemoveByDays(days); err != nil {
+				logger.Errorf("自动清理工作流执行记录失败: %s", err)
+			}
+			backfillModel := new(models.TaskBackfill)
+			if _, err = backfillModel.RemoveByDays(days); err != nil {
+				logger.Errorf("自动清理任务回填记录失败: %s", err)
+			}
 			// 清理日志文件
 			cleanupLogFiles()
 		}
@@ -175,11 +360,13 @@ func (task Task) initLogCleanupTask() {
 
 // 重新加载日志清理任务
 func (task Task) ReloadLogCleanupTask() {
-	// 先移除旧任务
-	serviceCron.RemoveJob("log-cleanup")
-	// 重新添加任务
-	task.initLogCleanupTask()
-	logger.Info("日志清理任务已重新加载")
+	task.runOnScheduler(func() {
+		// 先移除旧任务
+		serviceCron.RemoveJob("log-cleanup")
+		// 重新添加任务
+		task.initLogCleanupTask()
+		logger.Info("日志清理任务已重新加载")
+	})
 }
 
 // 批量添加任务
@@ -197,6 +384,12 @@ func (task Task) RemoveAndAdd(taskModel models.Task) {
 
 // 添加任务
 func (task Task) Add(taskModel models.Task) {
+	task.runOnScheduler(func() {
+		task.add(taskModel)
+	})
+}
+
+func (task Task) add(taskModel models.Task) {
 	if taskModel.Level == models.TaskLevelChild {
 		logger.Errorf("添加任务失败#不允许添加子任务到调度器#任务Id-%d", taskModel.Id)
 		return
@@ -207,49 +400,152 @@ func (task Task) Add(taskModel models.Task) {
 		return
 	}
 
-	cronName := strconv.Itoa(taskModel.Id)
-	err := utils.PanicToError(func() {
-		serviceCron.AddFunc(taskModel.Spec, taskFunc, cronName)
-	})
+	schedule, err := parseTaskSchedule(taskModel)
 	if err != nil {
 		logger.Error("添加任务到调度器失败#", err)
+		return
 	}
+	serviceCron.Schedule(schedule, taskFunc, strconv.Itoa(taskModel.Id))
 }
 
+// 下次执行时间, 以任务时区表示
 func (task Task) NextRunTime(taskModel models.Task) time.Time {
 	if taskModel.Level != models.TaskLevelParent ||
 		taskModel.Status != models.Enabled {
 		return time.Time{}
 	}
-	entries := serviceCron.Entries()
-	taskName := strconv.Itoa(taskModel.Id)
-	for _, item := range entries {
-		if item.Name == taskName {
-			return item.Next
+	location, err := loadTaskLocation(taskModel.Timezone)
+	if err != nil {
+		return time.Time{}
+	}
+	schedule, err := parseTaskSchedule(taskModel)
+	if err != nil {
+		return time.Time{}
+	}
+	var next time.Time
+	if task.IsScheduler() {
+		taskName := strconv.Itoa(taskModel.Id)
+		for _, item := range serviceCron.Entries() {
+			if item.Name == taskName {
+				next = item.Next
+				break
+			}
+		}
+	} else {
+		// 非调度节点根据表达式计算下次执行时间
+		next = schedule.Next(time.Now())
+	}
+	// 跳过日历阻止的日期
+	next = skipCalendarBlocked(taskModel, schedule, next)
+	if next.IsZero() {
+		return next
+	}
+
+	return next.In(location)
+}
+
+// StopOnHosts 停止在主机上执行中的RPC、SSH任务, 按主机执行记录停止实际执行中的主机
+// 任务由调度节点发起, 只有调度节点能停止, 非调度节点写入调度请求
+func (task Task) StopOnHosts(taskModel models.Task, taskLogId int64) error {
+	hostLogs, err := listHostLogsFunc(taskLogId)
+	if err != nil {
+		return err
+	}
+	payload := schedulerStopPayload{Protocol: taskModel.Protocol}
+	stopping := make(map[schedulerStopHost]bool)
+	for _, hostLog := range hostLogs {
+		// 主机执行记录中的端口为实际连接的端口, 重试时同一主机有多条记录
+		host := schedulerStopHost{Name: hostLog.Name, Port: hostLog.Port}
+		if hostLog.Status != models.Running || stopping[host] {
+			continue
+		}
+		stopping[host] = true
+		payload.Hosts = append(payload.Hosts, host)
+	}
+	task.dispatch(models.SchedulerRequestStopHosts, taskLogId, payload, func() {
+		stopOnHosts(taskLogId, payload)
+	})
+
+	return nil
+}
+
+// 停止各主机上执行中的任务, 都未找到时任务已不在执行(如系统重启后), 直接将任务日志标记为已取消
+func stopOnHosts(taskLogId int64, payload schedulerStopPayload) {
+	if ServiceTask.CancelQueued(taskLogId) {
+		return
+	}
+	// 滚动执行在批次间等待时没有执行中的主机
+	stopped := cancelRollingRun(taskLogId)
+	for _, host := range payload.Hosts {
+		logger.Infof("尝试停止任务#主机-%s:%d#taskLogId-%d", host.Name, host.Port, taskLogId)
+		if payload.Protocol == models.TaskSSH {
+			stopped = sshCancelFunc(host.Name, host.Port, taskLogId) || stopped
+		} else {
+			stopped = rpcCancelFunc(host.Name, host.Port, taskLogId) || stopped
 		}
 	}
+	if !stopped {
+		logger.Warnf("未找到执行中的任务, 可能是系统重启前的任务, 直接更新任务日志状态#taskLogId-%d", taskLogId)
+		updateOrphanedTaskLogFunc(taskLogId)
+	}
+}
 
-	return time.Time{}
+// 系统重启后丢失的任务, 任务日志标记为已取消
+func updateOrphanedTaskLog(taskLogId int64) {
+	taskLogModel := new(models.TaskLog)
+	_, err := taskLogModel.Update(taskLogId, models.CommonMap{
+		"status":   models.Cancel,
+		"result":   "系统重启后手动停止",
+		"end_time": time.Now(),
+	})
+	if err != nil {
+		logger.Errorf("更新孤立任务日志状态失败#taskLogId-%d#%s", taskLogId, err)
+	}
 }
 
-// 停止运行中的任务
-func (task Task) Stop(ip string, port int, id int64) {
-	rpcClient.Stop(ip, port, id)
+// StopSQL 停止执行中的SQL任务, SQL任务在调度节点上执行, 非调度节点写入调度请求
+func (task Task) StopSQL(taskLogId int64) {
+	task.dispatch(models.SchedulerRequestStopSql, taskLogId, nil, func() {
+		cancelSqlRun(taskLogId)
+	})
 }
 
 func (task Task) Remove(id int) {
-	serviceCron.RemoveJob(strconv.Itoa(id))
+	task.runOnScheduler(func() {
+		serviceCron.RemoveJob(strconv.Itoa(id))
+		heartbeatWaits.stop(id)
+	})
 }
 
 // 等待所有任务结束后退出
 func (task Task) WaitAndExit() {
+	if elector != nil {
+		elector.Stop()
+	}
 	serviceCron.Stop()
 	taskCount.Exit()
 }
 
 // 直接运行任务
 func (task Task) Run(taskModel models.Task) {
-	go createJob(taskModel)()
+	task.runManual(taskModel, nil)
+}
+
+// 手动运行任务, 高可用模式下由调度节点执行
+func (task Task) runManual(taskModel models.Task, override *models.TaskRunOverride) {
+	trigger := jobTrigger{Type: models.TaskLogTriggerManual, ScheduledTime: time.Now(), Override: override}
+	payload := schedulerRunPayload{Spec: taskModel.Spec, Override: override}
+	task.dispatch(models.SchedulerRequestRun, int64(taskModel.Id), payload, func() {
+		task.runWithTrigger(taskModel, trigger)
+	})
+}
+
+func (task Task) runWithTrigger(taskModel models.Task, trigger jobTrigger) {
+	taskFunc := createTriggeredJob(taskModel, trigger)
+	if taskFunc == nil {
+		return
+	}
+	go taskFunc()
 }
 
 type Handler interface {
@@ -259,13 +555,20 @@ type Handler interface {
 // HTTP任务
 type HTTPHandler struct{}
 
-// http任务执行时间不超过300秒
+// 未保存请求定义的旧任务执行时间不超过300秒, 其他任务未设置超时时间时的默认值
 const HttpExecTimeout = 300
 
 func (h *HTTPHandler) Run(taskModel models.Task, taskUniqueId int64) (result string, err error) {
+	if taskModel.Http != nil || taskModel.HttpMethod > models.TaskHttpMethodPost {
+		return runHttpRequest(taskModel, taskUniqueId)
+	}
 	if taskModel.Timeout <= 0 || taskModel.Timeout > HttpExecTimeout {
 		taskModel.Timeout = HttpExecTimeout
 	}
+	taskModel.Command, err = renderCommand(taskModel, taskUniqueId, nil)
+	if err != nil {
+		return "", err
+	}
 	var resp httpclient.ResponseWrapper
 	if taskModel.HttpMethod == models.TaskHTTPMethodGet {
 		resp = httpGetFunc(taskModel.Command, taskModel.Timeout)
@@ -280,7 +583,8 @@ func (h *HTTPHandler) Run(taskModel models.Task, taskUniqueId int64) (result str
 	}
 	// 返回状态码非200，均为失败
 	if resp.StatusCode != http.StatusOK {
-		return resp.Body, fmt.Errorf("HTTP状态码非200-->%d", resp.StatusCode)
+		return resp.Body, newTaskError(fmt.Errorf("HTTP状态码非200-->%d", resp.StatusCode),
+			classifyHTTPError(resp.StatusCode, resp.Err))
 	}
 
 	return resp.Body, err
@@ -291,68 +595,169 @@ type RPCHandler struct{}
 
 func (h *RPCHandler) Run(taskModel models.Task, taskUniqueId int64) (result string, err error) {
 	logger.Infof("RPC任务开始执行#任务ID-%d#主机数量-%d", taskModel.Id, len(taskModel.Hosts))
+	return runOnHosts(taskModel, taskUniqueId)
+}
+
+// SSH执行任务, 与RPC任务相同按主机策略、滚动执行配置在关联的主机上执行
+type SSHHandler struct{}
+
+func (h *SSHHandler) Run(taskModel models.Task, taskUniqueId int64) (result string, err error) {
+	logger.Infof("SSH任务开始执行#任务ID-%d#主机数量-%d", taskModel.Id, len(taskModel.Hosts))
+	return runOnHosts(taskModel, taskUniqueId)
+}
+
+// 在关联的主机上执行任务
+func runOnHosts(taskModel models.Task, taskUniqueId int64) (result string, err error) {
 	if len(taskModel.Hosts) == 0 {
+		if taskModel.HostSelector != "" {
+			return "", fmt.Errorf("没有匹配标签选择器[%s]的主机", taskModel.HostSelector)
+		}
 		return "", fmt.Errorf("任务未关联任何主机")
 	}
-	taskRequest := new(pb.TaskRequest)
-	taskRequest.Timeout = int32(taskModel.Timeout)
-	taskRequest.Command = taskModel.Command
-	taskRequest.Id = taskUniqueId
-	resultChan := make(chan TaskResult, len(taskModel.Hosts))
-	for _, taskHost := range taskModel.Hosts {
-		logger.Infof("准备执行RPC调用#主机-%s:%d#命令-%s", taskHost.Name, taskHost.Port, taskModel.Command)
-		go func(th models.TaskHostDetail) {
-			output, err := rpcClient.Exec(th.Name, th.Port, taskRequest)
-			errorMessage := ""
-			if err != nil {
-				errorMessage = err.Error()
-			}
-			output = strings.TrimSpace(output)
-			if errorMessage != "" {
-				errorMessage = strings.TrimSpace(errorMessage) + "\n"
-			}
-			outputMessage := fmt.Sprintf("主机: [%s-%s:%d]\n%s%s",
-				th.Alias, th.Name, th.Port, errorMessage, output,
-			)
-			logger.Infof("RPC调用完成#主机-%s:%d#输出长度-%d#错误-%v", th.Name, th.Port, len(output), err)
-			resultChan <- TaskResult{Err: err, Result: outputMessage}
-		}(taskHost)
-	}
-
-	var aggregationErr error = nil
-	aggregationResult := ""
-	for i := 0; i < len(taskModel.Hosts); i++ {
-		taskResult := <-resultChan
-		aggregationResult += taskResult.Result
-		if taskResult.Err != nil {
-			aggregationErr = taskResult.Err
+	if taskModel.HostStrategy > models.TaskHostAll {
+		return runOnSelectedHost(taskModel, taskUniqueId)
+	}
+	if taskModel.RollingBatch > 0 {
+		return runRolling(taskModel, taskUniqueId)
+	}
+
+	results := execOnHosts(taskModel, taskUniqueId, taskModel.Hosts)
+	if len(results) == 1 {
+		return results[0].Result, results[0].Err
+	}
+	summary := fmt.Sprintf("共%d台主机执行, 各主机的输出见主机执行记录\n", len(results)) +
+		hostsResultSummary(taskModel.Hosts, results)
+
+	return summary, hostsResultError(taskModel, results, len(taskModel.Hosts))
+}
+
+// 在多台主机上同时执行任务, 结果按主机顺序返回
+func execOnHosts(taskModel models.Task, taskUniqueId int64, hosts []models.TaskHostDetail) []TaskResult {
+	results := make([]TaskResult, len(hosts))
+	var wg sync.WaitGroup
+	for i, taskHost := range hosts {
+		wg.Add(1)
+		go func(i int, th models.TaskHostDetail) {
+			defer wg.Done()
+			results[i] = execOnHost(taskModel, taskUniqueId, th)
+		}(i, taskHost)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// 在一台主机上执行任务
+func execOnHost(taskModel models.Task, taskUniqueId int64, th models.TaskHostDetail) TaskResult {
+	protocol := "RPC"
+	if taskModel.Protocol == models.TaskSSH {
+		th.Port = th.SshPort
+		protocol = "SSH"
+	}
+	logger.Infof("准备执行%s调用#主机-%s:%d#命令-%s", protocol, th.Name, th.Port, taskModel.Command)
+	hostLogId := createHostLogFunc(taskModel, taskUniqueId, th)
+	var output string
+	// 命令模板按主机渲染
+	command, err := renderCommand(taskModel, taskUniqueId, &th)
+	if err == nil {
+		taskRequest := new(pb.TaskRequest)
+		taskRequest.Timeout = int32(taskModel.Timeout)
+		taskRequest.Command = command
+		taskRequest.Id = taskUniqueId
+		taskRequest.Env = taskModel.RunEnv
+		if taskModel.Protocol == models.TaskSSH {
+			output, err = execSSH(th, taskRequest)
+			err = newTaskError(err, classifySSHError(err))
+		} else {
+			output, err = rpcExecFunc(th.Name, th.Port, taskRequest)
+			err = newTaskError(err, classifyRPCError(err))
 		}
 	}
+	errorMessage := ""
+	if err != nil {
+		errorMessage = err.Error()
+	}
+	output = strings.TrimSpace(output)
+	finishHostLogFunc(hostLogId, output, err)
+	if errorMessage != "" {
+		errorMessage = strings.TrimSpace(errorMessage) + "\n"
+	}
+	outputMessage := errorMessage + output
+	logger.Infof("%s调用完成#主机-%s:%d#输出长度-%d#错误-%v", protocol, th.Name, th.Port, len(output), err)
+
+	return TaskResult{Err: err, Result: outputMessage}
+}
+
+// 使用主机保存的SSH配置执行命令
+func execSSH(th models.TaskHostDetail, taskRequest *pb.TaskRequest) (string, error) {
+	host, err := sshHostFunc(th.HostId)
+	if err != nil {
+		return "", fmt.Errorf("获取主机信息失败: %s", err)
+	}
+
+	return sshExecFunc(SSHConfig(host), taskRequest)
+}
+
+// SSHConfig 主机保存的SSH连接配置
+func SSHConfig(host models.Host) sshclient.Config {
+	cfg := sshclient.Config{
+		Host:     host.Name,
+		Port:     host.SshPort,
+		User:     host.SshUser,
+		Password: host.SshPass,
+		HostKey:  host.HostKey,
+	}
+	if host.SshAuth == models.HostSshAuthKey {
+		cfg.PrivateKey = host.SshKey
+	}
+
+	return cfg
+}
+
+func findHost(id int16) (models.Host, error) {
+	host := models.Host{}
+	err := host.Find(int(id))
 
-	return aggregationResult, aggregationErr
+	return host, err
 }
 
 // 创建任务日志
-func createTaskLog(taskModel models.Task, status models.Status) (int64, error) {
+func createTaskLog(taskModel models.Task, trigger jobTrigger, status models.Status) (int64, error) {
+	taskLogModel := newTaskLog(taskModel, trigger, status)
+	insertId, err := taskLogModel.Create()
+
+	return insertId, err
+}
+
+func newTaskLog(taskModel models.Task, trigger jobTrigger, status models.Status) *models.TaskLog {
 	taskLogModel := new(models.TaskLog)
 	taskLogModel.TaskId = taskModel.Id
 	taskLogModel.Name = taskModel.Name
 	taskLogModel.Spec = taskModel.Spec
 	taskLogModel.Protocol = taskModel.Protocol
-	taskLogModel.Command = taskModel.Command
+	taskLogModel.Command = commandSummary(taskModel.Command)
 	taskLogModel.Timeout = taskModel.Timeout
-	if taskModel.Protocol == models.TaskRPC {
-		aggregationHost := ""
-		for _, host := range taskModel.Hosts {
-			aggregationHost += fmt.Sprintf("%s - %s<br>", host.Alias, host.Name)
-		}
-		taskLogModel.Hostname = aggregationHost
+	if taskModel.Protocol.OnHosts() {
+		taskLogModel.Hostname = taskLogHostname(taskModel.Hosts)
 	}
 	taskLogModel.StartTime = models.LocalTime(time.Now())
+	taskLogModel.TriggerType = trigger.Type
+	taskLogModel.WorkflowRunId = trigger.WorkflowRunId
+	taskLogModel.BackfillId = trigger.BackfillId
+	if !trigger.ScheduledTime.IsZero() {
+		scheduledTime := models.LocalTime(trigger.ScheduledTime)
+		taskLogModel.ScheduledTime = &scheduledTime
+	}
 	taskLogModel.Status = status
-	insertId, err := taskLogModel.Create()
+	if trigger.Override != nil {
+		overrides, err := json.Marshal(trigger.Override)
+		if err != nil {
+			logger.Errorf("任务覆盖参数序列化失败#任务ID-%d#%s", taskModel.Id, err)
+		}
+		taskLogModel.Overrides = string(overrides)
+	}
 
-	return insertId, err
+	return taskLogModel
 }
 
 // 更新任务日志
@@ -369,44 +774,98 @@ func updateTaskLog(taskLogId int64, taskResult TaskResult) (int64, error) {
 		"retry_times": taskResult.RetryTimes,
 		"status":      status,
 		"result":      result,
+		"attempts":    encodeTaskAttempts(taskResult.Attempts),
 		"end_time":    time.Now(),
 	})
 
 }
 
+// 创建由定时器触发的任务Job
 func createJob(taskModel models.Task) cron.FuncJob {
 	logger.Infof("创建任务Job#ID-%d#名称-%s#主机数量-%d", taskModel.Id, taskModel.Name, len(taskModel.Hosts))
+	if taskModel.Protocol == models.TaskHeartbeat {
+		return createHeartbeatJob(taskModel)
+	}
 	handler := createHandler(taskModel)
 	if handler == nil {
 		return nil
 	}
 	taskFunc := func() {
-		logger.Infof("任务闭包执行#ID-%d#名称-%s#主机数量-%d", taskModel.Id, taskModel.Name, len(taskModel.Hosts))
-		taskCount.Add()
-		defer taskCount.Done()
-
-		taskLogId := beforeExecJob(taskModel)
-		if taskLogId <= 0 {
+		trigger := jobTrigger{Type: models.TaskLogTriggerCron, ScheduledTime: time.Now().Truncate(time.Second)}
+		if skipByCalendar(taskModel, trigger) {
 			return
 		}
-
-		if taskModel.Multi == 0 {
-			runInstance.add(taskModel.Id)
-			defer runInstance.done(taskModel.Id)
+		if _, ok := runJob(handler, taskModel, trigger); ok {
+			ServiceTask.finishOnce(taskModel)
 		}
+	}
 
-		concurrencyQueue.Add()
-		defer concurrencyQueue.Done()
+	return taskFunc
+}
 
-		logger.Infof("开始执行任务#%s#命令-%s", taskModel.Name, taskModel.Command)
-		taskResult := execJob(handler, taskModel, taskLogId)
-		logger.Infof("任务完成#%s#命令-%s", taskModel.Name, taskModel.Command)
-		afterExecJob(taskModel, taskResult, taskLogId)
+// 创建指定触发来源的任务Job
+func createTriggeredJob(taskModel models.Task, trigger jobTrigger) cron.FuncJob {
+	taskModel = applyRunOverride(taskModel, trigger.Override)
+	handler := createHandler(taskModel)
+	if handler == nil {
+		return nil
+	}
+	taskFunc := func() {
+		runJob(handler, taskModel, trigger)
 	}
 
 	return taskFunc
 }
 
+// 执行任务并返回执行结果, 未执行时ok为false
+func runJob(handler Handler, taskModel models.Task, trigger jobTrigger) (taskResult TaskResult, ok bool) {
+	// 手动指定执行主机时不重新匹配
+	if trigger.Override == nil || len(trigger.Override.HostIds) == 0 {
+		taskModel = resolveSelectorHosts(taskModel)
+	}
+	logger.Infof("任务闭包执行#ID-%d#名称-%s#主机数量-%d", taskModel.Id, taskModel.Name, len(taskModel.Hosts))
+	taskCount.Add()
+	defer taskCount.Done()
+
+	var taskLogId int64
+	var run *instanceRun
+	if taskModel.Multi == 0 {
+		var started bool
+		if run, started = runInstance.begin(taskModel, trigger); !started {
+			return
+		}
+		defer runInstance.finish(taskModel.Id, run)
+		taskLogId = run.taskLogId
+		handler = instanceHandler{Handler: handler, run: run}
+	} else if taskLogId = beforeExecJob(taskModel, trigger); taskLogId <= 0 {
+		return
+	}
+
+	taskModel = selectRunHosts(taskModel)
+	release, acquired := acquireConcurrency(taskModel, taskLogId)
+	if !acquired {
+		return
+	}
+	defer release()
+
+	logger.Infof("开始执行任务#%s#命令-%s", taskModel.Name, taskModel.Command)
+	taskModel.RunScheduledTime = trigger.ScheduledTime
+	startTime := time.Now()
+	taskResult = execJob(handler, taskModel, taskLogId)
+	logger.Infof("任务完成#%s#命令-%s", taskModel.Name, taskModel.Command)
+	if run != nil {
+		if replacedBy := runInstance.replacedBy(run); replacedBy > 0 {
+			finishReplacedRun(taskLogId, replacedBy, taskResult)
+			return taskResult, false
+		}
+	}
+	afterExecJob(taskModel, taskResult, taskLogId)
+	// 执行期间未检查到超时的任务, 结束时再检查一次
+	checkSlaDuration(taskModel, taskLogId, time.Since(startTime))
+
+	return taskResult, true
+}
+
 func createHandler(taskModel models.Task) Handler {
 	var handler Handler = nil
 	switch taskModel.Protocol {
@@ -414,19 +873,18 @@ func createHandler(taskModel models.Task) Handler {
 		handler = new(HTTPHandler)
 	case models.TaskRPC:
 		handler = new(RPCHandler)
+	case models.TaskSSH:
+		handler = new(SSHHandler)
+	case models.TaskSQL:
+		handler = new(SQLHandler)
 	}
 
 	return handler
 }
 
 // 任务前置操作
-func beforeExecJob(taskModel models.Task) (taskLogId int64) {
-	if taskModel.Multi == 0 && runInstance.has(taskModel.Id) {
-		logger.Infof("任务已在运行中，取消本次执行#ID-%d", taskModel.Id)
-		taskLogId, _ = createTaskLog(taskModel, models.Cancel)
-		return
-	}
-	taskLogId, err := createTaskLog(taskModel, models.Running)
+func beforeExecJob(taskModel models.Task, trigger jobTrigger) (taskLogId int64) {
+	taskLogId, err := createTaskLog(taskModel, trigger, models.Running)
 	if err != nil {
 		logger.Error("任务开始执行#写入任务日志失败-", err)
 		return
@@ -446,43 +904,6 @@ func afterExecJob(taskModel models.Task, taskResult TaskResult, taskLogId int64)
 
 	// 发送邮件
 	go SendNotification(taskModel, taskResult)
-	// 执行依赖任务
-	go execDependencyTask(taskModel, taskResult)
-}
-
-// 执行依赖任务, 多个任务并发执行
-func execDependencyTask(taskModel models.Task, taskResult TaskResult) {
-	// 父任务才能执行子任务
-	if taskModel.Level != models.TaskLevelParent {
-		return
-	}
-
-	// 是否存在子任务
-	dependencyTaskId := strings.TrimSpace(taskModel.DependencyTaskId)
-	if dependencyTaskId == "" {
-		return
-	}
-
-	// 父子任务关系为强依赖, 父任务执行失败, 不执行依赖任务
-	if taskModel.DependencyStatus == models.TaskDependencyStatusStrong && taskResult.Err != nil {
-		logger.Infof("父子任务为强依赖关系, 父任务执行失败, 不运行依赖任务#主任务ID-%d", taskModel.Id)
-		return
-	}
-
-	// 获取子任务
-	model := new(models.Task)
-	tasks, err := model.GetDependencyTaskList(dependencyTaskId)
-	if err != nil {
-		logger.Errorf("获取依赖任务失败#主任务ID-%d#%s", taskModel.Id, err.Error())
-		return
-	}
-	if len(tasks) == 0 {
-		logger.Errorf("依赖任务列表为空#主任务ID-%d", taskModel.Id)
-	}
-	for _, task := range tasks {
-		task.Spec = fmt.Sprintf("依赖任务(主任务ID-%d)", taskModel.Id)
-		ServiceTask.Run(task)
-	}
 }
 
 // 发送任务结果通知
@@ -538,24 +959,31 @@ func execJob(handler Handler, taskModel models.Task, taskUniqueId int64) TaskRes
 	var i int8 = 0
 	var output string
 	var err error
+	attempts := make([]TaskAttempt, 0, execTimes)
 	for i < execTimes {
+		startTime := time.Now()
+		taskModel.RunAttempt = int(i) + 1
 		output, err = handler.Run(taskModel, taskUniqueId)
+		attempts = append(attempts, newTaskAttempt(int(i)+1, startTime, output, err))
 		if err == nil {
-			return TaskResult{Result: output, Err: err, RetryTimes: i}
+			return TaskResult{Result: output, Err: err, RetryTimes: i, Attempts: attempts}
+		}
+		if !shouldRetry(taskModel, err) {
+			if i+1 < execTimes {
+				logger.Warnf("任务执行失败#任务id-%d#错误类型-%s#不在重试范围内, 不再重试",
+					taskModel.Id, strings.Join(taskErrorClasses(err), ","))
+			}
+			return TaskResult{Result: output, Err: err, RetryTimes: i, Attempts: attempts}
 		}
 		i++
 		if i < execTimes {
-			logger.Warnf("任务执行失败#任务id-%d#重试第%d次#输出-%s#错误-%s", taskModel.Id, i, output, err.Error())
-			if taskModel.RetryInterval > 0 {
-				sleepFunc(time.Duration(taskModel.RetryInterval) * time.Second)
-			} else {
-				// 默认重试间隔时间，每次递增1分钟
-				sleepFunc(time.Duration(i) * time.Minute)
-			}
+			delay := retryDelay(taskModel, int(i))
+			logger.Warnf("任务执行失败#任务id-%d#%s后重试第%d次#输出-%s#错误-%s", taskModel.Id, delay, i, output, err.Error())
+			sleepFunc(delay)
 		}
 	}
 
-	return TaskResult{Result: output, Err: err, RetryTimes: taskModel.RetryTimes}
+	return TaskResult{Result: output, Err: err, RetryTimes: taskModel.RetryTimes, Attempts: attempts}
 }
 
 // 清理日志文件
@@ -2,8 +2,10 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -259,3 +261,64 @@ func stubNotifyPush(t *testing.T) *[]notify.Message {
 	t.Cleanup(func() { notifyPushFunc = original })
 	return &captured
 }
+
+func TestStopOnHosts(t *testing.T) {
+	originalRPC, originalSSH, originalOrphan, originalLogs := rpcCancelFunc, sshCancelFunc, updateOrphanedTaskLogFunc, listHostLogsFunc
+	defer func() {
+		rpcCancelFunc, sshCancelFunc, updateOrphanedTaskLogFunc, listHostLogsFunc = originalRPC, originalSSH, originalOrphan, originalLogs
+	}()
+	canceled := make([]string, 0)
+	rpcCancelFunc = func(ip string, port int, id int64) bool {
+		canceled = append(canceled, fmt.Sprintf("rpc-%s:%d", ip, port))
+		return ip == "b"
+	}
+	sshCancelFunc = func(ip string, port int, id int64) bool {
+		canceled = append(canceled, fmt.Sprintf("ssh-%s:%d", ip, port))
+		return false
+	}
+	orphaned := make([]int64, 0)
+	updateOrphanedTaskLogFunc = func(taskLogId int64) {
+		orphaned = append(orphaned, taskLogId)
+	}
+	// 按实际执行的主机停止, 已结束的主机及重试的重复记录跳过
+	listHostLogsFunc = func(taskLogId int64) ([]models.TaskHostLog, error) {
+		port := 5921
+		if taskLogId == 2 {
+			port = 22
+		}
+		return []models.TaskHostLog{
+			{Name: "a", Port: port, Status: models.Running},
+			{Name: "b", Port: port, Status: models.Failure},
+			{Name: "b", Port: port, Status: models.Running},
+			{Name: "c", Port: port, Status: models.Finish},
+		}, nil
+	}
+
+	task := models.Task{Protocol: models.TaskRPC, Hosts: testHosts("c")}
+	// 任一主机上找到执行中的任务时不更新任务日志
+	if err := ServiceTask.StopOnHosts(task, 1); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(canceled, ",") != "rpc-a:5921,rpc-b:5921" || len(orphaned) != 0 {
+		t.Fatalf("unexpected stop canceled=%v orphaned=%v", canceled, orphaned)
+	}
+	// 都未找到时任务日志标记为已取消
+	canceled = canceled[:0]
+	task.Protocol = models.TaskSSH
+	if err := ServiceTask.StopOnHosts(task, 2); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(canceled, ",") != "ssh-a:22,ssh-b:22" || len(orphaned) != 1 || orphaned[0] != 2 {
+		t.Fatalf("unexpected stop canceled=%v orphaned=%v", canceled, orphaned)
+	}
+	// 排队中的任务直接取消
+	canceled = canceled[:0]
+	queued := make(chan struct{})
+	queuedJobs.Store(int64(3), queued)
+	if err := ServiceTask.StopOnHosts(task, 3); err != nil {
+		t.Fatal(err)
+	}
+	if len(canceled) != 0 || len(orphaned) != 1 {
+		t.Fatalf("expected queued run to be canceled only, canceled=%v orphaned=%v", canceled, orphaned)
+	}
+}
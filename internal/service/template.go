@@ -0,0 +1,179 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+// 命令模板变量
+// 任务命令(HTTP任务的URL、RPC任务的shell命令)中可使用${{ }}引用模板变量, 每次执行时渲染
+// 使用${{ }}而不是{{ }}, 避免与命令中原有的{{ }}冲突, 如docker --format '{{.Names}}'
+// 示例: 计划执行时间的前一天 ${{ .ScheduledTime | addDays -1 | date "YYYYMMDD" }}
+// 变量拼接到shell命令中时使用shellquote加引号, 拼接到URL中时使用urlquery转义
+// 示例: backup.sh ${{ .TaskName | shellquote }}, http://example.com/job?name=${{ .TaskName | urlquery }}
+
+const (
+	templateLeftDelim  = "${{"
+	templateRightDelim = "}}"
+)
+
+var ErrCommandTemplate = errors.New("命令模板错误")
+
+// 命令模板中可使用的变量
+type commandTemplateData struct {
+	ScheduledTime time.Time // 计划执行时间, 重试、错过执行后补执行时不变
+	StartTime     time.Time // 本次执行的开始时间
+	TaskLogId     int64
+	TaskId        int
+	TaskName      string
+	Host          string // 主机别名, 仅RPC任务
+	HostName      string // 主机名, 仅RPC任务
+	Attempt       int    // 第几次执行, 从1开始, 重试时递增
+	CallbackUrl   string // 回调地址, 仅异步HTTP任务
+	CallbackToken string // 回调令牌, 仅异步HTTP任务
+}
+
+// 时间格式中的占位符, 较长的占位符在前
+var dateTokens = []struct {
+	token  string
+	layout string
+}{
+	{"YYYY", "2006"},
+	{"YY", "06"},
+	{"MM", "01"},
+	{"DD", "02"},
+	{"HH", "15"},
+	{"mm", "04"},
+	{"ss", "05"},
+}
+
+var commandTemplateFuncs = template.FuncMap{
+	"date": formatDate,
+	"addDays": func(days int, t time.Time) time.Time {
+		return t.AddDate(0, 0, days)
+	},
+	"addMonths": func(months int, t time.Time) time.Time {
+		return t.AddDate(0, months, 0)
+	},
+	"addHours": func(hours int, t time.Time) time.Time {
+		return t.Add(time.Duration(hours) * time.Hour)
+	},
+	"addMinutes": func(minutes int, t time.Time) time.Time {
+		return t.Add(time.Duration(minutes) * time.Minute)
+	},
+	"unix": func(t time.Time) int64 {
+		return t.Unix()
+	},
+	"shellquote": func(value interface{}) string {
+		return shellQuote(fmt.Sprint(value))
+	},
+	"urlquery": func(value interface{}) string {
+		return url.QueryEscape(fmt.Sprint(value))
+	},
+}
+
+// 按YYYY、MM、DD、HH、mm、ss格式化时间, 其他字符原样输出
+func formatDate(format string, t time.Time) string {
+	var builder strings.Builder
+	for i := 0; i < len(format); {
+		matched := false
+		for _, item := range dateTokens {
+			if strings.HasPrefix(format[i:], item.token) {
+				builder.WriteString(t.Format(item.layout))
+				i += len(item.token)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			builder.WriteByte(format[i])
+			i++
+		}
+	}
+
+	return builder.String()
+}
+
+func isCommandTemplate(command string) bool {
+	return strings.Contains(command, templateLeftDelim)
+}
+
+// 转义文本中的模板分隔符, 拼接到命令后渲染时原样输出
+func escapeCommandTemplate(text string) string {
+	return strings.ReplaceAll(text, templateLeftDelim, templateLeftDelim+` "`+templateLeftDelim+`" `+templateRightDelim)
+}
+
+func parseCommandTemplate(command string) (*template.Template, error) {
+	return template.New("command").
+		Delims(templateLeftDelim, templateRightDelim).
+		Funcs(commandTemplateFuncs).
+		Option("missingkey=error").
+		Parse(command)
+}
+
+// ValidateCommandTemplate 校验命令模板语法及引用的变量
+func ValidateCommandTemplate(command string) error {
+	if !isCommandTemplate(command) {
+		return nil
+	}
+	tmpl, err := parseCommandTemplate(command)
+	if err == nil {
+		now := time.Now()
+		err = tmpl.Execute(new(strings.Builder), commandTemplateData{ScheduledTime: now, StartTime: now, Attempt: 1})
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrCommandTemplate, err)
+	}
+
+	return nil
+}
+
+// 渲染任务命令, host为nil时主机相关变量为空
+func renderCommand(taskModel models.Task, taskUniqueId int64, host *models.TaskHostDetail) (string, error) {
+	if !isCommandTemplate(taskModel.Command) {
+		return taskModel.Command, nil
+	}
+	tmpl, err := parseCommandTemplate(taskModel.Command)
+	if err != nil {
+		return "", newTaskError(fmt.Errorf("%w: %s", ErrCommandTemplate, err), models.TaskErrorTemplate)
+	}
+	location, err := loadTaskLocation(taskModel.Timezone)
+	if err != nil {
+		location = time.Local
+	}
+	scheduledTime := taskModel.RunScheduledTime
+	startTime := time.Now()
+	if scheduledTime.IsZero() {
+		scheduledTime = startTime
+	}
+	attempt := taskModel.RunAttempt
+	if attempt <= 0 {
+		attempt = 1
+	}
+	data := commandTemplateData{
+		ScheduledTime: scheduledTime.In(location),
+		StartTime:     startTime.In(location),
+		TaskLogId:     taskUniqueId,
+		TaskId:        taskModel.Id,
+		TaskName:      taskModel.Name,
+		Attempt:       attempt,
+		CallbackUrl:   taskModel.RunCallbackUrl,
+		CallbackToken: taskModel.RunCallbackToken,
+	}
+	if host != nil {
+		data.Host = host.Alias
+		data.HostName = host.Name
+	}
+	var builder strings.Builder
+	if err = tmpl.Execute(&builder, data); err != nil {
+		return "", newTaskError(fmt.Errorf("%w: %s", ErrCommandTemplate, err), models.TaskErrorTemplate)
+	}
+
+	return builder.String(), nil
+}
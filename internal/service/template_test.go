@@ -0,0 +1,111 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/httpclient"
+)
+
+func TestFormatDate(t *testing.T) {
+	date := time.Date(2024, 3, 5, 7, 8, 9, 0, time.UTC)
+	cases := map[string]string{
+		"YYYYMMDD":            "20240305",
+		"YY-MM-DD HH:mm:ss":   "24-03-05 07:08:09",
+		"dt=YYYY/MM/DD_1 Jan": "dt=2024/03/05_1 Jan",
+	}
+	for format, expected := range cases {
+		if got := formatDate(format, date); got != expected {
+			t.Fatalf("format %q: expected %q, got %q", format, expected, got)
+		}
+	}
+}
+
+func TestRenderCommand(t *testing.T) {
+	task := models.Task{
+		Id:               3,
+		Name:             "report",
+		Timezone:         "Asia/Shanghai",
+		Command:          `run.sh ${{ .ScheduledTime | addDays -1 | date "YYYYMMDD" }} ${{ .TaskLogId }} ${{ .Host }} ${{ .Attempt }} {{.Names}}`,
+		RunScheduledTime: time.Date(2024, 3, 1, 16, 30, 0, 0, time.UTC),
+		RunAttempt:       2,
+	}
+	host := &models.TaskHostDetail{Alias: "web-1"}
+	command, err := renderCommand(task, 42, host)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 按任务时区, 计划执行时间为2024-03-02 00:30
+	expected := "run.sh 20240301 42 web-1 2 {{.Names}}"
+	if command != expected {
+		t.Fatalf("expected %q, got %q", expected, command)
+	}
+
+	task.Name = "it's a report"
+	task.Command = `run.sh ${{ .TaskName | shellquote }} && curl "http://example.com/?name=${{ .TaskName | urlquery }}"`
+	command, err = renderCommand(task, 42, host)
+	expected = `run.sh 'it'\''s a report' && curl "http://example.com/?name=it%27s+a+report"`
+	if err != nil || command != expected {
+		t.Fatalf("expected %q, got %q, %v", expected, command, err)
+	}
+
+	task.Command = "echo {{.Names}}"
+	command, err = renderCommand(task, 42, nil)
+	if err != nil || command != task.Command {
+		t.Fatalf("expected command without template unchanged, got %q, %v", command, err)
+	}
+}
+
+func TestRenderCommandError(t *testing.T) {
+	task := models.Task{Command: "echo ${{ .Unknown }}"}
+	_, err := renderCommand(task, 1, nil)
+	if !errors.Is(err, ErrCommandTemplate) {
+		t.Fatalf("expected template error, got %v", err)
+	}
+	if shouldRetry(task, err) {
+		t.Fatal("expected template error not to be retried")
+	}
+	if ValidateCommandTemplate(task.Command) == nil {
+		t.Fatal("expected validation to fail for unknown variable")
+	}
+	if ValidateCommandTemplate("echo ${{ .ScheduledTime | date") == nil {
+		t.Fatal("expected validation to fail for syntax error")
+	}
+	if err = ValidateCommandTemplate(`echo ${{ .StartTime | addHours 1 | date "HH" }}`); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+}
+
+func TestExecJobRendersAttempt(t *testing.T) {
+	originalGet := httpGetFunc
+	originalSleep := sleepFunc
+	defer func() {
+		httpGetFunc = originalGet
+		sleepFunc = originalSleep
+	}()
+	sleepFunc = func(d time.Duration) {}
+
+	urls := make([]string, 0)
+	httpGetFunc = func(url string, timeout int) httpclient.ResponseWrapper {
+		urls = append(urls, url)
+		if len(urls) == 1 {
+			return httpclient.ResponseWrapper{StatusCode: http.StatusInternalServerError}
+		}
+		return httpclient.ResponseWrapper{StatusCode: http.StatusOK}
+	}
+	task := models.Task{
+		Command:    "http://example.com/?attempt=${{ .Attempt }}",
+		HttpMethod: models.TaskHTTPMethodGet,
+		RetryTimes: 1,
+	}
+	result := execJob(&HTTPHandler{}, task, 1)
+	if result.Err != nil {
+		t.Fatalf("unexpected error: %v", result.Err)
+	}
+	if len(urls) != 2 || urls[0] != "http://example.com/?attempt=1" || urls[1] != "http://example.com/?attempt=2" {
+		t.Fatalf("unexpected urls %v", urls)
+	}
+}
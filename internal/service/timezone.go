@@ -0,0 +1,121 @@
+package service
+
+import (
+	"time"
+
+	"github.com/gocronx-team/cron"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/utils"
+)
+
+// 任务时区
+// 任务表达式按任务配置的时区解析, 未配置时区时使用服务器本地时区
+// 夏令时切换规则:
+//   - 小时字段为*(包括*/n)的表达式按实际经过的时间执行, 跳过的一小时内不执行, 重复的一小时内执行两次
+//   - 小时字段为固定值的表达式按墙上时间执行:
+//     落在跳过时间段内的执行时间点, 在时钟跳变后立即执行一次
+//     落在重复时间段内的执行时间点, 只在第一次出现时执行
+
+// cron.SpecSchedule中表示字段包含*的标志位
+const scheduleStarBit = 1 << 63
+
+// 按任务时区计算执行时间
+type zoneSchedule struct {
+	spec     *cron.SpecSchedule
+	location *time.Location
+}
+
+// 解析任务表达式, timezone为空时使用服务器本地时区
+func parseSchedule(spec, timezone string) (cron.Schedule, error) {
+	location, err := loadTaskLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+	var schedule cron.Schedule
+	err = utils.PanicToError(func() {
+		schedule = cron.Parse(spec)
+	})
+	if err != nil {
+		return nil, err
+	}
+	specSchedule, ok := schedule.(*cron.SpecSchedule)
+	if !ok || location == time.Local {
+		return schedule, nil
+	}
+
+	return &zoneSchedule{spec: specSchedule, location: location}, nil
+}
+
+func parseTaskSchedule(taskModel models.Task) (cron.Schedule, error) {
+	if taskModel.IsOnce() {
+		return parseOnceSchedule(taskModel)
+	}
+
+	return parseSchedule(taskModel.Spec, taskModel.Timezone)
+}
+
+// 加载时区, 为空时返回服务器本地时区
+func loadTaskLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.Local, nil
+	}
+
+	return time.LoadLocation(timezone)
+}
+
+// 返回t之后的下次执行时间, 以服务器本地时区表示, 调度器按本地时区比较时间
+func (s *zoneSchedule) Next(t time.Time) time.Time {
+	if s.spec.Hour&scheduleStarBit > 0 {
+		next := s.spec.Next(t.In(s.location))
+		if next.IsZero() {
+			return next
+		}
+		return next.In(time.Local)
+	}
+
+	// 在不含夏令时的UTC中按墙上时间计算, 再换算为任务时区的实际时间
+	wall := wallClock(t.In(s.location))
+	for {
+		wall = s.spec.Next(wall)
+		if wall.IsZero() {
+			return wall
+		}
+		next := s.fromWallClock(wall)
+		if next.After(t) {
+			return next.In(time.Local)
+		}
+	}
+}
+
+// 把墙上时间换算为任务时区的实际时间
+// 墙上时间不存在时返回时钟跳变的时刻, 出现两次时返回第一次
+func (s *zoneSchedule) fromWallClock(wall time.Time) time.Time {
+	t := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, s.location)
+	actual := wallClock(t)
+	if actual.After(wall) {
+		// 位于跳过的时间段, time.Date按跳变前的偏移换算到了跳变之后
+		start, _ := t.ZoneBounds()
+		return start
+	}
+	if actual.Before(wall) {
+		_, end := t.ZoneBounds()
+		return end
+	}
+	// 重复的时间段内同一墙上时间对应两个时刻, 取较早的一个
+	start, _ := t.ZoneBounds()
+	if !start.IsZero() {
+		_, previousOffset := start.Add(-time.Second).Zone()
+		_, offset := t.Zone()
+		earlier := t.Add(time.Duration(offset-previousOffset) * time.Second)
+		if earlier.Before(start) && wallClock(earlier).Equal(wall) {
+			return earlier
+		}
+	}
+
+	return t
+}
+
+// 去掉时区信息, 以UTC表示的墙上时间
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/gocronx-team/cron"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load location %s failed: %v", name, err)
+	}
+
+	return location
+}
+
+func mustParseSchedule(t *testing.T, spec, timezone string) cron.Schedule {
+	t.Helper()
+	schedule, err := parseSchedule(spec, timezone)
+	if err != nil {
+		t.Fatalf("parse schedule failed: %v", err)
+	}
+
+	return schedule
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	if _, err := parseSchedule("0 0 1 * *", "Mars/Olympus"); err == nil {
+		t.Fatal("expected error for unknown time zone")
+	}
+	if _, err := parseSchedule("invalid", "UTC"); err == nil {
+		t.Fatal("expected error for invalid spec")
+	}
+}
+
+func TestZoneScheduleUsesTaskTimezone(t *testing.T) {
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	schedule := mustParseSchedule(t, "0 0 9 * * *", "Asia/Tokyo")
+
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, tokyo)
+	next := schedule.Next(now)
+	expected := time.Date(2024, 5, 2, 9, 0, 0, 0, tokyo)
+	if !next.Equal(expected) {
+		t.Fatalf("expected %s, got %s", expected, next.In(tokyo))
+	}
+	if next.Location() != time.Local {
+		t.Fatalf("expected next time in local zone, got %s", next.Location())
+	}
+}
+
+func TestZoneScheduleDSTGapRunsAfterJump(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	schedule := mustParseSchedule(t, "0 30 2 * * *", "America/New_York")
+
+	// 2024-03-10 02:00 时钟跳到 03:00, 02:30 不存在
+	now := time.Date(2024, 3, 9, 12, 0, 0, 0, newYork)
+	next := schedule.Next(now)
+	expected := time.Date(2024, 3, 10, 3, 0, 0, 0, newYork)
+	if !next.Equal(expected) {
+		t.Fatalf("expected %s, got %s", expected, next.In(newYork))
+	}
+
+	next = schedule.Next(next)
+	expected = time.Date(2024, 3, 11, 2, 30, 0, 0, newYork)
+	if !next.Equal(expected) {
+		t.Fatalf("expected %s, got %s", expected, next.In(newYork))
+	}
+}
+
+func TestZoneScheduleDSTGapMinutesRunOnce(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	schedule := mustParseSchedule(t, "0 */15 2 * * *", "America/New_York")
+
+	now := time.Date(2024, 3, 10, 1, 59, 0, 0, newYork)
+	next := schedule.Next(now)
+	expected := time.Date(2024, 3, 10, 3, 0, 0, 0, newYork)
+	if !next.Equal(expected) {
+		t.Fatalf("expected %s, got %s", expected, next.In(newYork))
+	}
+	next = schedule.Next(next)
+	expected = time.Date(2024, 3, 11, 2, 0, 0, 0, newYork)
+	if !next.Equal(expected) {
+		t.Fatalf("expected %s, got %s", expected, next.In(newYork))
+	}
+}
+
+func TestZoneScheduleDSTOverlapRunsOnce(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	schedule := mustParseSchedule(t, "0 30 1 * * *", "America/New_York")
+
+	// 2024-11-03 02:00 时钟回拨到 01:00, 01:30 出现两次
+	now := time.Date(2024, 11, 3, 0, 0, 0, 0, newYork)
+	first := schedule.Next(now)
+	if first.In(newYork).Hour() != 1 || first.In(newYork).Minute() != 30 {
+		t.Fatalf("unexpected first run %s", first.In(newYork))
+	}
+	if _, offset := first.In(newYork).Zone(); offset != -4*3600 {
+		t.Fatalf("expected first occurrence in EDT, got offset %d", offset)
+	}
+
+	next := schedule.Next(first)
+	expected := time.Date(2024, 11, 4, 1, 30, 0, 0, newYork)
+	if !next.Equal(expected) {
+		t.Fatalf("expected %s, got %s", expected, next.In(newYork))
+	}
+
+	// 从第二个01:00开始计算也不会重复执行
+	secondOneOClock := first.Add(30 * time.Minute)
+	next = schedule.Next(secondOneOClock)
+	if !next.Equal(expected) {
+		t.Fatalf("expected %s, got %s", expected, next.In(newYork))
+	}
+}
+
+func TestZoneScheduleHourlyFollowsElapsedTime(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	schedule := mustParseSchedule(t, "0 30 * * * *", "America/New_York")
+
+	// 时钟回拨时每小时执行的任务在重复的一小时内执行两次
+	now := time.Date(2024, 11, 3, 1, 0, 0, 0, newYork)
+	first := schedule.Next(now)
+	second := schedule.Next(first)
+	if second.Sub(first) != time.Hour {
+		t.Fatalf("expected runs one hour apart, got %s and %s", first.In(newYork), second.In(newYork))
+	}
+	if second.In(newYork).Hour() != 1 {
+		t.Fatalf("expected second run at 01:30 EST, got %s", second.In(newYork))
+	}
+}
@@ -0,0 +1,359 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocronx-team/cron"
+	"github.com/gocronx-team/gocron/internal/models"
+	"github.com/gocronx-team/gocron/internal/modules/logger"
+)
+
+// 工作流
+// 由任务节点和连线组成的有向无环图, 每条连线配置条件(上游成功、失败、总是)
+// 节点的所有上游执行完成后, 按汇合方式(全部/任一)判断入边条件是否满足, 满足则执行, 否则跳过
+// 汇合节点不执行任务, 只用于等待多个分支; 没有依赖关系的节点并发执行
+// 每次执行生成一条执行记录, 各节点的任务日志通过执行记录ID关联
+
+var (
+	ErrWorkflowEmpty       = errors.New("workflow has no node")
+	ErrWorkflowInvalidNode = errors.New("workflow node is invalid")
+	ErrWorkflowInvalidEdge = errors.New("workflow edge is invalid")
+	ErrWorkflowCycle       = errors.New("workflow contains a cycle")
+)
+
+var ServiceWorkflow Workflow
+
+type Workflow struct{}
+
+// 节点执行状态
+type workflowNodeState int8
+
+const (
+	workflowNodePending workflowNodeState = iota
+	workflowNodeRunning
+	workflowNodeSuccess
+	workflowNodeFailed
+	workflowNodeSkipped
+)
+
+// 调度器中工作流的名称, 与任务ID区分
+func workflowJobName(id int) string {
+	return "workflow-" + strconv.Itoa(id)
+}
+
+// ValidateWorkflow 校验节点标识唯一、连线指向已有节点且图中不存在环
+func ValidateWorkflow(workflow models.Workflow) error {
+	if len(workflow.Nodes) == 0 {
+		return ErrWorkflowEmpty
+	}
+	nodeKeys := make(map[string]bool, len(workflow.Nodes))
+	for _, node := range workflow.Nodes {
+		if node.NodeKey == "" || nodeKeys[node.NodeKey] {
+			return fmt.Errorf("%w: %q", ErrWorkflowInvalidNode, node.NodeKey)
+		}
+		nodeKeys[node.NodeKey] = true
+	}
+	edgeKeys := make(map[string]bool, len(workflow.Edges))
+	inDegree := make(map[string]int, len(workflow.Nodes))
+	outgoing := make(map[string][]string, len(workflow.Nodes))
+	for _, edge := range workflow.Edges {
+		edgeKey := edge.FromKey + "->" + edge.ToKey
+		if !nodeKeys[edge.FromKey] || !nodeKeys[edge.ToKey] || edge.FromKey == edge.ToKey || edgeKeys[edgeKey] {
+			return fmt.Errorf("%w: %s", ErrWorkflowInvalidEdge, edgeKey)
+		}
+		edgeKeys[edgeKey] = true
+		inDegree[edge.ToKey]++
+		outgoing[edge.FromKey] = append(outgoing[edge.FromKey], edge.ToKey)
+	}
+
+	// 拓扑排序, 无法排序的节点位于环中
+	queue := make([]string, 0, len(workflow.Nodes))
+	for _, node := range workflow.Nodes {
+		if inDegree[node.NodeKey] == 0 {
+			queue = append(queue, node.NodeKey)
+		}
+	}
+	visited := 0
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, to := range outgoing[key] {
+			inDegree[to]--
+			if inDegree[to] == 0 {
+				queue = append(queue, to)
+			}
+		}
+	}
+	if visited != len(workflow.Nodes) {
+		return ErrWorkflowCycle
+	}
+
+	return nil
+}
+
+// 从数据库加载所有启用的工作流到调度器
+func (w Workflow) loadWorkflows() error {
+	workflowModel := new(models.Workflow)
+	workflows, err := workflowModel.ActiveList()
+	if err != nil {
+		return err
+	}
+	for _, item := range workflows {
+		logger.Infof("添加工作流到调度器#ID-%d#名称-%s", item.Id, item.Name)
+		w.add(item)
+	}
+
+	return nil
+}
+
+// 删除工作流后添加
+func (w Workflow) RemoveAndAdd(workflow models.Workflow) {
+	w.Remove(workflow.Id)
+	ServiceTask.runOnScheduler(func() {
+		w.add(workflow)
+	})
+}
+
+func (w Workflow) add(workflow models.Workflow) {
+	if workflow.Status != models.Enabled || workflow.Spec == "" {
+		return
+	}
+	schedule, err := parseSchedule(workflow.Spec, workflow.Timezone)
+	if err != nil {
+		logger.Error("添加工作流到调度器失败#", err)
+		return
+	}
+	workflowId := workflow.Id
+	serviceCron.Schedule(schedule, cron.FuncJob(func() {
+		w.run(workflowId, jobTrigger{Type: models.TaskLogTriggerCron, ScheduledTime: time.Now().Truncate(time.Second)})
+	}), workflowJobName(workflowId))
+}
+
+func (w Workflow) Remove(id int) {
+	ServiceTask.runOnScheduler(func() {
+		serviceCron.RemoveJob(workflowJobName(id))
+	})
+}
+
+// 手动运行工作流, 高可用模式下由调度节点执行
+func (w Workflow) Run(workflowId int) {
+	ServiceTask.dispatch(models.SchedulerRequestWorkflowRun, int64(workflowId), nil, func() {
+		go w.run(workflowId, jobTrigger{Type: models.TaskLogTriggerManual, ScheduledTime: time.Now()})
+	})
+}
+
+// 下次执行时间, 以工作流时区表示
+func (w Workflow) NextRunTime(workflow models.Workflow) time.Time {
+	if workflow.Status != models.Enabled || workflow.Spec == "" {
+		return time.Time{}
+	}
+	location, err := loadTaskLocation(workflow.Timezone)
+	if err != nil {
+		return time.Time{}
+	}
+	var next time.Time
+	if ServiceTask.IsScheduler() {
+		name := workflowJobName(workflow.Id)
+		for _, item := range serviceCron.Entries() {
+			if item.Name == name {
+				next = item.Next
+				break
+			}
+		}
+	} else {
+		schedule, err := parseSchedule(workflow.Spec, workflow.Timezone)
+		if err != nil {
+			return time.Time{}
+		}
+		next = schedule.Next(time.Now())
+	}
+	if next.IsZero() {
+		return next
+	}
+
+	return next.In(location)
+}
+
+// 执行一次工作流, 执行前从数据库读取最新的节点和连线
+func (w Workflow) run(workflowId int, trigger jobTrigger) {
+	workflowModel := new(models.Workflow)
+	workflow, err := workflowModel.Detail(workflowId)
+	if err != nil {
+		logger.Errorf("工作流执行#获取工作流详情失败#ID-%d#%s", workflowId, err)
+		return
+	}
+	runModel := &models.WorkflowRun{
+		WorkflowId:  workflow.Id,
+		Name:        workflow.Name,
+		TriggerType: trigger.Type,
+		Status:      models.Running,
+		StartTime:   models.LocalTime(time.Now()),
+	}
+	runId, err := runModel.Create()
+	if err != nil {
+		logger.Errorf("工作流执行#写入执行记录失败#ID-%d#%s", workflowId, err)
+		return
+	}
+	logger.Infof("开始执行工作流#ID-%d#名称-%s#执行记录ID-%d", workflow.Id, workflow.Name, runId)
+
+	stepTrigger := jobTrigger{Type: models.TaskLogTriggerWorkflow, ScheduledTime: trigger.ScheduledTime, WorkflowRunId: runId}
+	states := executeWorkflow(workflow, func(node models.WorkflowNode) bool {
+		return runWorkflowNode(workflow, node, stepTrigger)
+	})
+
+	status := models.Finish
+	if workflowFailed(workflow, states) {
+		status = models.Failure
+	}
+	_, err = runModel.Update(runId, models.CommonMap{
+		"status":   status,
+		"result":   workflowResult(workflow, states),
+		"end_time": time.Now(),
+	})
+	if err != nil {
+		logger.Errorf("工作流执行#更新执行记录失败#执行记录ID-%d#%s", runId, err)
+	}
+	logger.Infof("工作流执行完成#ID-%d#名称-%s#执行记录ID-%d", workflow.Id, workflow.Name, runId)
+}
+
+// 执行节点任务, 返回是否执行成功, 汇合节点直接返回成功
+func runWorkflowNode(workflow models.Workflow, node models.WorkflowNode, trigger jobTrigger) bool {
+	if node.IsJoin() {
+		return true
+	}
+	taskModel := new(models.Task)
+	task, err := taskModel.Detail(node.TaskId)
+	if err != nil || task.Id == 0 {
+		logger.Errorf("工作流执行#获取节点任务失败#工作流ID-%d#节点-%s#任务ID-%d", workflow.Id, node.NodeKey, node.TaskId)
+		return false
+	}
+	handler := createHandler(task)
+	if handler == nil {
+		logger.Errorf("工作流执行#不支持的任务协议#工作流ID-%d#任务ID-%d", workflow.Id, task.Id)
+		return false
+	}
+	task.Spec = fmt.Sprintf("工作流(%s)", workflow.Name)
+	taskResult, ok := runJob(handler, task, trigger)
+
+	return ok && taskResult.Err == nil
+}
+
+// 按依赖关系执行各节点, 返回每个节点的最终状态
+func executeWorkflow(workflow models.Workflow, runNode func(node models.WorkflowNode) bool) map[string]workflowNodeState {
+	incoming := make(map[string][]models.WorkflowEdge, len(workflow.Nodes))
+	for _, edge := range workflow.Edges {
+		incoming[edge.ToKey] = append(incoming[edge.ToKey], edge)
+	}
+	states := make(map[string]workflowNodeState, len(workflow.Nodes))
+	type nodeResult struct {
+		key     string
+		success bool
+	}
+	results := make(chan nodeResult, len(workflow.Nodes))
+	running := 0
+	for {
+		// 跳过节点可能使下游节点变为可判断, 循环到没有新的节点可处理为止
+		for changed := true; changed; {
+			changed = false
+			for _, node := range workflow.Nodes {
+				if states[node.NodeKey] != workflowNodePending {
+					continue
+				}
+				decided, satisfied := workflowNodeReady(node, incoming[node.NodeKey], states)
+				if !decided {
+					continue
+				}
+				changed = true
+				if !satisfied {
+					states[node.NodeKey] = workflowNodeSkipped
+					continue
+				}
+				states[node.NodeKey] = workflowNodeRunning
+				running++
+				go func(node models.WorkflowNode) {
+					results <- nodeResult{key: node.NodeKey, success: runNode(node)}
+				}(node)
+			}
+		}
+		if running == 0 {
+			break
+		}
+		result := <-results
+		running--
+		if result.success {
+			states[result.key] = workflowNodeSuccess
+		} else {
+			states[result.key] = workflowNodeFailed
+		}
+	}
+
+	return states
+}
+
+// 判断节点能否执行, decided为false表示还有上游节点未执行完成
+func workflowNodeReady(node models.WorkflowNode, edges []models.WorkflowEdge, states map[string]workflowNodeState) (decided, satisfied bool) {
+	if len(edges) == 0 {
+		return true, true
+	}
+	satisfiedCount := 0
+	for _, edge := range edges {
+		switch states[edge.FromKey] {
+		case workflowNodePending, workflowNodeRunning:
+			return false, false
+		case workflowNodeSuccess:
+			if edge.Condition != models.WorkflowEdgeOnFailure {
+				satisfiedCount++
+			}
+		case workflowNodeFailed:
+			if edge.Condition != models.WorkflowEdgeOnSuccess {
+				satisfiedCount++
+			}
+		}
+	}
+	if node.JoinMode == models.WorkflowJoinAny {
+		return true, satisfiedCount > 0
+	}
+
+	return true, satisfiedCount == len(edges)
+}
+
+// 存在未被处理的失败节点时工作流执行失败, 即失败节点没有失败或总是执行的下游连线
+func workflowFailed(workflow models.Workflow, states map[string]workflowNodeState) bool {
+	handled := make(map[string]bool)
+	for _, edge := range workflow.Edges {
+		if edge.Condition != models.WorkflowEdgeOnSuccess {
+			handled[edge.FromKey] = true
+		}
+	}
+	for key, state := range states {
+		if state == workflowNodeFailed && !handled[key] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 执行结果, 每行一个节点的执行状态
+func workflowResult(workflow models.Workflow, states map[string]workflowNodeState) string {
+	stateNames := map[workflowNodeState]string{
+		workflowNodeSuccess: "成功",
+		workflowNodeFailed:  "失败",
+		workflowNodeSkipped: "跳过",
+	}
+	lines := make([]string, 0, len(workflow.Nodes))
+	for _, node := range workflow.Nodes {
+		name := node.Name
+		if name == "" {
+			name = node.NodeKey
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", name, stateNames[states[node.NodeKey]]))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gocronx-team/gocron/internal/models"
+)
+
+func workflowNode(key string, taskId int) models.WorkflowNode {
+	return models.WorkflowNode{NodeKey: key, Name: key, TaskId: taskId, JoinMode: models.WorkflowJoinAll}
+}
+
+func workflowEdge(from, to string, condition models.WorkflowEdgeCondition) models.WorkflowEdge {
+	return models.WorkflowEdge{FromKey: from, ToKey: to, Condition: condition}
+}
+
+// 记录节点执行顺序, failed中的节点返回执行失败
+type workflowRecorder struct {
+	mu     sync.Mutex
+	order  []string
+	failed map[string]bool
+}
+
+func (r *workflowRecorder) run(node models.WorkflowNode) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.order = append(r.order, node.NodeKey)
+
+	return !r.failed[node.NodeKey]
+}
+
+func (r *workflowRecorder) index(key string) int {
+	for i, item := range r.order {
+		if item == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func TestValidateWorkflow(t *testing.T) {
+	nodes := []models.WorkflowNode{workflowNode("a", 1), workflowNode("b", 2), workflowNode("c", 3)}
+	valid := models.Workflow{Nodes: nodes, Edges: []models.WorkflowEdge{
+		workflowEdge("a", "c", models.WorkflowEdgeOnSuccess),
+		workflowEdge("b", "c", models.WorkflowEdgeOnSuccess),
+	}}
+	if err := ValidateWorkflow(valid); err != nil {
+		t.Fatalf("expected valid workflow, got %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		workflow models.Workflow
+		expected error
+	}{
+		{"empty", models.Workflow{}, ErrWorkflowEmpty},
+		{"duplicate node", models.Workflow{Nodes: []models.WorkflowNode{workflowNode("a", 1), workflowNode("a", 2)}}, ErrWorkflowInvalidNode},
+		{"unknown node", models.Workflow{Nodes: nodes, Edges: []models.WorkflowEdge{workflowEdge("a", "x", models.WorkflowEdgeAlways)}}, ErrWorkflowInvalidEdge},
+		{"self loop", models.Workflow{Nodes: nodes, Edges: []models.WorkflowEdge{workflowEdge("a", "a", models.WorkflowEdgeAlways)}}, ErrWorkflowInvalidEdge},
+		{"cycle", models.Workflow{Nodes: nodes, Edges: []models.WorkflowEdge{
+			workflowEdge("a", "b", models.WorkflowEdgeOnSuccess),
+			workflowEdge("b", "c", models.WorkflowEdgeOnSuccess),
+			workflowEdge("c", "a", models.WorkflowEdgeOnSuccess),
+		}}, ErrWorkflowCycle},
+	}
+	for _, item := range cases {
+		if err := ValidateWorkflow(item.workflow); !errors.Is(err, item.expected) {
+			t.Fatalf("%s: expected %v, got %v", item.name, item.expected, err)
+		}
+	}
+}
+
+func TestExecuteWorkflowWaitsForAllParents(t *testing.T) {
+	workflow := models.Workflow{
+		Nodes: []models.WorkflowNode{workflowNode("c", 3), workflowNode("a", 1), workflowNode("b", 2)},
+		Edges: []models.WorkflowEdge{
+			workflowEdge("a", "c", models.WorkflowEdgeOnSuccess),
+			workflowEdge("b", "c", models.WorkflowEdgeOnSuccess),
+		},
+	}
+	recorder := &workflowRecorder{}
+	states := executeWorkflow(workflow, recorder.run)
+
+	if len(recorder.order) != 3 || recorder.index("c") != 2 {
+		t.Fatalf("expected c to run after a and b, got %v", recorder.order)
+	}
+	for key, state := range states {
+		if state != workflowNodeSuccess {
+			t.Fatalf("expected node %s to succeed, got %d", key, state)
+		}
+	}
+	if workflowFailed(workflow, states) {
+		t.Fatal("expected workflow to succeed")
+	}
+}
+
+func TestExecuteWorkflowEdgeConditions(t *testing.T) {
+	workflow := models.Workflow{
+		Nodes: []models.WorkflowNode{
+			workflowNode("build", 1), workflowNode("deploy", 2), workflowNode("rollback", 3),
+			workflowNode("notify", 4), workflowNode("verify", 5),
+		},
+		Edges: []models.WorkflowEdge{
+			workflowEdge("build", "deploy", models.WorkflowEdgeOnSuccess),
+			workflowEdge("build", "rollback", models.WorkflowEdgeOnFailure),
+			workflowEdge("build", "notify", models.WorkflowEdgeAlways),
+			workflowEdge("deploy", "verify", models.WorkflowEdgeOnSuccess),
+		},
+	}
+	recorder := &workflowRecorder{failed: map[string]bool{"build": true}}
+	states := executeWorkflow(workflow, recorder.run)
+
+	expected := map[string]workflowNodeState{
+		"build":    workflowNodeFailed,
+		"deploy":   workflowNodeSkipped,
+		"rollback": workflowNodeSuccess,
+		"notify":   workflowNodeSuccess,
+		"verify":   workflowNodeSkipped,
+	}
+	for key, state := range expected {
+		if states[key] != state {
+			t.Fatalf("node %s: expected state %d, got %d", key, state, states[key])
+		}
+	}
+	// 失败已由下游连线处理
+	if workflowFailed(workflow, states) {
+		t.Fatal("expected handled failure not to fail the workflow")
+	}
+}
+
+func TestExecuteWorkflowJoinNode(t *testing.T) {
+	anyJoin := workflowNode("join", 0)
+	anyJoin.JoinMode = models.WorkflowJoinAny
+	workflow := models.Workflow{
+		Nodes: []models.WorkflowNode{workflowNode("a", 1), workflowNode("b", 2), anyJoin, workflowNode("c", 3)},
+		Edges: []models.WorkflowEdge{
+			workflowEdge("a", "join", models.WorkflowEdgeOnSuccess),
+			workflowEdge("b", "join", models.WorkflowEdgeOnSuccess),
+			workflowEdge("join", "c", models.WorkflowEdgeOnSuccess),
+		},
+	}
+	recorder := &workflowRecorder{failed: map[string]bool{"b": true}}
+	states := executeWorkflow(workflow, recorder.run)
+
+	if states["join"] != workflowNodeSuccess || states["c"] != workflowNodeSuccess {
+		t.Fatalf("expected any-join to continue after one branch succeeded, got %v", states)
+	}
+	if !workflowFailed(workflow, states) {
+		t.Fatal("expected unhandled failure to fail the workflow")
+	}
+
+	workflow.Nodes[2].JoinMode = models.WorkflowJoinAll
+	states = executeWorkflow(workflow, (&workflowRecorder{failed: map[string]bool{"b": true}}).run)
+	if states["join"] != workflowNodeSkipped || states["c"] != workflowNodeSkipped {
+		t.Fatalf("expected all-join to be skipped when one branch failed, got %v", states)
+	}
+}
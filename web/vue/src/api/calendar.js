@@ -0,0 +1,29 @@
+import httpClient from '../utils/httpClient'
+
+export default {
+  // 日历列表
+  list (query, callback) {
+    httpClient.get('/calendar', query, callback)
+  },
+
+  all (callback) {
+    httpClient.get('/calendar/all', {}, callback)
+  },
+
+  detail (id, callback) {
+    httpClient.get(`/calendar/${id}`, {}, callback)
+  },
+
+  update (data, callback) {
+    httpClient.postJson('/calendar/store', data, callback)
+  },
+
+  // 导入ics文件
+  importIcs (formData, callback) {
+    httpClient.upload('/calendar/import', formData, callback)
+  },
+
+  remove (id, callback) {
+    httpClient.post(`/calendar/remove/${id}`, {}, callback)
+  }
+}
@@ -0,0 +1,20 @@
+import httpClient from '../utils/httpClient'
+
+export default {
+  // 并发限制组列表
+  list (query, callback) {
+    httpClient.get('/concurrency-group', query, callback)
+  },
+
+  all (callback) {
+    httpClient.get('/concurrency-group/all', {}, callback)
+  },
+
+  update (data, callback) {
+    httpClient.postJson('/concurrency-group/store', data, callback)
+  },
+
+  remove (id, callback) {
+    httpClient.post(`/concurrency-group/remove/${id}`, {}, callback)
+  }
+}
@@ -0,0 +1,24 @@
+import httpClient from '../utils/httpClient'
+
+export default {
+  // 数据源列表
+  list (query, callback) {
+    httpClient.get('/datasource', query, callback)
+  },
+
+  all (callback) {
+    httpClient.get('/datasource/all', {}, callback)
+  },
+
+  update (data, callback) {
+    httpClient.postJson('/datasource/store', data, callback)
+  },
+
+  ping (data, callback) {
+    httpClient.postJson('/datasource/ping', data, callback)
+  },
+
+  remove (id, callback) {
+    httpClient.post(`/datasource/remove/${id}`, {}, callback)
+  }
+}
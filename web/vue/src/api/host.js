@@ -24,5 +24,13 @@ export default {
 
   ping (id, callback) {
     httpClient.get(`/host/ping/${id}`, {}, callback)
+  },
+
+  sshPing (id, callback) {
+    httpClient.get(`/host/ssh-ping/${id}`, {}, callback)
+  },
+
+  sshHostKey (data, callback) {
+    httpClient.post('/host/ssh-host-key', data, callback)
   }
 }
@@ -0,0 +1,20 @@
+import httpClient from '../utils/httpClient'
+
+export default {
+  // HTTP客户端配置列表
+  list (query, callback) {
+    httpClient.get('/http-profile', query, callback)
+  },
+
+  all (callback) {
+    httpClient.get('/http-profile/all', {}, callback)
+  },
+
+  update (data, callback) {
+    httpClient.postJson('/http-profile/store', data, callback)
+  },
+
+  remove (id, callback) {
+    httpClient.post(`/http-profile/remove/${id}`, {}, callback)
+  }
+}
@@ -41,6 +41,30 @@ export default {
     httpClient.get(`/task/run/${id}`, { _t: Date.now() }, callback)
   },
 
+  previewCron (params, callback, errorCallback) {
+    httpClient.get('/task/cron/preview', params, callback, errorCallback)
+  },
+
+  backfill (id, data, callback) {
+    httpClient.post(`/task/backfill/${id}`, data, callback)
+  },
+
+  backfills (query, callback) {
+    httpClient.get('/task/backfill', query, callback)
+  },
+
+  cancelBackfill (id, callback) {
+    httpClient.post(`/task/backfill/cancel/${id}`, {}, callback)
+  },
+
+  resetPingToken (id, callback) {
+    httpClient.post(`/task/ping-token/reset/${id}`, {}, callback)
+  },
+
+  runWithOverride (id, data, callback) {
+    httpClient.postJson(`/task/run/${id}`, data, callback)
+  },
+
   batchEnable (ids, callback) {
     httpClient.postJson('/task/batch-enable', { ids }, callback)
   },
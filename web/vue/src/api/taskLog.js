@@ -11,5 +11,13 @@ export default {
 
   stop (id, taskId, callback) {
     httpClient.post('/task/log/stop', {id, task_id: taskId}, callback)
+  },
+
+  hosts (id, callback) {
+    httpClient.get(`/task/log/hosts/${id}`, {}, callback)
+  },
+
+  replay (id, callback) {
+    httpClient.post(`/task/log/replay/${id}`, {}, callback)
   }
 }
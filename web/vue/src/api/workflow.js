@@ -0,0 +1,49 @@
+import httpClient from '../utils/httpClient'
+
+export default {
+  // 工作流列表
+  list (query, callback) {
+    httpClient.get('/workflow', query, callback)
+  },
+
+  // 工作流详情及所有任务, 用于选择节点任务
+  detail (id, callback) {
+    if (!id) {
+      httpClient.get('/task', { page_size: 1000 }, (tasks) => {
+        callback(null, tasks.data || [])
+      })
+      return
+    }
+    httpClient.batchGet([
+      { uri: `/workflow/${id}` },
+      { uri: '/task', params: { page_size: 1000 } }
+    ], (workflow, tasks) => {
+      callback(workflow, tasks.data || [])
+    })
+  },
+
+  update (data, callback) {
+    httpClient.postJson('/workflow/store', data, callback)
+  },
+
+  remove (id, callback) {
+    httpClient.post(`/workflow/remove/${id}`, {}, callback)
+  },
+
+  enable (id, callback) {
+    httpClient.post(`/workflow/enable/${id}`, {}, callback)
+  },
+
+  disable (id, callback) {
+    httpClient.post(`/workflow/disable/${id}`, {}, callback)
+  },
+
+  run (id, callback) {
+    httpClient.get(`/workflow/run/${id}`, { _t: Date.now() }, callback)
+  },
+
+  // 执行记录
+  runs (query, callback) {
+    httpClient.get('/workflow/runs', query, callback)
+  }
+}
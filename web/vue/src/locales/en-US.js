@@ -53,25 +53,152 @@ export default {
     type: 'Task Type',
     mainTask: 'Main Task',
     childTask: 'Child Task',
-    dependency: 'Dependency',
-    strongDependency: 'Strong Dependency',
-    weakDependency: 'Weak Dependency',
-    childTaskId: 'Child Task ID',
-    childTaskIdPlaceholder: 'Multiple IDs separated by comma',
     cronExpression: 'Crontab Expression',
     cronPlaceholder: 'Second Minute Hour Day Month Week',
+    timezone: 'Time Zone',
+    timezonePlaceholder: 'e.g. America/New_York, default server time zone',
     cronExample: 'Examples',
+    cronPreview: 'Preview',
+    cronNextTimes: 'Next fire times',
     protocol: 'Execution Method',
     httpMethod: 'HTTP Method',
+    httpProfile: 'HTTP Client',
+    httpProfileDefault: 'Default',
+    sqlDatasource: 'Datasource',
+    sqlDatasourcePlaceholder: 'Please select a datasource',
+    sqlFailOnRows: 'Fail When Rows Returned',
+    sqlStatements: 'SQL Statements',
+    sqlStatementsRequired: 'Please enter SQL statements',
+    sqlStatementsPlaceholder: 'Separate statements with semicolons, command templates supported',
+    sqlTip: 'Statements run in order on one connection and stop at the first failure. Queries record the row count and the first 20 rows, other statements record affected rows. With Fail When Rows Returned on, a query can check data, e.g. SELECT id FROM orders WHERE status = \'pending\' AND created < ... Timeout defaults to 300 seconds when not set',
+    httpAsync: 'Async',
+    httpDeadline: 'Callback wait (s)',
+    httpAsyncTip: 'After a successful request the run stays running until the remote system calls back: POST to the URL in header X-Gocron-Callback-Url (requires callback.url) with status=success or failure and output, as JSON or form data. The token is also sent in header X-Gocron-Callback-Token and available as template variables .CallbackToken and .CallbackUrl. The run fails if no callback arrives within the wait time, 0 means 3600 seconds',
+    httpHeaders: 'Headers',
+    httpHeadersPlaceholder: 'One per line, e.g. X-Token: abc',
+    httpBodyType: 'Body',
+    httpBodyNone: 'None',
+    httpBodyRaw: 'Raw',
+    httpBodyForm: 'Form',
+    httpBody: 'Body Content',
+    httpAuthType: 'Auth',
+    httpAuthNone: 'No Auth',
+    httpAuthUser: 'Username',
+    httpAuthPassword: 'Password',
+    httpAuthToken: 'Token',
+    httpStatusCodes: 'Success Status',
+    httpStatusCodesPlaceholder: 'Default 200-299, e.g. 200,201,300-399',
+    httpAssertRegex: 'Response Regex',
+    httpAssertRegexPlaceholder: 'Regex the response must match, optional',
+    httpJsonPath: 'Response JSON Path',
+    httpJsonPathPlaceholder: 'e.g. data.items[0].status, optional',
+    httpJsonValue: 'Equals',
+    httpTip: 'URL and body support command templates. The task succeeds when the status code is a success status and response assertions pass; assertion failures have the error type Assertion failed. Without a timeout, HTTP tasks run at most 300 seconds',
     taskNode: 'Task Node',
     taskNodePlaceholder: 'Please select task node',
     command: 'Command',
     timeout: 'Task Timeout',
     singleInstance: 'Single Instance',
+    overlapPolicy: 'If Still Running',
+    overlapSkip: 'Skip this run',
+    overlapQueue: 'Queue until previous run finishes',
+    overlapReplace: 'Stop previous run and start again',
+    hostStrategy: 'Run on',
+    hostStrategyAll: 'All hosts',
+    hostStrategyRandom: 'One random host',
+    hostStrategyRoundRobin: 'Round robin',
+    hostStrategyLeastRecent: 'Least recently used host',
+    hostStrategyFailover: 'Failover (in order, next host when unavailable)',
+    hostTarget: 'Hosts',
+    hostTargetList: 'Select hosts',
+    hostTargetSelector: 'Label selector',
+    hostSelectorPlaceholder: 'key=value,key=value, hosts matching all labels are chosen at each run',
+    rollingBatch: 'Hosts per batch',
+    rollingPause: 'Batch pause (s)',
+    rollingMaxFail: 'Max failed hosts',
+    rollingFailRate: 'Max failure rate (%)',
+    rollingTip: 'Rolling run: hosts run in batches in order; remaining hosts are skipped once failed hosts exceed the limit. A failure rate above 0 is used instead of the host count',
+    hostSuccessRule: 'Success rule',
+    hostSuccessAll: 'All hosts succeed',
+    hostSuccessAny: 'Any host succeeds',
+    hostSuccessQuorum: 'More than half of hosts succeed',
+    backfill: 'Backfill',
+    backfillRecords: 'Backfills',
+    backfillTip: 'Run every fire time of task "{name}" within the range, in the task timezone',
+    backfillRange: 'Time range',
+    backfillStartTime: 'Start time',
+    backfillEndTime: 'End time',
+    backfillParallelism: 'Parallelism',
+    backfillRangeRequired: 'Please select a time range',
+    backfillStarted: 'Backfill started',
+    backfillProgress: 'Progress',
+    backfillCancel: 'Cancel backfill',
+    backfillCancelConfirm: 'Cancel this backfill? Running executions will be stopped',
     retryTimes: 'Retry Times on Failure',
     retryTimesPlaceholder: '0 - 10, default 0, no retry',
     retryInterval: 'Retry Interval on Failure',
     retryIntervalPlaceholder: '0 - 3600 (seconds), default 0, use system default',
+    retryStrategy: 'Retry Backoff',
+    retryDefault: 'Default',
+    retryFixed: 'Fixed',
+    retryLinear: 'Linear',
+    retryExponential: 'Exponential',
+    retryMaxInterval: 'Max Retry Interval',
+    retryMaxIntervalPlaceholder: '0 - 86400 (seconds), default 0, no limit',
+    retryJitter: 'Jitter',
+    retryJitterPlaceholder: '0 - 100 (%), randomize interval within this ratio',
+    retryOn: 'Retry On',
+    retryOnPlaceholder: 'Retry all failures if empty',
+    errorUnavailable: 'Unavailable',
+    errorTimeout: 'Timeout',
+    errorHttp5xx: 'HTTP 5xx',
+    errorHttp4xx: 'HTTP 4xx',
+    errorExit: 'Command failed (non-zero exit)',
+    errorOther: 'Other errors',
+    errorAssert: 'Assertion failed or SQL rows returned',
+    misfirePolicy: 'Misfire Policy',
+    misfireSkip: 'Skip',
+    misfireRunOnce: 'Run Once',
+    misfireRunAll: 'Run Every Missed',
+    misfireMaxRuns: 'Max Catch-up Runs',
+    misfireMaxRunsPlaceholder: '0 - 100, default 0, at most 10 runs',
+    slaMaxDuration: 'Expected Max Duration',
+    slaMaxDurationPlaceholder: '0 - 86400 (seconds), alert via task notification when exceeded, default 0, no check',
+    slaDeadline: 'Latest Completion Time',
+    slaDeadlinePlaceholder: 'Alert if not succeeded by this time each day',
+    protocolHeartbeat: 'Heartbeat',
+    pingGrace: 'Heartbeat Grace',
+    pingGracePlaceholder: '0 - 86400 (seconds), time to wait for the heartbeat after the expected time',
+    pingUrl: 'Heartbeat URL',
+    pingUrlAfterSave: 'The heartbeat URL is generated after saving',
+    resetPingToken: 'Regenerate',
+    confirmResetPingToken: 'The current heartbeat URL will stop working. Regenerate it?',
+    heartbeatTip: 'The cron expression sets when heartbeats are expected. External jobs send a GET or POST request to the heartbeat URL when done, and the POST body is recorded as output. A notification is sent if no heartbeat arrives before the grace period ends',
+    rerunInterrupted: 'Rerun If Interrupted',
+    runArgs: 'Extra Arguments',
+    runArgsPlaceholder: 'Split by spaces, each quoted and appended to the command for this run only',
+    runArgsHttpPlaceholder: 'Appended to the URL query, e.g. a=1&b=2',
+    runEnv: 'Environment',
+    runEnvPlaceholder: 'One per line, KEY=VALUE',
+    runEnvInvalid: 'Invalid environment variable, use one KEY=VALUE per line',
+    runHosts: 'Hosts',
+    runHostsPlaceholder: 'Run on all task hosts if empty',
+    runTimeoutPlaceholder: 'Use the task timeout if empty',
+    scheduleType: 'Schedule',
+    scheduleCron: 'Recurring',
+    scheduleOnce: 'One-shot',
+    runAt: 'Run At',
+    runAtPlaceholder: 'Select run time, in task time zone',
+    onceAction: 'After Run',
+    onceKeep: 'Keep Task',
+    onceDisable: 'Disable Task',
+    onceDelete: 'Delete Task',
+    excludeCalendars: 'Exclude Calendars',
+    excludeCalendarsPlaceholder: 'Do not run on dates in these calendars',
+    includeCalendars: 'Include Calendars',
+    includeCalendarsPlaceholder: 'Run only on dates in these calendars',
+    concurrencyGroups: 'Concurrency Groups',
+    concurrencyGroupsPlaceholder: 'Only the global concurrency limit applies if empty',
     notification: 'Task Notification',
     notifyType: 'Notification Type',
     notifyReceiver: 'Receiver',
@@ -87,8 +214,9 @@ export default {
     viewLog: 'View Log',
     enable: 'Enable',
     disable: 'Disable',
-    mainTaskTip: 'Main task can configure multiple child tasks. Child tasks will be executed automatically after main task completes.\nTask type cannot be changed after creation.',
-    dependencyTip: 'Strong Dependency: Child tasks run only when main task succeeds\nWeak Dependency: Child tasks run regardless of main task result',
+    mainTaskTip: 'Main tasks run on their schedule. Child tasks are never scheduled and run as workflow nodes.\nTask type cannot be changed after creation.',
+    dependencyTip: 'Configure dependencies between tasks in workflows. Existing child task settings have been migrated to workflows.',
+    commandTemplateTip: 'Commands support template variables: ScheduledTime, StartTime, TaskLogId, TaskId, TaskName, Host (host alias), HostName, Attempt (starting from 1), e.g. the day before as {example}; quote values used in shell commands with shellquote and escape values used in URLs with urlquery, e.g. {quoteExample}',
     timeoutTip: 'Force terminate task on timeout, range 0-86400 (seconds), default 0, no limit',
     singleInstanceTip: 'Single instance mode: whether to execute next scheduled task if previous task is still running',
     cronStandard: 'Standard Syntax (Second Minute Hour Day Month Week)',
@@ -108,6 +236,24 @@ export default {
     alias: 'Alias',
     port: 'Port',
     remark: 'Remark',
+    labels: 'Labels',
+    labelsPlaceholder: 'Format key=value,key=value, e.g. role=db,env=prod',
+    sshPort: 'SSH Port',
+    sshUser: 'SSH User',
+    sshUserPlaceholder: 'Set to run SSH tasks without installing gocron-node',
+    sshAuth: 'Authentication',
+    sshAuthPassword: 'Password',
+    sshAuthKey: 'Private Key',
+    sshPassword: 'SSH Password',
+    sshKey: 'SSH Private Key',
+    sshPassphrase: 'Key Passphrase',
+    sshSecretKeep: 'Saved, leave empty to keep',
+    hostKey: 'Host Key',
+    fetchHostKey: 'Fetch Host Key',
+    hostKeyTip: 'Used to verify the host identity, get it with ssh-keyscan or cat /etc/ssh/ssh_host_ed25519_key.pub on the host',
+    hostKeyConfirm: 'Please verify the fetched host key matches the key on the host before saving',
+    sshTest: 'Test SSH',
+    installLabelsTip: "To label the host, set GOCRON_LABELS before bash, e.g. GOCRON_LABELS='role=db,env=prod' bash",
     createTime: 'Create Time',
     createNew: 'Add Node',
     namePlaceholder: 'Please enter host name',
@@ -225,7 +371,123 @@ export default {
     output: 'Output',
     success: 'Success',
     failed: 'Failed',
-    viewOutput: 'View Output'
+    viewOutput: 'View Output',
+    triggerType: 'Trigger',
+    triggerCron: 'Cron',
+    triggerManual: 'Manual',
+    triggerDependency: 'Dependency',
+    triggerMisfire: 'Misfire Catch-up',
+    triggerWorkflow: 'Workflow',
+    triggerRerun: 'Rerun After Interrupt',
+    triggerPing: 'Heartbeat',
+    triggerBackfill: 'Backfill',
+    backfillId: 'Backfill',
+    overrides: 'Overrides',
+    replay: 'Run Again',
+    confirmReplay: 'Run the task again with the parameters of this log?',
+    workflowRunId: 'Workflow Run',
+    attempts: 'Attempts',
+    hostResults: 'Per-host results',
+    host: 'Host',
+    attemptNo: 'Attempt',
+    errorType: 'Error type',
+    attempt: 'Attempt {n}',
+    scheduledTime: 'Scheduled Time',
+    skipped: 'Skipped',
+    queued: 'Queued',
+    interrupted: 'Interrupted'
+  },
+  concurrencyGroup: {
+    menu: 'Concurrency',
+    name: 'Group Name',
+    nameRequired: 'Please enter group name',
+    maxConcurrency: 'Max Concurrency',
+    maxConcurrencyRequired: 'Please enter max concurrency between 1-1000',
+    scope: 'Scope',
+    scopeGroup: 'Shared by group',
+    scopeHost: 'Per host',
+    scopeTask: 'Per task',
+    tag: 'Auto-join Tag',
+    tagPlaceholder: 'Tasks with this tag join the group automatically',
+    confirmDelete: 'Are you sure to delete this concurrency group?'
+  },
+  httpProfile: {
+    menu: 'HTTP Clients',
+    name: 'Profile Name',
+    nameRequired: 'Please enter profile name',
+    tls: 'TLS',
+    caCert: 'CA Certificate',
+    clientCert: 'Client Certificate',
+    clientKey: 'Client Key',
+    clientKeyKeep: 'Already set, leave empty to keep',
+    pemPlaceholder: 'PEM format, optional',
+    skipVerify: 'Skip Verification',
+    proxyUrl: 'Proxy URL',
+    proxyUrlPlaceholder: 'e.g. http://proxy:3128 or socks5://proxy:1080, optional',
+    redirect: 'Redirects',
+    redirectFollow: 'Follow',
+    redirectNone: 'Do not follow',
+    maxRedirects: 'Max Redirects',
+    confirmDelete: 'Delete this HTTP client profile?'
+  },
+  datasource: {
+    menu: 'Datasources',
+    name: 'Name',
+    nameRequired: 'Please enter datasource name',
+    engine: 'Database Type',
+    address: 'Address',
+    host: 'Host',
+    port: 'Port',
+    user: 'User',
+    password: 'Password',
+    passwordKeep: 'Already set, leave empty to keep',
+    database: 'Database',
+    databaseRequired: 'Please enter database name or database file path',
+    file: 'Database File',
+    testConnection: 'Test Connection',
+    confirmDelete: 'Are you sure to delete this datasource?'
+  },
+  calendar: {
+    menu: 'Calendars',
+    name: 'Calendar Name',
+    nameRequired: 'Please enter calendar name',
+    dates: 'Dates',
+    datesPlaceholder: 'One date or date range per line, optionally followed by a summary, e.g.:\n2024-01-01 New Year\n2024-12-24~2024-12-26 Christmas',
+    file: 'ics File',
+    importIcs: 'Import ics',
+    importReplaceTip: 'Importing replaces all dates of this calendar',
+    importSuccess: 'Imported {count} dates',
+    confirmDelete: 'Are you sure to delete this calendar?'
+  },
+  workflow: {
+    menu: 'Workflows',
+    name: 'Workflow Name',
+    nameRequired: 'Please enter workflow name',
+    specPlaceholder: 'Leave empty to run manually only',
+    manualOnly: 'Manual only',
+    nodes: 'Nodes',
+    nodeKey: 'Node Key',
+    nodeName: 'Node Name',
+    nodeTask: 'Task',
+    joinNode: 'Join node (no task)',
+    joinMode: 'Join Mode',
+    joinAll: 'All upstream',
+    joinAny: 'Any upstream',
+    addNode: 'Add Node',
+    nodesRequired: 'Please add at least one node',
+    edges: 'Edges',
+    fromNode: 'From',
+    toNode: 'To',
+    condition: 'Condition',
+    onSuccess: 'On success',
+    onFailure: 'On failure',
+    always: 'Always',
+    addEdge: 'Add Edge',
+    runs: 'Runs',
+    viewLogs: 'Task Logs',
+    confirmRun: 'Are you sure to run workflow {name}?',
+    started: 'Started, please check the runs',
+    confirmDelete: 'Are you sure to delete workflow {name}?'
   },
   twoFactor: {
     title: 'Two-Factor Authentication (2FA)',
@@ -313,6 +575,11 @@ export default {
     pleaseEnterValidTimeout: 'Please enter valid task timeout',
     pleaseEnterValidRetryTimes: 'Please enter valid retry times',
     pleaseEnterValidRetryInterval: 'Please enter valid retry interval',
+    pleaseEnterValidMisfireMaxRuns: 'Please enter valid max catch-up runs',
+    pleaseEnterValidSlaMaxDuration: 'Please enter valid expected max duration',
+    pleaseEnterValidPingGrace: 'Please enter valid heartbeat grace',
+    pleaseEnterValidRetryMaxInterval: 'Please enter valid max retry interval',
+    pleaseEnterValidRetryJitter: 'Please enter valid jitter ratio',
     pleaseEnterNotifyKeyword: 'Please enter notification keyword',
     pleaseEnterUrl: 'Please enter URL',
     pleaseEnterShellCommand: 'Please enter shell command',
@@ -53,25 +53,152 @@ export default {
     type: '任务类型',
     mainTask: '主任务',
     childTask: '子任务',
-    dependency: '依赖关系',
-    strongDependency: '强依赖',
-    weakDependency: '弱依赖',
-    childTaskId: '子任务ID',
-    childTaskIdPlaceholder: '多个ID逗号分隔',
     cronExpression: 'crontab表达式',
     cronPlaceholder: '秒 分 时 天 月 周',
+    timezone: '时区',
+    timezonePlaceholder: '如 Asia/Shanghai, 默认使用服务器时区',
     cronExample: '示例',
+    cronPreview: '表达式预览',
+    cronNextTimes: '之后执行时间',
     protocol: '执行方式',
     httpMethod: '请求方法',
+    httpProfile: 'HTTP客户端配置',
+    httpProfileDefault: '默认',
+    sqlDatasource: '数据源',
+    sqlDatasourcePlaceholder: '请选择数据源',
+    sqlFailOnRows: '查询返回数据时失败',
+    sqlStatements: 'SQL语句',
+    sqlStatementsRequired: '请输入SQL语句',
+    sqlStatementsPlaceholder: '多条语句以分号分隔, 支持命令模板',
+    sqlTip: '语句在同一个连接上按顺序执行, 任一语句失败时停止; 查询语句记录返回行数及前20行数据, 其他语句记录影响行数; 开启查询返回数据时失败后, 可用于检查数据, 如 SELECT id FROM orders WHERE status = \'pending\' AND created < ...; 未设置超时时间时默认300秒',
+    httpAsync: '异步执行',
+    httpDeadline: '等待回调(秒)',
+    httpAsyncTip: '请求成功后任务保持执行中, 等待远程系统回调: POST 请求头 X-Gocron-Callback-Url 中的回调地址(需配置 callback.url), 内容为 status=success 或 failure 及 output, 支持JSON或表单; 回调令牌通过请求头 X-Gocron-Callback-Token 及命令模板变量 .CallbackToken、.CallbackUrl 传递; 超过等待时间未回调时执行失败, 0为3600秒',
+    httpHeaders: '请求头',
+    httpHeadersPlaceholder: '每行一个, 如 X-Token: abc',
+    httpBodyType: '请求体',
+    httpBodyNone: '无',
+    httpBodyRaw: '原始文本',
+    httpBodyForm: '表单',
+    httpBody: '请求体内容',
+    httpAuthType: '认证方式',
+    httpAuthNone: '不认证',
+    httpAuthUser: '用户名',
+    httpAuthPassword: '密码',
+    httpAuthToken: '令牌',
+    httpStatusCodes: '成功状态码',
+    httpStatusCodesPlaceholder: '默认200-299, 如 200,201,300-399',
+    httpAssertRegex: '响应匹配正则',
+    httpAssertRegexPlaceholder: '响应内容需匹配的正则, 可不填',
+    httpJsonPath: '响应JSON字段',
+    httpJsonPathPlaceholder: '如 data.items[0].status, 可不填',
+    httpJsonValue: '字段值等于',
+    httpTip: '请求地址、请求体支持命令模板; 状态码在成功状态码中且响应断言通过时任务执行成功, 断言失败的错误类型为响应断言失败; 未设置超时时间时最长执行300秒',
     taskNode: '任务节点',
     taskNodePlaceholder: '请选择任务节点',
     command: '命令',
     timeout: '任务超时时间',
     singleInstance: '单实例运行',
+    overlapPolicy: '上次未结束时',
+    overlapSkip: '跳过本次执行',
+    overlapQueue: '排队, 上次结束后执行',
+    overlapReplace: '停止上次执行并重新执行',
+    hostStrategy: '执行主机',
+    hostStrategyAll: '所有主机执行',
+    hostStrategyRandom: '随机选择一台',
+    hostStrategyRoundRobin: '轮询',
+    hostStrategyLeastRecent: '最久未执行的主机',
+    hostStrategyFailover: '故障转移(按顺序, 不可用时使用下一台)',
+    hostTarget: '主机范围',
+    hostTargetList: '指定主机',
+    hostTargetSelector: '标签选择器',
+    hostSelectorPlaceholder: '格式 key=value,key=value, 每次执行时选择标签全部匹配的主机',
+    rollingBatch: '每批主机数',
+    rollingPause: '批次间隔(秒)',
+    rollingMaxFail: '允许失败主机数',
+    rollingFailRate: '允许失败比例(%)',
+    rollingTip: '滚动执行: 按主机顺序每批同时执行设置数量的主机, 失败主机数超过允许值时停止执行剩余主机; 允许失败比例大于0时按比例判断',
+    hostSuccessRule: '成功条件',
+    hostSuccessAll: '所有主机执行成功',
+    hostSuccessAny: '任意一台主机执行成功',
+    hostSuccessQuorum: '超过半数主机执行成功',
+    backfill: '回填',
+    backfillRecords: '回填记录',
+    backfillTip: '按任务「{name}」的时间表达式执行时间范围内的每个时间点, 时间按任务时区',
+    backfillRange: '时间范围',
+    backfillStartTime: '开始时间',
+    backfillEndTime: '结束时间',
+    backfillParallelism: '并发数',
+    backfillRangeRequired: '请选择时间范围',
+    backfillStarted: '回填已开始',
+    backfillProgress: '进度',
+    backfillCancel: '取消回填',
+    backfillCancelConfirm: '确定取消回填? 执行中的任务将被停止',
     retryTimes: '任务失败重试次数',
     retryTimesPlaceholder: '0 - 10, 默认0，不重试',
     retryInterval: '任务失败重试间隔时间',
     retryIntervalPlaceholder: '0 - 3600 (秒), 默认0，执行系统默认策略',
+    retryStrategy: '重试间隔策略',
+    retryDefault: '默认',
+    retryFixed: '固定间隔',
+    retryLinear: '线性递增',
+    retryExponential: '指数退避',
+    retryMaxInterval: '最大重试间隔',
+    retryMaxIntervalPlaceholder: '0 - 86400 (秒), 默认0, 不限制',
+    retryJitter: '随机抖动',
+    retryJitterPlaceholder: '0 - 100 (%), 间隔在该比例内随机浮动',
+    retryOn: '重试的错误类型',
+    retryOnPlaceholder: '不选择则所有失败都重试',
+    errorUnavailable: '无法连接',
+    errorTimeout: '执行超时',
+    errorHttp5xx: 'HTTP 5xx',
+    errorHttp4xx: 'HTTP 4xx',
+    errorExit: '命令执行失败(退出码非0)',
+    errorOther: '其他错误',
+    errorAssert: '响应断言失败、SQL查询返回数据',
+    misfirePolicy: '错过执行策略',
+    misfireSkip: '跳过',
+    misfireRunOnce: '补偿执行一次',
+    misfireRunAll: '补偿执行每一次',
+    misfireMaxRuns: '最多补偿次数',
+    misfireMaxRunsPlaceholder: '0 - 100, 默认0, 最多补偿10次',
+    slaMaxDuration: '预期最长执行时间',
+    slaMaxDurationPlaceholder: '0 - 86400 (秒), 超出时通过任务通知发送告警, 默认0, 不检查',
+    slaDeadline: '最晚完成时间',
+    slaDeadlinePlaceholder: '每天该时间前未执行成功时发送告警',
+    protocolHeartbeat: '被动心跳',
+    pingGrace: '心跳宽限时间',
+    pingGracePlaceholder: '0 - 86400 (秒), 预期时间点后等待心跳的时间',
+    pingUrl: '心跳地址',
+    pingUrlAfterSave: '保存后生成心跳地址',
+    resetPingToken: '重新生成',
+    confirmResetPingToken: '重新生成后原心跳地址将失效, 确定重新生成?',
+    heartbeatTip: '任务表达式为预期收到心跳的时间点, 外部任务执行完成后通过GET或POST请求心跳地址, POST请求的内容记录为执行输出; 宽限时间结束前未收到心跳时按通知配置发送通知',
+    rerunInterrupted: '中断后重新执行',
+    runArgs: '追加参数',
+    runArgsPlaceholder: '按空格分隔, 每个参数加引号后追加到命令末尾, 只对本次执行生效',
+    runArgsHttpPlaceholder: '追加到URL的查询参数, 如 a=1&b=2',
+    runEnv: '环境变量',
+    runEnvPlaceholder: '每行一个, 格式 KEY=VALUE',
+    runEnvInvalid: '环境变量格式错误, 每行一个 KEY=VALUE',
+    runHosts: '执行主机',
+    runHostsPlaceholder: '不选择则在任务的所有主机上执行',
+    runTimeoutPlaceholder: '不填写则使用任务配置的超时时间',
+    scheduleType: '调度方式',
+    scheduleCron: '周期执行',
+    scheduleOnce: '一次性',
+    runAt: '执行时间',
+    runAtPlaceholder: '请选择执行时间, 按任务时区',
+    onceAction: '执行后',
+    onceKeep: '保留任务',
+    onceDisable: '禁用任务',
+    onceDelete: '删除任务',
+    excludeCalendars: '排除日历',
+    excludeCalendarsPlaceholder: '日历中的日期不执行',
+    includeCalendars: '限定日历',
+    includeCalendarsPlaceholder: '只在日历中的日期执行',
+    concurrencyGroups: '并发限制组',
+    concurrencyGroupsPlaceholder: '不选择则只受全局并发数限制',
     notification: '任务通知',
     notifyType: '通知类型',
     notifyReceiver: '接收用户',
@@ -87,8 +214,9 @@ export default {
     viewLog: '查看日志',
     enable: '启用',
     disable: '禁用',
-    mainTaskTip: '主任务可以配置多个子任务, 当主任务执行完成后，自动执行子任务\\n任务类型新增后不能变更',
-    dependencyTip: '强依赖: 主任务执行成功，才会运行子任务\\n弱依赖: 无论主任务执行是否成功，都会运行子任务',
+    mainTaskTip: '主任务按表达式定时执行, 子任务不会被定时执行, 可作为工作流节点执行\\n任务类型新增后不能变更',
+    dependencyTip: '任务之间的依赖关系请在工作流中配置, 原有的子任务配置已迁移为工作流',
+    commandTemplateTip: '命令中可使用模板变量: ScheduledTime(计划执行时间), StartTime(开始时间), TaskLogId, TaskId, TaskName, Host(主机别名), HostName, Attempt(第几次执行), 如前一天的日期 {example}; 拼接到shell命令中的变量使用shellquote加引号, 拼接到URL中的变量使用urlquery转义, 如 {quoteExample}',
     timeoutTip: '任务执行超时强制结束, 取值0-86400(秒), 默认0, 不限制',
     singleInstanceTip: '单实例运行, 前次任务未执行完成，下次任务调度时间到了是否要执行, 即是否允许多进程执行同一任务',
     cronStandard: '标准语法（秒 分 时 天 月 周）',
@@ -108,6 +236,24 @@ export default {
     alias: '别名',
     port: '端口',
     remark: '备注',
+    labels: '标签',
+    labelsPlaceholder: '格式 key=value,key=value, 例如 role=db,env=prod',
+    sshPort: 'SSH端口',
+    sshUser: 'SSH用户',
+    sshUserPlaceholder: '填写后可执行SSH协议的任务, 无需部署gocron-node',
+    sshAuth: '认证方式',
+    sshAuthPassword: '密码',
+    sshAuthKey: '私钥',
+    sshPassword: 'SSH密码',
+    sshKey: 'SSH私钥',
+    sshPassphrase: '私钥密码',
+    sshSecretKeep: '已保存, 留空不修改',
+    hostKey: '主机公钥',
+    fetchHostKey: '获取主机公钥',
+    hostKeyTip: '用于校验主机身份, 可在主机上执行 ssh-keyscan 或 cat /etc/ssh/ssh_host_ed25519_key.pub 获取',
+    hostKeyConfirm: '请确认获取的主机公钥与主机上的公钥一致后保存',
+    sshTest: '测试SSH',
+    installLabelsTip: "为主机设置标签时, 在bash前添加环境变量 GOCRON_LABELS, 例如 GOCRON_LABELS='role=db,env=prod' bash",
     createTime: '创建时间',
     createNew: '新增节点',
     namePlaceholder: '请输入主机名',
@@ -225,7 +371,123 @@ export default {
     output: '执行输出',
     success: '成功',
     failed: '失败',
-    viewOutput: '查看输出'
+    viewOutput: '查看输出',
+    triggerType: '触发方式',
+    triggerCron: '定时调度',
+    triggerManual: '手动执行',
+    triggerDependency: '依赖任务',
+    triggerMisfire: '错过补偿',
+    triggerWorkflow: '工作流',
+    triggerRerun: '中断后重新执行',
+    triggerPing: '心跳请求',
+    triggerBackfill: '回填',
+    backfillId: '回填记录',
+    overrides: '覆盖参数',
+    replay: '按此参数重新执行',
+    confirmReplay: '确定按此日志的参数重新执行任务?',
+    workflowRunId: '工作流执行记录',
+    attempts: '每次执行结果',
+    hostResults: '每台主机执行结果',
+    host: '主机',
+    attemptNo: '执行次数',
+    errorType: '错误类型',
+    attempt: '第{n}次执行',
+    scheduledTime: '计划执行时间',
+    skipped: '跳过',
+    queued: '排队中',
+    interrupted: '中断'
+  },
+  concurrencyGroup: {
+    menu: '并发限制',
+    name: '组名称',
+    nameRequired: '请输入组名称',
+    maxConcurrency: '最大并发数',
+    maxConcurrencyRequired: '请输入1-1000的最大并发数',
+    scope: '限制范围',
+    scopeGroup: '组内任务共享',
+    scopeHost: '每台主机',
+    scopeTask: '每个任务',
+    tag: '自动加入的标签',
+    tagPlaceholder: '该标签的任务自动加入本组',
+    confirmDelete: '确定删除该并发限制组?'
+  },
+  httpProfile: {
+    menu: 'HTTP客户端',
+    name: '配置名称',
+    nameRequired: '请输入配置名称',
+    tls: 'TLS',
+    caCert: 'CA证书',
+    clientCert: '客户端证书',
+    clientKey: '客户端私钥',
+    clientKeyKeep: '已设置, 不修改请留空',
+    pemPlaceholder: 'PEM格式, 可不填',
+    skipVerify: '跳过证书校验',
+    proxyUrl: '代理地址',
+    proxyUrlPlaceholder: '如 http://proxy:3128 或 socks5://proxy:1080, 可不填',
+    redirect: '重定向',
+    redirectFollow: '跟随重定向',
+    redirectNone: '不跟随',
+    maxRedirects: '最大重定向次数',
+    confirmDelete: '确定删除该HTTP客户端配置?'
+  },
+  datasource: {
+    menu: '数据源',
+    name: '数据源名称',
+    nameRequired: '请输入数据源名称',
+    engine: '数据库类型',
+    address: '地址',
+    host: '主机',
+    port: '端口',
+    user: '用户名',
+    password: '密码',
+    passwordKeep: '已设置, 不修改请留空',
+    database: '数据库名',
+    databaseRequired: '请输入数据库名或数据库文件路径',
+    file: '数据库文件',
+    testConnection: '测试连接',
+    confirmDelete: '确定删除该数据源?'
+  },
+  calendar: {
+    menu: '日历管理',
+    name: '日历名称',
+    nameRequired: '请输入日历名称',
+    dates: '日期',
+    datesPlaceholder: '每行一个日期或日期范围, 可跟说明, 如:\n2024-01-01 元旦\n2024-10-01~2024-10-07 国庆节',
+    file: 'ics文件',
+    importIcs: '导入ics',
+    importReplaceTip: '导入将替换该日历的全部日期',
+    importSuccess: '导入成功, 共{count}个日期',
+    confirmDelete: '确定删除此日历?'
+  },
+  workflow: {
+    menu: '工作流',
+    name: '工作流名称',
+    nameRequired: '请输入工作流名称',
+    specPlaceholder: '留空则只能手动运行',
+    manualOnly: '仅手动运行',
+    nodes: '节点',
+    nodeKey: '节点标识',
+    nodeName: '节点名称',
+    nodeTask: '任务',
+    joinNode: '汇合节点(不执行任务)',
+    joinMode: '汇合方式',
+    joinAll: '所有上游满足',
+    joinAny: '任一上游满足',
+    addNode: '添加节点',
+    nodesRequired: '请至少添加一个节点',
+    edges: '连线',
+    fromNode: '上游节点',
+    toNode: '下游节点',
+    condition: '执行条件',
+    onSuccess: '上游成功',
+    onFailure: '上游失败',
+    always: '总是执行',
+    addEdge: '添加连线',
+    runs: '执行记录',
+    viewLogs: '查看任务日志',
+    confirmRun: '确定运行工作流 {name} 吗?',
+    started: '已开始运行, 请查看执行记录',
+    confirmDelete: '确定删除工作流 {name} 吗?'
   },
   twoFactor: {
     title: '双因素认证 (2FA)',
@@ -313,6 +575,11 @@ export default {
     pleaseEnterValidTimeout: '请输入有效的任务超时时间',
     pleaseEnterValidRetryTimes: '请输入有效的任务执行失败重试次数',
     pleaseEnterValidRetryInterval: '请输入有效的任务执行失败，重试间隔时间',
+    pleaseEnterValidMisfireMaxRuns: '请输入有效的最多补偿次数',
+    pleaseEnterValidSlaMaxDuration: '请输入有效的预期最长执行时间',
+    pleaseEnterValidPingGrace: '请输入有效的心跳宽限时间',
+    pleaseEnterValidRetryMaxInterval: '请输入有效的最大重试间隔',
+    pleaseEnterValidRetryJitter: '请输入有效的随机抖动比例',
     pleaseEnterNotifyKeyword: '请输入要匹配的任务执行输出关键字',
     pleaseEnterUrl: '请输入URL地址',
     pleaseEnterShellCommand: '请输入shell命令',
@@ -39,6 +39,51 @@ const routes = [
     component: () => import('../pages/taskLog/list.vue'),
     meta: { noNeedAdmin: true }
   },
+  {
+    path: '/task/calendar',
+    name: 'task-calendar',
+    component: () => import('../pages/calendar/list.vue')
+  },
+  {
+    path: '/task/concurrency-group',
+    name: 'task-concurrency-group',
+    component: () => import('../pages/concurrencyGroup/list.vue')
+  },
+  {
+    path: '/task/http-profile',
+    name: 'task-http-profile',
+    component: () => import('../pages/httpProfile/list.vue')
+  },
+  {
+    path: '/task/datasource',
+    name: 'task-datasource',
+    component: () => import('../pages/datasource/list.vue')
+  },
+  {
+    path: '/task/workflow',
+    name: 'task-workflow',
+    component: () => import('../pages/workflow/list.vue')
+  },
+  {
+    path: '/task/workflow/create',
+    name: 'task-workflow-create',
+    component: () => import('../pages/workflow/edit.vue')
+  },
+  {
+    path: '/task/workflow/edit/:id',
+    name: 'task-workflow-edit',
+    component: () => import('../pages/workflow/edit.vue')
+  },
+  {
+    path: '/task/backfill',
+    name: 'task-backfill',
+    component: () => import('../pages/task/backfill.vue')
+  },
+  {
+    path: '/task/workflow/runs',
+    name: 'task-workflow-runs',
+    component: () => import('../pages/workflow/runs.vue')
+  },
   {
     path: '/host',
     name: 'host-list',
@@ -87,9 +87,9 @@ function failureCallback (error) {
 }
 
 export default {
-  get (uri, params, next) {
+  get (uri, params, next, errorCallback) {
     const promise = axios.get(uri, {params})
-    handle(promise, next)
+    handle(promise, next, errorCallback)
   },
 
   batchGet (uriGroup, next) {
@@ -123,6 +123,15 @@ export default {
     handle(promise, next, errorCallback)
   },
 
+  upload (uri, formData, next, errorCallback) {
+    const promise = axios.post(uri, formData, {
+      headers: {
+        'Content-Type': 'multipart/form-data'
+      }
+    })
+    handle(promise, next, errorCallback)
+  },
+
   postJson (uri, data, next, errorCallback) {
     const promise = axios.post(uri, data, {
       headers: {